@@ -7,7 +7,6 @@ require (
 	github.com/gin-gonic/gin v1.7.4
 	github.com/go-chi/chi/v5 v5.0.4
 	github.com/go-chi/cors v1.2.0
-	github.com/gofiber/adaptor/v2 v2.1.18
 	github.com/gofiber/fiber/v2 v2.27.0
 	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.8.0
@@ -15,10 +14,22 @@ require (
 	github.com/labstack/echo/v4 v4.6.1
 	github.com/osohq/go-oso v0.21.0
 	github.com/spf13/viper v1.8.1
-	github.com/supertokens/supertokens-golang v0.0.0-20210909070424-b13c10ce5994
+	github.com/supertokens/supertokens-golang v0.9.14
+	github.com/supertokens/supertokens-golang/supertokens/adapters/echoadapter v0.0.0-00010101000000-000000000000
+	github.com/supertokens/supertokens-golang/supertokens/adapters/fiberadapter v0.0.0-00010101000000-000000000000
+	github.com/supertokens/supertokens-golang/supertokens/adapters/ginadapter v0.0.0-00010101000000-000000000000
+	github.com/supertokens/supertokens-golang/supertokens/adapters/twirpadapter v0.0.0-00010101000000-000000000000
 	github.com/twitchtv/twirp v8.1.0+incompatible
 	github.com/zeromicro/go-zero v1.3.5
 	google.golang.org/protobuf v1.28.0
 )
 
 replace github.com/supertokens/supertokens-golang => ../
+
+replace github.com/supertokens/supertokens-golang/supertokens/adapters/echoadapter => ../supertokens/adapters/echoadapter
+
+replace github.com/supertokens/supertokens-golang/supertokens/adapters/fiberadapter => ../supertokens/adapters/fiberadapter
+
+replace github.com/supertokens/supertokens-golang/supertokens/adapters/ginadapter => ../supertokens/adapters/ginadapter
+
+replace github.com/supertokens/supertokens-golang/supertokens/adapters/twirpadapter => ../supertokens/adapters/twirpadapter
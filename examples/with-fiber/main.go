@@ -2,20 +2,18 @@ package main
 
 import (
 	"log"
-	"net/http"
 
-	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
-	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/adapters/fiberadapter"
 )
 
 func main() {
@@ -98,7 +96,9 @@ func main() {
 	if err != nil {
 		log.Fatal("Something went wrong while starting up supertokens: ", err.Error())
 	}
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: fiberadapter.ErrorHandler(fiber.DefaultErrorHandler),
+	})
 
 	allowedHeaders := append([]string{"Content-Type"}, supertokens.GetAllCORSHeaders()...)
 	allowedHeadersInCommaSeparetedStringFormat := stringArrayToStringConvertor(allowedHeaders)
@@ -111,32 +111,14 @@ func main() {
 	}))
 
 	//adding the supertokens middleware
-	app.Use(adaptor.HTTPMiddleware(supertokens.Middleware))
+	app.Use(fiberadapter.Middleware())
 
-	app.Get("/sessionInfo", verifySession(nil), sessioninfo)
+	app.Get("/sessionInfo", fiberadapter.VerifySession(nil), sessioninfo)
 	log.Fatal(app.Listen(":3001"))
 }
 
-// wrapper of the original implementation of verify session to match the required function signature
-func verifySession(options *sessmodels.VerifySessionOptions) fiber.Handler {
-	shouldCallNext := false
-	return func(c *fiber.Ctx) error {
-		err := adaptor.HTTPHandlerFunc(session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
-			c.SetUserContext(r.Context())
-			shouldCallNext = true
-		}))(c)
-		if err != nil {
-			return err
-		}
-		if shouldCallNext {
-			return c.Next()
-		}
-		return nil
-	}
-}
-
 func sessioninfo(c *fiber.Ctx) error {
-	sessionContainer := session.GetSessionFromRequestContext(c.UserContext())
+	sessionContainer := fiberadapter.GetSession(c)
 	if sessionContainer == nil {
 		return c.Status(500).JSON("no session found")
 	}
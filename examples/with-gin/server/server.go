@@ -1,15 +1,13 @@
 package server
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/supertokens/supertokens-golang/examples/with-gin/config"
-	"github.com/supertokens/supertokens-golang/recipe/session"
-	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/adapters/ginadapter"
 )
 
 func Init() {
@@ -29,16 +27,10 @@ func Init() {
 	}))
 
 	// Adding the SuperTokens middleware
-	router.Use(func(c *gin.Context) {
-		supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-			c.Next()
-		})).ServeHTTP(c.Writer, c.Request)
-		// we call Abort so that the next handler in the chain is not called, unless we call Next explicitly
-		c.Abort()
-	})
+	router.Use(ginadapter.ErrorHandler(), ginadapter.Middleware())
 
 	// Adding an API that requires session verification
-	router.GET("/sessioninfo", verifySession(nil), sessioninfo)
+	router.GET("/sessioninfo", ginadapter.VerifySession(nil), sessioninfo)
 
 	// starting the server
 	err := router.Run(config.GetString("server.apiPort"))
@@ -47,32 +39,16 @@ func Init() {
 	}
 }
 
-// This is a function that wraps the supertokens verification function
-// to work the gin
-func verifySession(options *sessmodels.VerifySessionOptions) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
-			c.Request = c.Request.WithContext(r.Context())
-			c.Next()
-		})(c.Writer, c.Request)
-		// we call Abort so that the next handler in the chain is not called, unless we call Next explicitly
-		c.Abort()
-	}
-}
-
 func sessioninfo(c *gin.Context) {
-	sessionContainer := session.GetSessionFromRequestContext(c.Request.Context())
+	sessionContainer := ginadapter.GetSession(c)
 	if sessionContainer == nil {
 		c.JSON(500, "no session found")
 		return
 	}
 	sessionData, err := sessionContainer.GetSessionData()
 	if err != nil {
-		err = supertokens.ErrorHandler(err, c.Request, c.Writer)
-		if err != nil {
-			c.JSON(500, err.Error())
-			return
-		}
+		// ginadapter.ErrorHandler sends the response for errors from SuperTokens
+		c.Error(err)
 		return
 	}
 	c.JSON(200, map[string]interface{}{
@@ -3,19 +3,18 @@ package main
 import (
 	"encoding/json"
 	"errors"
-	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
-	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/adapters/echoadapter"
 )
 
 func main() {
@@ -120,47 +119,24 @@ func main() {
 	})
 
 	// SuperTokens Middleware
-	e.Use(func(hf echo.HandlerFunc) echo.HandlerFunc {
-		return echo.HandlerFunc(func(c echo.Context) error {
-			supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-				if err := hf(c); err != nil {
-					c.Error(err)
-				}
-			})).ServeHTTP(c.Response(), c.Request())
-			return nil
-		})
-	})
+	e.HTTPErrorHandler = echoadapter.ErrorHandler(e.DefaultHTTPErrorHandler)
+	e.Use(echoadapter.Middleware())
 
-	e.GET("/sessioninfo", sessioninfo, verifySession(nil))
+	e.GET("/sessioninfo", sessioninfo, echoadapter.VerifySession(nil))
 
 	e.Start(":3001")
 }
 
-func verifySession(options *sessmodels.VerifySessionOptions) echo.MiddlewareFunc {
-	return func(hf echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
-				c.Set("session", session.GetSessionFromRequestContext(r.Context()))
-				hf(c)
-			})(c.Response(), c.Request())
-			return nil
-		}
-	}
-}
-
 func sessioninfo(c echo.Context) error {
-	sessionContainer := c.Get("session").(sessmodels.SessionContainer)
+	sessionContainer := echoadapter.GetSession(c)
 
 	if sessionContainer == nil {
 		return errors.New("no session found")
 	}
 	sessionData, err := sessionContainer.GetSessionData()
 	if err != nil {
-		err = supertokens.ErrorHandler(err, c.Request(), c.Response())
-		if err != nil {
-			return err
-		}
-		return nil
+		// echoadapter.ErrorHandler sends the response for errors from SuperTokens
+		return err
 	}
 	c.Response().WriteHeader(200)
 	c.Response().Header().Add("content-type", "application/json")
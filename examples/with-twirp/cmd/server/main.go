@@ -22,7 +22,6 @@ import (
 	"github.com/supertokens/supertokens-golang/examples/with-twirp/haberdasher"
 	"github.com/supertokens/supertokens-golang/examples/with-twirp/internal/haberdasherserver"
 	"github.com/supertokens/supertokens-golang/examples/with-twirp/internal/hooks"
-	"github.com/supertokens/supertokens-golang/examples/with-twirp/internal/interceptor"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
@@ -32,6 +31,7 @@ import (
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/supertokens/adapters/twirpadapter"
 	"github.com/twitchtv/twirp"
 )
 
@@ -120,15 +120,15 @@ func main() {
 
 	hook := hooks.LoggingHooks(os.Stderr)
 	service := haberdasherserver.New()
-	server := haberdasher.NewHaberdasherServer(service,
-		twirp.WithServerInterceptors(interceptor.NewSuperTokensErrorHandlerInterceptor()), hook)
 	sessionRequired := false
+	server := haberdasher.NewHaberdasherServer(service,
+		twirp.WithServerInterceptors(twirpadapter.Interceptor(&sessmodels.VerifySessionOptions{
+			SessionRequired: &sessionRequired,
+		})), hook)
 	log.Fatal(http.ListenAndServe(":3001", handlers.CORS(
 		handlers.AllowedHeaders(append([]string{"Content-Type"}, supertokens.GetAllCORSHeaders()...)),
 		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS"}),
 		handlers.AllowedOrigins([]string{"http://localhost:3000"}),
 		handlers.AllowCredentials(),
-	)(supertokens.Middleware(session.VerifySession(&sessmodels.VerifySessionOptions{
-		SessionRequired: &sessionRequired,
-	}, server.ServeHTTP)))))
+	)(twirpadapter.Middleware(server))))
 }
@@ -19,7 +19,7 @@ import (
 	"math/rand"
 
 	"github.com/supertokens/supertokens-golang/examples/with-twirp/haberdasher"
-	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens/adapters/twirpadapter"
 	"github.com/twitchtv/twirp"
 )
 
@@ -35,7 +35,7 @@ func New() *randomHaberdasher {
 type randomHaberdasher struct{}
 
 func (h *randomHaberdasher) MakeHat(ctx context.Context, size *haberdasher.Size) (*haberdasher.Hat, error) {
-	sessionContainer := session.GetSessionFromRequestContext(ctx)
+	sessionContainer := twirpadapter.GetSession(ctx)
 	if sessionContainer == nil {
 		fmt.Println("no session exists!")
 	} else {
@@ -0,0 +1,284 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package deliveryqueue sends emails and SMSs in the background, so that a slow or failing
+// delivery service does not fail or slow down the requests that trigger them. It is used through
+// emaildelivery.Queue and smsdelivery.Queue.
+package deliveryqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+var ErrQueueClosed = errors.New("delivery queue is shut down")
+var ErrQueueFull = errors.New("delivery queue is full")
+
+// Job is an email or SMS that has not been delivered yet.
+type Job[T any] struct {
+	ID    string
+	Input T
+	// UserContext is a copy of the user context that the delivery was requested with, without
+	// the request. Stores that persist jobs should drop its "_default" key.
+	UserContext   map[string]interface{}
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+// Store persists the jobs that have not been delivered yet, so that they can be resumed after a
+// restart. The default store keeps them in memory.
+type Store[T any] interface {
+	// Save adds the job, or replaces the job with the same ID.
+	Save(job Job[T]) error
+	Remove(id string) error
+	// GetAll returns all jobs that were saved and not removed.
+	GetAll() ([]Job[T], error)
+}
+
+type Config[T any] struct {
+	// Workers is the number of jobs that are delivered concurrently. It defaults to 4.
+	Workers int
+
+	// BufferSize is the number of jobs that can wait for a worker before new jobs are rejected
+	// with ErrQueueFull. It defaults to 1000.
+	BufferSize int
+
+	// MaxAttempts is the number of times delivery of a job is attempted before it is given to
+	// OnDeadLetter. It defaults to 5.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It doubles with every retry, up to
+	// MaxBackoff. They default to 1 second and 5 minutes.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	Store Store[T]
+
+	// OnDeadLetter is called with jobs that could not be delivered in MaxAttempts attempts, and
+	// the error of the last attempt.
+	OnDeadLetter func(job Job[T], err error)
+}
+
+type Queue[T any] struct {
+	config   Config[T]
+	deliver  func(input T, userContext supertokens.UserContext) error
+	jobs     chan Job[T]
+	stop     chan struct{}
+	pending  sync.WaitGroup
+	workers  sync.WaitGroup
+	mutex    sync.Mutex
+	started  bool
+	shutDown bool
+}
+
+func NewQueue[T any](config Config[T]) *Queue[T] {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.Store == nil {
+		config.Store = NewInMemoryStore[T]()
+	}
+	return &Queue[T]{
+		config: config,
+		jobs:   make(chan Job[T], config.BufferSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start starts the workers, which deliver jobs with deliver. Jobs that are in the store, for
+// example from before a restart, are resumed.
+func (q *Queue[T]) Start(deliver func(input T, userContext supertokens.UserContext) error) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.started {
+		return errors.New("delivery queue is already started")
+	}
+	if q.shutDown {
+		return ErrQueueClosed
+	}
+	savedJobs, err := q.config.Store.GetAll()
+	if err != nil {
+		return err
+	}
+	q.deliver = deliver
+	q.started = true
+
+	for i := 0; i < q.config.Workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+	for _, job := range savedJobs {
+		q.pending.Add(1)
+		q.schedule(job, time.Until(job.NextAttemptAt))
+	}
+	return nil
+}
+
+// Enqueue saves a job for the input and returns without waiting for it to be delivered.
+func (q *Queue[T]) Enqueue(input T, userContext supertokens.UserContext) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.shutDown {
+		return ErrQueueClosed
+	}
+	if len(q.jobs) >= cap(q.jobs) {
+		return ErrQueueFull
+	}
+	id, err := generateJobID()
+	if err != nil {
+		return err
+	}
+	job := Job[T]{
+		ID:            id,
+		Input:         input,
+		UserContext:   *supertokens.DetachUserContext(userContext),
+		NextAttemptAt: time.Now(),
+	}
+	err = q.config.Store.Save(job)
+	if err != nil {
+		return err
+	}
+	q.pending.Add(1)
+	q.jobs <- job
+	return nil
+}
+
+// Shutdown stops accepting new jobs and waits until all jobs, including their retries, are done
+// or ctx is done. Jobs that are not done stay in the store.
+func (q *Queue[T]) Shutdown(ctx context.Context) error {
+	q.mutex.Lock()
+	if q.shutDown {
+		q.mutex.Unlock()
+		return nil
+	}
+	q.shutDown = true
+	started := q.started
+	q.mutex.Unlock()
+
+	var err error
+	if started {
+		drained := make(chan struct{})
+		go func() {
+			q.pending.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	close(q.stop)
+	q.workers.Wait()
+	return err
+}
+
+func (q *Queue[T]) work() {
+	defer q.workers.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case job := <-q.jobs:
+			q.process(job)
+		}
+	}
+}
+
+func (q *Queue[T]) process(job Job[T]) {
+	userContext := map[string]interface{}{}
+	for k, v := range job.UserContext {
+		userContext[k] = v
+	}
+	err := q.deliverSafely(job.Input, &userContext)
+	job.Attempts++
+
+	if err == nil || job.Attempts >= q.config.MaxAttempts {
+		removeErr := q.config.Store.Remove(job.ID)
+		if removeErr != nil {
+			supertokens.LogDebugMessage("deliveryqueue: could not remove job " + job.ID + ": " + removeErr.Error())
+		}
+		if err != nil && q.config.OnDeadLetter != nil {
+			job.LastError = err.Error()
+			q.config.OnDeadLetter(job, err)
+		}
+		q.pending.Done()
+		return
+	}
+
+	delay := q.config.InitialBackoff << (job.Attempts - 1)
+	if delay > q.config.MaxBackoff || delay <= 0 {
+		delay = q.config.MaxBackoff
+	}
+	job.LastError = err.Error()
+	job.NextAttemptAt = time.Now().Add(delay)
+	saveErr := q.config.Store.Save(job)
+	if saveErr != nil {
+		supertokens.LogDebugMessage("deliveryqueue: could not save job " + job.ID + ": " + saveErr.Error())
+	}
+	q.schedule(job, delay)
+}
+
+func (q *Queue[T]) deliverSafely(input T, userContext supertokens.UserContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("delivery panicked: %v", r)
+		}
+	}()
+	return q.deliver(input, userContext)
+}
+
+// schedule puts the job back in the queue after the delay. If the queue is stopped before that,
+// the job stays in the store.
+func (q *Queue[T]) schedule(job Job[T], delay time.Duration) {
+	if delay < 0 {
+		delay = 0
+	}
+	time.AfterFunc(delay, func() {
+		select {
+		case q.jobs <- job:
+		case <-q.stop:
+		}
+	})
+}
+
+func generateJobID() (string, error) {
+	bytes := make([]byte, 16)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
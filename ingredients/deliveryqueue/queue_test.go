@@ -0,0 +1,137 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package deliveryqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestQueueDeliversJobsInTheBackground(t *testing.T) {
+	var delivered []string
+	var mutex sync.Mutex
+	queue := NewQueue(Config[string]{})
+	err := queue.Start(func(input string, userContext supertokens.UserContext) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delivered = append(delivered, input)
+		assert.Equal(t, "value", (*userContext)["key"])
+		return nil
+	})
+	assert.NoError(t, err)
+
+	for _, input := range []string{"a", "b", "c"} {
+		assert.NoError(t, queue.Enqueue(input, &map[string]interface{}{"key": "value"}))
+	}
+	assert.NoError(t, queue.Shutdown(context.Background()))
+
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, delivered)
+	jobs, err := queue.config.Store.GetAll()
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+	assert.Equal(t, ErrQueueClosed, queue.Enqueue("d", nil))
+}
+
+func TestQueueRetriesFailedJobs(t *testing.T) {
+	var attempts int32
+	queue := NewQueue(Config[string]{
+		InitialBackoff: time.Millisecond,
+	})
+	err := queue.Start(func(input string, userContext supertokens.UserContext) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("service unavailable")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, queue.Enqueue("a", nil))
+	assert.NoError(t, queue.Shutdown(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestQueueSendsFailedJobsToDeadLetter(t *testing.T) {
+	var deadLetters []Job[string]
+	var attempts int32
+	queue := NewQueue(Config[string]{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnDeadLetter: func(job Job[string], err error) {
+			deadLetters = append(deadLetters, job)
+		},
+	})
+	err := queue.Start(func(input string, userContext supertokens.UserContext) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			panic("unexpected")
+		}
+		return errors.New("service unavailable")
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, queue.Enqueue("a", nil))
+	assert.NoError(t, queue.Shutdown(context.Background()))
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	if assert.Len(t, deadLetters, 1) {
+		assert.Equal(t, "a", deadLetters[0].Input)
+		assert.Equal(t, 3, deadLetters[0].Attempts)
+		assert.Equal(t, "service unavailable", deadLetters[0].LastError)
+	}
+}
+
+func TestQueueResumesJobsFromStore(t *testing.T) {
+	store := NewInMemoryStore[string]()
+	queue := NewQueue(Config[string]{
+		Store:          store,
+		InitialBackoff: time.Hour,
+	})
+	assert.NoError(t, queue.Start(func(input string, userContext supertokens.UserContext) error {
+		return errors.New("service unavailable")
+	}))
+	assert.NoError(t, queue.Enqueue("a", nil))
+
+	// the retry is an hour away, so the job is still in the store after shutting down
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, queue.Shutdown(ctx))
+	jobs, err := store.GetAll()
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, 1, jobs[0].Attempts)
+	}
+
+	// a new queue with the same store, like after a restart, resumes the job when it is due
+	jobs[0].NextAttemptAt = time.Now().Add(10 * time.Millisecond)
+	assert.NoError(t, store.Save(jobs[0]))
+	var delivered []string
+	queue = NewQueue(Config[string]{
+		Store: store,
+	})
+	assert.NoError(t, queue.Start(func(input string, userContext supertokens.UserContext) error {
+		delivered = append(delivered, input)
+		return nil
+	}))
+	assert.Error(t, queue.Start(nil))
+	assert.NoError(t, queue.Shutdown(context.Background()))
+	assert.Equal(t, []string{"a"}, delivered)
+}
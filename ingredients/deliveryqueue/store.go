@@ -0,0 +1,53 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package deliveryqueue
+
+import "sync"
+
+type InMemoryStore[T any] struct {
+	jobs  map[string]Job[T]
+	mutex sync.Mutex
+}
+
+func NewInMemoryStore[T any]() *InMemoryStore[T] {
+	return &InMemoryStore[T]{
+		jobs: map[string]Job[T]{},
+	}
+}
+
+func (s *InMemoryStore[T]) Save(job Job[T]) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *InMemoryStore[T]) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *InMemoryStore[T]) GetAll() ([]Job[T], error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	jobs := make([]Job[T], 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,70 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type MailgunSettings struct {
+	APIKey string
+	Domain string
+	From   SMTPFrom
+
+	// BaseURL defaults to https://api.mailgun.net. Domains in the EU region must use
+	// https://api.eu.mailgun.net.
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func MailgunProvider(settings MailgunSettings) EmailProvider {
+	return EmailProvider{
+		SendRawEmail: func(input EmailContent, userContext supertokens.UserContext) error {
+			return SendMailgunEmail(settings, input, userContext)
+		},
+	}
+}
+
+func SendMailgunEmail(settings MailgunSettings, content EmailContent, userContext supertokens.UserContext) error {
+	baseURL := "https://api.mailgun.net"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	textBody, htmlBody := getTextAndHTMLBody(content)
+	form := url.Values{}
+	form.Set("from", formatFrom(settings.From))
+	form.Set("to", content.ToEmail)
+	form.Set("subject", content.Subject)
+	if textBody != "" {
+		form.Set("text", textBody)
+	}
+	if htmlBody != "" {
+		form.Set("html", htmlBody)
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/v3/"+url.PathEscape(settings.Domain)+"/messages", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", settings.APIKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return sendProviderRequest("mailgun", settings.HTTPClient, req)
+}
@@ -41,17 +41,27 @@ func MakeIngredient(config TypeInputWithService) Ingredient {
 }
 
 func SendSMTPEmail(settings SMTPSettings, content EmailContent) error {
+	return makeSMTPDialer(settings).DialAndSend(makeSMTPMessage(settings, content))
+}
+
+func makeSMTPMessage(settings SMTPSettings, content EmailContent) *gomail.Message {
 	m := gomail.NewMessage()
 	m.SetHeader("From", fmt.Sprintf("%s <%s>", settings.From.Name, settings.From.Email))
 	m.SetHeader("To", content.ToEmail)
 	m.SetHeader("Subject", content.Subject)
 
-	if content.IsHtml {
+	if content.IsHtml && content.PlainTextBody != "" {
+		m.SetBody("text/plain", content.PlainTextBody)
+		m.AddAlternative("text/html", content.Body)
+	} else if content.IsHtml {
 		m.SetBody("text/html", content.Body)
 	} else {
 		m.SetBody("text/plain", content.Body)
 	}
+	return m
+}
 
+func makeSMTPDialer(settings SMTPSettings) *gomail.Dialer {
 	username := settings.From.Email
 	if settings.Username != nil {
 		username = *settings.Username
@@ -63,5 +73,5 @@ func SendSMTPEmail(settings SMTPSettings, content EmailContent) error {
 		d.TLSConfig = &tls.Config{InsecureSkipVerify: true, ServerName: settings.Host}
 		d.SSL = true
 	}
-	return d.DialAndSend(m)
+	return d
 }
@@ -0,0 +1,81 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type PostmarkSettings struct {
+	ServerToken string
+	From        SMTPFrom
+
+	// MessageStream defaults to the transactional stream of the server.
+	MessageStream string
+
+	// BaseURL defaults to https://api.postmarkapp.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func PostmarkProvider(settings PostmarkSettings) EmailProvider {
+	return EmailProvider{
+		SendRawEmail: func(input EmailContent, userContext supertokens.UserContext) error {
+			return SendPostmarkEmail(settings, input, userContext)
+		},
+	}
+}
+
+func SendPostmarkEmail(settings PostmarkSettings, content EmailContent, userContext supertokens.UserContext) error {
+	baseURL := "https://api.postmarkapp.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	textBody, htmlBody := getTextAndHTMLBody(content)
+	email := map[string]string{
+		"From":    formatFrom(settings.From),
+		"To":      content.ToEmail,
+		"Subject": content.Subject,
+	}
+	if textBody != "" {
+		email["TextBody"] = textBody
+	}
+	if htmlBody != "" {
+		email["HtmlBody"] = htmlBody
+	}
+	if settings.MessageStream != "" {
+		email["MessageStream"] = settings.MessageStream
+	}
+	body, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/email", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Postmark-Server-Token", settings.ServerToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	return sendProviderRequest("postmark", settings.HTTPClient, req)
+}
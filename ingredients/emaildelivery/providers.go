@@ -0,0 +1,93 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// EmailProvider sends emails with the HTTP API of an email provider, like SendGrid.
+type EmailProvider struct {
+	SendRawEmail func(input EmailContent, userContext supertokens.UserContext) error
+}
+
+type ProviderServiceConfig struct {
+	Provider EmailProvider
+
+	// Templates configures the content of the emails, like for the SMTP services.
+	Templates *TemplateConfig
+	Override  func(originalImplementation SMTPInterface) SMTPInterface
+}
+
+// ToSMTPServiceConfig returns the config of an SMTP service that sends emails with the provider
+// instead of SMTP, so that the content of the emails is the same with all providers.
+func (config ProviderServiceConfig) ToSMTPServiceConfig() SMTPServiceConfig {
+	return SMTPServiceConfig{
+		Settings: SMTPSettings{
+			Templates: config.Templates,
+		},
+		Override: func(originalImplementation SMTPInterface) SMTPInterface {
+			sendRawEmail := config.Provider.SendRawEmail
+			originalImplementation.SendRawEmail = &sendRawEmail
+			if config.Override != nil {
+				return config.Override(originalImplementation)
+			}
+			return originalImplementation
+		},
+	}
+}
+
+var defaultProviderHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+func getTextAndHTMLBody(content EmailContent) (string, string) {
+	if content.IsHtml {
+		return content.PlainTextBody, content.Body
+	}
+	return content.Body, ""
+}
+
+func formatFrom(from SMTPFrom) string {
+	if from.Name == "" {
+		return from.Email
+	}
+	return fmt.Sprintf("%s <%s>", from.Name, from.Email)
+}
+
+// sendProviderRequest sends the request and returns an error for responses that are not 2xx
+func sendProviderRequest(providerName string, client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = defaultProviderHTTPClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%s: email could not be sent, status code %d: %s", providerName, res.StatusCode, string(bytes.TrimSpace(body)))
+	}
+	return nil
+}
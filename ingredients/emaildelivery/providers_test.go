@@ -0,0 +1,148 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testEmailContent = EmailContent{
+	Body:          "<p>Hello</p>",
+	IsHtml:        true,
+	PlainTextBody: "Hello",
+	Subject:       "Greetings",
+	ToEmail:       "test@example.com",
+}
+
+var testFrom = SMTPFrom{
+	Name:  "SuperTokens",
+	Email: "noreply@supertokens.io",
+}
+
+func TestSendGridProvider(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer apiKey", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider := SendGridProvider(SendGridSettings{APIKey: "apiKey", From: testFrom, BaseURL: server.URL})
+	assert.NoError(t, provider.SendRawEmail(testEmailContent, &map[string]interface{}{}))
+
+	assert.Equal(t, "Greetings", body["subject"])
+	assert.Equal(t, map[string]interface{}{"email": "noreply@supertokens.io", "name": "SuperTokens"}, body["from"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "text/plain", "value": "Hello"},
+		map[string]interface{}{"type": "text/html", "value": "<p>Hello</p>"},
+	}, body["content"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"to": []interface{}{map[string]interface{}{"email": "test@example.com"}}},
+	}, body["personalizations"])
+}
+
+func TestMailgunProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages", r.URL.Path)
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api", username)
+		assert.Equal(t, "apiKey", password)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "SuperTokens <noreply@supertokens.io>", r.PostForm.Get("from"))
+		assert.Equal(t, "test@example.com", r.PostForm.Get("to"))
+		assert.Equal(t, "Greetings", r.PostForm.Get("subject"))
+		assert.Equal(t, "Hello", r.PostForm.Get("text"))
+		assert.Equal(t, "<p>Hello</p>", r.PostForm.Get("html"))
+	}))
+	defer server.Close()
+
+	provider := MailgunProvider(MailgunSettings{APIKey: "apiKey", Domain: "mg.example.com", From: testFrom, BaseURL: server.URL})
+	assert.NoError(t, provider.SendRawEmail(testEmailContent, &map[string]interface{}{}))
+}
+
+func TestPostmarkProvider(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/email", r.URL.Path)
+		assert.Equal(t, "serverToken", r.Header.Get("X-Postmark-Server-Token"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer server.Close()
+
+	provider := PostmarkProvider(PostmarkSettings{ServerToken: "serverToken", From: testFrom, MessageStream: "outbound", BaseURL: server.URL})
+	assert.NoError(t, provider.SendRawEmail(testEmailContent, &map[string]interface{}{}))
+	assert.Equal(t, map[string]string{
+		"From":          "SuperTokens <noreply@supertokens.io>",
+		"To":            "test@example.com",
+		"Subject":       "Greetings",
+		"TextBody":      "Hello",
+		"HtmlBody":      "<p>Hello</p>",
+		"MessageStream": "outbound",
+	}, body)
+}
+
+func TestSESProvider(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.Equal(t, "sessionToken", r.Header.Get("X-Amz-Security-Token"))
+		authorization := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=accessKeyId/"))
+		assert.Contains(t, authorization, "/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature=")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer server.Close()
+
+	provider := SESProvider(SESSettings{
+		Region:          "eu-west-1",
+		From:            testFrom,
+		AccessKeyID:     "accessKeyId",
+		SecretAccessKey: "secretAccessKey",
+		SessionToken:    "sessionToken",
+		BaseURL:         server.URL,
+	})
+	assert.NoError(t, provider.SendRawEmail(testEmailContent, &map[string]interface{}{}))
+	assert.Equal(t, "SuperTokens <noreply@supertokens.io>", body["FromEmailAddress"])
+	assert.Equal(t, map[string]interface{}{"ToAddresses": []interface{}{"test@example.com"}}, body["Destination"])
+	simple := body["Content"].(map[string]interface{})["Simple"].(map[string]interface{})
+	assert.Equal(t, "Greetings", simple["Subject"].(map[string]interface{})["Data"])
+	assert.Equal(t, "Hello", simple["Body"].(map[string]interface{})["Text"].(map[string]interface{})["Data"])
+	assert.Equal(t, "<p>Hello</p>", simple["Body"].(map[string]interface{})["Html"].(map[string]interface{})["Data"])
+}
+
+func TestProviderErrorResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"invalid api key"}`))
+	}))
+	defer server.Close()
+
+	err := PostmarkProvider(PostmarkSettings{ServerToken: "wrong", From: testFrom, BaseURL: server.URL}).SendRawEmail(testEmailContent, &map[string]interface{}{})
+	assert.EqualError(t, err, `postmark: email could not be sent, status code 401: {"message":"invalid api key"}`)
+
+	err = SESProvider(SESSettings{From: testFrom, BaseURL: server.URL}).SendRawEmail(testEmailContent, &map[string]interface{}{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"github.com/supertokens/supertokens-golang/ingredients/deliveryqueue"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type QueueConfig = deliveryqueue.Config[EmailType]
+
+// Queue sends the emails of a recipe in the background, with retries. Use its Override as the
+// Override of the recipe's EmailDelivery config. A queue can only be used by one recipe.
+type Queue struct {
+	*deliveryqueue.Queue[EmailType]
+}
+
+func MakeQueue(config QueueConfig) *Queue {
+	return &Queue{
+		Queue: deliveryqueue.NewQueue(config),
+	}
+}
+
+func (q *Queue) Override(originalImplementation EmailDeliveryInterface) EmailDeliveryInterface {
+	err := q.Start(*originalImplementation.SendEmail)
+	if err != nil {
+		panic("emaildelivery: could not start the queue: " + err.Error())
+	}
+
+	sendEmail := func(input EmailType, userContext supertokens.UserContext) error {
+		return q.Enqueue(input, userContext)
+	}
+	return EmailDeliveryInterface{
+		SendEmail: &sendEmail,
+	}
+}
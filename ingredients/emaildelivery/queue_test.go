@@ -0,0 +1,177 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// fakeSMTPServer accepts emails without authentication and counts connections and emails
+type fakeSMTPServer struct {
+	listener    net.Listener
+	connections int32
+	emails      int32
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	server := &fakeSMTPServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&server.connections, 1)
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	write := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+	write("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if inData {
+			if line == "." {
+				inData = false
+				atomic.AddInt32(&s.emails, 1)
+				write("250 OK")
+			}
+			continue
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			write("250 localhost")
+		case "DATA":
+			inData = true
+			write("354 Go ahead")
+		case "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) settings(maxIdleConnections int) SMTPSettings {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return SMTPSettings{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		From: SMTPFrom{
+			Name:  "SuperTokens",
+			Email: "noreply@supertokens.io",
+		},
+		MaxIdleConnections: maxIdleConnections,
+	}
+}
+
+func TestSMTPSenderReusesConnections(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	defer server.listener.Close()
+
+	content := EmailContent{
+		Body:          "<p>Hello</p>",
+		IsHtml:        true,
+		PlainTextBody: "Hello",
+		Subject:       "Hello",
+		ToEmail:       "test@example.com",
+	}
+
+	sender := MakeSMTPSender(server.settings(0))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sender.Send(content))
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&server.connections))
+
+	sender = MakeSMTPSender(server.settings(1))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sender.Send(content))
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&server.connections))
+	assert.Equal(t, int32(6), atomic.LoadInt32(&server.emails))
+}
+
+func TestQueueOverrideSendsEmailsInTheBackground(t *testing.T) {
+	var sentTo []string
+	var mutex sync.Mutex
+	var attempts int32
+	sendEmail := func(input EmailType, userContext supertokens.UserContext) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return errors.New("smtp server unavailable")
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		sentTo = append(sentTo, input.PasswordReset.User.Email)
+		return nil
+	}
+
+	queue := MakeQueue(QueueConfig{
+		InitialBackoff: time.Millisecond,
+	})
+	ingredient := MakeIngredient(TypeInputWithService{
+		Service: EmailDeliveryInterface{
+			SendEmail: &sendEmail,
+		},
+		Override: queue.Override,
+	})
+
+	for i := 0; i < 3; i++ {
+		err := (*ingredient.IngredientInterfaceImpl.SendEmail)(EmailType{
+			PasswordReset: &PasswordResetType{
+				User: User{
+					ID:    "userId" + strconv.Itoa(i),
+					Email: "test" + strconv.Itoa(i) + "@example.com",
+				},
+				PasswordResetLink: "https://supertokens.io/reset",
+			},
+		}, &map[string]interface{}{})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, queue.Shutdown(context.Background()))
+	assert.ElementsMatch(t, []string{"test0@example.com", "test1@example.com", "test2@example.com"}, sentTo)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,83 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type SendGridSettings struct {
+	APIKey string
+	From   SMTPFrom
+
+	// BaseURL defaults to https://api.sendgrid.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func SendGridProvider(settings SendGridSettings) EmailProvider {
+	return EmailProvider{
+		SendRawEmail: func(input EmailContent, userContext supertokens.UserContext) error {
+			return SendSendGridEmail(settings, input, userContext)
+		},
+	}
+}
+
+func SendSendGridEmail(settings SendGridSettings, content EmailContent, userContext supertokens.UserContext) error {
+	baseURL := "https://api.sendgrid.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	textBody, htmlBody := getTextAndHTMLBody(content)
+	// SendGrid requires the plain text content to come before the HTML content
+	contents := []map[string]string{}
+	if textBody != "" {
+		contents = append(contents, map[string]string{"type": "text/plain", "value": textBody})
+	}
+	if htmlBody != "" {
+		contents = append(contents, map[string]string{"type": "text/html", "value": htmlBody})
+	}
+	from := map[string]string{"email": settings.From.Email}
+	if settings.From.Name != "" {
+		from["name"] = settings.From.Name
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []map[string]string{{"email": content.ToEmail}}},
+		},
+		"from":    from,
+		"subject": content.Subject,
+		"content": contents,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+settings.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	return sendProviderRequest("sendgrid", settings.HTTPClient, req)
+}
@@ -0,0 +1,107 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/internal/awssigv4"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type SESSettings struct {
+	Region string
+	From   SMTPFrom
+
+	// AccessKeyID, SecretAccessKey and SessionToken default to the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	ConfigurationSetName string
+
+	// BaseURL defaults to https://email.<Region>.amazonaws.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func SESProvider(settings SESSettings) EmailProvider {
+	return EmailProvider{
+		SendRawEmail: func(input EmailContent, userContext supertokens.UserContext) error {
+			return SendSESEmail(settings, input, userContext)
+		},
+	}
+}
+
+// SendSESEmail sends the email with the SendEmail operation of the SES v2 API.
+func SendSESEmail(settings SESSettings, content EmailContent, userContext supertokens.UserContext) error {
+	if settings.Region == "" {
+		return errors.New("ses: Region is required")
+	}
+	accessKeyID, secretAccessKey, sessionToken := awssigv4.ResolveCredentials(settings.AccessKeyID, settings.SecretAccessKey, settings.SessionToken)
+	if accessKeyID == "" || secretAccessKey == "" {
+		return errors.New("ses: AccessKeyID and SecretAccessKey are required")
+	}
+	baseURL := "https://email." + settings.Region + ".amazonaws.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	textBody, htmlBody := getTextAndHTMLBody(content)
+	emailBody := map[string]interface{}{}
+	if textBody != "" {
+		emailBody["Text"] = map[string]string{"Data": textBody, "Charset": "UTF-8"}
+	}
+	if htmlBody != "" {
+		emailBody["Html"] = map[string]string{"Data": htmlBody, "Charset": "UTF-8"}
+	}
+	email := map[string]interface{}{
+		"FromEmailAddress": formatFrom(settings.From),
+		"Destination": map[string]interface{}{
+			"ToAddresses": []string{content.ToEmail},
+		},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": map[string]string{"Data": content.Subject, "Charset": "UTF-8"},
+				"Body":    emailBody,
+			},
+		},
+	}
+	if settings.ConfigurationSetName != "" {
+		email["ConfigurationSetName"] = settings.ConfigurationSetName
+	}
+	body, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	awssigv4.SignRequest(req, body, accessKeyID, secretAccessKey, settings.Region, "ses", time.Now())
+	return sendProviderRequest("ses", settings.HTTPClient, req)
+}
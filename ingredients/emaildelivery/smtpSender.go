@@ -0,0 +1,104 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// idle connections are closed after this time, before the SMTP server closes them
+const smtpIdleTimeout = 30 * time.Second
+
+// SMTPSender sends emails over SMTP, reusing up to SMTPSettings.MaxIdleConnections connections.
+type SMTPSender struct {
+	settings SMTPSettings
+	dialer   *gomail.Dialer
+	idle     []idleSMTPConnection
+	mutex    sync.Mutex
+}
+
+type idleSMTPConnection struct {
+	connection gomail.SendCloser
+	idleSince  time.Time
+}
+
+func MakeSMTPSender(settings SMTPSettings) *SMTPSender {
+	return &SMTPSender{
+		settings: settings,
+		dialer:   makeSMTPDialer(settings),
+	}
+}
+
+func (s *SMTPSender) Send(content EmailContent) error {
+	message := makeSMTPMessage(s.settings, content)
+	if s.settings.MaxIdleConnections <= 0 {
+		return s.dialer.DialAndSend(message)
+	}
+
+	connection := s.getIdleConnection()
+	if connection != nil {
+		err := gomail.Send(connection, message)
+		if err == nil {
+			s.putIdleConnection(connection)
+			return nil
+		}
+		// the server may have closed the connection, so the email is sent again with a new one
+		connection.Close()
+		message = makeSMTPMessage(s.settings, content)
+	}
+
+	connection, err := s.dialer.Dial()
+	if err != nil {
+		return err
+	}
+	err = gomail.Send(connection, message)
+	if err != nil {
+		connection.Close()
+		return err
+	}
+	s.putIdleConnection(connection)
+	return nil
+}
+
+func (s *SMTPSender) getIdleConnection() gomail.SendCloser {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for len(s.idle) > 0 {
+		idleConnection := s.idle[len(s.idle)-1]
+		s.idle = s.idle[:len(s.idle)-1]
+		if time.Since(idleConnection.idleSince) < smtpIdleTimeout {
+			return idleConnection.connection
+		}
+		idleConnection.connection.Close()
+	}
+	return nil
+}
+
+func (s *SMTPSender) putIdleConnection(connection gomail.SendCloser) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.idle) >= s.settings.MaxIdleConnections {
+		connection.Close()
+		return
+	}
+	s.idle = append(s.idle, idleSMTPConnection{
+		connection: connection,
+		idleSince:  time.Now(),
+	})
+}
@@ -27,6 +27,13 @@ type SMTPSettings struct {
 	Username *string
 	Password string
 	Secure   bool
+
+	// MaxIdleConnections is the number of connections to the SMTP server that are kept open to
+	// send later emails. If it is 0, a new connection is opened for every email.
+	MaxIdleConnections int
+
+	// Templates configures the content of the emails. If nil, the built-in English templates are used.
+	Templates *TemplateConfig
 }
 
 type SMTPFrom struct {
@@ -39,6 +46,9 @@ type EmailContent struct {
 	IsHtml  bool
 	Subject string
 	ToEmail string
+
+	// PlainTextBody is sent as an alternative to an HTML Body, for clients that do not show HTML.
+	PlainTextBody string
 }
 
 type SMTPInterface struct {
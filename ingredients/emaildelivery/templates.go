@@ -0,0 +1,211 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	textTemplate "text/template"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Names of the templates used by the SMTP services. Each template consists of the files
+// <locale>/<name>.subject, <locale>/<name>.txt and <locale>/<name>.html.
+const (
+	TemplateEmailVerification                = "emailVerification"
+	TemplatePasswordReset                    = "passwordReset"
+	TemplatePasswordlessLoginMagicLink       = "passwordlessLoginMagicLink"
+	TemplatePasswordlessLoginOTP             = "passwordlessLoginOTP"
+	TemplatePasswordlessLoginMagicLinkAndOTP = "passwordlessLoginMagicLinkAndOTP"
+)
+
+// LocaleUserContextKey is the key of the user context under which the locale of an email can be
+// set for a single call, for example from the Accept-Language header of the request.
+const LocaleUserContextKey = "emailLocale"
+
+const defaultLocale = "en"
+
+type TemplateConfig struct {
+	// FS contains templates that replace the built-in ones. Files that are not in FS, for example
+	// the subject of an email if only its HTML is overridden, are taken from the built-in templates.
+	FS fs.FS
+
+	// DefaultLocale is used if the locale of an email is not known. It defaults to "en".
+	DefaultLocale string
+
+	// GetLocale returns the locale of the user an email is sent to, or an empty string if it is
+	// not known. userID is nil if the user does not exist yet, like for passwordless login emails.
+	GetLocale func(userID *string, email string, userContext supertokens.UserContext) (string, error)
+}
+
+// TemplateData is the data that the templates are executed with. Values are escaped according to
+// where they are used in the HTML templates.
+type TemplateData struct {
+	AppName      string
+	ToEmail      string
+	Subject      string
+	Locale       string
+	Link         string
+	OTP          string
+	CodeLifetime string
+}
+
+type templateExecutor interface {
+	Execute(wr io.Writer, data interface{}) error
+}
+
+type TemplateRenderer struct {
+	config TemplateConfig
+	cache  sync.Map
+}
+
+func MakeTemplateRenderer(config *TemplateConfig) *TemplateRenderer {
+	renderer := &TemplateRenderer{}
+	if config != nil {
+		renderer.config = *config
+	}
+	if renderer.config.DefaultLocale == "" {
+		renderer.config.DefaultLocale = defaultLocale
+	}
+	return renderer
+}
+
+// Render executes the subject, text and HTML files of the named template in the locale of the
+// user, and returns them as a multipart email.
+func (r *TemplateRenderer) Render(name string, data TemplateData, userID *string, userContext supertokens.UserContext) (EmailContent, error) {
+	locale, err := r.getLocale(userID, data.ToEmail, userContext)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	data.Locale = locale
+
+	subject, err := r.execute(name+".subject", locale, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	// the subject is sent as a header, so it must be a single line
+	data.Subject = strings.Join(strings.Fields(subject), " ")
+
+	textBody, err := r.execute(name+".txt", locale, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+	htmlBody, err := r.execute(name+".html", locale, data)
+	if err != nil {
+		return EmailContent{}, err
+	}
+
+	return EmailContent{
+		Body:          htmlBody,
+		IsHtml:        true,
+		PlainTextBody: textBody,
+		Subject:       data.Subject,
+		ToEmail:       data.ToEmail,
+	}, nil
+}
+
+func (r *TemplateRenderer) getLocale(userID *string, email string, userContext supertokens.UserContext) (string, error) {
+	if userContext != nil {
+		if locale, ok := (*userContext)[LocaleUserContextKey].(string); ok && locale != "" {
+			return locale, nil
+		}
+	}
+	if r.config.GetLocale != nil {
+		locale, err := r.config.GetLocale(userID, email, userContext)
+		if err != nil {
+			return "", err
+		}
+		if locale != "" {
+			return locale, nil
+		}
+	}
+	return r.config.DefaultLocale, nil
+}
+
+func (r *TemplateRenderer) execute(fileName string, locale string, data TemplateData) (string, error) {
+	tmpl, err := r.getTemplate(fileName, locale)
+	if err != nil {
+		return "", err
+	}
+	var result bytes.Buffer
+	err = tmpl.Execute(&result, data)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+func (r *TemplateRenderer) getTemplate(fileName string, locale string) (templateExecutor, error) {
+	fsys, path, cacheKey, err := r.findTemplateFile(fileName, locale)
+	if err != nil {
+		return nil, err
+	}
+	// the cache is keyed on the file that was found, since the locale may come from the request and
+	// would otherwise add an entry for every value that is sent
+	if tmpl, ok := r.cache.Load(cacheKey); ok {
+		return tmpl.(templateExecutor), nil
+	}
+
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl templateExecutor
+	if strings.HasSuffix(fileName, ".html") {
+		tmpl, err = htmlTemplate.New(fileName).Parse(string(content))
+	} else {
+		tmpl, err = textTemplate.New(fileName).Parse(string(content))
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Store(cacheKey, tmpl)
+	return tmpl, nil
+}
+
+// findTemplateFile looks for the file in the locale, in its language without the region, and in
+// the default locale, preferring the templates in the config over the built-in ones. It returns the
+// file system and path of the file, and a key that identifies the file.
+func (r *TemplateRenderer) findTemplateFile(fileName string, locale string) (fs.FS, string, string, error) {
+	locales := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	locales = append(locales, r.config.DefaultLocale, defaultLocale)
+
+	for _, l := range locales {
+		path := l + "/" + fileName
+		if r.config.FS != nil {
+			if _, err := fs.Stat(r.config.FS, path); err == nil {
+				return r.config.FS, path, "config/" + path, nil
+			}
+		}
+		if _, err := fs.Stat(defaultTemplates, "templates/"+path); err == nil {
+			return defaultTemplates, "templates/" + path, "builtin/" + path, nil
+		}
+	}
+	return nil, "", "", fmt.Errorf("email template %s not found for locale %s", fileName, locale)
+}
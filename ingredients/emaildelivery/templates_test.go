@@ -0,0 +1,110 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emaildelivery
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestRenderBuiltInTemplate(t *testing.T) {
+	renderer := MakeTemplateRenderer(nil)
+	content, err := renderer.Render(TemplatePasswordReset, TemplateData{
+		AppName: "SuperTokens",
+		ToEmail: "test@example.com",
+		Link:    "https://supertokens.io/reset-password?token=abc&rid=emailpassword",
+	}, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Password reset instructions", content.Subject)
+	assert.Equal(t, "test@example.com", content.ToEmail)
+	assert.True(t, content.IsHtml)
+	assert.Contains(t, content.Body, "<title>Password reset instructions</title>")
+	assert.Contains(t, content.Body, `href="https://supertokens.io/reset-password?token=abc&amp;rid=emailpassword"`)
+	assert.Contains(t, content.PlainTextBody, "https://supertokens.io/reset-password?token=abc&rid=emailpassword")
+	assert.NotContains(t, content.Body, "{{")
+}
+
+func TestRenderEscapesUserControlledValues(t *testing.T) {
+	renderer := MakeTemplateRenderer(nil)
+	content, err := renderer.Render(TemplateEmailVerification, TemplateData{
+		AppName: "SuperTokens",
+		ToEmail: `"><script>alert(1)</script>@example.com`,
+		Link:    "javascript:alert(1)",
+	}, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+
+	assert.NotContains(t, content.Body, "<script>alert(1)</script>")
+	assert.Contains(t, content.Body, "&lt;script&gt;alert(1)&lt;/script&gt;@example.com")
+	assert.NotContains(t, content.Body, `href="javascript:alert(1)"`)
+}
+
+func TestRenderWithOverriddenAndLocalisedTemplates(t *testing.T) {
+	renderer := MakeTemplateRenderer(&TemplateConfig{
+		FS: fstest.MapFS{
+			"de/passwordReset.subject": {Data: []byte("Passwort für {{.AppName}} zurücksetzen")},
+			"de/passwordReset.txt":     {Data: []byte("Link: {{.Link}}")},
+			"fr/passwordReset.subject": {Data: []byte("Réinitialiser le mot de passe")},
+		},
+		GetLocale: func(userID *string, email string, userContext supertokens.UserContext) (string, error) {
+			return "de-AT", nil
+		},
+	})
+	data := TemplateData{
+		AppName: "SuperTokens",
+		ToEmail: "test@example.com",
+		Link:    "https://supertokens.io/reset",
+	}
+
+	content, err := renderer.Render(TemplatePasswordReset, data, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Passwort für SuperTokens zurücksetzen", content.Subject)
+	assert.Equal(t, "Link: https://supertokens.io/reset", content.PlainTextBody)
+	// the HTML is not overridden, so the built-in template is used with the localised subject
+	assert.Contains(t, content.Body, "<title>Passwort für SuperTokens zurücksetzen</title>")
+
+	content, err = renderer.Render(TemplatePasswordReset, data, nil, &map[string]interface{}{
+		LocaleUserContextKey: "fr",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Réinitialiser le mot de passe", content.Subject)
+	assert.Contains(t, content.PlainTextBody, "A password reset request")
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	renderer := MakeTemplateRenderer(nil)
+	_, err := renderer.Render("unknown", TemplateData{}, nil, &map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestTemplatesAreCachedPerFileNotPerRequestedLocale(t *testing.T) {
+	renderer := MakeTemplateRenderer(nil)
+	for _, locale := range []string{"en", "en-GB", "xx", "yy-ZZ", "does-not-exist"} {
+		_, err := renderer.Render(TemplateEmailVerification, TemplateData{}, nil, &map[string]interface{}{
+			LocaleUserContextKey: locale,
+		})
+		assert.NoError(t, err)
+	}
+	cachedTemplates := 0
+	renderer.cache.Range(func(key, value interface{}) bool {
+		cachedTemplates++
+		return true
+	})
+	assert.Equal(t, 3, cachedTemplates)
+}
@@ -19,6 +19,7 @@ package smsdelivery
 import (
 	"errors"
 
+	"github.com/supertokens/supertokens-golang/supertokens"
 	"github.com/twilio/twilio-go"
 	openapi "github.com/twilio/twilio-go/rest/api/v2010"
 )
@@ -39,6 +40,15 @@ func MakeIngredient(config TypeInputWithService) Ingredient {
 	return result
 }
 
+// TwilioProvider sends SMSs with Twilio, for use with the provider services.
+func TwilioProvider(settings TwilioSettings) SmsProvider {
+	return SmsProvider{
+		SendRawSms: func(input SMSContent, userContext supertokens.UserContext) error {
+			return SendTwilioSms(settings, input)
+		},
+	}
+}
+
 func SendTwilioSms(settings TwilioSettings, content SMSContent) error {
 	client := twilio.NewRestClientWithParams(twilio.ClientParams{
 		Username: settings.AccountSid,
@@ -49,7 +59,9 @@ func SendTwilioSms(settings TwilioSettings, content SMSContent) error {
 	params.SetTo(content.ToPhoneNumber)
 	params.SetBody(content.Body)
 
-	if settings.From != "" {
+	if from := getSender(content.ToPhoneNumber, "", settings.FromByRegion); from != "" {
+		params.SetFrom(from)
+	} else if settings.From != "" {
 		params.SetFrom(settings.From)
 	} else if settings.MessagingServiceSid != "" {
 		params.SetMessagingServiceSid(settings.MessagingServiceSid)
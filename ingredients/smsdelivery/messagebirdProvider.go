@@ -0,0 +1,76 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type MessageBirdSettings struct {
+	AccessKey string
+
+	// From is the originator of the SMSs. FromByRegion overrides it for the regions of the
+	// recipients, like "US".
+	From         string
+	FromByRegion map[string]string
+
+	// BaseURL defaults to https://rest.messagebird.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func MessageBirdProvider(settings MessageBirdSettings) SmsProvider {
+	return SmsProvider{
+		SendRawSms: func(input SMSContent, userContext supertokens.UserContext) error {
+			return SendMessageBirdSms(settings, input, userContext)
+		},
+	}
+}
+
+// SendMessageBirdSms sends the SMS with the Messages API of MessageBird.
+func SendMessageBirdSms(settings MessageBirdSettings, content SMSContent, userContext supertokens.UserContext) error {
+	baseURL := "https://rest.messagebird.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	message := map[string]interface{}{
+		"originator": getSender(content.ToPhoneNumber, settings.From, settings.FromByRegion),
+		"recipients": []string{content.ToPhoneNumber},
+		"body":       content.Body,
+	}
+	if GetSegments(content.Body).Encoding == EncodingUCS2 {
+		message["datacoding"] = "unicode"
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "AccessKey "+settings.AccessKey)
+	req.Header.Set("Content-Type", "application/json")
+	_, err = sendProviderRequest("messagebird", settings.HTTPClient, req)
+	return err
+}
@@ -0,0 +1,68 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// SmsProvider sends SMSs with the API of an SMS provider, like Vonage.
+type SmsProvider struct {
+	SendRawSms func(input SMSContent, userContext supertokens.UserContext) error
+}
+
+type ProviderServiceConfig struct {
+	Provider SmsProvider
+
+	// Templates configures the content of the SMSs, like for the Twilio service.
+	Templates *TemplateConfig
+	Override  func(originalImplementation TwilioInterface) TwilioInterface
+}
+
+var defaultProviderHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// sendProviderRequest sends the request and returns the body of the response, or an error for
+// responses that are not 2xx
+func sendProviderRequest(providerName string, client *http.Client, req *http.Request) ([]byte, error) {
+	if client == nil {
+		client = defaultProviderHTTPClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		if len(body) > 1024 {
+			body = body[:1024]
+		}
+		return nil, fmt.Errorf("%s: sms could not be sent, status code %d: %s", providerName, res.StatusCode, string(bytes.TrimSpace(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,215 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSmsContent = SMSContent{
+	Body:          "OTP to login is 123456",
+	ToPhoneNumber: "+919876543210",
+}
+
+func TestGetSegments(t *testing.T) {
+	assert.Equal(t, SMSSegments{Encoding: EncodingGSM7, Length: 160, Count: 1}, GetSegments(strings.Repeat("a", 160)))
+	assert.Equal(t, SMSSegments{Encoding: EncodingGSM7, Length: 161, Count: 2}, GetSegments(strings.Repeat("a", 161)))
+	assert.Equal(t, SMSSegments{Encoding: EncodingGSM7, Length: 307, Count: 3}, GetSegments(strings.Repeat("a", 307)))
+	// characters of the extension table take two septets
+	assert.Equal(t, SMSSegments{Encoding: EncodingGSM7, Length: 162, Count: 2}, GetSegments("€"+strings.Repeat("a", 160)))
+	assert.Equal(t, SMSSegments{Encoding: EncodingUCS2, Length: 70, Count: 1}, GetSegments(strings.Repeat("ä", 69)+"ç"))
+	assert.Equal(t, SMSSegments{Encoding: EncodingUCS2, Length: 71, Count: 2}, GetSegments(strings.Repeat("a", 70)+"ç"))
+	// characters outside of the BMP take two UTF-16 code units
+	assert.Equal(t, SMSSegments{Encoding: EncodingUCS2, Length: 2, Count: 1}, GetSegments("😀"))
+}
+
+func TestGetSender(t *testing.T) {
+	fromByRegion := map[string]string{"US": "+15005550006", "IN": "STAUTH"}
+	assert.Equal(t, "STAUTH", getSender("+919876543210", "SuperTokens", fromByRegion))
+	assert.Equal(t, "+15005550006", getSender("+12015550123", "SuperTokens", fromByRegion))
+	assert.Equal(t, "SuperTokens", getSender("+447700900123", "SuperTokens", fromByRegion))
+	assert.Equal(t, "SuperTokens", getSender("not a number", "SuperTokens", fromByRegion))
+	assert.Equal(t, "SuperTokens", getSender("+919876543210", "SuperTokens", nil))
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	renderer := MakeTemplateRenderer(&TemplateConfig{
+		FS: fstest.MapFS{
+			"de/passwordlessLoginOTP.txt": {Data: []byte("Dein Code für {{.AppName}} ist {{.OTP}}")},
+		},
+		GetLocale: func(userID *string, phoneNumber string, userContext *map[string]interface{}) (string, error) {
+			return "de-AT", nil
+		},
+	})
+	data := TemplateData{AppName: "SuperTokens", ToPhoneNumber: "+436641234567", OTP: "123456", CodeLifetime: "15 minutes"}
+
+	content, err := renderer.Render(TemplatePasswordlessLoginOTP, data, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, SMSContent{Body: "Dein Code für SuperTokens ist 123456", ToPhoneNumber: "+436641234567"}, content)
+
+	// templates that are not in the FS fall back to the built-in ones
+	content, err = renderer.Render(TemplatePasswordlessLoginMagicLink, data, nil, &map[string]interface{}{LocaleUserContextKey: "fr"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Click  to login to SuperTokens\n\nThis is valid for 15 minutes.", content.Body)
+}
+
+func TestTemplateRendererMaxSegments(t *testing.T) {
+	renderer := MakeTemplateRenderer(&TemplateConfig{MaxSegments: 1})
+	data := TemplateData{AppName: "SuperTokens", OTP: "123456", CodeLifetime: "15 minutes"}
+
+	_, err := renderer.Render(TemplatePasswordlessLoginOTP, data, nil, nil)
+	assert.NoError(t, err)
+
+	data.Link = "https://example.com/auth/verify?rid=passwordless&preAuthSessionId=" + strings.Repeat("a", 100)
+	_, err = renderer.Render(TemplatePasswordlessLoginMagicLinkAndOTP, data, nil, nil)
+	assert.EqualError(t, err, "SMS passwordlessLoginMagicLinkAndOTP is 2 segments long, which exceeds MaxSegments (1)")
+}
+
+func TestVonageProvider(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sms/json", r.URL.Path)
+		r.ParseForm()
+		form = r.PostForm
+		if form.Get("api_secret") != "secret" {
+			w.Write([]byte(`{"message-count":"1","messages":[{"status":"4","error-text":"Bad Credentials"}]}`))
+			return
+		}
+		w.Write([]byte(`{"message-count":"1","messages":[{"status":"0"}]}`))
+	}))
+	defer server.Close()
+
+	settings := VonageSettings{
+		APIKey:       "key",
+		APISecret:    "secret",
+		From:         "SuperTokens",
+		FromByRegion: map[string]string{"IN": "STAUTH"},
+		BaseURL:      server.URL,
+	}
+	assert.NoError(t, VonageProvider(settings).SendRawSms(testSmsContent, &map[string]interface{}{}))
+	assert.Equal(t, "919876543210", form.Get("to"))
+	assert.Equal(t, "STAUTH", form.Get("from"))
+	assert.Equal(t, "OTP to login is 123456", form.Get("text"))
+	assert.Empty(t, form.Get("type"))
+
+	assert.NoError(t, VonageProvider(settings).SendRawSms(SMSContent{Body: "Код 123456", ToPhoneNumber: "+79161234567"}, &map[string]interface{}{}))
+	assert.Equal(t, "SuperTokens", form.Get("from"))
+	assert.Equal(t, "unicode", form.Get("type"))
+
+	settings.APISecret = "wrong"
+	err := VonageProvider(settings).SendRawSms(testSmsContent, &map[string]interface{}{})
+	assert.EqualError(t, err, "vonage: sms could not be sent, status 4: Bad Credentials")
+}
+
+func TestMessageBirdProvider(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "AccessKey key", r.Header.Get("Authorization"))
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	provider := MessageBirdProvider(MessageBirdSettings{AccessKey: "key", From: "SuperTokens", BaseURL: server.URL})
+	assert.NoError(t, provider.SendRawSms(testSmsContent, &map[string]interface{}{}))
+	assert.Equal(t, "SuperTokens", body["originator"])
+	assert.Equal(t, []interface{}{"+919876543210"}, body["recipients"])
+	assert.Equal(t, "OTP to login is 123456", body["body"])
+}
+
+func TestSNSProvider(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=accessKeyId/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/sns/aws4_request")
+		assert.Equal(t, "sessionToken", r.Header.Get("X-Amz-Security-Token"))
+		r.ParseForm()
+		form = r.PostForm
+	}))
+	defer server.Close()
+
+	provider := SNSProvider(SNSSettings{
+		Region:          "eu-west-1",
+		AccessKeyID:     "accessKeyId",
+		SecretAccessKey: "secretAccessKey",
+		SessionToken:    "sessionToken",
+		FromByRegion:    map[string]string{"IN": "STAUTH"},
+		BaseURL:         server.URL,
+	})
+	assert.NoError(t, provider.SendRawSms(testSmsContent, &map[string]interface{}{}))
+	assert.Equal(t, "Publish", form.Get("Action"))
+	assert.Equal(t, "+919876543210", form.Get("PhoneNumber"))
+	assert.Equal(t, "OTP to login is 123456", form.Get("Message"))
+	assert.Equal(t, "AWS.SNS.SMS.SMSType", form.Get("MessageAttributes.entry.1.Name"))
+	assert.Equal(t, "Transactional", form.Get("MessageAttributes.entry.1.Value.StringValue"))
+	assert.Equal(t, "AWS.SNS.SMS.SenderID", form.Get("MessageAttributes.entry.2.Name"))
+	assert.Equal(t, "STAUTH", form.Get("MessageAttributes.entry.2.Value.StringValue"))
+}
+
+func TestWebhookProvider(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(WebhookSignatureHeader))
+		json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	provider := WebhookProvider(WebhookSettings{
+		URL:           server.URL,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+		SigningSecret: "secret",
+		From:          "SuperTokens",
+	})
+	assert.NoError(t, provider.SendRawSms(testSmsContent, &map[string]interface{}{}))
+	assert.Equal(t, WebhookPayload{
+		To:       "+919876543210",
+		From:     "SuperTokens",
+		Body:     "OTP to login is 123456",
+		Encoding: EncodingGSM7,
+		Segments: 1,
+	}, payload)
+}
+
+func TestProviderErrorResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors":[{"description":"Request not allowed"}]}`))
+	}))
+	defer server.Close()
+
+	err := MessageBirdProvider(MessageBirdSettings{AccessKey: "wrong", BaseURL: server.URL}).SendRawSms(testSmsContent, &map[string]interface{}{})
+	assert.EqualError(t, err, `messagebird: sms could not be sent, status code 401: {"errors":[{"description":"Request not allowed"}]}`)
+
+	err = SNSProvider(SNSSettings{BaseURL: server.URL}).SendRawSms(testSmsContent, &map[string]interface{}{})
+	assert.EqualError(t, err, "sns: Region is required")
+}
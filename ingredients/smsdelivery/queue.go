@@ -0,0 +1,49 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"github.com/supertokens/supertokens-golang/ingredients/deliveryqueue"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type QueueConfig = deliveryqueue.Config[SmsType]
+
+// Queue sends the SMSs of a recipe in the background, with retries. Use its Override as the
+// Override of the recipe's SmsDelivery config. A queue can only be used by one recipe.
+type Queue struct {
+	*deliveryqueue.Queue[SmsType]
+}
+
+func MakeQueue(config QueueConfig) *Queue {
+	return &Queue{
+		Queue: deliveryqueue.NewQueue(config),
+	}
+}
+
+func (q *Queue) Override(originalImplementation SmsDeliveryInterface) SmsDeliveryInterface {
+	err := q.Start(*originalImplementation.SendSms)
+	if err != nil {
+		panic("smsdelivery: could not start the queue: " + err.Error())
+	}
+
+	sendSms := func(input SmsType, userContext supertokens.UserContext) error {
+		return q.Enqueue(input, userContext)
+	}
+	return SmsDeliveryInterface{
+		SendSms: &sendSms,
+	}
+}
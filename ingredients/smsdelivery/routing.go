@@ -0,0 +1,41 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import "github.com/nyaruka/phonenumbers"
+
+// getSender returns the sender in fromByRegion for the region of the phone number, like "US" or
+// "IN", and defaultFrom if the region has no sender or the number can not be parsed. Some
+// countries require registered sender IDs or local numbers, so they can be routed separately.
+func getSender(phoneNumber string, defaultFrom string, fromByRegion map[string]string) string {
+	if len(fromByRegion) == 0 {
+		return defaultFrom
+	}
+	if from, ok := fromByRegion[GetRegionCode(phoneNumber)]; ok {
+		return from
+	}
+	return defaultFrom
+}
+
+// GetRegionCode returns the ISO 3166-1 alpha-2 code of the region of a phone number in the E.164
+// format, or an empty string if it can not be determined.
+func GetRegionCode(phoneNumber string) string {
+	parsedPhoneNumber, err := phonenumbers.Parse(phoneNumber, "")
+	if err != nil {
+		return ""
+	}
+	return phonenumbers.GetRegionCodeForNumber(parsedPhoneNumber)
+}
@@ -0,0 +1,80 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import "unicode/utf16"
+
+type SMSEncoding string
+
+const (
+	EncodingGSM7 SMSEncoding = "GSM-7"
+	EncodingUCS2 SMSEncoding = "UCS-2"
+)
+
+// SMSSegments describes how an SMS body is split into segments when it is sent.
+type SMSSegments struct {
+	Encoding SMSEncoding
+	// Length is the number of GSM-7 septets or UCS-2 code units of the body.
+	Length int
+	Count  int
+}
+
+// characters of the GSM 03.38 default alphabet
+const gsm7Characters = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// characters of the GSM 03.38 extension table, which take two septets each
+const gsm7ExtensionCharacters = "\f^{}\\[~]|€"
+
+var gsm7Lengths = func() map[rune]int {
+	lengths := map[rune]int{}
+	for _, c := range gsm7Characters {
+		lengths[c] = 1
+	}
+	for _, c := range gsm7ExtensionCharacters {
+		lengths[c] = 2
+	}
+	return lengths
+}()
+
+// GetSegments returns the encoding an SMS with the body is sent with, and the number of segments
+// it is split into. Bodies with characters outside of the GSM-7 alphabet are sent as UCS-2, which
+// fits 70 instead of 160 characters into a single segment.
+func GetSegments(body string) SMSSegments {
+	length := 0
+	for _, c := range body {
+		l, ok := gsm7Lengths[c]
+		if !ok {
+			return getSegmentCount(EncodingUCS2, len(utf16.Encode([]rune(body))), 70, 67)
+		}
+		length += l
+	}
+	return getSegmentCount(EncodingGSM7, length, 160, 153)
+}
+
+// getSegmentCount splits the length into segments. Messages that do not fit into a single
+// segment lose some space in each segment to the header that concatenates them.
+func getSegmentCount(encoding SMSEncoding, length int, singleSegmentLength int, multiSegmentLength int) SMSSegments {
+	count := 1
+	if length > singleSegmentLength {
+		count = (length + multiSegmentLength - 1) / multiSegmentLength
+	}
+	return SMSSegments{
+		Encoding: encoding,
+		Length:   length,
+		Count:    count,
+	}
+}
@@ -0,0 +1,107 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/ingredients/internal/awssigv4"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type SNSSettings struct {
+	Region string
+
+	// AccessKeyID, SecretAccessKey and SessionToken default to the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// From is sent as the sender ID of the SMSs, if set. FromByRegion overrides it for the
+	// regions of the recipients, like "IN".
+	From         string
+	FromByRegion map[string]string
+
+	// SMSType defaults to "Transactional"
+	SMSType string
+
+	// BaseURL defaults to https://sns.<Region>.amazonaws.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func SNSProvider(settings SNSSettings) SmsProvider {
+	return SmsProvider{
+		SendRawSms: func(input SMSContent, userContext supertokens.UserContext) error {
+			return SendSNSSms(settings, input, userContext)
+		},
+	}
+}
+
+// SendSNSSms sends the SMS with the Publish action of the SNS API.
+func SendSNSSms(settings SNSSettings, content SMSContent, userContext supertokens.UserContext) error {
+	if settings.Region == "" {
+		return errors.New("sns: Region is required")
+	}
+	accessKeyID, secretAccessKey, sessionToken := awssigv4.ResolveCredentials(settings.AccessKeyID, settings.SecretAccessKey, settings.SessionToken)
+	if accessKeyID == "" || secretAccessKey == "" {
+		return errors.New("sns: AccessKeyID and SecretAccessKey are required")
+	}
+	baseURL := "https://sns." + settings.Region + ".amazonaws.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+	smsType := settings.SMSType
+	if smsType == "" {
+		smsType = "Transactional"
+	}
+
+	form := url.Values{}
+	form.Set("Action", "Publish")
+	form.Set("Version", "2010-03-31")
+	form.Set("PhoneNumber", content.ToPhoneNumber)
+	form.Set("Message", content.Body)
+	attributes := [][2]string{{"AWS.SNS.SMS.SMSType", smsType}}
+	if from := getSender(content.ToPhoneNumber, settings.From, settings.FromByRegion); from != "" {
+		attributes = append(attributes, [2]string{"AWS.SNS.SMS.SenderID", from})
+	}
+	for i, attribute := range attributes {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1)
+		form.Set(prefix+".Name", attribute[0])
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", attribute[1])
+	}
+	body := []byte(form.Encode())
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	awssigv4.SignRequest(req, body, accessKeyID, secretAccessKey, settings.Region, "sns", time.Now())
+	_, err = sendProviderRequest("sns", settings.HTTPClient, req)
+	return err
+}
@@ -0,0 +1,180 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Names of the templates used by the SMS services. Each template is the file <locale>/<name>.txt.
+const (
+	TemplatePasswordlessLoginMagicLink       = "passwordlessLoginMagicLink"
+	TemplatePasswordlessLoginOTP             = "passwordlessLoginOTP"
+	TemplatePasswordlessLoginMagicLinkAndOTP = "passwordlessLoginMagicLinkAndOTP"
+)
+
+// LocaleUserContextKey is the key of the user context under which the locale of an SMS can be
+// set for a single call, for example from the Accept-Language header of the request.
+const LocaleUserContextKey = "smsLocale"
+
+const defaultLocale = "en"
+
+type TemplateConfig struct {
+	// FS contains templates that replace the built-in ones.
+	FS fs.FS
+
+	// DefaultLocale is used if the locale of an SMS is not known. It defaults to "en".
+	DefaultLocale string
+
+	// GetLocale returns the locale of the user an SMS is sent to, or an empty string if it is
+	// not known. userID is nil if the user does not exist yet, like for passwordless login SMSs.
+	GetLocale func(userID *string, phoneNumber string, userContext supertokens.UserContext) (string, error)
+
+	// MaxSegments is the maximum number of segments an SMS may be split into. Rendering an SMS
+	// that is longer fails, rather than sending a message that is billed many times. It is not
+	// limited if 0.
+	MaxSegments int
+}
+
+// TemplateData is the data that the templates are executed with.
+type TemplateData struct {
+	AppName       string
+	ToPhoneNumber string
+	Locale        string
+	Link          string
+	OTP           string
+	CodeLifetime  string
+}
+
+// TemplateRenderer generates the content of SMSs from templates. It is shared by all SMS
+// services, so that the content is the same whichever provider sends it.
+type TemplateRenderer struct {
+	config TemplateConfig
+	cache  sync.Map
+}
+
+func MakeTemplateRenderer(config *TemplateConfig) *TemplateRenderer {
+	renderer := &TemplateRenderer{}
+	if config != nil {
+		renderer.config = *config
+	}
+	if renderer.config.DefaultLocale == "" {
+		renderer.config.DefaultLocale = defaultLocale
+	}
+	return renderer
+}
+
+// Render executes the named template in the locale of the user and checks that the result does
+// not exceed MaxSegments.
+func (r *TemplateRenderer) Render(name string, data TemplateData, userID *string, userContext supertokens.UserContext) (SMSContent, error) {
+	locale, err := r.getLocale(userID, data.ToPhoneNumber, userContext)
+	if err != nil {
+		return SMSContent{}, err
+	}
+	data.Locale = locale
+
+	tmpl, err := r.getTemplate(name+".txt", locale)
+	if err != nil {
+		return SMSContent{}, err
+	}
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, data)
+	if err != nil {
+		return SMSContent{}, err
+	}
+
+	if r.config.MaxSegments > 0 {
+		segments := GetSegments(body.String())
+		if segments.Count > r.config.MaxSegments {
+			return SMSContent{}, fmt.Errorf("SMS %s is %d segments long, which exceeds MaxSegments (%d)", name, segments.Count, r.config.MaxSegments)
+		}
+	}
+
+	return SMSContent{
+		Body:          body.String(),
+		ToPhoneNumber: data.ToPhoneNumber,
+	}, nil
+}
+
+func (r *TemplateRenderer) getLocale(userID *string, phoneNumber string, userContext supertokens.UserContext) (string, error) {
+	if userContext != nil {
+		if locale, ok := (*userContext)[LocaleUserContextKey].(string); ok && locale != "" {
+			return locale, nil
+		}
+	}
+	if r.config.GetLocale != nil {
+		locale, err := r.config.GetLocale(userID, phoneNumber, userContext)
+		if err != nil {
+			return "", err
+		}
+		if locale != "" {
+			return locale, nil
+		}
+	}
+	return r.config.DefaultLocale, nil
+}
+
+func (r *TemplateRenderer) getTemplate(fileName string, locale string) (*template.Template, error) {
+	cacheKey := locale + "/" + fileName
+	if tmpl, ok := r.cache.Load(cacheKey); ok {
+		return tmpl.(*template.Template), nil
+	}
+	content, err := r.readTemplateFile(fileName, locale)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(fileName).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Store(cacheKey, tmpl)
+	return tmpl, nil
+}
+
+// readTemplateFile looks for the file in the locale, in its language without the region, and in
+// the default locale, preferring the templates in the config over the built-in ones.
+func (r *TemplateRenderer) readTemplateFile(fileName string, locale string) ([]byte, error) {
+	locales := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	locales = append(locales, r.config.DefaultLocale, defaultLocale)
+
+	for _, l := range locales {
+		if r.config.FS != nil {
+			content, err := fs.ReadFile(r.config.FS, l+"/"+fileName)
+			if err == nil {
+				return content, nil
+			}
+		}
+		content, err := defaultTemplates.ReadFile("templates/" + l + "/" + fileName)
+		if err == nil {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("sms template %s not found for locale %s", fileName, locale)
+}
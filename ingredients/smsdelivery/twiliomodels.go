@@ -27,6 +27,13 @@ type TwilioSettings struct {
 	AuthToken           string
 	From                string
 	MessagingServiceSid string
+
+	// FromByRegion overrides From or MessagingServiceSid with a from number for the regions of
+	// the recipients, like "US".
+	FromByRegion map[string]string
+
+	// Templates configures the content of the SMSs. The built-in templates are used if it is nil.
+	Templates *TemplateConfig
 }
 
 type SMSContent struct {
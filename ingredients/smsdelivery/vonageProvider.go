@@ -0,0 +1,96 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type VonageSettings struct {
+	APIKey    string
+	APISecret string
+
+	// From is the sender ID or number of the SMSs. FromByRegion overrides it for the regions of
+	// the recipients, like "US".
+	From         string
+	FromByRegion map[string]string
+
+	// BaseURL defaults to https://rest.nexmo.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func VonageProvider(settings VonageSettings) SmsProvider {
+	return SmsProvider{
+		SendRawSms: func(input SMSContent, userContext supertokens.UserContext) error {
+			return SendVonageSms(settings, input, userContext)
+		},
+	}
+}
+
+type vonageResponse struct {
+	Messages []struct {
+		Status    string `json:"status"`
+		ErrorText string `json:"error-text"`
+	} `json:"messages"`
+}
+
+// SendVonageSms sends the SMS with the SMS API of Vonage (formerly Nexmo).
+func SendVonageSms(settings VonageSettings, content SMSContent, userContext supertokens.UserContext) error {
+	baseURL := "https://rest.nexmo.com"
+	if settings.BaseURL != "" {
+		baseURL = strings.TrimSuffix(settings.BaseURL, "/")
+	}
+
+	form := url.Values{}
+	form.Set("api_key", settings.APIKey)
+	form.Set("api_secret", settings.APISecret)
+	form.Set("from", getSender(content.ToPhoneNumber, settings.From, settings.FromByRegion))
+	form.Set("to", strings.TrimPrefix(content.ToPhoneNumber, "+"))
+	form.Set("text", content.Body)
+	if GetSegments(content.Body).Encoding == EncodingUCS2 {
+		form.Set("type", "unicode")
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, baseURL+"/sms/json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := sendProviderRequest("vonage", settings.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+
+	// Vonage responds with 200 to failed messages, and sets the status of every message part
+	var response vonageResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return err
+	}
+	for _, message := range response.Messages {
+		if message.Status != "0" {
+			return fmt.Errorf("vonage: sms could not be sent, status %s: %s", message.Status, message.ErrorText)
+		}
+	}
+	return nil
+}
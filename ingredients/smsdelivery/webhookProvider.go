@@ -0,0 +1,102 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package smsdelivery
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// WebhookSignatureHeader is the header with the HMAC-SHA256 of the request body, if
+// WebhookSettings.SigningSecret is set.
+const WebhookSignatureHeader = "X-Supertokens-Signature"
+
+type WebhookSettings struct {
+	// URL receives a POST request with a WebhookPayload for every SMS.
+	URL string
+
+	// Headers are added to every request, for example to authenticate it.
+	Headers map[string]string
+
+	// SigningSecret is used to sign the body of the requests, which is sent in the
+	// X-Supertokens-Signature header as "sha256=<hex encoded HMAC>".
+	SigningSecret string
+
+	// From is sent as the sender of the SMSs. FromByRegion overrides it for the regions of the
+	// recipients, like "US".
+	From         string
+	FromByRegion map[string]string
+
+	HTTPClient *http.Client
+}
+
+type WebhookPayload struct {
+	To       string      `json:"to"`
+	From     string      `json:"from,omitempty"`
+	Body     string      `json:"body"`
+	Encoding SMSEncoding `json:"encoding"`
+	Segments int         `json:"segments"`
+}
+
+// WebhookProvider sends SMSs to a webhook, for providers that do not have a built-in
+// implementation or an SMS gateway of your own.
+func WebhookProvider(settings WebhookSettings) SmsProvider {
+	return SmsProvider{
+		SendRawSms: func(input SMSContent, userContext supertokens.UserContext) error {
+			return SendWebhookSms(settings, input, userContext)
+		},
+	}
+}
+
+func SendWebhookSms(settings WebhookSettings, content SMSContent, userContext supertokens.UserContext) error {
+	if settings.URL == "" {
+		return errors.New("webhook: URL is required")
+	}
+	segments := GetSegments(content.Body)
+	body, err := json.Marshal(WebhookPayload{
+		To:       content.ToPhoneNumber,
+		From:     getSender(content.ToPhoneNumber, settings.From, settings.FromByRegion),
+		Body:     content.Body,
+		Encoding: segments.Encoding,
+		Segments: segments.Count,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodPost, settings.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range settings.Headers {
+		req.Header.Set(name, value)
+	}
+	if settings.SigningSecret != "" {
+		mac := hmac.New(sha256.New, []byte(settings.SigningSecret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	_, err = sendProviderRequest("webhook", settings.HTTPClient, req)
+	return err
+}
@@ -0,0 +1,225 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking/accountlinkingmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func initForTest(t *testing.T, config *accountlinkingmodels.TypeInput) *supertokens.Querier {
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(config),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	querier, err := supertokens.GetNewQuerierInstanceOrThrowError("")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return querier
+}
+
+func createEmailPasswordUser(t *testing.T, querier *supertokens.Querier, email string) string {
+	response, err := querier.SendPostRequest("/recipe/signup", map[string]interface{}{
+		"email":    email,
+		"password": "validpass123",
+	})
+	assert.NoError(t, err)
+	return response["user"].(map[string]interface{})["id"].(string)
+}
+
+func createThirdPartyUser(t *testing.T, querier *supertokens.Querier, email string) string {
+	response, err := querier.SendPostRequest("/recipe/signinup", map[string]interface{}{
+		"thirdPartyId":     "google",
+		"thirdPartyUserId": "google-" + email,
+		"email":            map[string]interface{}{"id": email},
+	})
+	assert.NoError(t, err)
+	return response["user"].(map[string]interface{})["id"].(string)
+}
+
+func verifyEmail(t *testing.T, querier *supertokens.Querier, userID string, email string) {
+	response, err := querier.SendPostRequest("/recipe/user/email/verify/token", map[string]interface{}{
+		"userId": userID,
+		"email":  email,
+	})
+	assert.NoError(t, err)
+	_, err = querier.SendPostRequest("/recipe/user/email/verify", map[string]interface{}{
+		"method": "token",
+		"token":  response["token"],
+	})
+	assert.NoError(t, err)
+}
+
+func TestLinkAndUnlinkAccounts(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	querier := initForTest(t, nil)
+
+	epUserID := createEmailPasswordUser(t, querier, "test@example.com")
+	tpUserID := createThirdPartyUser(t, querier, "test@example.com")
+
+	linkResponse, err := LinkAccounts(tpUserID, epUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, linkResponse.InputUserIsNotAPrimaryUserError)
+
+	primaryResponse, err := CreatePrimaryUser(epUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, primaryResponse.OK)
+	assert.False(t, primaryResponse.OK.WasAlreadyAPrimaryUser)
+
+	linkResponse, err = LinkAccounts(tpUserID, epUserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, linkResponse.OK)
+	assert.False(t, linkResponse.OK.AccountsAlreadyLinked)
+
+	user, err := GetUser(tpUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, epUserID, user.ID)
+	assert.True(t, user.IsPrimaryUser)
+	assert.Equal(t, []string{"test@example.com"}, user.Emails)
+	assert.Len(t, user.LoginMethods, 2)
+	assert.Equal(t, "thirdparty", user.LoginMethods[1].RecipeID)
+	assert.Equal(t, "google", user.LoginMethods[1].ThirdParty.ID)
+
+	email := "test@example.com"
+	users, err := ListUsersByAccountInfo(accountlinkingmodels.AccountInfo{Email: &email})
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	unlinkResponse, err := UnlinkAccount(tpUserID)
+	assert.NoError(t, err)
+	assert.True(t, unlinkResponse.OK.WasLinked)
+	assert.False(t, unlinkResponse.OK.WasRecipeUserDeleted)
+
+	user, err = GetUser(tpUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, tpUserID, user.ID)
+	assert.False(t, user.IsPrimaryUser)
+
+	// the unlinked user can not become a primary user while it shares its email with one
+	primaryResponse, err = CreatePrimaryUser(tpUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, epUserID, primaryResponse.AccountInfoAlreadyAssociatedWithAnotherPrimaryUserIdError.PrimaryUserID)
+}
+
+func TestAutomaticLinkingRequiresVerifiedEmails(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	linkedUsers := []accountlinkingmodels.User{}
+	querier := initForTest(t, &accountlinkingmodels.TypeInput{
+		OnAccountLinked: func(user accountlinkingmodels.User, newLoginMethod accountlinkingmodels.LoginMethod, userContext supertokens.UserContext) error {
+			linkedUsers = append(linkedUsers, user)
+			return nil
+		},
+	})
+
+	epUserID := createEmailPasswordUser(t, querier, "test@example.com")
+	sessionUserID, err := GetPrimaryUserIdAfterSignInUp(epUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, epUserID, sessionUserID)
+
+	tpUserID := createThirdPartyUser(t, querier, "test@example.com")
+	verifyEmail(t, querier, tpUserID, "test@example.com")
+	sessionUserID, err = GetPrimaryUserIdAfterSignInUp(tpUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, tpUserID, sessionUserID)
+
+	// the email of the emailpassword user is not verified yet, so it stays separate
+	sessionUserID, err = GetPrimaryUserIdAfterSignInUp(epUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, epUserID, sessionUserID)
+	assert.Len(t, linkedUsers, 0)
+
+	verifyEmail(t, querier, epUserID, "test@example.com")
+	sessionUserID, err = GetPrimaryUserIdAfterSignInUp(epUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, tpUserID, sessionUserID)
+	assert.Len(t, linkedUsers, 1)
+	assert.Len(t, linkedUsers[0].LoginMethods, 2)
+
+	sessionUserID, err = GetPrimaryUserIdAfterSignInUp(epUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, tpUserID, sessionUserID)
+	assert.Len(t, linkedUsers, 1)
+}
+
+func TestAutomaticLinkingCanBeDisabled(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	querier := initForTest(t, &accountlinkingmodels.TypeInput{
+		ShouldDoAutomaticAccountLinking: func(newLoginMethod accountlinkingmodels.LoginMethod, primaryUser *accountlinkingmodels.User, userContext supertokens.UserContext) (accountlinkingmodels.ShouldDoAutomaticAccountLinkingResponse, error) {
+			return accountlinkingmodels.ShouldDoAutomaticAccountLinkingResponse{
+				ShouldAutomaticallyLink: false,
+			}, nil
+		},
+	})
+
+	tpUserID := createThirdPartyUser(t, querier, "test@example.com")
+	verifyEmail(t, querier, tpUserID, "test@example.com")
+	sessionUserID, err := GetPrimaryUserIdAfterSignInUp(tpUserID, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, tpUserID, sessionUserID)
+
+	user, err := GetUser(tpUserID)
+	assert.NoError(t, err)
+	assert.False(t, user.IsPrimaryUser)
+}
+
+func TestInitFailsWithCoreWithoutAccountLinking(t *testing.T) {
+	resetAll()
+	defer resetAll()
+	core := fakecore.NewServer(fakecore.Config{
+		CDIVersions: []string{"2.15", "3.0"},
+	})
+	defer core.Close()
+
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: core.URL,
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+		},
+	})
+	assert.ErrorAs(t, err, &supertokens.CoreAPIVersionError{})
+	assert.Contains(t, err.Error(), "Account linking needs a SuperTokens core that supports CDI version 4.0 or later")
+}
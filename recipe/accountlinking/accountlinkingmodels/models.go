@@ -0,0 +1,84 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinkingmodels
+
+import "github.com/supertokens/supertokens-golang/supertokens"
+
+// User is a primary user together with all the login methods that are linked to it. A
+// user that is not linked to any other user has a single login method.
+type User struct {
+	ID            string        `json:"id"`
+	IsPrimaryUser bool          `json:"isPrimaryUser"`
+	TimeJoined    uint64        `json:"timeJoined"`
+	Emails        []string      `json:"emails"`
+	PhoneNumbers  []string      `json:"phoneNumbers"`
+	ThirdParty    []ThirdParty  `json:"thirdParty"`
+	LoginMethods  []LoginMethod `json:"loginMethods"`
+}
+
+type ThirdParty struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+}
+
+// LoginMethod is a user of the emailpassword, passwordless or thirdparty recipe.
+type LoginMethod struct {
+	RecipeID     string `json:"recipeId"`
+	RecipeUserID string `json:"recipeUserId"`
+	TimeJoined   uint64 `json:"timeJoined"`
+	// Verified is true if the email (or phone number) of the login method is verified.
+	Verified    bool        `json:"verified"`
+	Email       *string     `json:"email"`
+	PhoneNumber *string     `json:"phoneNumber"`
+	ThirdParty  *ThirdParty `json:"thirdParty"`
+}
+
+// AccountInfo is used to find users. Exactly one of the fields should be set.
+type AccountInfo struct {
+	Email       *string
+	PhoneNumber *string
+	ThirdParty  *ThirdParty
+}
+
+type ShouldDoAutomaticAccountLinkingResponse struct {
+	ShouldAutomaticallyLink bool
+	// ShouldRequireVerification only allows linking if the new login method and the
+	// matching login method of the primary user are both verified.
+	ShouldRequireVerification bool
+}
+
+type TypeInput struct {
+	// ShouldDoAutomaticAccountLinking is called after a user signs up or signs in with a login
+	// method that is not linked yet. primaryUser is the user that has a login method with the
+	// same email or phone number, or nil if there is none, in which case the login method
+	// becomes a primary user itself if linking is allowed. Defaults to linking verified
+	// login methods only.
+	ShouldDoAutomaticAccountLinking func(newLoginMethod LoginMethod, primaryUser *User, userContext supertokens.UserContext) (ShouldDoAutomaticAccountLinkingResponse, error)
+	// OnAccountLinked is called after a login method was linked to a primary user.
+	OnAccountLinked func(user User, newLoginMethod LoginMethod, userContext supertokens.UserContext) error
+	Override        *OverrideStruct
+}
+
+type TypeNormalisedInput struct {
+	ShouldDoAutomaticAccountLinking func(newLoginMethod LoginMethod, primaryUser *User, userContext supertokens.UserContext) (ShouldDoAutomaticAccountLinkingResponse, error)
+	OnAccountLinked                 func(user User, newLoginMethod LoginMethod, userContext supertokens.UserContext) error
+	Override                        OverrideStruct
+}
+
+type OverrideStruct struct {
+	Functions func(originalImplementation RecipeInterface) RecipeInterface
+	APIs      func(originalImplementation APIInterface) APIInterface
+}
@@ -0,0 +1,63 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinkingmodels
+
+import "github.com/supertokens/supertokens-golang/supertokens"
+
+type RecipeInterface struct {
+	// GetUser returns the primary user that userID belongs to. userID can be the ID of a
+	// primary user or of any of its login methods. Returns nil if there is no such user.
+	GetUser                *func(userID string, userContext supertokens.UserContext) (*User, error)
+	ListUsersByAccountInfo *func(accountInfo AccountInfo, userContext supertokens.UserContext) ([]User, error)
+	CreatePrimaryUser      *func(recipeUserID string, userContext supertokens.UserContext) (CreatePrimaryUserResponse, error)
+	LinkAccounts           *func(recipeUserID string, primaryUserID string, userContext supertokens.UserContext) (LinkAccountsResponse, error)
+	UnlinkAccount          *func(recipeUserID string, userContext supertokens.UserContext) (UnlinkAccountResponse, error)
+}
+
+type PrimaryUserIdError struct {
+	PrimaryUserID string
+}
+
+type CreatePrimaryUserResponse struct {
+	OK *struct {
+		User                   User
+		WasAlreadyAPrimaryUser bool
+	}
+	UnknownUserIdError                                        *struct{}
+	RecipeUserIdAlreadyLinkedWithPrimaryUserIdError           *PrimaryUserIdError
+	AccountInfoAlreadyAssociatedWithAnotherPrimaryUserIdError *PrimaryUserIdError
+}
+
+type LinkAccountsResponse struct {
+	OK *struct {
+		User                  User
+		AccountsAlreadyLinked bool
+	}
+	UnknownUserIdError                                        *struct{}
+	InputUserIsNotAPrimaryUserError                           *struct{}
+	RecipeUserIdAlreadyLinkedWithAnotherPrimaryUserIdError    *PrimaryUserIdError
+	AccountInfoAlreadyAssociatedWithAnotherPrimaryUserIdError *PrimaryUserIdError
+}
+
+type UnlinkAccountResponse struct {
+	OK *struct {
+		// WasRecipeUserDeleted is true if the login method was the one the primary user was
+		// created from. Its ID stays the ID of the primary user, so the login method is deleted.
+		WasRecipeUserDeleted bool
+		WasLinked            bool
+	}
+	UnknownUserIdError *struct{}
+}
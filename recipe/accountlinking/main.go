@@ -0,0 +1,97 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinking
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking/accountlinkingmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func Init(config *accountlinkingmodels.TypeInput) supertokens.Recipe {
+	return recipeInit(config)
+}
+
+func GetUser(userID string) (*accountlinkingmodels.User, error) {
+	return GetUserWithContext(userID, &map[string]interface{}{})
+}
+
+func GetUserWithContext(userID string, userContext supertokens.UserContext) (*accountlinkingmodels.User, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.GetUser)(userID, userContext)
+}
+
+func ListUsersByAccountInfo(accountInfo accountlinkingmodels.AccountInfo) ([]accountlinkingmodels.User, error) {
+	return ListUsersByAccountInfoWithContext(accountInfo, &map[string]interface{}{})
+}
+
+func ListUsersByAccountInfoWithContext(accountInfo accountlinkingmodels.AccountInfo, userContext supertokens.UserContext) ([]accountlinkingmodels.User, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.ListUsersByAccountInfo)(accountInfo, userContext)
+}
+
+func CreatePrimaryUser(recipeUserID string) (accountlinkingmodels.CreatePrimaryUserResponse, error) {
+	return CreatePrimaryUserWithContext(recipeUserID, &map[string]interface{}{})
+}
+
+func CreatePrimaryUserWithContext(recipeUserID string, userContext supertokens.UserContext) (accountlinkingmodels.CreatePrimaryUserResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return accountlinkingmodels.CreatePrimaryUserResponse{}, err
+	}
+	return (*instance.RecipeImpl.CreatePrimaryUser)(recipeUserID, userContext)
+}
+
+func LinkAccounts(recipeUserID string, primaryUserID string) (accountlinkingmodels.LinkAccountsResponse, error) {
+	return LinkAccountsWithContext(recipeUserID, primaryUserID, &map[string]interface{}{})
+}
+
+func LinkAccountsWithContext(recipeUserID string, primaryUserID string, userContext supertokens.UserContext) (accountlinkingmodels.LinkAccountsResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return accountlinkingmodels.LinkAccountsResponse{}, err
+	}
+	return (*instance.RecipeImpl.LinkAccounts)(recipeUserID, primaryUserID, userContext)
+}
+
+func UnlinkAccount(recipeUserID string) (accountlinkingmodels.UnlinkAccountResponse, error) {
+	return UnlinkAccountWithContext(recipeUserID, &map[string]interface{}{})
+}
+
+func UnlinkAccountWithContext(recipeUserID string, userContext supertokens.UserContext) (accountlinkingmodels.UnlinkAccountResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return accountlinkingmodels.UnlinkAccountResponse{}, err
+	}
+	return (*instance.RecipeImpl.UnlinkAccount)(recipeUserID, userContext)
+}
+
+// GetPrimaryUserIdAfterSignInUp is called by the emailpassword, passwordless and thirdparty
+// recipes before they create a session for a user that signed up or signed in. It links
+// the user automatically according to ShouldDoAutomaticAccountLinking and returns the ID
+// to create the session with. If this recipe is not initialised, recipeUserID is returned.
+func GetPrimaryUserIdAfterSignInUp(recipeUserID string, userContext supertokens.UserContext) (string, error) {
+	instance := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
+	if instance == nil {
+		return recipeUserID, nil
+	}
+	return instance.getPrimaryUserIdAfterSignInUp(recipeUserID, userContext)
+}
@@ -0,0 +1,208 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinking
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking/accountlinkingmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const RECIPE_ID = "accountlinking"
+
+// minCDIVersion is the first version of the core driver interface with account linking.
+const minCDIVersion = "4.0"
+
+type Recipe struct {
+	RecipeModule supertokens.RecipeModule
+	Config       accountlinkingmodels.TypeNormalisedInput
+	RecipeImpl   accountlinkingmodels.RecipeInterface
+}
+
+func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *accountlinkingmodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+	r := &Recipe{}
+	verifiedConfig := validateAndNormaliseUserInput(appInfo, config)
+	r.Config = verifiedConfig
+
+	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	if err != nil {
+		return Recipe{}, err
+	}
+	recipeImplementation := makeRecipeImplementation(*querierInstance)
+	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
+
+	supertokens.AddPostInitCallback(func() error {
+		err := requireCoreWithAccountLinking(context.Background(), *querierInstance)
+		if errors.As(err, &supertokens.CoreAPIVersionError{}) {
+			return err
+		}
+		if err != nil {
+			// the core may just be unreachable right now, the version is checked again before every request
+			supertokens.LogDebugMessage("accountlinking: could not check the version of the core: " + err.Error())
+		}
+		return nil
+	})
+
+	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
+	r.RecipeModule = recipeModuleInstance
+
+	return *r, nil
+}
+
+func GetRecipeInstanceOrThrowError() (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+// GetRecipeInstanceFor returns the account linking recipe of the given instance, or nil if
+// the instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+func recipeInit(config *accountlinkingmodels.TypeInput) supertokens.Recipe {
+	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onSuperTokensAPIError)
+			if err != nil {
+				return nil, err
+			}
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
+		}
+		return nil, errors.New("Account linking recipe has already been initialised. Please check your code for bugs.")
+	}
+}
+
+// getPrimaryUserIdAfterSignInUp links the login method to an existing primary user, or makes
+// it a primary user itself, if the config allows it. It returns the ID of the primary user
+// that the login method belongs to afterwards, or recipeUserID if it is not linked.
+func (r *Recipe) getPrimaryUserIdAfterSignInUp(recipeUserID string, userContext supertokens.UserContext) (string, error) {
+	user, err := (*r.RecipeImpl.GetUser)(recipeUserID, userContext)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return recipeUserID, nil
+	}
+	if user.IsPrimaryUser {
+		return user.ID, nil
+	}
+	loginMethod := getLoginMethod(*user, recipeUserID)
+	if loginMethod == nil {
+		return recipeUserID, nil
+	}
+
+	var primaryUser *accountlinkingmodels.User
+	accountInfo := getAccountInfoForLinking(*loginMethod)
+	if accountInfo != nil {
+		users, err := (*r.RecipeImpl.ListUsersByAccountInfo)(*accountInfo, userContext)
+		if err != nil {
+			return "", err
+		}
+		for _, other := range users {
+			if other.IsPrimaryUser && other.ID != recipeUserID {
+				primaryUser = &other
+				break
+			}
+		}
+	}
+
+	shouldLink, err := r.Config.ShouldDoAutomaticAccountLinking(*loginMethod, primaryUser, userContext)
+	if err != nil {
+		return "", err
+	}
+	if !shouldLink.ShouldAutomaticallyLink {
+		return recipeUserID, nil
+	}
+	if shouldLink.ShouldRequireVerification && !loginMethod.Verified {
+		return recipeUserID, nil
+	}
+
+	if primaryUser == nil {
+		response, err := (*r.RecipeImpl.CreatePrimaryUser)(recipeUserID, userContext)
+		if err != nil {
+			return "", err
+		}
+		if response.OK != nil {
+			return response.OK.User.ID, nil
+		}
+		return recipeUserID, nil
+	}
+
+	// Without this check, anyone could sign up with the email of someone else and get
+	// access to their account once that person verifies their email.
+	if shouldLink.ShouldRequireVerification && !hasVerifiedLoginMethodWithAccountInfo(*primaryUser, *accountInfo) {
+		return recipeUserID, nil
+	}
+	response, err := (*r.RecipeImpl.LinkAccounts)(recipeUserID, primaryUser.ID, userContext)
+	if err != nil {
+		return "", err
+	}
+	if response.OK == nil {
+		return recipeUserID, nil
+	}
+	if !response.OK.AccountsAlreadyLinked {
+		err = r.Config.OnAccountLinked(response.OK.User, *loginMethod, userContext)
+		if err != nil {
+			return "", err
+		}
+	}
+	return response.OK.User.ID, nil
+}
+
+// implement RecipeModule
+
+func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
+	return []supertokens.APIHandled{}, nil
+}
+
+func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, _ supertokens.NormalisedURLPath, _ string) error {
+	return errors.New("should never come here")
+}
+
+func (r *Recipe) getAllCORSHeaders() []string {
+	return []string{}
+}
+
+func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+	return false, nil
+}
+
+func ResetForTest() {
+}
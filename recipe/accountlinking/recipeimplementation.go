@@ -0,0 +1,190 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinking
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking/accountlinkingmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func makeRecipeImplementation(querier supertokens.Querier) accountlinkingmodels.RecipeInterface {
+	getUser := func(userID string, userContext supertokens.UserContext) (*accountlinkingmodels.User, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithAccountLinking(ctx, querier)
+		if err != nil {
+			return nil, err
+		}
+		response, err := querier.SendGetRequestWithContext(ctx, "/user/id", map[string]string{
+			"userId": userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if response["status"] != "OK" {
+			return nil, nil
+		}
+		return parseUser(response["user"])
+	}
+
+	listUsersByAccountInfo := func(accountInfo accountlinkingmodels.AccountInfo, userContext supertokens.UserContext) ([]accountlinkingmodels.User, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithAccountLinking(ctx, querier)
+		if err != nil {
+			return nil, err
+		}
+		query := map[string]string{
+			"doUnionOfAccountInfo": "false",
+		}
+		if accountInfo.Email != nil {
+			query["email"] = *accountInfo.Email
+		}
+		if accountInfo.PhoneNumber != nil {
+			query["phoneNumber"] = *accountInfo.PhoneNumber
+		}
+		if accountInfo.ThirdParty != nil {
+			query["thirdPartyId"] = accountInfo.ThirdParty.ID
+			query["thirdPartyUserId"] = accountInfo.ThirdParty.UserID
+		}
+		response, err := querier.SendGetRequestWithContext(ctx, getUsersByAccountInfoPath(ctx), query)
+		if err != nil {
+			return nil, err
+		}
+		return parseUsers(response["users"])
+	}
+
+	createPrimaryUser := func(recipeUserID string, userContext supertokens.UserContext) (accountlinkingmodels.CreatePrimaryUserResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithAccountLinking(ctx, querier)
+		if err != nil {
+			return accountlinkingmodels.CreatePrimaryUserResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/accountlinking/user/primary", map[string]interface{}{
+			"recipeUserId": recipeUserID,
+		})
+		if err != nil {
+			return accountlinkingmodels.CreatePrimaryUserResponse{}, err
+		}
+		switch response["status"] {
+		case "OK":
+			user, err := parseUser(response["user"])
+			if err != nil {
+				return accountlinkingmodels.CreatePrimaryUserResponse{}, err
+			}
+			return accountlinkingmodels.CreatePrimaryUserResponse{
+				OK: &struct {
+					User                   accountlinkingmodels.User
+					WasAlreadyAPrimaryUser bool
+				}{
+					User:                   *user,
+					WasAlreadyAPrimaryUser: response["wasAlreadyAPrimaryUser"] == true,
+				},
+			}, nil
+		case "RECIPE_USER_ID_ALREADY_LINKED_WITH_PRIMARY_USER_ID_ERROR":
+			return accountlinkingmodels.CreatePrimaryUserResponse{
+				RecipeUserIdAlreadyLinkedWithPrimaryUserIdError: parsePrimaryUserIdError(response),
+			}, nil
+		case "ACCOUNT_INFO_ALREADY_ASSOCIATED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR":
+			return accountlinkingmodels.CreatePrimaryUserResponse{
+				AccountInfoAlreadyAssociatedWithAnotherPrimaryUserIdError: parsePrimaryUserIdError(response),
+			}, nil
+		}
+		return accountlinkingmodels.CreatePrimaryUserResponse{
+			UnknownUserIdError: &struct{}{},
+		}, nil
+	}
+
+	linkAccounts := func(recipeUserID string, primaryUserID string, userContext supertokens.UserContext) (accountlinkingmodels.LinkAccountsResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithAccountLinking(ctx, querier)
+		if err != nil {
+			return accountlinkingmodels.LinkAccountsResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/accountlinking/user/link", map[string]interface{}{
+			"recipeUserId":  recipeUserID,
+			"primaryUserId": primaryUserID,
+		})
+		if err != nil {
+			return accountlinkingmodels.LinkAccountsResponse{}, err
+		}
+		switch response["status"] {
+		case "OK":
+			user, err := parseUser(response["user"])
+			if err != nil {
+				return accountlinkingmodels.LinkAccountsResponse{}, err
+			}
+			return accountlinkingmodels.LinkAccountsResponse{
+				OK: &struct {
+					User                  accountlinkingmodels.User
+					AccountsAlreadyLinked bool
+				}{
+					User:                  *user,
+					AccountsAlreadyLinked: response["accountsAlreadyLinked"] == true,
+				},
+			}, nil
+		case "INPUT_USER_IS_NOT_A_PRIMARY_USER":
+			return accountlinkingmodels.LinkAccountsResponse{
+				InputUserIsNotAPrimaryUserError: &struct{}{},
+			}, nil
+		case "RECIPE_USER_ID_ALREADY_LINKED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR":
+			return accountlinkingmodels.LinkAccountsResponse{
+				RecipeUserIdAlreadyLinkedWithAnotherPrimaryUserIdError: parsePrimaryUserIdError(response),
+			}, nil
+		case "ACCOUNT_INFO_ALREADY_ASSOCIATED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR":
+			return accountlinkingmodels.LinkAccountsResponse{
+				AccountInfoAlreadyAssociatedWithAnotherPrimaryUserIdError: parsePrimaryUserIdError(response),
+			}, nil
+		}
+		return accountlinkingmodels.LinkAccountsResponse{
+			UnknownUserIdError: &struct{}{},
+		}, nil
+	}
+
+	unlinkAccount := func(recipeUserID string, userContext supertokens.UserContext) (accountlinkingmodels.UnlinkAccountResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithAccountLinking(ctx, querier)
+		if err != nil {
+			return accountlinkingmodels.UnlinkAccountResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/accountlinking/user/unlink", map[string]interface{}{
+			"recipeUserId": recipeUserID,
+		})
+		if err != nil {
+			return accountlinkingmodels.UnlinkAccountResponse{}, err
+		}
+		if response["status"] != "OK" {
+			return accountlinkingmodels.UnlinkAccountResponse{
+				UnknownUserIdError: &struct{}{},
+			}, nil
+		}
+		return accountlinkingmodels.UnlinkAccountResponse{
+			OK: &struct {
+				WasRecipeUserDeleted bool
+				WasLinked            bool
+			}{
+				WasRecipeUserDeleted: response["wasRecipeUserDeleted"] == true,
+				WasLinked:            response["wasLinked"] == true,
+			},
+		}, nil
+	}
+
+	return accountlinkingmodels.RecipeInterface{
+		GetUser:                &getUser,
+		ListUsersByAccountInfo: &listUsersByAccountInfo,
+		CreatePrimaryUser:      &createPrimaryUser,
+		LinkAccounts:           &linkAccounts,
+		UnlinkAccount:          &unlinkAccount,
+	}
+}
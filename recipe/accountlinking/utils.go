@@ -0,0 +1,152 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package accountlinking
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking/accountlinkingmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config *accountlinkingmodels.TypeInput) accountlinkingmodels.TypeNormalisedInput {
+	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
+
+	if config != nil {
+		if config.ShouldDoAutomaticAccountLinking != nil {
+			typeNormalisedInput.ShouldDoAutomaticAccountLinking = config.ShouldDoAutomaticAccountLinking
+		}
+		if config.OnAccountLinked != nil {
+			typeNormalisedInput.OnAccountLinked = config.OnAccountLinked
+		}
+		if config.Override != nil {
+			if config.Override.Functions != nil {
+				typeNormalisedInput.Override.Functions = config.Override.Functions
+			}
+			if config.Override.APIs != nil {
+				typeNormalisedInput.Override.APIs = config.Override.APIs
+			}
+		}
+	}
+
+	return typeNormalisedInput
+}
+
+func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) accountlinkingmodels.TypeNormalisedInput {
+	return accountlinkingmodels.TypeNormalisedInput{
+		ShouldDoAutomaticAccountLinking: func(newLoginMethod accountlinkingmodels.LoginMethod, primaryUser *accountlinkingmodels.User, userContext supertokens.UserContext) (accountlinkingmodels.ShouldDoAutomaticAccountLinkingResponse, error) {
+			return accountlinkingmodels.ShouldDoAutomaticAccountLinkingResponse{
+				ShouldAutomaticallyLink:   true,
+				ShouldRequireVerification: true,
+			}, nil
+		},
+		OnAccountLinked: func(user accountlinkingmodels.User, newLoginMethod accountlinkingmodels.LoginMethod, userContext supertokens.UserContext) error {
+			return nil
+		},
+		Override: accountlinkingmodels.OverrideStruct{
+			Functions: func(originalImplementation accountlinkingmodels.RecipeInterface) accountlinkingmodels.RecipeInterface {
+				return originalImplementation
+			},
+			APIs: func(originalImplementation accountlinkingmodels.APIInterface) accountlinkingmodels.APIInterface {
+				return originalImplementation
+			},
+		},
+	}
+}
+
+func parseUser(value interface{}) (*accountlinkingmodels.User, error) {
+	respJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var user accountlinkingmodels.User
+	err = json.Unmarshal(respJSON, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func parseUsers(value interface{}) ([]accountlinkingmodels.User, error) {
+	respJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var users []accountlinkingmodels.User
+	err = json.Unmarshal(respJSON, &users)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func parsePrimaryUserIdError(response map[string]interface{}) *accountlinkingmodels.PrimaryUserIdError {
+	primaryUserID, _ := response["primaryUserId"].(string)
+	return &accountlinkingmodels.PrimaryUserIdError{
+		PrimaryUserID: primaryUserID,
+	}
+}
+
+func getLoginMethod(user accountlinkingmodels.User, recipeUserID string) *accountlinkingmodels.LoginMethod {
+	for _, loginMethod := range user.LoginMethods {
+		if loginMethod.RecipeUserID == recipeUserID {
+			return &loginMethod
+		}
+	}
+	return nil
+}
+
+// getAccountInfoForLinking returns the email or phone number that other login methods
+// of the same person would share with loginMethod.
+func getAccountInfoForLinking(loginMethod accountlinkingmodels.LoginMethod) *accountlinkingmodels.AccountInfo {
+	if loginMethod.Email != nil {
+		return &accountlinkingmodels.AccountInfo{Email: loginMethod.Email}
+	}
+	if loginMethod.PhoneNumber != nil {
+		return &accountlinkingmodels.AccountInfo{PhoneNumber: loginMethod.PhoneNumber}
+	}
+	return nil
+}
+
+func hasVerifiedLoginMethodWithAccountInfo(user accountlinkingmodels.User, accountInfo accountlinkingmodels.AccountInfo) bool {
+	for _, loginMethod := range user.LoginMethods {
+		if !loginMethod.Verified {
+			continue
+		}
+		if accountInfo.Email != nil && loginMethod.Email != nil && *loginMethod.Email == *accountInfo.Email {
+			return true
+		}
+		if accountInfo.PhoneNumber != nil && loginMethod.PhoneNumber != nil && *loginMethod.PhoneNumber == *accountInfo.PhoneNumber {
+			return true
+		}
+	}
+	return false
+}
+
+func requireCoreWithAccountLinking(ctx context.Context, querier supertokens.Querier) error {
+	return querier.RequireCoreAPIVersionWithContext(ctx, minCDIVersion, "Account linking")
+}
+
+// getUsersByAccountInfoPath returns the path of the tenant of ctx, since the querier only adds
+// the tenant to recipe paths.
+func getUsersByAccountInfoPath(ctx context.Context) string {
+	tenantId := supertokens.GetTenantIdFromContext(ctx)
+	if tenantId == supertokens.DefaultTenantId {
+		return "/users/by-accountinfo"
+	}
+	return "/" + tenantId + "/users/by-accountinfo"
+}
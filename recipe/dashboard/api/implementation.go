@@ -38,7 +38,7 @@ func MakeAPIImplementation() dashboardmodels.APIInterface {
 
 		bundleDomain := normalizedDomain.GetAsStringDangerous() + normalizedPath.GetAsStringDangerous()
 
-		stInstance, err := supertokens.GetInstanceOrThrowError()
+		stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
 		if err != nil {
 			return "", err
 		}
@@ -34,7 +34,12 @@ func UserDelete(apiInterface dashboardmodels.APIInterface, options dashboardmode
 		}
 	}
 
-	deleteError := supertokens.DeleteUser(userId)
+	stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(req))
+	if err != nil {
+		return userDeleteResponse{}, err
+	}
+
+	deleteError := stInstance.DeleteUser(userId)
 
 	if deleteError != nil {
 		return userDeleteResponse{}, deleteError
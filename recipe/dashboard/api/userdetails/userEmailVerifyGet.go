@@ -27,6 +27,7 @@ type userEmailVerifyGetResponse struct {
 }
 
 func UserEmailVerifyGet(apiImplementation dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userEmailVerifyGetResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	req := options.Req
 	userId := req.URL.Query().Get("userId")
 
@@ -36,7 +37,7 @@ func UserEmailVerifyGet(apiImplementation dashboardmodels.APIInterface, options
 		}
 	}
 
-	emailverificationInstance := emailverification.GetRecipeInstance()
+	emailverificationInstance := emailverification.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
 
 	if emailverificationInstance == nil {
 		return userEmailVerifyGetResponse{
@@ -44,7 +45,7 @@ func UserEmailVerifyGet(apiImplementation dashboardmodels.APIInterface, options
 		}, nil
 	}
 
-	response, verificationError := emailverification.IsEmailVerified(userId, nil)
+	response, verificationError := emailverification.IsEmailVerifiedWithContext(userId, nil, userContext)
 
 	if verificationError != nil {
 		return userEmailVerifyGetResponse{}, verificationError
@@ -35,6 +35,7 @@ type userEmailverifyTokenPostRequestBody struct {
 }
 
 func UserEmailVerifyTokenPost(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userEmailVerifyTokenPost, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	body, err := supertokens.ReadFromRequest(options.Req)
 
 	if err != nil {
@@ -53,7 +54,7 @@ func UserEmailVerifyTokenPost(apiInterface dashboardmodels.APIInterface, options
 		}
 	}
 
-	emailresponse, emailErr := emailverification.GetRecipeInstance().GetEmailForUserID(*readBody.UserId, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	emailresponse, emailErr := emailverification.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).GetEmailForUserID(*readBody.UserId, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 
 	if emailErr != nil {
 		return userEmailVerifyTokenPost{}, emailErr
@@ -63,7 +64,7 @@ func UserEmailVerifyTokenPost(apiInterface dashboardmodels.APIInterface, options
 		return userEmailVerifyTokenPost{}, errors.New("Should never come here")
 	}
 
-	emailVerificationToken, tokenError := emailverification.CreateEmailVerificationToken(*readBody.UserId, &emailresponse.OK.Email)
+	emailVerificationToken, tokenError := emailverification.CreateEmailVerificationTokenWithContext(*readBody.UserId, &emailresponse.OK.Email, userContext)
 
 	if tokenError != nil {
 		return userEmailVerifyTokenPost{}, tokenError
@@ -83,7 +84,7 @@ func UserEmailVerifyTokenPost(apiInterface dashboardmodels.APIInterface, options
 		options.RecipeID,
 	)
 
-	emailverification.SendEmail(emaildelivery.EmailType{
+	emailverification.SendEmailWithContext(emaildelivery.EmailType{
 		EmailVerification: &emaildelivery.EmailVerificationType{
 			User: emaildelivery.User{
 				ID:    *readBody.UserId,
@@ -91,7 +92,7 @@ func UserEmailVerifyTokenPost(apiInterface dashboardmodels.APIInterface, options
 			},
 			EmailVerifyLink: emailVerificationURL,
 		},
-	})
+	}, userContext)
 
 	return userEmailVerifyTokenPost{
 		Status: "OK",
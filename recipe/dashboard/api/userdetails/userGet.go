@@ -29,6 +29,7 @@ type userGetResponse struct {
 }
 
 func UserGet(apiImplementation dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userGetResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	req := options.Req
 	userId := req.URL.Query().Get("userId")
 	recipeId := req.URL.Query().Get("recipeId")
@@ -51,13 +52,13 @@ func UserGet(apiImplementation dashboardmodels.APIInterface, options dashboardmo
 		}
 	}
 
-	if !api.IsRecipeInitialised(recipeId) {
+	if !api.IsRecipeInitialisedWithContext(recipeId, userContext) {
 		return userGetResponse{
 			Status: "RECIPE_NOT_INITIALISED",
 		}, nil
 	}
 
-	userForRecipeId, _ := api.GetUserForRecipeId(userId, recipeId)
+	userForRecipeId, _ := api.GetUserForRecipeIdWithContext(userId, recipeId, userContext)
 
 	if userForRecipeId == (dashboardmodels.UserType{}) {
 		return userGetResponse{
@@ -65,7 +66,7 @@ func UserGet(apiImplementation dashboardmodels.APIInterface, options dashboardmo
 		}, nil
 	}
 
-	_, err := usermetadata.GetRecipeInstanceOrThrowError()
+	_, err := usermetadata.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 	if err != nil {
 		// If metadata is not enabled then the frontend will show this as the name
@@ -79,7 +80,7 @@ func UserGet(apiImplementation dashboardmodels.APIInterface, options dashboardmo
 		}, nil
 	}
 
-	metadata, metadataerr := usermetadata.GetUserMetadata(userId)
+	metadata, metadataerr := usermetadata.GetUserMetadataWithContext(userId, userContext)
 
 	if metadataerr != nil {
 		return userGetResponse{}, metadataerr
@@ -0,0 +1,93 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+*
+* This software is licensed under the Apache License, Version 2.0 (the
+* "License") as published by the Apache Software Foundation.
+*
+* You may not use this file except in compliance with the License. You may
+* obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+* WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+* License for the specific language governing permissions and limitations
+* under the License.
+ */
+
+package userdetails
+
+import (
+	"encoding/json"
+	defaultErrors "errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/dashboard/dashboardmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// dashboardImpersonatorID is recorded as the impersonator of sessions started from the dashboard,
+// since dashboard users are identified by the API key only.
+const dashboardImpersonatorID = "dashboard"
+
+type userImpersonatePostResponse struct {
+	Status        string `json:"status"`
+	SessionHandle string `json:"sessionHandle,omitempty"`
+	ExpiresAt     uint64 `json:"expiresAt,omitempty"`
+}
+
+type userImpersonatePostRequestBody struct {
+	UserId *string `json:"userId"`
+}
+
+// UserImpersonatePost starts an impersonation session of a user and attaches its tokens to the
+// response, so that the browser of the dashboard user is signed in as that user. This replaces any
+// session of the app that the browser already has.
+func UserImpersonatePost(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userImpersonatePostResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	body, err := supertokens.ReadFromRequest(options.Req)
+
+	if err != nil {
+		return userImpersonatePostResponse{}, err
+	}
+
+	var readBody userImpersonatePostRequestBody
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return userImpersonatePostResponse{}, err
+	}
+
+	if readBody.UserId == nil || *readBody.UserId == "" {
+		return userImpersonatePostResponse{}, supertokens.BadInputError{
+			Msg: "Required parameter 'userId' is missing or has an invalid type",
+		}
+	}
+
+	sessionRecipe, err := session.GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return userImpersonatePostResponse{}, err
+	}
+	if !sessionRecipe.Config.Impersonation.Enabled {
+		return userImpersonatePostResponse{
+			Status: "IMPERSONATION_DISABLED_ERROR",
+		}, nil
+	}
+
+	sessionContainer, err := session.CreateImpersonationSessionWithContext(options.Res, dashboardImpersonatorID, *readBody.UserId, userContext)
+	if err != nil {
+		if defaultErrors.As(err, &errors.UnknownUserIDError{}) {
+			return userImpersonatePostResponse{
+				Status: "UNKNOWN_USER_ID_ERROR",
+			}, nil
+		}
+		return userImpersonatePostResponse{}, err
+	}
+
+	response := userImpersonatePostResponse{
+		Status:        "OK",
+		SessionHandle: sessionContainer.GetHandle(),
+	}
+	if info := session.GetImpersonationInfo(sessionContainer); info != nil {
+		response.ExpiresAt = info.ExpiresAt
+	}
+	return response, nil
+}
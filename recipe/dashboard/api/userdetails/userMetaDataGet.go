@@ -27,6 +27,7 @@ type userMetaDataGetResponse struct {
 }
 
 func UserMetaDataGet(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userMetaDataGetResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	req := options.Req
 	userId := req.URL.Query().Get("userId")
 
@@ -36,7 +37,7 @@ func UserMetaDataGet(apiInterface dashboardmodels.APIInterface, options dashboar
 		}
 	}
 
-	_, instanceError := usermetadata.GetRecipeInstanceOrThrowError()
+	_, instanceError := usermetadata.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 	if instanceError != nil {
 		return userMetaDataGetResponse{
@@ -44,7 +45,7 @@ func UserMetaDataGet(apiInterface dashboardmodels.APIInterface, options dashboar
 		}, nil
 	}
 
-	metadata, err := usermetadata.GetUserMetadata(userId)
+	metadata, err := usermetadata.GetUserMetadataWithContext(userId, userContext)
 
 	if err != nil {
 		return userMetaDataGetResponse{}, err
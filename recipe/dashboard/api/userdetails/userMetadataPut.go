@@ -33,6 +33,7 @@ type userMetaDataRequestBody struct {
 }
 
 func UserMetaDataPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userMetadataPutResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	body, err := supertokens.ReadFromRequest(options.Req)
 
 	if err != nil {
@@ -57,7 +58,7 @@ func UserMetaDataPut(apiInterface dashboardmodels.APIInterface, options dashboar
 		}
 	}
 
-	_, instanceError := usermetadata.GetRecipeInstanceOrThrowError()
+	_, instanceError := usermetadata.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 	// This is so that the API exists early if the recipe has not been initialised
 	if instanceError != nil {
@@ -84,13 +85,13 @@ func UserMetaDataPut(apiInterface dashboardmodels.APIInterface, options dashboar
 	 *
 	 * Removing first ensures that the final data is exactly what the user wanted it to be
 	 */
-	clearErr := usermetadata.ClearUserMetadata(*readBody.UserId)
+	clearErr := usermetadata.ClearUserMetadataWithContext(*readBody.UserId, userContext)
 
 	if clearErr != nil {
 		return userMetadataPutResponse{}, clearErr
 	}
 
-	_, updateErr := usermetadata.UpdateUserMetadata(*readBody.UserId, parsedMetaData)
+	_, updateErr := usermetadata.UpdateUserMetadataWithContext(*readBody.UserId, parsedMetaData, userContext)
 
 	if updateErr != nil {
 		return userMetadataPutResponse{}, updateErr
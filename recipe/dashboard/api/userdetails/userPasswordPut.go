@@ -37,6 +37,7 @@ type userPasswordPutRequestBody struct {
 }
 
 func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userPasswordPutResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	body, err := supertokens.ReadFromRequest(options.Req)
 
 	if err != nil {
@@ -63,14 +64,14 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 
 	recipeToUse := "none"
 
-	emailPasswordInstance := emailpassword.GetRecipeInstance()
+	emailPasswordInstance := emailpassword.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
 
 	if emailPasswordInstance != nil {
 		recipeToUse = "emailpassword"
 	}
 
 	if recipeToUse == "none" {
-		tpepInstance := thirdpartyemailpassword.GetRecipeInstance()
+		tpepInstance := thirdpartyemailpassword.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
 
 		if tpepInstance != nil {
 			recipeToUse = "thirdpartyemailpassword"
@@ -91,7 +92,11 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 			}
 		}
 
-		validationError := passwordField.Validate(*readBody.NewPassword)
+		validationError, err := passwordField.ValidateValue(*readBody.NewPassword, []epmodels.TypeFormField{{ID: "password", Value: *readBody.NewPassword}}, userContext)
+
+		if err != nil {
+			return userPasswordPutResponse{}, err
+		}
 
 		if validationError != nil {
 			return userPasswordPutResponse{
@@ -100,7 +105,7 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 			}, nil
 		}
 
-		passwordResetToken, resetTokenErr := emailpassword.CreateResetPasswordToken(*readBody.UserId)
+		passwordResetToken, resetTokenErr := emailpassword.CreateResetPasswordTokenWithContext(*readBody.UserId, userContext)
 
 		if resetTokenErr != nil {
 			return userPasswordPutResponse{}, resetTokenErr
@@ -111,7 +116,7 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 			return userPasswordPutResponse{}, errors.New("Should never come here")
 		}
 
-		passwordResetResponse, passwordResetErr := emailpassword.ResetPasswordUsingToken(passwordResetToken.OK.Token, *readBody.NewPassword)
+		passwordResetResponse, passwordResetErr := emailpassword.ResetPasswordUsingTokenWithContext(passwordResetToken.OK.Token, *readBody.NewPassword, userContext)
 
 		if passwordResetErr != nil {
 			return userPasswordPutResponse{}, passwordResetErr
@@ -121,6 +126,13 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 			return userPasswordPutResponse{}, errors.New("Should never come here")
 		}
 
+		if passwordResetResponse.PasswordPolicyViolatedError != nil {
+			return userPasswordPutResponse{
+				Status: "INVALID_PASSWORD_ERROR",
+				Error:  passwordResetResponse.PasswordPolicyViolatedError.FailureReason,
+			}, nil
+		}
+
 		return userPasswordPutResponse{
 			Status: "OK",
 		}, nil
@@ -128,7 +140,7 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 
 	var passwordField epmodels.TypeInputFormField
 
-	for _, value := range thirdpartyemailpassword.GetRecipeInstance().Config.SignUpFeature.FormFields {
+	for _, value := range thirdpartyemailpassword.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config.SignUpFeature.FormFields {
 		if value.ID == "password" {
 			passwordField = value
 		}
@@ -143,7 +155,7 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 		}, nil
 	}
 
-	passwordResetToken, resetTokenErr := thirdpartyemailpassword.CreateResetPasswordToken(*readBody.UserId)
+	passwordResetToken, resetTokenErr := thirdpartyemailpassword.CreateResetPasswordTokenWithContext(*readBody.UserId, userContext)
 
 	if resetTokenErr != nil {
 		return userPasswordPutResponse{}, resetTokenErr
@@ -154,7 +166,7 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 		return userPasswordPutResponse{}, errors.New("Should never come here")
 	}
 
-	passwordResetResponse, passwordResetErr := thirdpartyemailpassword.ResetPasswordUsingToken(passwordResetToken.OK.Token, *readBody.NewPassword)
+	passwordResetResponse, passwordResetErr := thirdpartyemailpassword.ResetPasswordUsingTokenWithContext(passwordResetToken.OK.Token, *readBody.NewPassword, userContext)
 
 	if passwordResetErr != nil {
 		return userPasswordPutResponse{}, passwordResetErr
@@ -164,6 +176,13 @@ func UserPasswordPut(apiInterface dashboardmodels.APIInterface, options dashboar
 		return userPasswordPutResponse{}, errors.New("Should never come here")
 	}
 
+	if passwordResetResponse.PasswordPolicyViolatedError != nil {
+		return userPasswordPutResponse{
+			Status: "INVALID_PASSWORD_ERROR",
+			Error:  passwordResetResponse.PasswordPolicyViolatedError.FailureReason,
+		}, nil
+	}
+
 	return userPasswordPutResponse{
 		Status: "OK",
 	}, nil
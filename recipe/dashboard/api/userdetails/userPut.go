@@ -55,11 +55,11 @@ type userPutRequestBody struct {
 	Phone     *string `json:"phone"`
 }
 
-func updateEmailForRecipeId(recipeId string, userId string, email string) (updateEmailResponse, error) {
+func updateEmailForRecipeId(recipeId string, userId string, email string, userContext supertokens.UserContext) (updateEmailResponse, error) {
 	if recipeId == "emailpassword" {
 		var emailField epmodels.NormalisedFormField
 
-		for _, value := range emailpassword.GetRecipeInstance().Config.SignUpFeature.FormFields {
+		for _, value := range emailpassword.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config.SignUpFeature.FormFields {
 			if value.ID == "email" {
 				emailField = value
 			}
@@ -74,7 +74,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 			}, nil
 		}
 
-		updateResponse, err := emailpassword.UpdateEmailOrPassword(userId, &email, nil)
+		updateResponse, err := emailpassword.UpdateEmailOrPasswordWithContext(userId, &email, nil, userContext)
 
 		if err != nil {
 			return updateEmailResponse{}, err
@@ -98,7 +98,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 	if recipeId == "thirdpartyemailpassword" {
 		var emailField epmodels.TypeInputFormField
 
-		for _, value := range thirdpartyemailpassword.GetRecipeInstance().Config.SignUpFeature.FormFields {
+		for _, value := range thirdpartyemailpassword.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config.SignUpFeature.FormFields {
 			if value.ID == "email" {
 				emailField = value
 			}
@@ -113,7 +113,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 			}, nil
 		}
 
-		updateResponse, err := thirdpartyemailpassword.UpdateEmailOrPassword(userId, &email, nil)
+		updateResponse, err := thirdpartyemailpassword.UpdateEmailOrPasswordWithContext(userId, &email, nil, userContext)
 
 		if err != nil {
 			return updateEmailResponse{}, err
@@ -138,7 +138,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 		isValidEmail := true
 		validationError := ""
 
-		passwordlessConfig := passwordless.GetRecipeInstance().Config
+		passwordlessConfig := passwordless.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config
 
 		if passwordlessConfig.ContactMethodPhone.Enabled {
 			validationResult := passwordless.DefaultValidateEmailAddress(email)
@@ -170,7 +170,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 			}, nil
 		}
 
-		updateResponse, updateErr := passwordless.UpdateUser(userId, &email, nil)
+		updateResponse, updateErr := passwordless.UpdateUserWithContext(userId, &email, nil, userContext)
 
 		if updateErr != nil {
 			return updateEmailResponse{}, updateErr
@@ -195,7 +195,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 		isValidEmail := true
 		validationError := ""
 
-		passwordlessConfig := thirdpartypasswordless.GetRecipeInstance().Config
+		passwordlessConfig := thirdpartypasswordless.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config
 
 		if passwordlessConfig.ContactMethodPhone.Enabled {
 			validationResult := passwordless.DefaultValidateEmailAddress(email)
@@ -227,7 +227,7 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 			}, nil
 		}
 
-		updateResponse, updateErr := thirdpartypasswordless.UpdatePasswordlessUser(userId, &email, nil)
+		updateResponse, updateErr := thirdpartypasswordless.UpdatePasswordlessUserWithContext(userId, &email, nil, userContext)
 
 		if updateErr != nil {
 			return updateEmailResponse{}, updateErr
@@ -251,12 +251,12 @@ func updateEmailForRecipeId(recipeId string, userId string, email string) (updat
 	return updateEmailResponse{}, errors.New("Should never come here")
 }
 
-func updatePhoneForRecipeId(recipeId string, userId string, phone string) (updatePhoneResponse, error) {
+func updatePhoneForRecipeId(recipeId string, userId string, phone string, userContext supertokens.UserContext) (updatePhoneResponse, error) {
 	if recipeId == "passwordless" {
 		isValidPhone := true
 		validationError := ""
 
-		passwordlessConfig := passwordless.GetRecipeInstance().Config
+		passwordlessConfig := passwordless.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config
 
 		if passwordlessConfig.ContactMethodEmail.Enabled {
 			validationResult := passwordless.DefaultValidatePhoneNumber(phone)
@@ -288,7 +288,7 @@ func updatePhoneForRecipeId(recipeId string, userId string, phone string) (updat
 			}, nil
 		}
 
-		updateResponse, updateErr := passwordless.UpdateUser(userId, nil, &phone)
+		updateResponse, updateErr := passwordless.UpdateUserWithContext(userId, nil, &phone, userContext)
 
 		if updateErr != nil {
 			return updatePhoneResponse{}, updateErr
@@ -313,7 +313,7 @@ func updatePhoneForRecipeId(recipeId string, userId string, phone string) (updat
 		isValidPhone := true
 		validationError := ""
 
-		passwordlessConfig := thirdpartypasswordless.GetRecipeInstance().Config
+		passwordlessConfig := thirdpartypasswordless.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)).Config
 
 		if passwordlessConfig.ContactMethodEmail.Enabled {
 			validationResult := passwordless.DefaultValidatePhoneNumber(phone)
@@ -345,7 +345,7 @@ func updatePhoneForRecipeId(recipeId string, userId string, phone string) (updat
 			}, nil
 		}
 
-		updateResponse, updateErr := thirdpartypasswordless.UpdatePasswordlessUser(userId, nil, &phone)
+		updateResponse, updateErr := thirdpartypasswordless.UpdatePasswordlessUserWithContext(userId, nil, &phone, userContext)
 
 		if updateErr != nil {
 			return updatePhoneResponse{}, updateErr
@@ -373,6 +373,7 @@ func updatePhoneForRecipeId(recipeId string, userId string, phone string) (updat
 }
 
 func UserPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userPutResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	body, err := supertokens.ReadFromRequest(options.Req)
 
 	if err != nil {
@@ -421,12 +422,12 @@ func UserPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.
 		}
 	}
 
-	_, recipeId := api.GetUserForRecipeId(*readBody.UserId, *readBody.RecipeId)
+	_, recipeId := api.GetUserForRecipeIdWithContext(*readBody.UserId, *readBody.RecipeId, userContext)
 
 	if *readBody.FirstName != "" || *readBody.LastName != "" {
 		isRecipeInitialised := false
 
-		_, err = usermetadata.GetRecipeInstanceOrThrowError()
+		_, err = usermetadata.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 		if err == nil {
 			isRecipeInitialised = true
@@ -444,12 +445,12 @@ func UserPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.
 				metadataupdate["last_name"] = strings.TrimSpace(*readBody.LastName)
 			}
 
-			usermetadata.UpdateUserMetadata(*readBody.UserId, metadataupdate)
+			usermetadata.UpdateUserMetadataWithContext(*readBody.UserId, metadataupdate, userContext)
 		}
 	}
 
 	if strings.TrimSpace(*readBody.Email) != "" {
-		updateResponse, updateError := updateEmailForRecipeId(recipeId, *readBody.UserId, strings.TrimSpace(*readBody.Email))
+		updateResponse, updateError := updateEmailForRecipeId(recipeId, *readBody.UserId, strings.TrimSpace(*readBody.Email), userContext)
 
 		if updateError != nil {
 			return userPutResponse{}, updateError
@@ -464,7 +465,7 @@ func UserPut(apiInterface dashboardmodels.APIInterface, options dashboardmodels.
 	}
 
 	if strings.TrimSpace(*readBody.Phone) != "" {
-		updateResponse, updateError := updatePhoneForRecipeId(recipeId, *readBody.UserId, *readBody.Phone)
+		updateResponse, updateError := updatePhoneForRecipeId(recipeId, *readBody.UserId, *readBody.Phone, userContext)
 
 		if updateError != nil {
 			return userPutResponse{}, updateError
@@ -16,10 +16,9 @@
 package userdetails
 
 import (
-	"sync"
-
 	"github.com/supertokens/supertokens-golang/recipe/dashboard/dashboardmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -30,6 +29,8 @@ type SessionType struct {
 	Expiry             uint64      `json:"expiry"`
 	TimeCreated        uint64      `json:"timeCreated"`
 	SessionHandle      string      `json:"sessionHandle"`
+
+	Metadata *sessmodels.SessionMetadata `json:"metadata,omitempty"`
 }
 
 type userSessionsGetResponse struct {
@@ -38,6 +39,7 @@ type userSessionsGetResponse struct {
 }
 
 func UserSessionsGet(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userSessionsGetResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	req := options.Req
 	userId := req.URL.Query().Get("userId")
 
@@ -47,52 +49,25 @@ func UserSessionsGet(apiInterface dashboardmodels.APIInterface, options dashboar
 		}
 	}
 
-	response, err := session.GetAllSessionHandlesForUser(userId)
+	response, err := session.GetSessionsForUserWithContext(userId, userContext)
 
 	if err != nil {
 		return userSessionsGetResponse{}, err
 	}
 
 	sessions := []SessionType{}
-
-	var processingGroup sync.WaitGroup
-	processingGroup.Add(len(response))
-	var errInBackground error
-
-	for i, sessionHandle := range response {
-		if errInBackground != nil {
-			return userSessionsGetResponse{}, errInBackground
-		}
-
-		go func(i int, handle string) {
-			sessionResponse, sessionError := session.GetSessionInformation(handle)
-
-			if sessionError != nil {
-				errInBackground = sessionError
-				return
-			}
-
-			if sessionResponse != nil {
-				sessions = append(sessions, SessionType{
-					SessionData:        sessionResponse.SessionData,
-					AccessTokenPayload: sessionResponse.AccessTokenPayload,
-					UserId:             sessionResponse.UserId,
-					Expiry:             sessionResponse.Expiry,
-					TimeCreated:        sessionResponse.TimeCreated,
-					SessionHandle:      sessionResponse.SessionHandle,
-				})
-			}
-
-			defer processingGroup.Done()
-		}(i, sessionHandle)
+	for _, sessionResponse := range response {
+		sessions = append(sessions, SessionType{
+			SessionData:        sessionResponse.SessionData,
+			AccessTokenPayload: sessionResponse.AccessTokenPayload,
+			UserId:             sessionResponse.UserId,
+			Expiry:             sessionResponse.Expiry,
+			TimeCreated:        sessionResponse.TimeCreated,
+			SessionHandle:      sessionResponse.SessionHandle,
+			Metadata:           sessionResponse.Metadata,
+		})
 	}
 
-	if errInBackground != nil {
-		return userSessionsGetResponse{}, errInBackground
-	}
-
-	processingGroup.Wait()
-
 	return userSessionsGetResponse{
 		Status:   "OK",
 		Sessions: sessions,
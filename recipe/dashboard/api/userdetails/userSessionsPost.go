@@ -32,6 +32,7 @@ type userSessionsPostRequestBody struct {
 }
 
 func UserSessionsRevoke(apiInterface dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (userSessionsPostResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	body, err := supertokens.ReadFromRequest(options.Req)
 
 	if err != nil {
@@ -52,7 +53,7 @@ func UserSessionsRevoke(apiInterface dashboardmodels.APIInterface, options dashb
 		}
 	}
 
-	session.RevokeMultipleSessions(*sessionHandles)
+	session.RevokeMultipleSessionsWithContext(*sessionHandles, userContext)
 
 	return userSessionsPostResponse{
 		Status: "OK",
@@ -11,7 +11,12 @@ type usersCountGetResponse struct {
 }
 
 func UsersCountGet(apiImplementation dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (usersCountGetResponse, error) {
-	count, err := supertokens.GetUserCount(nil)
+	stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	if err != nil {
+		return usersCountGetResponse{}, err
+	}
+
+	count, err := stInstance.GetUserCount(nil)
 	if err != nil {
 		return usersCountGetResponse{}, err
 	}
@@ -31,6 +31,7 @@ type User struct {
 }
 
 func UsersGet(apiImplementation dashboardmodels.APIInterface, options dashboardmodels.APIOptions) (UsersGetResponse, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
 	req := options.Req
 	limitStr := req.URL.Query().Get("limit")
 
@@ -62,18 +63,23 @@ func UsersGet(apiImplementation dashboardmodels.APIInterface, options dashboardm
 		paginationTokenPtr = &paginationToken
 	}
 
+	stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return UsersGetResponse{}, err
+	}
+
 	var usersResponse supertokens.UserPaginationResult
 
 	if timeJoinedOrder == "ASC" {
-		usersResponse, err = supertokens.GetUsersOldestFirst(paginationTokenPtr, &limit, nil)
+		usersResponse, err = stInstance.GetUsersOldestFirst(paginationTokenPtr, &limit, nil)
 	} else {
-		usersResponse, err = supertokens.GetUsersNewestFirst(paginationTokenPtr, &limit, nil)
+		usersResponse, err = stInstance.GetUsersNewestFirst(paginationTokenPtr, &limit, nil)
 	}
 	if err != nil {
 		return UsersGetResponse{}, err
 	}
 
-	_, err = usermetadata.GetRecipeInstanceOrThrowError()
+	_, err = usermetadata.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return UsersGetResponse{
 			Status:              "OK",
@@ -101,7 +107,7 @@ func UsersGet(apiImplementation dashboardmodels.APIInterface, options dashboardm
 			User     map[string]interface{} `json:"user"`
 		}) {
 			defer processingGroup.Done()
-			userMetadataResponse, err := usermetadata.GetUserMetadata(userObj.User["id"].(string))
+			userMetadataResponse, err := usermetadata.GetUserMetadataWithContext(userObj.User["id"].(string), userContext)
 			<-sem
 			if err != nil {
 				errInBackground = err
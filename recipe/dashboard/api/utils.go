@@ -7,6 +7,7 @@ import (
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword"
 	"github.com/supertokens/supertokens-golang/recipe/thirdpartypasswordless"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 func IsValidRecipeId(recipeId string) bool {
@@ -26,11 +27,16 @@ recipe so we need to check for both.
 If this function returns an empty user struct, it should be treated as if the user does not exist
 */
 func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.UserType, recipe string) {
+	return GetUserForRecipeIdWithContext(userId, recipeId, &map[string]interface{}{})
+}
+
+// GetUserForRecipeIdWithContext is GetUserForRecipeId for the instance that handles the user context.
+func GetUserForRecipeIdWithContext(userId string, recipeId string, userContext supertokens.UserContext) (user dashboardmodels.UserType, recipe string) {
 	var userToReturn dashboardmodels.UserType
 	var recipeToReturn string
 
 	if recipeId == emailpassword.RECIPE_ID {
-		response, error := emailpassword.GetUserByID(userId)
+		response, error := emailpassword.GetUserByIDWithContext(userId, userContext)
 
 		if error == nil {
 			userToReturn.Id = response.ID
@@ -43,7 +49,7 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 		}
 
 		if userToReturn == (dashboardmodels.UserType{}) {
-			tpepResponse, tpepError := thirdpartyemailpassword.GetUserById(userId)
+			tpepResponse, tpepError := thirdpartyemailpassword.GetUserByIdWithContext(userId, userContext)
 
 			if tpepError == nil {
 				userToReturn.Id = tpepResponse.ID
@@ -56,7 +62,7 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 			}
 		}
 	} else if recipeId == thirdparty.RECIPE_ID {
-		response, error := thirdparty.GetUserByID(userId)
+		response, error := thirdparty.GetUserByIDWithContext(userId, userContext)
 
 		if error == nil {
 			userToReturn.Id = response.ID
@@ -65,13 +71,13 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 			userToReturn.LastName = ""
 			userToReturn.Email = response.Email
 			userToReturn.ThirdParty = &dashboardmodels.ThirdParty{
-				Id: response.ThirdParty.ID,
+				Id:     response.ThirdParty.ID,
 				UserId: response.ThirdParty.UserID,
 			}
 		}
 
 		if userToReturn == (dashboardmodels.UserType{}) {
-			tpepResponse, tpepError := thirdpartyemailpassword.GetUserById(userId)
+			tpepResponse, tpepError := thirdpartyemailpassword.GetUserByIdWithContext(userId, userContext)
 
 			if tpepError == nil {
 				userToReturn.Id = tpepResponse.ID
@@ -80,13 +86,13 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 				userToReturn.LastName = ""
 				userToReturn.Email = tpepResponse.Email
 				userToReturn.ThirdParty = &dashboardmodels.ThirdParty{
-					Id: tpepResponse.ThirdParty.ID,
+					Id:     tpepResponse.ThirdParty.ID,
 					UserId: tpepResponse.ThirdParty.UserID,
 				}
 			}
 		}
 	} else if recipeId == passwordless.RECIPE_ID {
-		response, error := passwordless.GetUserByID(userId)
+		response, error := passwordless.GetUserByIDWithContext(userId, userContext)
 
 		if error == nil {
 			userToReturn.Id = response.ID
@@ -104,7 +110,7 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 		}
 
 		if userToReturn == (dashboardmodels.UserType{}) {
-			tppResponse, tppError := thirdpartypasswordless.GetUserByID(userId)
+			tppResponse, tppError := thirdpartypasswordless.GetUserByIDWithContext(userId, userContext)
 
 			if tppError == nil {
 				userToReturn.Id = tppResponse.ID
@@ -127,45 +133,50 @@ func GetUserForRecipeId(userId string, recipeId string) (user dashboardmodels.Us
 }
 
 func IsRecipeInitialised(recipeId string) bool {
+	return IsRecipeInitialisedWithContext(recipeId, &map[string]interface{}{})
+}
+
+// IsRecipeInitialisedWithContext is IsRecipeInitialised for the instance that handles the user context.
+func IsRecipeInitialisedWithContext(recipeId string, userContext supertokens.UserContext) bool {
 	isRecipeInitialised := false
 
 	if recipeId == emailpassword.RECIPE_ID {
-		_, err := emailpassword.GetRecipeInstanceOrThrowError()
+		_, err := emailpassword.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 		if err == nil {
 			isRecipeInitialised = true
 		}
 
 		if !isRecipeInitialised {
-			_, err := thirdpartyemailpassword.GetRecipeInstanceOrThrowError()
+			_, err := thirdpartyemailpassword.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 			if err == nil {
 				isRecipeInitialised = true
 			}
 		}
 	} else if recipeId == passwordless.RECIPE_ID {
-		_, err := passwordless.GetRecipeInstanceOrThrowError()
+		_, err := passwordless.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 		if err == nil {
 			isRecipeInitialised = true
 		}
 
 		if !isRecipeInitialised {
-			_, err := thirdpartypasswordless.GetRecipeInstanceOrThrowError()
+			_, err := thirdpartypasswordless.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 			if err == nil {
 				isRecipeInitialised = true
 			}
 		}
 	} else if recipeId == thirdparty.RECIPE_ID {
-		_, err := thirdparty.GetRecipeInstanceOrThrowError()
+		_, err := thirdparty.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 		if err == nil {
 			isRecipeInitialised = true
 		}
 
 		if !isRecipeInitialised {
-			_, err := thirdpartyemailpassword.GetRecipeInstanceOrThrowError()
+			_, err := thirdpartyemailpassword.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 			if err == nil {
 				isRecipeInitialised = true
@@ -173,7 +184,7 @@ func IsRecipeInitialised(recipeId string) bool {
 		}
 
 		if !isRecipeInitialised {
-			_, err := thirdpartypasswordless.GetRecipeInstanceOrThrowError()
+			_, err := thirdpartypasswordless.GetRecipeInstanceFromUserContextOrThrowError(userContext)
 
 			if err == nil {
 				isRecipeInitialised = true
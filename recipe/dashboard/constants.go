@@ -10,3 +10,4 @@ const userSessionsAPI = "/api/user/sessions"
 const userMetaDataAPI = "/api/user/metadata"
 const userEmailVerifyTokenAPI = "/api/user/email/verify/token"
 const userPasswordAPI = "/api/user/password"
+const userImpersonateAPI = "/api/user/impersonate"
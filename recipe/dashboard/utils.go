@@ -124,5 +124,10 @@ func getApiIdIfMatched(path supertokens.NormalisedURLPath, method string) (*stri
 		return &val, nil
 	}
 
+	if method == http.MethodPost && strings.HasSuffix(path.GetAsStringDangerous(), userImpersonateAPI) {
+		val := userImpersonateAPI
+		return &val, nil
+	}
+
 	return nil, nil
 }
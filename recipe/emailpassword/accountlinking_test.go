@@ -0,0 +1,97 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func getSessionUserId(t *testing.T, resp *http.Response) string {
+	b64Bytes, err := base64.StdEncoding.DecodeString(resp.Header.Get("front-token"))
+	assert.NoError(t, err)
+	frontendInfo := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(b64Bytes, &frontendInfo))
+	return frontendInfo["uid"].(string)
+}
+
+func TestSessionIsCreatedForPrimaryUserAfterLinking(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	testServer := supertokensInitForTest(t,
+		Init(nil),
+		session.Init(nil),
+		accountlinking.Init(nil),
+	)
+	defer testServer.Close()
+
+	querier, err := supertokens.GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	verifyEmail := func(userId string) {
+		response, err := querier.SendPostRequest("/recipe/user/email/verify/token", map[string]interface{}{
+			"userId": userId,
+			"email":  "random@gmail.com",
+		})
+		assert.NoError(t, err)
+		_, err = querier.SendPostRequest("/recipe/user/email/verify", map[string]interface{}{
+			"method": "token",
+			"token":  response["token"],
+		})
+		assert.NoError(t, err)
+	}
+
+	response, err := querier.SendPostRequest("/recipe/signinup", map[string]interface{}{
+		"thirdPartyId":     "google",
+		"thirdPartyUserId": "googleUserId",
+		"email":            map[string]interface{}{"id": "random@gmail.com"},
+	})
+	assert.NoError(t, err)
+	primaryUserId := response["user"].(map[string]interface{})["id"].(string)
+	verifyEmail(primaryUserId)
+	primaryResponse, err := accountlinking.CreatePrimaryUser(primaryUserId)
+	assert.NoError(t, err)
+	assert.NotNil(t, primaryResponse.OK)
+
+	// the email of the new user is not verified, so it is not linked yet
+	resp, err := unittesting.SignupRequest("random@gmail.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	recipeUserId := getSessionUserId(t, resp)
+	assert.NotEqual(t, primaryUserId, recipeUserId)
+
+	verifyEmail(recipeUserId)
+	resp, err = unittesting.SignInRequest("random@gmail.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, primaryUserId, getSessionUserId(t, resp))
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, recipeUserId, result["user"].(map[string]interface{})["id"])
+
+	user, err := accountlinking.GetUser(recipeUserId)
+	assert.NoError(t, err)
+	assert.Equal(t, primaryUserId, user.ID)
+	assert.Len(t, user.LoginMethods, 2)
+}
@@ -39,12 +39,13 @@ func GeneratePasswordResetToken(apiImplementation epmodels.APIInterface, options
 		return err
 	}
 
-	formFields, err := validateFormFieldsOrThrowError(options.Config.ResetPasswordUsingTokenFeature.FormFieldsForGenerateTokenForm, formFieldsRaw["formFields"].([]interface{}))
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	formFields, err := validateFormFieldsOrThrowError(options.Config.ResetPasswordUsingTokenFeature.FormFieldsForGenerateTokenForm, formFieldsRaw["formFields"].([]interface{}), userContext)
 	if err != nil {
 		return err
 	}
 
-	resp, err := (*apiImplementation.GeneratePasswordResetTokenPOST)(formFields, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	resp, err := (*apiImplementation.GeneratePasswordResetTokenPOST)(formFields, options, userContext)
 	if err != nil {
 		return err
 	}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
@@ -116,6 +117,10 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			return epmodels.ResetPasswordPOSTResponse{
 				OK: response.OK,
 			}, nil
+		} else if response.PasswordPolicyViolatedError != nil {
+			return epmodels.ResetPasswordPOSTResponse{
+				PasswordPolicyViolatedError: response.PasswordPolicyViolatedError,
+			}, nil
 		} else {
 			return epmodels.ResetPasswordPOSTResponse{
 				ResetPasswordInvalidTokenError: response.ResetPasswordInvalidTokenError,
@@ -148,7 +153,11 @@ func MakeAPIImplementation() epmodels.APIInterface {
 		}
 
 		user := response.OK.User
-		session, err := session.CreateNewSessionWithContext(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		sessionUserID, err := accountlinking.GetPrimaryUserIdAfterSignInUp(user.ID, userContext)
+		if err != nil {
+			return epmodels.SignInPOSTResponse{}, err
+		}
+		session, err := session.CreateNewSessionOrReauthenticateWithContext(options.Req, options.Res, sessionUserID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
 			return epmodels.SignInPOSTResponse{}, err
 		}
@@ -190,7 +199,11 @@ func MakeAPIImplementation() epmodels.APIInterface {
 
 		user := response.OK.User
 
-		session, err := session.CreateNewSessionWithContext(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		sessionUserID, err := accountlinking.GetPrimaryUserIdAfterSignInUp(user.ID, userContext)
+		if err != nil {
+			return epmodels.SignUpPOSTResponse{}, err
+		}
+		session, err := session.CreateNewSessionOrReauthenticateWithContext(options.Req, options.Res, sessionUserID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
 			return epmodels.SignUpPOSTResponse{}, err
 		}
@@ -20,6 +20,7 @@ import (
 	"reflect"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -39,7 +40,8 @@ func PasswordReset(apiImplementation epmodels.APIInterface, options epmodels.API
 		return err
 	}
 
-	formFields, err := validateFormFieldsOrThrowError(options.Config.ResetPasswordUsingTokenFeature.FormFieldsForPasswordResetForm, formFieldsRaw["formFields"].([]interface{}))
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	formFields, err := validateFormFieldsOrThrowError(options.Config.ResetPasswordUsingTokenFeature.FormFieldsForPasswordResetForm, formFieldsRaw["formFields"].([]interface{}), userContext)
 	if err != nil {
 		return err
 	}
@@ -52,7 +54,7 @@ func PasswordReset(apiImplementation epmodels.APIInterface, options epmodels.API
 		return supertokens.BadInputError{Msg: "The password reset token must be a string"}
 	}
 
-	result, err := (*apiImplementation.PasswordResetPOST)(formFields, token.(string), options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	result, err := (*apiImplementation.PasswordResetPOST)(formFields, token.(string), options, userContext)
 	if err != nil {
 		return err
 	}
@@ -64,6 +66,14 @@ func PasswordReset(apiImplementation epmodels.APIInterface, options epmodels.API
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "RESET_PASSWORD_INVALID_TOKEN_ERROR",
 		})
+	} else if result.PasswordPolicyViolatedError != nil {
+		return errors.FieldError{
+			Msg: "Error in input formFields",
+			Payload: []errors.ErrorPayload{{
+				ID:       "password",
+				ErrorMsg: result.PasswordPolicyViolatedError.FailureReason,
+			}},
+		}
 	} else if result.GeneralError != nil {
 		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
 	}
@@ -38,12 +38,13 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return err
 	}
 
-	formFields, err := validateFormFieldsOrThrowError(options.Config.SignInFeature.FormFields, formFieldsRaw["formFields"].([]interface{}))
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	formFields, err := validateFormFieldsOrThrowError(options.Config.SignInFeature.FormFields, formFieldsRaw["formFields"].([]interface{}), userContext)
 	if err != nil {
 		return err
 	}
 
-	result, err := (*apiImplementation.SignInPOST)(formFields, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	result, err := (*apiImplementation.SignInPOST)(formFields, options, userContext)
 	if err != nil {
 		return err
 	}
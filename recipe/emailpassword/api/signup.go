@@ -39,12 +39,13 @@ func SignUpAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return err
 	}
 
-	formFields, err := validateFormFieldsOrThrowError(options.Config.SignUpFeature.FormFields, formFieldsRaw["formFields"].([]interface{}))
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	formFields, err := validateFormFieldsOrThrowError(options.Config.SignUpFeature.FormFields, formFieldsRaw["formFields"].([]interface{}), userContext)
 	if err != nil {
 		return err
 	}
 
-	result, err := (*apiImplementation.SignUpPOST)(formFields, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+	result, err := (*apiImplementation.SignUpPOST)(formFields, options, userContext)
 	if err != nil {
 		return err
 	}
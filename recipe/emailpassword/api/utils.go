@@ -22,9 +22,10 @@ import (
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func validateFormFieldsOrThrowError(configFormFields []epmodels.NormalisedFormField, formFieldsRaw []interface{}) ([]epmodels.TypeFormField, error) {
+func validateFormFieldsOrThrowError(configFormFields []epmodels.NormalisedFormField, formFieldsRaw []interface{}, userContext supertokens.UserContext) ([]epmodels.TypeFormField, error) {
 	if formFieldsRaw == nil {
 		return nil, defaultErrors.New("Missing input param: formFields")
 	}
@@ -58,10 +59,10 @@ func validateFormFieldsOrThrowError(configFormFields []epmodels.NormalisedFormFi
 		}
 	}
 
-	return formFields, validateFormOrThrowError(configFormFields, formFields)
+	return formFields, validateFormOrThrowError(configFormFields, formFields, userContext)
 }
 
-func validateFormOrThrowError(configFormFields []epmodels.NormalisedFormField, inputs []epmodels.TypeFormField) error {
+func validateFormOrThrowError(configFormFields []epmodels.NormalisedFormField, inputs []epmodels.TypeFormField, userContext supertokens.UserContext) error {
 	var validationErrors []errors.ErrorPayload
 	if len(configFormFields) != len(inputs) {
 		return defaultErrors.New("Are you sending too many / too few formFields?")
@@ -77,11 +78,14 @@ func validateFormOrThrowError(configFormFields []epmodels.NormalisedFormField, i
 		if input.Value == "" && !field.Optional {
 			validationErrors = append(validationErrors, errors.ErrorPayload{ID: field.ID, ErrorMsg: "Field is not optional"})
 		} else {
-			err := field.Validate(input.Value)
+			validationError, err := field.ValidateValue(input.Value, inputs, userContext)
 			if err != nil {
+				return err
+			}
+			if validationError != nil {
 				validationErrors = append(validationErrors, errors.ErrorPayload{
 					ID:       field.ID,
-					ErrorMsg: *err,
+					ErrorMsg: *validationError,
 				})
 			}
 		}
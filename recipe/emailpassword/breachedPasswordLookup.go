@@ -0,0 +1,81 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// PwnedPasswordsLookup looks up breached passwords with the range API of Have I Been Pwned.
+// It can be used as the Lookup of epmodels.BreachCheck.
+type PwnedPasswordsLookup struct {
+	// BaseURL defaults to https://api.pwnedpasswords.com
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+var defaultPwnedPasswordsHTTPClient = &http.Client{
+	Timeout: 5 * time.Second,
+}
+
+func (lookup PwnedPasswordsLookup) GetBreachedSuffixes(hashPrefix string, userContext supertokens.UserContext) (map[string]int, error) {
+	baseURL := "https://api.pwnedpasswords.com"
+	if lookup.BaseURL != "" {
+		baseURL = strings.TrimSuffix(lookup.BaseURL, "/")
+	}
+	client := lookup.HTTPClient
+	if client == nil {
+		client = defaultPwnedPasswordsHTTPClient
+	}
+
+	req, err := http.NewRequestWithContext(supertokens.GetContextFromUserContext(userContext), http.MethodGet, baseURL+"/range/"+hashPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	// padding hides the number of suffixes of the prefix from observers of the response size
+	req.Header.Set("Add-Padding", "true")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pwned passwords lookup failed with status code %d", res.StatusCode)
+	}
+
+	// every line of the response is <suffix>:<count>, and padding lines have a count of 0
+	suffixes := map[string]int{}
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil || count == 0 {
+			continue
+		}
+		suffixes[strings.ToUpper(parts[0])] = count
+	}
+	return suffixes, scanner.Err()
+}
@@ -17,7 +17,9 @@
 package emailpassword
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -561,3 +563,47 @@ func TestSMTPOverrideEmailVerifyForEmailPasswordUser(t *testing.T) {
 	assert.Equal(t, getContentCalled, true)
 	assert.Equal(t, sendRawEmailCalled, true)
 }
+
+func TestProviderServicePasswordResetForEmailPasswordUser(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	var sendGridRequest map[string]interface{}
+	sendGridServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer apiKey", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&sendGridRequest))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sendGridServer.Close()
+
+	epConfig := &epmodels.TypeInput{
+		EmailDelivery: &emaildelivery.TypeInput{
+			Service: MakeProviderService(emaildelivery.ProviderServiceConfig{
+				Provider: emaildelivery.SendGridProvider(emaildelivery.SendGridSettings{
+					APIKey: "apiKey",
+					From: emaildelivery.SMTPFrom{
+						Name:  "Test User",
+						Email: "noreply@supertokens.io",
+					},
+					BaseURL: sendGridServer.URL,
+				}),
+			}),
+		},
+	}
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(epConfig))
+	defer testServer.Close()
+
+	SignUp("test@example.com", "1234abcd")
+	resp, err := unittesting.PasswordResetTokenRequest("test@example.com", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.False(t, PasswordResetEmailSentForTest)
+	assert.Equal(t, "Password reset instructions", sendGridRequest["subject"])
+	contents := sendGridRequest["content"].([]interface{})
+	assert.Len(t, contents, 2)
+	assert.Equal(t, "text/plain", contents[0].(map[string]interface{})["type"])
+	assert.Contains(t, contents[1].(map[string]interface{})["value"], "test@example.com")
+}
@@ -47,7 +47,10 @@ type ResetPasswordPOSTResponse struct {
 		UserId *string
 	}
 	ResetPasswordInvalidTokenError *struct{}
-	GeneralError                   *supertokens.GeneralErrorResponse
+	PasswordPolicyViolatedError    *struct {
+		FailureReason string
+	}
+	GeneralError *supertokens.GeneralErrorResponse
 }
 
 type SignUpPOSTResponse struct {
@@ -25,6 +25,7 @@ type TypeNormalisedInput struct {
 	SignInFeature                  TypeNormalisedInputSignIn
 	ResetPasswordUsingTokenFeature TypeNormalisedInputResetPasswordUsingTokenFeature
 	Override                       OverrideStruct
+	PasswordPolicy                 *PasswordPolicy
 	GetEmailDeliveryConfig         func(recipeImpl RecipeInterface) emaildelivery.TypeInputWithService
 }
 
@@ -36,7 +37,11 @@ type OverrideStruct struct {
 type TypeInputFormField struct {
 	ID       string
 	Validate func(value interface{}) *string
-	Optional *bool
+	// ValidateWithContext is used instead of Validate if set. It gets all fields of the form,
+	// for example to compare the password with the email, and can return an error if the value
+	// could not be checked.
+	ValidateWithContext func(value interface{}, formFields []TypeFormField, userContext supertokens.UserContext) (*string, error)
+	Optional            *bool
 }
 
 type TypeInputSignUp struct {
@@ -44,9 +49,18 @@ type TypeInputSignUp struct {
 }
 
 type NormalisedFormField struct {
-	ID       string
-	Validate func(value interface{}) *string
-	Optional bool
+	ID                  string
+	Validate            func(value interface{}) *string
+	ValidateWithContext func(value interface{}, formFields []TypeFormField, userContext supertokens.UserContext) (*string, error)
+	Optional            bool
+}
+
+// ValidateValue validates the value with ValidateWithContext if it is set, and with Validate otherwise.
+func (field NormalisedFormField) ValidateValue(value interface{}, formFields []TypeFormField, userContext supertokens.UserContext) (*string, error) {
+	if field.ValidateWithContext != nil {
+		return field.ValidateWithContext(value, formFields, userContext)
+	}
+	return field.Validate(value), nil
 }
 
 type TypeNormalisedInputSignUp struct {
@@ -77,6 +91,7 @@ type TypeInput struct {
 	ResetPasswordUsingTokenFeature *TypeInputResetPasswordUsingTokenFeature
 	Override                       *OverrideStruct
 	EmailDelivery                  *emaildelivery.TypeInput
+	PasswordPolicy                 *PasswordPolicy
 }
 
 type TypeFormField struct {
@@ -0,0 +1,81 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package epmodels
+
+import "github.com/supertokens/supertokens-golang/supertokens"
+
+// PasswordPolicy replaces the default password validator of the sign up and password reset forms.
+// It is also enforced by ResetPasswordUsingToken and UpdateEmailOrPassword.
+type PasswordPolicy struct {
+	// MinLength defaults to 8 and MaxLength to 100 characters.
+	MinLength int
+	MaxLength int
+
+	RequireLetter    bool
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+
+	// DisallowUserInputs rejects passwords that contain the local part of the user's email, or
+	// the value of one of the UserInputFormFields of the sign up form, like "name".
+	DisallowUserInputs  bool
+	UserInputFormFields []string
+
+	// MinStrengthScore is the minimum zxcvbn score (0 to 4) of passwords. The user inputs are
+	// taken into account for the score. Passwords are not scored if it is 0.
+	MinStrengthScore int
+
+	// History prevents users from reusing their previous passwords.
+	History *PasswordHistory
+
+	// BreachCheck rejects passwords that appeared in data breaches.
+	BreachCheck *BreachCheck
+}
+
+type PasswordHistory struct {
+	// Size is the number of passwords, including the current one, that can not be reused.
+	Size  int
+	Store PasswordHistoryStore
+}
+
+// PasswordHistoryStore stores the bcrypt hashes of the previous passwords of users, most recent
+// first. Passwords are only added to the history when they are set after the policy is enabled.
+type PasswordHistoryStore interface {
+	GetPasswordHashes(userID string, userContext supertokens.UserContext) ([]string, error)
+	SetPasswordHashes(userID string, hashes []string, userContext supertokens.UserContext) error
+}
+
+type BreachCheck struct {
+	Lookup BreachedPasswordLookup
+
+	// MinOccurrences is the number of breaches a password must have appeared in to be rejected.
+	// It defaults to 1.
+	MinOccurrences int
+
+	// FailClosed makes validation fail with the error of the lookup if it is not available.
+	// Otherwise, passwords are accepted if they could not be checked.
+	FailClosed bool
+}
+
+// BreachedPasswordLookup finds breached passwords using k-anonymity, so that neither the password
+// nor its full hash leave the server.
+type BreachedPasswordLookup interface {
+	// GetBreachedSuffixes returns the suffixes of the upper-case hex encoded SHA-1 hashes of breached
+	// passwords that start with hashPrefix (the first 5 characters), with the number of times they
+	// appeared in breaches.
+	GetBreachedSuffixes(hashPrefix string, userContext supertokens.UserContext) (map[string]int, error)
+}
@@ -53,10 +53,16 @@ type ResetPasswordUsingTokenResponse struct {
 		UserId *string
 	}
 	ResetPasswordInvalidTokenError *struct{}
+	PasswordPolicyViolatedError    *struct {
+		FailureReason string
+	}
 }
 
 type UpdateEmailOrPasswordResponse struct {
-	OK                      *struct{}
-	UnknownUserIdError      *struct{}
-	EmailAlreadyExistsError *struct{}
+	OK                          *struct{}
+	UnknownUserIdError          *struct{}
+	EmailAlreadyExistsError     *struct{}
+	PasswordPolicyViolatedError *struct {
+		FailureReason string
+	}
 }
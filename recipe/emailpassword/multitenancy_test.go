@@ -0,0 +1,156 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func tenantSignupRequest(t *testing.T, testUrl string, tenantId string, email string) *http.Response {
+	postBody, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]string{
+			{"id": "email", "value": email},
+			{"id": "password", "value": "validpass123"},
+		},
+	})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, testUrl+"/auth/signup", bytes.NewBuffer(postBody))
+	assert.NoError(t, err)
+	req.Header.Set("st-tenant-id", tenantId)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func TestSignUpIsScopedToTenant(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	customAntiCsrfVal := "NONE"
+	testServer := supertokensInitForTest(t,
+		Init(nil),
+		session.Init(&sessmodels.TypeInput{
+			AntiCsrf: &customAntiCsrfVal,
+		}),
+		multitenancy.Init(&multitenancymodels.TypeInput{
+			Tenants: map[string]multitenancymodels.TenantConfig{
+				"acme": {
+					EmailPassword: multitenancymodels.EmailPasswordConfig{Enabled: true},
+				},
+				"beta": {
+					Passwordless: multitenancymodels.PasswordlessConfig{Enabled: true},
+				},
+			},
+		}),
+	)
+	defer testServer.Close()
+
+	resp := tenantSignupRequest(t, testServer.URL, "", "random@gmail.com")
+	assert.Equal(t, 200, resp.StatusCode)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+
+	// the same email can sign up with another tenant
+	resp = tenantSignupRequest(t, testServer.URL, "acme", "random@gmail.com")
+	assert.Equal(t, 200, resp.StatusCode)
+	frontToken := resp.Header.Get("front-token")
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+
+	b64Bytes, err := base64.StdEncoding.DecodeString(frontToken)
+	assert.NoError(t, err)
+	frontendInfo := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(b64Bytes, &frontendInfo))
+	assert.Equal(t, "acme", frontendInfo["up"].(map[string]interface{})["tId"].(map[string]interface{})["v"])
+
+	resp = tenantSignupRequest(t, testServer.URL, "acme", "random@gmail.com")
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "FIELD_ERROR", result["status"])
+
+	resp = tenantSignupRequest(t, testServer.URL, "beta", "random@gmail.com")
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp = tenantSignupRequest(t, testServer.URL, "unknown", "random@gmail.com")
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestSessionIsRejectedForOtherTenant(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	customAntiCsrfVal := "NONE"
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(&sessmodels.TypeInput{
+				AntiCsrf: &customAntiCsrfVal,
+			}),
+			multitenancy.Init(&multitenancymodels.TypeInput{
+				Tenants: map[string]multitenancymodels.TenantConfig{
+					"acme": {
+						EmailPassword: multitenancymodels.EmailPasswordConfig{Enabled: true},
+					},
+				},
+			}),
+		},
+	})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/protected", session.VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(200)
+	}))
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	resp := tenantSignupRequest(t, testServer.URL, "acme", "random@gmail.com")
+	assert.Equal(t, 200, resp.StatusCode)
+	cookieData := unittesting.ExtractInfoFromResponseWhenAntiCSRFisNone(resp)
+
+	for tenantId, expectedStatus := range map[string]int{"acme": 200, "": 403} {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/protected", nil)
+		assert.NoError(t, err)
+		req.Header.Set("st-tenant-id", tenantId)
+		req.AddCookie(&http.Cookie{Name: "sAccessToken", Value: cookieData["sAccessToken"]})
+		req.AddCookie(&http.Cookie{Name: "sIdRefreshToken", Value: cookieData["sIdRefreshToken"]})
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedStatus, resp.StatusCode, tenantId)
+	}
+}
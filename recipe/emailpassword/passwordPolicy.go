@@ -0,0 +1,423 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	defaultErrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nbutton23/zxcvbn-go"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minCDIVersionForPasswordResetTokenConsume is the first version of the core driver interface that
+// can consume a password reset token without setting the password.
+const minCDIVersionForPasswordResetTokenConsume = "4.0"
+
+// needsUserBeforePasswordReset is true if the policy has rules that need the user of a password
+// reset token before the new password is set.
+func needsUserBeforePasswordReset(policy *epmodels.PasswordPolicy) bool {
+	return policy.History != nil || policy.DisallowUserInputs
+}
+
+func requireCoreWithPasswordResetTokenConsume(ctx context.Context, querier supertokens.Querier) error {
+	return querier.RequireCoreAPIVersionWithContext(ctx, minCDIVersionForPasswordResetTokenConsume, "PasswordPolicy with History or DisallowUserInputs")
+}
+
+// NormalisePasswordPolicy sets the defaults of the policy and validates it. It is used by the recipes
+// that are built on emailpassword.
+func NormalisePasswordPolicy(policy epmodels.PasswordPolicy) (*epmodels.PasswordPolicy, error) {
+	if policy.MinLength == 0 {
+		policy.MinLength = 8
+	}
+	if policy.MaxLength == 0 {
+		policy.MaxLength = 100
+	}
+	if policy.MinLength > policy.MaxLength {
+		return nil, defaultErrors.New("PasswordPolicy.MinLength must not be greater than MaxLength")
+	}
+	if policy.MinStrengthScore < 0 || policy.MinStrengthScore > 4 {
+		return nil, defaultErrors.New("PasswordPolicy.MinStrengthScore must be between 0 and 4")
+	}
+	if policy.History != nil {
+		if policy.History.Size <= 0 {
+			return nil, defaultErrors.New("PasswordPolicy.History.Size must be greater than 0")
+		}
+		if policy.History.Store == nil {
+			return nil, defaultErrors.New("PasswordPolicy.History.Store is required")
+		}
+	}
+	if policy.BreachCheck != nil {
+		breachCheck := *policy.BreachCheck
+		if breachCheck.Lookup == nil {
+			return nil, defaultErrors.New("PasswordPolicy.BreachCheck.Lookup is required")
+		}
+		if breachCheck.MinOccurrences <= 0 {
+			breachCheck.MinOccurrences = 1
+		}
+		policy.BreachCheck = &breachCheck
+	}
+	return &policy, nil
+}
+
+// validatePasswordWithPolicy checks the password against all rules of the policy except the
+// history, which needs the ID of the user.
+func validatePasswordWithPolicy(policy *epmodels.PasswordPolicy, value interface{}, formFields []epmodels.TypeFormField, userContext supertokens.UserContext) (*string, error) {
+	if reflect.TypeOf(value).Kind() != reflect.String {
+		msg := "Development bug: Please make sure the password field yields a string"
+		return &msg, nil
+	}
+	password := value.(string)
+	if msg := validatePasswordRules(policy, password, getPasswordUserInputs(policy, formFields)); msg != nil {
+		return msg, nil
+	}
+	if policy.BreachCheck != nil {
+		breached, err := isPasswordBreached(*policy.BreachCheck, password, userContext)
+		if err != nil {
+			if policy.BreachCheck.FailClosed {
+				return nil, err
+			}
+			supertokens.LogDebugMessage("emailpassword: breached password lookup failed, accepting the password: " + err.Error())
+		} else if breached {
+			msg := "This password has appeared in a data breach, please choose a different one"
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
+
+func validatePasswordRules(policy *epmodels.PasswordPolicy, password string, userInputs []string) *string {
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		msg := fmt.Sprintf("Password must contain at least %d characters", policy.MinLength)
+		return &msg
+	}
+	if length > policy.MaxLength {
+		msg := fmt.Sprintf("Password must contain at most %d characters", policy.MaxLength)
+		return &msg
+	}
+
+	var hasLetter, hasLowercase, hasUppercase, hasNumber, hasSymbol bool
+	for _, c := range password {
+		hasLetter = hasLetter || unicode.IsLetter(c)
+		hasLowercase = hasLowercase || unicode.IsLower(c)
+		hasUppercase = hasUppercase || unicode.IsUpper(c)
+		hasNumber = hasNumber || unicode.IsDigit(c)
+		hasSymbol = hasSymbol || unicode.IsPunct(c) || unicode.IsSymbol(c)
+	}
+	var missing string
+	if policy.RequireLetter && !hasLetter {
+		missing = "alphabet"
+	} else if policy.RequireLowercase && !hasLowercase {
+		missing = "lowercase letter"
+	} else if policy.RequireUppercase && !hasUppercase {
+		missing = "uppercase letter"
+	} else if policy.RequireNumber && !hasNumber {
+		missing = "number"
+	} else if policy.RequireSymbol && !hasSymbol {
+		missing = "symbol"
+	}
+	if missing != "" {
+		msg := "Password must contain at least one " + missing
+		return &msg
+	}
+
+	if policy.DisallowUserInputs {
+		lowercasePassword := strings.ToLower(password)
+		for _, input := range userInputs {
+			// short inputs, like initials, would reject too many passwords
+			if utf8.RuneCountInString(input) >= 3 && strings.Contains(lowercasePassword, strings.ToLower(input)) {
+				msg := "Password must not contain your email or other personal information"
+				return &msg
+			}
+		}
+	}
+
+	if policy.MinStrengthScore > 0 && zxcvbn.PasswordStrength(password, userInputs).Score < policy.MinStrengthScore {
+		msg := "Password is too easy to guess"
+		return &msg
+	}
+	return nil
+}
+
+// getPasswordUserInputs returns the email, its local part and the values of the
+// UserInputFormFields of the form.
+func getPasswordUserInputs(policy *epmodels.PasswordPolicy, formFields []epmodels.TypeFormField) []string {
+	userInputs := []string{}
+	for _, formField := range formFields {
+		if formField.Value == "" {
+			continue
+		}
+		if formField.ID == "email" {
+			userInputs = append(userInputs, formField.Value)
+			if i := strings.LastIndex(formField.Value, "@"); i > 0 {
+				userInputs = append(userInputs, formField.Value[:i])
+			}
+			continue
+		}
+		for _, id := range policy.UserInputFormFields {
+			if formField.ID == id {
+				userInputs = append(userInputs, formField.Value)
+			}
+		}
+	}
+	return userInputs
+}
+
+func isPasswordBreached(breachCheck epmodels.BreachCheck, password string, userContext supertokens.UserContext) (bool, error) {
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	suffixes, err := breachCheck.Lookup.GetBreachedSuffixes(hexHash[:5], userContext)
+	if err != nil {
+		return false, err
+	}
+	return suffixes[hexHash[5:]] >= breachCheck.MinOccurrences, nil
+}
+
+// bcrypt only uses the first 72 bytes of its input, so passwords are hashed with SHA-256 first
+func getPasswordHistoryInput(password string) []byte {
+	hash := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(hash[:]))
+}
+
+func isPasswordInHistory(history epmodels.PasswordHistory, userID string, password string, userContext supertokens.UserContext) (bool, error) {
+	hashes, err := history.Store.GetPasswordHashes(userID, userContext)
+	if err != nil {
+		return false, err
+	}
+	input := getPasswordHistoryInput(password)
+	for i, hash := range hashes {
+		if i >= history.Size {
+			break
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hash), input) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func addPasswordToHistory(history epmodels.PasswordHistory, userID string, password string, userContext supertokens.UserContext) error {
+	hash, err := bcrypt.GenerateFromPassword(getPasswordHistoryInput(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	hashes, err := history.Store.GetPasswordHashes(userID, userContext)
+	if err != nil {
+		return err
+	}
+	hashes = append([]string{string(hash)}, hashes...)
+	if len(hashes) > history.Size {
+		hashes = hashes[:history.Size]
+	}
+	return history.Store.SetPasswordHashes(userID, hashes, userContext)
+}
+
+// recordPasswordInHistory adds the password to the history after it has been set. Failing to
+// do so does not undo the change, so errors are only logged.
+func recordPasswordInHistory(policy *epmodels.PasswordPolicy, userID string, password string, userContext supertokens.UserContext) {
+	if policy.History == nil {
+		return
+	}
+	err := addPasswordToHistory(*policy.History, userID, password, userContext)
+	if err != nil {
+		supertokens.LogDebugMessage("emailpassword: could not add the password of user " + userID + " to the password history: " + err.Error())
+	}
+}
+
+func makePasswordViolatedError(msg string) *struct{ FailureReason string } {
+	return &struct{ FailureReason string }{FailureReason: msg}
+}
+
+// MakePasswordPolicyRecipeImplementation enforces the policy in the functions that set passwords.
+// The history is checked in ResetPasswordUsingToken and UpdateEmailOrPassword. The other rules are
+// checked by the sign up and password reset forms, and again by UpdateEmailOrPassword. The policy
+// must have been normalised with NormalisePasswordPolicy.
+func MakePasswordPolicyRecipeImplementation(originalImplementation epmodels.RecipeInterface, querier supertokens.Querier, policy *epmodels.PasswordPolicy) epmodels.RecipeInterface {
+	originalSignUp := *originalImplementation.SignUp
+	originalResetPasswordUsingToken := *originalImplementation.ResetPasswordUsingToken
+	originalUpdateEmailOrPassword := *originalImplementation.UpdateEmailOrPassword
+	originalGetUserByID := *originalImplementation.GetUserByID
+
+	signUp := func(email, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		response, err := originalSignUp(email, password, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		if response.OK != nil {
+			recordPasswordInHistory(policy, response.OK.User.ID, password, userContext)
+		}
+		return response, nil
+	}
+
+	resetPasswordUsingToken := func(token, newPassword string, userContext supertokens.UserContext) (epmodels.ResetPasswordUsingTokenResponse, error) {
+		if msg := validatePasswordRules(policy, newPassword, nil); msg != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{
+				PasswordPolicyViolatedError: makePasswordViolatedError(*msg),
+			}, nil
+		}
+		if !needsUserBeforePasswordReset(policy) {
+			return originalResetPasswordUsingToken(token, newPassword, userContext)
+		}
+
+		// The core only tells which user a token belongs to when the token is consumed, so it is
+		// consumed before the rules that depend on the user are checked. If one of them rejects
+		// the password, the token can not be used again.
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithPasswordResetTokenConsume(ctx, querier)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/user/password/reset/token/consume", map[string]interface{}{
+			"method": "token",
+			"token":  token,
+		})
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		status, ok := response["status"].(string)
+		if !ok {
+			return epmodels.ResetPasswordUsingTokenResponse{}, defaultErrors.New("the core returned a response without a status when consuming a password reset token")
+		}
+		if status != "OK" {
+			return epmodels.ResetPasswordUsingTokenResponse{
+				ResetPasswordInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		userId, ok := response["userId"].(string)
+		if !ok {
+			return epmodels.ResetPasswordUsingTokenResponse{}, defaultErrors.New("the core returned a response without a userId when consuming a password reset token")
+		}
+		email, _ := response["email"].(string)
+
+		msg, err := checkPasswordForUser(policy, userId, email, newPassword, userContext)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		if msg != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{
+				PasswordPolicyViolatedError: makePasswordViolatedError(*msg + ". This password reset link has been used, please request a new one"),
+			}, nil
+		}
+
+		updateResponse, err := originalUpdateEmailOrPassword(userId, nil, &newPassword, userContext)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		if updateResponse.OK == nil {
+			return epmodels.ResetPasswordUsingTokenResponse{
+				ResetPasswordInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		recordPasswordInHistory(policy, userId, newPassword, userContext)
+		supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+			Type:     supertokens.SecurityEventPasswordResetCompleted,
+			RecipeID: RECIPE_ID,
+			UserID:   userId,
+		}, userContext)
+		return epmodels.ResetPasswordUsingTokenResponse{
+			OK: &struct {
+				UserId *string
+			}{
+				UserId: &userId,
+			},
+		}, nil
+	}
+
+	updateEmailOrPassword := func(userId string, email, password *string, userContext supertokens.UserContext) (epmodels.UpdateEmailOrPasswordResponse, error) {
+		if password == nil {
+			return originalUpdateEmailOrPassword(userId, email, password, userContext)
+		}
+
+		var userEmail string
+		if email != nil {
+			userEmail = *email
+		} else {
+			user, err := originalGetUserByID(userId, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+			if user == nil {
+				return epmodels.UpdateEmailOrPasswordResponse{
+					UnknownUserIdError: &struct{}{},
+				}, nil
+			}
+			userEmail = user.Email
+		}
+
+		msg, err := validatePasswordWithPolicy(policy, *password, []epmodels.TypeFormField{{ID: "email", Value: userEmail}}, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if msg == nil {
+			msg, err = checkPasswordForUser(policy, userId, "", *password, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+		}
+		if msg != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{
+				PasswordPolicyViolatedError: makePasswordViolatedError(*msg),
+			}, nil
+		}
+
+		response, err := originalUpdateEmailOrPassword(userId, email, password, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if response.OK != nil {
+			recordPasswordInHistory(policy, userId, *password, userContext)
+		}
+		return response, nil
+	}
+
+	originalImplementation.SignUp = &signUp
+	originalImplementation.ResetPasswordUsingToken = &resetPasswordUsingToken
+	originalImplementation.UpdateEmailOrPassword = &updateEmailOrPassword
+	return originalImplementation
+}
+
+// checkPasswordForUser checks the rules that depend on the user: the user inputs, if email is
+// given, and the history.
+func checkPasswordForUser(policy *epmodels.PasswordPolicy, userId string, email string, password string, userContext supertokens.UserContext) (*string, error) {
+	if email != "" {
+		if msg := validatePasswordRules(policy, password, getPasswordUserInputs(policy, []epmodels.TypeFormField{{ID: "email", Value: email}})); msg != nil {
+			return msg, nil
+		}
+	}
+	if policy.History != nil {
+		inHistory, err := isPasswordInHistory(*policy.History, userId, password, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if inHistory {
+			msg := fmt.Sprintf("Password must be different from your last %d passwords", policy.History.Size)
+			return &msg, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,350 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+type inMemoryPasswordHistoryStore map[string][]string
+
+func (store inMemoryPasswordHistoryStore) GetPasswordHashes(userID string, userContext supertokens.UserContext) ([]string, error) {
+	return store[userID], nil
+}
+
+func (store inMemoryPasswordHistoryStore) SetPasswordHashes(userID string, hashes []string, userContext supertokens.UserContext) error {
+	store[userID] = hashes
+	return nil
+}
+
+// breachedPasswords is a BreachedPasswordLookup that knows the given passwords
+type breachedPasswords []string
+
+func (passwords breachedPasswords) GetBreachedSuffixes(hashPrefix string, userContext supertokens.UserContext) (map[string]int, error) {
+	suffixes := map[string]int{}
+	for _, password := range passwords {
+		hash := sha1.Sum([]byte(password))
+		hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+		if strings.HasPrefix(hexHash, hashPrefix) {
+			suffixes[hexHash[5:]] = 10
+		}
+	}
+	return suffixes, nil
+}
+
+type failingLookup struct{}
+
+func (failingLookup) GetBreachedSuffixes(hashPrefix string, userContext supertokens.UserContext) (map[string]int, error) {
+	return nil, errors.New("lookup failed")
+}
+
+func TestValidatePasswordRules(t *testing.T) {
+	policy, err := NormalisePasswordPolicy(epmodels.PasswordPolicy{
+		RequireLowercase:    true,
+		RequireUppercase:    true,
+		RequireNumber:       true,
+		RequireSymbol:       true,
+		DisallowUserInputs:  true,
+		UserInputFormFields: []string{"name"},
+	})
+	assert.NoError(t, err)
+	userInputs := getPasswordUserInputs(policy, []epmodels.TypeFormField{
+		{ID: "email", Value: "johnny@example.com"},
+		{ID: "name", Value: "Jonathan"},
+		{ID: "company", Value: "Acme"},
+	})
+	assert.Equal(t, []string{"johnny@example.com", "johnny", "Jonathan"}, userInputs)
+
+	tests := map[string]string{
+		"Ab1!":                   "Password must contain at least 8 characters",
+		strings.Repeat("a", 101): "Password must contain at most 100 characters",
+		"ABCDEFG1!":              "Password must contain at least one lowercase letter",
+		"abcdefg1!":              "Password must contain at least one uppercase letter",
+		"Abcdefgh!":              "Password must contain at least one number",
+		"Abcdefgh1":              "Password must contain at least one symbol",
+		"Xx1!JOHNNY":             "Password must not contain your email or other personal information",
+		"Xx1!jonathan":           "Password must not contain your email or other personal information",
+		"Xx1!AcmeAcme":           "",
+	}
+	for password, expected := range tests {
+		msg := validatePasswordRules(policy, password, userInputs)
+		if expected == "" {
+			assert.Nil(t, msg, password)
+		} else if assert.NotNil(t, msg, password) {
+			assert.Equal(t, expected, *msg, password)
+		}
+	}
+
+	policy, err = NormalisePasswordPolicy(epmodels.PasswordPolicy{MinStrengthScore: 3})
+	assert.NoError(t, err)
+	msg := validatePasswordRules(policy, "password123", nil)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Password is too easy to guess", *msg)
+	}
+	assert.Nil(t, validatePasswordRules(policy, "correct horse battery staple", nil))
+
+	_, err = NormalisePasswordPolicy(epmodels.PasswordPolicy{History: &epmodels.PasswordHistory{Size: 3}})
+	assert.EqualError(t, err, "PasswordPolicy.History.Store is required")
+	_, err = NormalisePasswordPolicy(epmodels.PasswordPolicy{MinLength: 20, MaxLength: 10})
+	assert.Error(t, err)
+}
+
+func TestBreachCheck(t *testing.T) {
+	policy, err := NormalisePasswordPolicy(epmodels.PasswordPolicy{
+		BreachCheck: &epmodels.BreachCheck{Lookup: breachedPasswords{"breached123"}},
+	})
+	assert.NoError(t, err)
+
+	msg, err := validatePasswordWithPolicy(policy, "breached123", nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "This password has appeared in a data breach, please choose a different one", *msg)
+	}
+	msg, err = validatePasswordWithPolicy(policy, "notbreached123", nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	// passwords are accepted if the lookup fails, unless FailClosed is set
+	policy.BreachCheck = &epmodels.BreachCheck{Lookup: failingLookup{}, MinOccurrences: 1}
+	msg, err = validatePasswordWithPolicy(policy, "breached123", nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	policy.BreachCheck.FailClosed = true
+	_, err = validatePasswordWithPolicy(policy, "breached123", nil, &map[string]interface{}{})
+	assert.EqualError(t, err, "lookup failed")
+}
+
+func TestPwnedPasswordsLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/range/CBFDA", r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		w.Write([]byte("C6D9A4C47F5BCE9A69FCF1A1D7F5C7E23B4:3\r\nc6d9a4c47f5bce9a69fcf1a1d7f5c7e23b5:0\r\n8C5CA4C8F0EAB4A8D4C3D4F0F1C6B4D5E6F:2\r\n"))
+	}))
+	defer server.Close()
+
+	suffixes, err := PwnedPasswordsLookup{BaseURL: server.URL}.GetBreachedSuffixes("CBFDA", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"C6D9A4C47F5BCE9A69FCF1A1D7F5C7E23B4": 3,
+		"8C5CA4C8F0EAB4A8D4C3D4F0F1C6B4D5E6F": 2,
+	}, suffixes)
+}
+
+func TestSignUpWithPasswordPolicy(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(&epmodels.TypeInput{
+		PasswordPolicy: &epmodels.PasswordPolicy{
+			MinLength:          10,
+			RequireNumber:      true,
+			DisallowUserInputs: true,
+			BreachCheck:        &epmodels.BreachCheck{Lookup: breachedPasswords{"breached1234"}},
+		},
+	}))
+	defer testServer.Close()
+
+	tests := map[string]string{
+		"short1":       "Password must contain at least 10 characters",
+		"nonumbers!!!": "Password must contain at least one number",
+		"johnny123456": "Password must not contain your email or other personal information",
+		"breached1234": "This password has appeared in a data breach, please choose a different one",
+	}
+	for password, expected := range tests {
+		resp, err := unittesting.SignupRequest("johnny@example.com", password, testServer.URL)
+		assert.NoError(t, err)
+		result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+		assert.Equal(t, "FIELD_ERROR", result["status"], password)
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": "password", "error": expected}}, result["formFields"], password)
+	}
+
+	resp, err := unittesting.SignupRequest("johnny@example.com", "valid-pass-1234", testServer.URL)
+	assert.NoError(t, err)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+}
+
+func TestPasswordHistory(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	store := inMemoryPasswordHistoryStore{}
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(&epmodels.TypeInput{
+		PasswordPolicy: &epmodels.PasswordPolicy{
+			RequireNumber:      true,
+			DisallowUserInputs: true,
+			History:            &epmodels.PasswordHistory{Size: 2, Store: store},
+		},
+	}))
+	defer testServer.Close()
+
+	signUpResponse, err := SignUp("test@example.com", "password1")
+	assert.NoError(t, err)
+	userId := signUpResponse.OK.User.ID
+	assert.Len(t, store[userId], 1)
+
+	// the current password can not be reused
+	newPassword := "password1"
+	updateResponse, err := UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	if assert.NotNil(t, updateResponse.PasswordPolicyViolatedError) {
+		assert.Equal(t, "Password must be different from your last 2 passwords", updateResponse.PasswordPolicyViolatedError.FailureReason)
+	}
+
+	// the email of the user is checked, although it is not passed
+	newPassword = "test1234"
+	updateResponse, err = UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	if assert.NotNil(t, updateResponse.PasswordPolicyViolatedError) {
+		assert.Equal(t, "Password must not contain your email or other personal information", updateResponse.PasswordPolicyViolatedError.FailureReason)
+	}
+
+	newPassword = "password2"
+	updateResponse, err = UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	assert.NotNil(t, updateResponse.OK)
+	assert.Len(t, store[userId], 2)
+
+	// password1 is still in the history, so it can not be set with a password reset
+	tokenResponse, err := CreateResetPasswordToken(userId)
+	assert.NoError(t, err)
+	resp, err := passwordResetRequest(testServer.URL, tokenResponse.OK.Token, "password1")
+	assert.NoError(t, err)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "FIELD_ERROR", result["status"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "password", "error": "Password must be different from your last 2 passwords. This password reset link has been used, please request a new one"}}, result["formFields"])
+
+	// the token was consumed, so a new one is needed
+	resp, err = passwordResetRequest(testServer.URL, tokenResponse.OK.Token, "password3")
+	assert.NoError(t, err)
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "RESET_PASSWORD_INVALID_TOKEN_ERROR", result["status"])
+
+	tokenResponse, err = CreateResetPasswordToken(userId)
+	assert.NoError(t, err)
+	resp, err = passwordResetRequest(testServer.URL, tokenResponse.OK.Token, "password3")
+	assert.NoError(t, err)
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+	assert.Len(t, store[userId], 2)
+
+	signInResponse, err := SignIn("test@example.com", "password3")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+
+	// only the last 2 passwords are kept, so password1 can be used again
+	newPassword = "password1"
+	updateResponse, err = UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	assert.NotNil(t, updateResponse.OK)
+}
+
+func TestInitFailsWithPasswordHistoryAndCoreWithoutTokenConsume(t *testing.T) {
+	resetAll()
+	defer resetAll()
+	core := fakecore.NewServer(fakecore.Config{
+		CDIVersions: []string{"2.14", "2.15", "2.21"},
+	})
+	defer core.Close()
+
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: core.URL,
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+			Init(&epmodels.TypeInput{
+				PasswordPolicy: &epmodels.PasswordPolicy{
+					History: &epmodels.PasswordHistory{Size: 2, Store: inMemoryPasswordHistoryStore{}},
+				},
+			}),
+		},
+	})
+	assert.ErrorAs(t, err, &supertokens.CoreAPIVersionError{})
+	assert.Contains(t, err.Error(), "needs a SuperTokens core that supports CDI version 4.0 or later")
+}
+
+func TestCustomPasswordValidatorWithContext(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(&epmodels.TypeInput{
+		SignUpFeature: &epmodels.TypeInputSignUp{
+			FormFields: []epmodels.TypeInputFormField{
+				{
+					ID: "password",
+					ValidateWithContext: func(value interface{}, formFields []epmodels.TypeFormField, userContext supertokens.UserContext) (*string, error) {
+						for _, formField := range formFields {
+							if formField.ID == "email" && strings.HasPrefix(formField.Value, "admin@") && len(value.(string)) < 16 {
+								msg := "Admins must use at least 16 characters"
+								return &msg, nil
+							}
+						}
+						return nil, nil
+					},
+				},
+			},
+		},
+		PasswordPolicy: &epmodels.PasswordPolicy{MinLength: 12},
+	}))
+	defer testServer.Close()
+
+	resp, err := unittesting.SignupRequest("admin@example.com", "short", testServer.URL)
+	assert.NoError(t, err)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "password", "error": "Admins must use at least 16 characters"}}, result["formFields"])
+
+	// the custom validator takes precedence over the policy
+	resp, err = unittesting.SignupRequest("user@example.com", "short", testServer.URL)
+	assert.NoError(t, err)
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+}
+
+func passwordResetRequest(testUrl string, token string, password string) (*http.Response, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]string{{"id": "password", "value": password}},
+		"token":      token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return http.Post(testUrl+"/auth/user/password/reset", "application/json", bytes.NewBuffer(body))
+}
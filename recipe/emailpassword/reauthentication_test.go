@@ -0,0 +1,201 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func sendSignInRequestWithAccessToken(t *testing.T, url string, email string, password string, accessToken string) *http.Response {
+	body, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]string{
+			{"id": "email", "value": email},
+			{"id": "password", "value": password},
+		},
+	})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url+"/auth/signin", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("st-auth-mode", "header")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	return res
+}
+
+func getAuthTime(t *testing.T, sessionHandle string) float64 {
+	sessionInformation, err := session.GetSessionInformation(sessionHandle)
+	assert.NoError(t, err)
+	authTime, ok := session.AuthTimeClaim.GetValueFromPayload(sessionInformation.AccessTokenPayload, &map[string]interface{}{}).(float64)
+	assert.True(t, ok)
+	return authTime
+}
+
+func TestSignInWithExistingSessionUpdatesAuthTime(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sensitive", session.VerifySession(&sessmodels.VerifySessionOptions{
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return append(globalClaimValidators, session.RequireRecentAuth(100*time.Millisecond)), nil
+		},
+	}, func(rw http.ResponseWriter, r *http.Request) {}))
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	_, err = unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	user, err := GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+	_, err = session.RevokeAllSessionsForUser(user.ID)
+	assert.NoError(t, err)
+
+	res := sendSignInRequestWithAccessToken(t, testServer.URL, "test@example.com", "validpass123", "")
+	accessToken := res.Header.Get("st-access-token")
+	handles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+	firstAuthTime := getAuthTime(t, handles[0])
+
+	req, err := http.NewRequest(http.MethodGet, testServer.URL+"/sensitive", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	time.Sleep(150 * time.Millisecond)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 403, res.StatusCode)
+	var result struct {
+		ClaimValidationErrors []struct {
+			ID     string                 `json:"id"`
+			Reason map[string]interface{} `json:"reason"`
+		} `json:"claimValidationErrors"`
+	}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	assert.Len(t, result.ClaimValidationErrors, 1)
+	assert.Equal(t, session.AuthTimeClaim.Key, result.ClaimValidationErrors[0].ID)
+	assert.Equal(t, session.ReauthenticationRequiredMessage, result.ClaimValidationErrors[0].Reason["message"])
+
+	// signing in again with the session keeps the session, but updates the auth time
+	res = sendSignInRequestWithAccessToken(t, testServer.URL, "test@example.com", "validpass123", accessToken)
+	handles, err = session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+	assert.Greater(t, getAuthTime(t, handles[0]), firstAuthTime)
+
+	req.Header.Set("Authorization", "Bearer "+res.Header.Get("st-access-token"))
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestSignInDoesNotReuseSessionThatFailsTheAntiCsrfCheck(t *testing.T) {
+	antiCsrf := "VIA_TOKEN"
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(&sessmodels.TypeInput{
+				AntiCsrf: &antiCsrf,
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+	user, err := GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+	handles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+	firstAuthTime := getAuthTime(t, handles[0])
+
+	// the session cookies are sent without the anti-csrf token, as in a cross site request
+	body, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]string{
+			{"id": "email", "value": "test@example.com"},
+			{"id": "password", "value": "validpass123"},
+		},
+	})
+	assert.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/signin", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	newHandles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, newHandles, 2)
+	assert.Equal(t, firstAuthTime, getAuthTime(t, handles[0]))
+}
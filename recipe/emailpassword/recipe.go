@@ -16,6 +16,7 @@
 package emailpassword
 
 import (
+	"context"
 	defaultErrors "errors"
 	"net/http"
 
@@ -27,6 +28,9 @@ import (
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -40,8 +44,6 @@ type Recipe struct {
 	EmailDelivery emaildelivery.Ingredient
 }
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput, emailDeliveryIngredient *emaildelivery.Ingredient, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	r := &Recipe{}
 	r.RecipeModule = supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
@@ -50,10 +52,17 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	if err != nil {
 		return Recipe{}, err
 	}
-	verifiedConfig := validateAndNormaliseUserInput(r, appInfo, config)
+	verifiedConfig, err := validateAndNormaliseUserInput(r, appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
-	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance))
+	recipeImplementation := MakeRecipeImplementation(*querierInstance)
+	if verifiedConfig.PasswordPolicy != nil {
+		recipeImplementation = MakePasswordPolicyRecipeImplementation(recipeImplementation, *querierInstance, verifiedConfig.PasswordPolicy)
+	}
+	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 
 	if emailDeliveryIngredient != nil {
 		r.EmailDelivery = *emailDeliveryIngredient
@@ -62,39 +71,84 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	}
 
 	supertokens.AddPostInitCallback(func() error {
-		emailVerificationRecipe := emailverification.GetRecipeInstance()
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return err
+		}
+		emailVerificationRecipe := emailverification.GetRecipeInstanceFor(stInstance)
 		if emailVerificationRecipe != nil {
 			emailVerificationRecipe.AddGetEmailForUserIdFunc(r.getEmailForUserId)
 		}
+		sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+		if sessionRecipe != nil {
+			sessionRecipe.AddUserExistsFunc(r.userExists)
+		}
 		return nil
 	})
 
+	if verifiedConfig.PasswordPolicy != nil && needsUserBeforePasswordReset(verifiedConfig.PasswordPolicy) {
+		supertokens.AddPostInitCallback(func() error {
+			err := requireCoreWithPasswordResetTokenConsume(context.Background(), *querierInstance)
+			if defaultErrors.As(err, &supertokens.CoreAPIVersionError{}) {
+				return err
+			}
+			if err != nil {
+				// the core may just be unreachable right now, the version is checked again before every password reset
+				supertokens.LogDebugMessage("emailpassword: could not check the version of the core: " + err.Error())
+			}
+			return nil
+		})
+	}
+
 	return *r, nil
 }
 
+// GetRecipeInstanceFor returns the emailpassword recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, defaultErrors.New("initialisation not done. Did you forget to call the init function?")
+}
+
 func recipeInit(config *epmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, defaultErrors.New("emailpassword recipe has already been initialised. Please check your code for bugs.")
 	}
 }
 
 func GetRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
 	}
 	return nil, defaultErrors.New("initialisation not done. Did you forget to call the init function?")
 }
 
 func GetRecipeInstance() *Recipe {
-	return singletonInstance
+	return GetRecipeInstanceFor(supertokens.GetDefaultInstance())
 }
 
 // implement RecipeModule
@@ -149,6 +203,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 }
 
 func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path supertokens.NormalisedURLPath, method string) error {
+	err := multitenancy.ValidateLoginMethodForRequest(req, multitenancymodels.LoginMethodEmailPassword)
+	if err != nil {
+		return err
+	}
+
 	options := epmodels.APIOptions{
 		Config:               r.Config,
 		OtherHandler:         theirHandler,
@@ -188,6 +247,14 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return false, nil
 }
 
+func (r *Recipe) userExists(userID string, userContext supertokens.UserContext) (bool, error) {
+	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
+	if err != nil {
+		return false, err
+	}
+	return userInfo != nil, nil
+}
+
 func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserContext) (evmodels.TypeEmailInfo, error) {
 	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
 	if err != nil {
@@ -206,7 +273,6 @@ func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserCo
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 	PasswordResetEmailSentForTest = false
 	PasswordResetDataForTest = struct {
 		User                      epmodels.User
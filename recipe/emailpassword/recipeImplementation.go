@@ -22,7 +22,7 @@ import (
 
 func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterface {
 	signUp := func(email, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/signup", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signup", map[string]interface{}{
 			"email":    email,
 			"password": password,
 		})
@@ -35,6 +35,9 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			if err != nil {
 				return epmodels.SignUpResponse{}, err
 			}
+			querier.GetInstrumentation().AddToCounter(supertokens.GetContextFromUserContext(userContext), supertokens.MetricSignUps, 1, map[string]interface{}{
+				"supertokens.login_method": "emailpassword",
+			})
 			return epmodels.SignUpResponse{
 				OK: &struct{ User epmodels.User }{User: *user},
 			}, nil
@@ -45,7 +48,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	}
 
 	signIn := func(email, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/signin", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signin", map[string]interface{}{
 			"email":    email,
 			"password": password,
 		})
@@ -58,17 +61,27 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			if err != nil {
 				return epmodels.SignInResponse{}, err
 			}
+			querier.GetInstrumentation().AddToCounter(supertokens.GetContextFromUserContext(userContext), supertokens.MetricSignIns, 1, map[string]interface{}{
+				"supertokens.login_method": "emailpassword",
+			})
 			return epmodels.SignInResponse{
 				OK: &struct{ User epmodels.User }{User: *user},
 			}, nil
 		}
+		supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+			Type:     supertokens.SecurityEventSignInFailed,
+			RecipeID: RECIPE_ID,
+			Details: map[string]interface{}{
+				"email": email,
+			},
+		}, userContext)
 		return epmodels.SignInResponse{
 			WrongCredentialsError: &struct{}{},
 		}, nil
 	}
 
 	getUserByID := func(userID string, userContext supertokens.UserContext) (*epmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"userId": userID,
 		})
 		if err != nil {
@@ -86,7 +99,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	}
 
 	getUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"email": email,
 		})
 		if err != nil {
@@ -104,7 +117,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	}
 
 	createResetPasswordToken := func(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/user/password/reset/token", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/password/reset/token", map[string]interface{}{
 			"userId": userID,
 		})
 		if err != nil {
@@ -112,6 +125,11 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 		}
 		status, ok := response["status"]
 		if ok && status.(string) == "OK" {
+			supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+				Type:     supertokens.SecurityEventPasswordResetRequested,
+				RecipeID: RECIPE_ID,
+				UserID:   userID,
+			}, userContext)
 			return epmodels.CreateResetPasswordTokenResponse{
 				OK: &struct{ Token string }{Token: response["token"].(string)},
 			}, nil
@@ -122,7 +140,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	}
 
 	resetPasswordUsingToken := func(token, newPassword string, userContext supertokens.UserContext) (epmodels.ResetPasswordUsingTokenResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/user/password/reset", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/password/reset", map[string]interface{}{
 			"method":      "token",
 			"token":       token,
 			"newPassword": newPassword,
@@ -136,6 +154,11 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			if ok {
 				// using CDI >= 2.12
 				userIdStr := userId.(string)
+				supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+					Type:     supertokens.SecurityEventPasswordResetCompleted,
+					RecipeID: RECIPE_ID,
+					UserID:   userIdStr,
+				}, userContext)
 				return epmodels.ResetPasswordUsingTokenResponse{
 					OK: &struct {
 						UserId *string
@@ -145,6 +168,10 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 				}, nil
 			} else {
 				// using CDI < 2.12
+				supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+					Type:     supertokens.SecurityEventPasswordResetCompleted,
+					RecipeID: RECIPE_ID,
+				}, userContext)
 				return epmodels.ResetPasswordUsingTokenResponse{
 					OK: &struct {
 						UserId *string
@@ -168,12 +195,32 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 		if password != nil {
 			requestBody["password"] = password
 		}
-		response, err := querier.SendPutRequest("/recipe/user", requestBody)
+		var userBeforeUpdate *epmodels.User = nil
+		// the user is only fetched to report the old email in the EMAIL_CHANGED event
+		if email != nil && supertokens.HasSecurityEventListeners(userContext) {
+			user, err := getUserByID(userId, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+			userBeforeUpdate = user
+		}
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", requestBody)
 		if err != nil {
 			return epmodels.UpdateEmailOrPasswordResponse{}, nil
 		}
 
 		if response["status"].(string) == "OK" {
+			if userBeforeUpdate != nil && userBeforeUpdate.Email != *email {
+				supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+					Type:     supertokens.SecurityEventEmailChanged,
+					RecipeID: RECIPE_ID,
+					UserID:   userId,
+					Details: map[string]interface{}{
+						"oldEmail": userBeforeUpdate.Email,
+						"newEmail": *email,
+					},
+				}, userContext)
+			}
 			return epmodels.UpdateEmailOrPasswordResponse{
 				OK: &struct{}{},
 			}, nil
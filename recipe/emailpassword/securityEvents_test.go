@@ -0,0 +1,85 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSecurityEventsForSignInAndEmailChange(t *testing.T) {
+	events := []supertokens.SecurityEvent{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(nil),
+		},
+		SecurityEventListeners: []supertokens.SecurityEventListener{
+			supertokens.SecurityEventListenerFunc(func(event supertokens.SecurityEvent, userContext supertokens.UserContext) {
+				events = append(events, event)
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	_, err = unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	_, err = unittesting.SignInRequest("test@example.com", "wrongpass123", testServer.URL)
+	assert.NoError(t, err)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, supertokens.SecurityEventSignInFailed, events[0].Type)
+	assert.Equal(t, RECIPE_ID, events[0].RecipeID)
+	assert.Equal(t, "test@example.com", events[0].Details["email"])
+	assert.Equal(t, "/auth/signin", events[0].Request.Path)
+
+	user, err := GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+	newEmail := "new@example.com"
+	_, err = UpdateEmailOrPassword(user.ID, &newEmail, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, supertokens.SecurityEventEmailChanged, events[1].Type)
+	assert.Equal(t, user.ID, events[1].UserID)
+	assert.Equal(t, "test@example.com", events[1].Details["oldEmail"])
+	assert.Equal(t, "new@example.com", events[1].Details["newEmail"])
+	assert.Nil(t, events[1].Request)
+}
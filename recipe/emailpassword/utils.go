@@ -26,7 +26,7 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) epmodels.TypeNormalisedInput {
+func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) (epmodels.TypeNormalisedInput, error) {
 
 	typeNormalisedInput := makeTypeNormalisedInput(recipeInstance)
 
@@ -35,6 +35,19 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(typeNormalisedInput.SignUpFeature)
 	}
 
+	if config != nil && config.PasswordPolicy != nil {
+		passwordPolicy, err := NormalisePasswordPolicy(*config.PasswordPolicy)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordPolicy = passwordPolicy
+		// a validator of the password field takes precedence over the policy
+		if !hasCustomPasswordValidator(config.SignUpFeature) {
+			typeNormalisedInput.SignUpFeature.FormFields = setPasswordPolicyValidator(typeNormalisedInput.SignUpFeature.FormFields, passwordPolicy)
+		}
+		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(typeNormalisedInput.SignUpFeature)
+	}
+
 	// we must call this after validateAndNormaliseSignupConfig
 	typeNormalisedInput.SignInFeature = validateAndNormaliseSignInConfig(typeNormalisedInput.SignUpFeature)
 
@@ -71,7 +84,40 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		}
 	}
 
-	return typeNormalisedInput
+	return typeNormalisedInput, nil
+}
+
+func hasCustomPasswordValidator(config *epmodels.TypeInputSignUp) bool {
+	if config == nil {
+		return false
+	}
+	for _, formField := range config.FormFields {
+		if formField.ID == "password" {
+			return formField.Validate != nil || formField.ValidateWithContext != nil
+		}
+	}
+	return false
+}
+
+func setPasswordPolicyValidator(formFields []epmodels.NormalisedFormField, passwordPolicy *epmodels.PasswordPolicy) []epmodels.NormalisedFormField {
+	for i, formField := range formFields {
+		if formField.ID != "password" {
+			continue
+		}
+		// Validate has no access to the other fields or a user context, so it only checks the
+		// rules that do not depend on them
+		formFields[i].Validate = func(value interface{}) *string {
+			if reflect.TypeOf(value).Kind() != reflect.String {
+				msg := "Development bug: Please make sure the password field yields a string"
+				return &msg
+			}
+			return validatePasswordRules(passwordPolicy, value.(string), nil)
+		}
+		formFields[i].ValidateWithContext = func(value interface{}, formFields []epmodels.TypeFormField, userContext supertokens.UserContext) (*string, error) {
+			return validatePasswordWithPolicy(passwordPolicy, value, formFields, userContext)
+		}
+	}
+	return formFields
 }
 
 func makeTypeNormalisedInput(recipeInstance *Recipe) epmodels.TypeNormalisedInput {
@@ -191,9 +237,10 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 				}
 			}
 			normalisedFormFields = append(normalisedFormFields, epmodels.NormalisedFormField{
-				ID:       formField.ID,
-				Validate: validate,
-				Optional: optional,
+				ID:                  formField.ID,
+				Validate:            validate,
+				ValidateWithContext: formField.ValidateWithContext,
+				Optional:            optional,
 			})
 		}
 	}
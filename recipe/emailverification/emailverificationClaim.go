@@ -12,7 +12,7 @@ import (
 // key string, fetchValue claims.FetchValueFunc
 func NewEmailVerificationClaim() (*claims.TypeSessionClaim, evclaims.TypeEmailVerificationClaimValidators) {
 	fetchValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
-		instance, err := getRecipeInstanceOrThrowError()
+		instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 		if err != nil {
 			return nil, err
 		}
@@ -29,7 +29,7 @@ func Init(config evmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateEmailVerificationTokenWithContext(userID string, email *string, userContext supertokens.UserContext) (evmodels.CreateEmailVerificationTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return evmodels.CreateEmailVerificationTokenResponse{}, err
 	}
@@ -52,7 +52,7 @@ func CreateEmailVerificationTokenWithContext(userID string, email *string, userC
 }
 
 func VerifyEmailUsingTokenWithContext(token string, userContext supertokens.UserContext) (evmodels.VerifyEmailUsingTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return evmodels.VerifyEmailUsingTokenResponse{}, err
 	}
@@ -60,7 +60,7 @@ func VerifyEmailUsingTokenWithContext(token string, userContext supertokens.User
 }
 
 func IsEmailVerifiedWithContext(userID string, email *string, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -81,7 +81,7 @@ func IsEmailVerifiedWithContext(userID string, email *string, userContext supert
 }
 
 func RevokeEmailVerificationTokensWithContext(userID string, email *string, userContext supertokens.UserContext) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return evmodels.RevokeEmailVerificationTokensResponse{}, err
 	}
@@ -104,7 +104,7 @@ func RevokeEmailVerificationTokensWithContext(userID string, email *string, user
 }
 
 func UnverifyEmailWithContext(userID string, email *string, userContext supertokens.UserContext) (evmodels.UnverifyEmailResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return evmodels.UnverifyEmailResponse{}, err
 	}
@@ -127,7 +127,7 @@ func UnverifyEmailWithContext(userID string, email *string, userContext supertok
 }
 
 func SendEmailWithContext(input emaildelivery.EmailType, userContext supertokens.UserContext) error {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -161,3 +161,7 @@ func SendEmail(input emaildelivery.EmailType) error {
 func MakeSMTPService(config emaildelivery.SMTPServiceConfig) *emaildelivery.EmailDeliveryInterface {
 	return smtpService.MakeSMTPService(config)
 }
+
+func MakeProviderService(config emaildelivery.ProviderServiceConfig) *emaildelivery.EmailDeliveryInterface {
+	return smtpService.MakeProviderService(config)
+}
@@ -40,8 +40,6 @@ type Recipe struct {
 	AddGetEmailForUserIdFunc func(function evmodels.TypeGetEmailForUserID)
 }
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config evmodels.TypeInput, emailDeliveryIngredient *emaildelivery.Ingredient, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	getEmailForUserIdFuncsFromOtherRecipes := []evmodels.TypeGetEmailForUserID{}
 
@@ -104,30 +102,52 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config e
 }
 
 func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
 
 func GetRecipeInstance() *Recipe {
-	return singletonInstance
+	return GetRecipeInstanceFor(supertokens.GetDefaultInstance())
+}
+
+// GetRecipeInstanceFor returns the email verification recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
 
 func recipeInit(config evmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
 
 			supertokens.AddPostInitCallback(func() error {
-				sessionRecipe, err := session.GetRecipeInstanceOrThrowError()
-
-				if err != nil {
-					return err
+				sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+				if sessionRecipe == nil {
+					return errors.New("Initialisation not done. Did you forget to call the init function?")
 				}
 
 				sessionRecipe.AddClaimFromOtherRecipe(evclaims.EmailVerificationClaim)
@@ -139,7 +159,7 @@ func recipeInit(config evmodels.TypeInput) supertokens.Recipe {
 				}
 				return nil
 			})
-			return &singletonInstance.RecipeModule, nil
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("Emailverification recipe has already been initialised. Please check your code for bugs.")
 	}
@@ -203,7 +223,6 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 	EmailVerificationEmailSentForTest = false
 	EmailVerificationDataForTest = struct {
 		User                    evmodels.User
@@ -22,7 +22,7 @@ import (
 
 func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterface {
 	createEmailVerificationToken := func(userID, email string, userContext supertokens.UserContext) (evmodels.CreateEmailVerificationTokenResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/user/email/verify/token", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/email/verify/token", map[string]interface{}{
 			"userId": userID,
 			"email":  email,
 		})
@@ -42,7 +42,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 	}
 
 	verifyEmailUsingToken := func(token string, userContext supertokens.UserContext) (evmodels.VerifyEmailUsingTokenResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/user/email/verify", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/email/verify", map[string]interface{}{
 			"method": "token",
 			"token":  token,
 		})
@@ -64,7 +64,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 	}
 
 	isEmailVerified := func(userID, email string, userContext supertokens.UserContext) (bool, error) {
-		response, err := querier.SendGetRequest("/recipe/user/email/verify", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/email/verify", map[string]string{
 			"userId": userID,
 			"email":  email,
 		})
@@ -75,7 +75,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 	}
 
 	revokeEmailVerificationTokens := func(userId string, email string, userContext supertokens.UserContext) (evmodels.RevokeEmailVerificationTokensResponse, error) {
-		_, err := querier.SendPostRequest("/recipe/user/email/verify/token/remove", map[string]interface{}{
+		_, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/email/verify/token/remove", map[string]interface{}{
 			"userId": userId,
 			"email":  email,
 		})
@@ -88,7 +88,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 	}
 
 	unverifyEmail := func(userId string, email string, userContext supertokens.UserContext) (evmodels.UnverifyEmailResponse, error) {
-		_, err := querier.SendPostRequest("/recipe/user/email/verify/remove", map[string]interface{}{
+		_, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/email/verify/remove", map[string]interface{}{
 			"userId": userId,
 			"email":  email,
 		})
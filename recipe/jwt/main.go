@@ -25,7 +25,7 @@ func Init(config *jwtmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer *uint64, userContext supertokens.UserContext) (jwtmodels.CreateJWTResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.CreateJWTResponse{}, err
 	}
@@ -33,7 +33,7 @@ func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer
 }
 
 func GetJWKSWithContext(userContext supertokens.UserContext) (jwtmodels.GetJWKSResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.GetJWKSResponse{}, err
 	}
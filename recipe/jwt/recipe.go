@@ -33,8 +33,6 @@ type Recipe struct {
 	APIImpl      jwtmodels.APIInterface
 }
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *jwtmodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	r := &Recipe{}
 	verifiedConfig := validateAndNormaliseUserInput(appInfo, config)
@@ -55,21 +53,44 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 }
 
 func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+// GetRecipeInstanceFor returns the JWT recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
 
 func recipeInit(config *jwtmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("JWT recipe has already been initialised. Please check your code for bugs.")
 	}
@@ -112,5 +133,4 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 }
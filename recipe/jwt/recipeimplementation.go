@@ -30,7 +30,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.Type
 			payload = map[string]interface{}{}
 		}
 
-		response, err := querier.SendPostRequest("/recipe/jwt", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/jwt", map[string]interface{}{
 			"payload":    payload,
 			"validity":   validitySeconds,
 			"algorithm":  "RS256",
@@ -54,7 +54,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.Type
 		}
 	}
 	getJWKS := func(userContext supertokens.UserContext) (jwtmodels.GetJWKSResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/jwt/jwks", map[string]string{})
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/jwt/jwks", map[string]string{})
 		if err != nil {
 			return jwtmodels.GetJWKSResponse{}, err
 		}
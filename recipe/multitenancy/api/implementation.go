@@ -0,0 +1,65 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func MakeAPIImplementation() multitenancymodels.APIInterface {
+	loginMethodsGET := func(tenantId string, options multitenancymodels.APIOptions, userContext supertokens.UserContext) (multitenancymodels.LoginMethodsGETResponse, error) {
+		tenantConfig, err := (*options.RecipeImplementation.GetTenantConfig)(tenantId, userContext)
+		if err != nil {
+			return multitenancymodels.LoginMethodsGETResponse{}, err
+		}
+		if tenantConfig == nil {
+			return multitenancymodels.LoginMethodsGETResponse{
+				UnknownTenantError: &struct{}{},
+			}, nil
+		}
+
+		providers := tenantConfig.ThirdParty.Providers
+		if providers == nil {
+			providers = options.StaticThirdPartyProviders
+		}
+		providerIds := []string{}
+		for _, provider := range providers {
+			providerIds = append(providerIds, provider.ID)
+		}
+
+		response := multitenancymodels.LoginMethodsGETResponse{
+			OK: &struct {
+				EmailPassword multitenancymodels.EmailPasswordConfig
+				Passwordless  multitenancymodels.PasswordlessConfig
+				ThirdParty    struct {
+					Enabled   bool
+					Providers []string
+				}
+			}{
+				EmailPassword: tenantConfig.EmailPassword,
+				Passwordless:  tenantConfig.Passwordless,
+			},
+		}
+		response.OK.ThirdParty.Enabled = tenantConfig.ThirdParty.Enabled
+		response.OK.ThirdParty.Providers = providerIds
+		return response, nil
+	}
+
+	return multitenancymodels.APIInterface{
+		LoginMethodsGET: &loginMethodsGET,
+	}
+}
@@ -0,0 +1,65 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func LoginMethodsAPI(apiImplementation multitenancymodels.APIInterface, options multitenancymodels.APIOptions) error {
+	if apiImplementation.LoginMethodsGET == nil ||
+		(*apiImplementation.LoginMethodsGET) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	tenantId := supertokens.GetTenantIdFromContext(options.Req.Context())
+
+	response, err := (*apiImplementation.LoginMethodsGET)(tenantId, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		providers := []map[string]interface{}{}
+		for _, providerId := range response.OK.ThirdParty.Providers {
+			providers = append(providers, map[string]interface{}{
+				"id": providerId,
+			})
+		}
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"emailPassword": map[string]interface{}{
+				"enabled": response.OK.EmailPassword.Enabled,
+			},
+			"passwordless": map[string]interface{}{
+				"enabled": response.OK.Passwordless.Enabled,
+			},
+			"thirdParty": map[string]interface{}{
+				"enabled":   response.OK.ThirdParty.Enabled,
+				"providers": providers,
+			},
+		})
+	} else if response.UnknownTenantError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNKNOWN_TENANT_ERROR",
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
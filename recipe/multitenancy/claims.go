@@ -0,0 +1,69 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancyclaims"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func init() {
+	// automatically called when this package is imported
+	multitenancyclaims.TenantIdClaim, multitenancyclaims.TenantIdClaimValidators = NewTenantIdClaim()
+}
+
+// NewTenantIdClaim returns a claim with the tenant that the session was created for.
+func NewTenantIdClaim() (*claims.TypeSessionClaim, claims.PrimitiveClaimValidators) {
+	fetchValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return GetTenantId(userContext), nil
+	}
+	return claims.PrimitiveClaim("tId", fetchValue, nil)
+}
+
+// NewTenantIdMatchesRequestValidator returns a validator that fails if the session was
+// created for a different tenant than the one of the request that is being handled.
+// Sessions without the claim are treated as belonging to the default tenant.
+func NewTenantIdMatchesRequestValidator() claims.SessionClaimValidator {
+	tenantIdClaim := multitenancyclaims.TenantIdClaim
+	return claims.SessionClaimValidator{
+		ID:    tenantIdClaim.Key,
+		Claim: tenantIdClaim,
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			return false
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) claims.ClaimValidationResult {
+			expectedValue := GetTenantId(userContext)
+			actualValue := tenantIdClaim.GetValueFromPayload(payload, userContext)
+			if actualValue == nil {
+				actualValue = supertokens.DefaultTenantId
+			}
+			if actualValue != expectedValue {
+				return claims.ClaimValidationResult{
+					IsValid: false,
+					Reason: map[string]interface{}{
+						"message":       "wrong tenant",
+						"expectedValue": expectedValue,
+						"actualValue":   actualValue,
+					},
+				}
+			}
+			return claims.ClaimValidationResult{
+				IsValid: true,
+			}
+		},
+	}
+}
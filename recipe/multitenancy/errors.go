@@ -0,0 +1,35 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+// UnknownTenantError is returned if a request is for a tenant that does not exist.
+type UnknownTenantError struct {
+	Msg string
+}
+
+func (err UnknownTenantError) Error() string {
+	return err.Msg
+}
+
+// LoginMethodNotEnabledError is returned if a login API is called for a tenant that
+// does not have that login method enabled.
+type LoginMethodNotEnabledError struct {
+	Msg string
+}
+
+func (err LoginMethodNotEnabledError) Error() string {
+	return err.Msg
+}
@@ -0,0 +1,115 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func Init(config *multitenancymodels.TypeInput) supertokens.Recipe {
+	return recipeInit(config)
+}
+
+// GetTenantId returns the tenant of the request in userContext, or the tenant set via
+// supertokens.WithTenantId on the context in userContext.
+func GetTenantId(userContext supertokens.UserContext) string {
+	return supertokens.GetTenantIdFromContext(supertokens.GetContextFromUserContext(userContext))
+}
+
+func GetTenantConfig(tenantId string) (*multitenancymodels.TenantConfig, error) {
+	return GetTenantConfigWithContext(tenantId, &map[string]interface{}{})
+}
+
+func GetTenantConfigWithContext(tenantId string, userContext supertokens.UserContext) (*multitenancymodels.TenantConfig, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.GetTenantConfig)(tenantId, userContext)
+}
+
+func IsLoginMethodEnabled(tenantId string, loginMethod multitenancymodels.LoginMethod) (bool, error) {
+	return IsLoginMethodEnabledWithContext(tenantId, loginMethod, &map[string]interface{}{})
+}
+
+// IsLoginMethodEnabledWithContext returns false if the tenant does not exist.
+func IsLoginMethodEnabledWithContext(tenantId string, loginMethod multitenancymodels.LoginMethod, userContext supertokens.UserContext) (bool, error) {
+	tenantConfig, err := GetTenantConfigWithContext(tenantId, userContext)
+	if err != nil {
+		return false, err
+	}
+	if tenantConfig == nil {
+		return false, nil
+	}
+	return isLoginMethodEnabledInConfig(*tenantConfig, loginMethod), nil
+}
+
+// ValidateLoginMethodForRequest is called by the login recipes before handling an API. It
+// returns an UnknownTenantError or a LoginMethodNotEnabledError if the API may not be used by
+// the tenant of req. If the multitenancy recipe is not initialised, it returns nil.
+func ValidateLoginMethodForRequest(req *http.Request, loginMethod multitenancymodels.LoginMethod) error {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(req)
+	instance := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
+	if instance == nil {
+		return nil
+	}
+	tenantId := GetTenantId(userContext)
+	tenantConfig, err := (*instance.RecipeImpl.GetTenantConfig)(tenantId, userContext)
+	if err != nil {
+		return err
+	}
+	if tenantConfig == nil {
+		return UnknownTenantError{Msg: "Unknown tenant: " + tenantId}
+	}
+	if !isLoginMethodEnabledInConfig(*tenantConfig, loginMethod) {
+		return LoginMethodNotEnabledError{Msg: "Login method " + string(loginMethod) + " is not enabled for tenant " + tenantId}
+	}
+	return nil
+}
+
+// GetThirdPartyProvidersForRequest returns the providers of the tenant of req, or providers
+// if the tenant does not have its own.
+func GetThirdPartyProvidersForRequest(req *http.Request, providers []tpmodels.TypeProvider) ([]tpmodels.TypeProvider, error) {
+	userContext := supertokens.MakeDefaultUserContextFromAPI(req)
+	instance := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
+	if instance == nil {
+		return providers, nil
+	}
+	tenantConfig, err := (*instance.RecipeImpl.GetTenantConfig)(GetTenantId(userContext), userContext)
+	if err != nil {
+		return nil, err
+	}
+	if tenantConfig == nil || tenantConfig.ThirdParty.Providers == nil {
+		return providers, nil
+	}
+	return tenantConfig.ThirdParty.Providers, nil
+}
+
+func isLoginMethodEnabledInConfig(tenantConfig multitenancymodels.TenantConfig, loginMethod multitenancymodels.LoginMethod) bool {
+	switch loginMethod {
+	case multitenancymodels.LoginMethodEmailPassword:
+		return tenantConfig.EmailPassword.Enabled
+	case multitenancymodels.LoginMethodPasswordless:
+		return tenantConfig.Passwordless.Enabled
+	case multitenancymodels.LoginMethodThirdParty:
+		return tenantConfig.ThirdParty.Enabled
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func initForTest(t *testing.T, config *multitenancymodels.TypeInput) *httptest.Server {
+	err := supertokens.Init(supertokens.TypeInput{
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(config),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(GetTenantId(supertokens.MakeDefaultUserContextFromAPI(r))))
+	})
+	return httptest.NewServer(supertokens.Middleware(mux))
+}
+
+func getJSON(t *testing.T, url string, tenantId string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	if tenantId != "" {
+		req.Header.Set("st-tenant-id", tenantId)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	result := map[string]interface{}{}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	json.Unmarshal(bodyBytes, &result)
+	return resp.StatusCode, result
+}
+
+var testTenants = map[string]multitenancymodels.TenantConfig{
+	"acme": {
+		EmailPassword: multitenancymodels.EmailPasswordConfig{Enabled: true},
+		ThirdParty: multitenancymodels.ThirdPartyConfig{
+			Enabled:   true,
+			Providers: []tpmodels.TypeProvider{{ID: "google"}},
+		},
+	},
+}
+
+func TestLoginMethodsForTenant(t *testing.T) {
+	BeforeEach()
+	defer AfterEach()
+	testServer := initForTest(t, &multitenancymodels.TypeInput{
+		Tenants: testTenants,
+	})
+	defer testServer.Close()
+
+	status, body := getJSON(t, testServer.URL+"/auth/loginmethods", "acme")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "OK", body["status"])
+	assert.Equal(t, true, body["emailPassword"].(map[string]interface{})["enabled"])
+	assert.Equal(t, false, body["passwordless"].(map[string]interface{})["enabled"])
+	thirdParty := body["thirdParty"].(map[string]interface{})
+	assert.Equal(t, true, thirdParty["enabled"])
+	assert.Equal(t, "google", thirdParty["providers"].([]interface{})[0].(map[string]interface{})["id"])
+
+	status, body = getJSON(t, testServer.URL+"/auth/loginmethods", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, true, body["passwordless"].(map[string]interface{})["enabled"])
+
+	status, body = getJSON(t, testServer.URL+"/auth/loginmethods", "unknown")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "UNKNOWN_TENANT_ERROR", body["status"])
+
+	status, _ = getJSON(t, testServer.URL+"/auth/loginmethods", "Not/Valid")
+	assert.Equal(t, 400, status)
+}
+
+func TestTenantIdFromPathPrefix(t *testing.T) {
+	BeforeEach()
+	defer AfterEach()
+	testServer := initForTest(t, &multitenancymodels.TypeInput{
+		GetTenantId: FromPathPrefix(),
+		Tenants:     testTenants,
+	})
+	defer testServer.Close()
+
+	status, body := getJSON(t, testServer.URL+"/auth/acme/loginmethods", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, false, body["passwordless"].(map[string]interface{})["enabled"])
+
+	status, body = getJSON(t, testServer.URL+"/auth/loginmethods", "acme")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, true, body["passwordless"].(map[string]interface{})["enabled"])
+
+	status, _ = getJSON(t, testServer.URL+"/auth/unknown/loginmethods", "")
+	assert.Equal(t, 404, status)
+}
+
+func TestTenantIdFromSubdomain(t *testing.T) {
+	resolver := FromSubdomain("example.com")
+	for host, expectedTenantId := range map[string]string{
+		"acme.example.com":      "acme",
+		"acme.example.com:3000": "acme",
+		"example.com":           "",
+		"a.b.example.com":       "",
+		"acme.other.com":        "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		tenantId, newReq, err := resolver(req, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, newReq)
+		assert.Equal(t, expectedTenantId, tenantId, host)
+	}
+}
+
+func TestTenantIdIsAvailableToOtherHandlers(t *testing.T) {
+	BeforeEach()
+	defer AfterEach()
+	testServer := initForTest(t, nil)
+	defer testServer.Close()
+
+	for tenantId, expected := range map[string]string{"": "public", "acme": "acme"} {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/tenant", nil)
+		assert.NoError(t, err)
+		req.Header.Set("st-tenant-id", tenantId)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, expected, string(bodyBytes))
+	}
+}
+
+func TestInvalidTenantIdIsNotRejectedOnOtherRoutes(t *testing.T) {
+	BeforeEach()
+	defer AfterEach()
+	testServer := initForTest(t, nil)
+	defer testServer.Close()
+
+	req, err := http.NewRequest(http.MethodGet, testServer.URL+"/tenant", nil)
+	assert.NoError(t, err)
+	req.Header.Set("st-tenant-id", "Not/Valid")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "public", string(bodyBytes))
+}
@@ -0,0 +1,6 @@
+package multitenancyclaims
+
+import "github.com/supertokens/supertokens-golang/recipe/session/claims"
+
+var TenantIdClaim *claims.TypeSessionClaim
+var TenantIdClaimValidators claims.PrimitiveClaimValidators
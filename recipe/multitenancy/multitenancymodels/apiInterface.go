@@ -0,0 +1,52 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancymodels
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type APIOptions struct {
+	RecipeImplementation RecipeInterface
+	Config               TypeNormalisedInput
+	RecipeID             string
+	Req                  *http.Request
+	Res                  http.ResponseWriter
+	OtherHandler         http.HandlerFunc
+	// StaticThirdPartyProviders are the providers of the thirdparty recipe, which are
+	// used for tenants that do not have their own providers.
+	StaticThirdPartyProviders []tpmodels.TypeProvider
+}
+
+type APIInterface struct {
+	LoginMethodsGET *func(tenantId string, options APIOptions, userContext supertokens.UserContext) (LoginMethodsGETResponse, error)
+}
+
+type LoginMethodsGETResponse struct {
+	OK *struct {
+		EmailPassword EmailPasswordConfig
+		Passwordless  PasswordlessConfig
+		ThirdParty    struct {
+			Enabled   bool
+			Providers []string
+		}
+	}
+	UnknownTenantError *struct{}
+	GeneralError       *supertokens.GeneralErrorResponse
+}
@@ -0,0 +1,81 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancymodels
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// TenantIdResolver returns the ID of the tenant that req is for, or an empty string for the
+// default tenant. It can return a copy of req that is handled instead of req, which is how
+// the tenant ID is removed from the path when using multitenancy.FromPathPrefix.
+type TenantIdResolver func(req *http.Request, userContext supertokens.UserContext) (tenantId string, newReq *http.Request, err error)
+
+type LoginMethod string
+
+const (
+	LoginMethodEmailPassword LoginMethod = "emailpassword"
+	LoginMethodPasswordless  LoginMethod = "passwordless"
+	LoginMethodThirdParty    LoginMethod = "thirdparty"
+)
+
+type TenantConfig struct {
+	EmailPassword EmailPasswordConfig
+	Passwordless  PasswordlessConfig
+	ThirdParty    ThirdPartyConfig
+}
+
+type EmailPasswordConfig struct {
+	Enabled bool
+}
+
+type PasswordlessConfig struct {
+	Enabled bool
+}
+
+type ThirdPartyConfig struct {
+	Enabled bool
+	// Providers replaces the providers of the thirdparty recipe for this tenant. If nil,
+	// the providers of the recipe are used.
+	Providers []tpmodels.TypeProvider
+}
+
+type TypeInput struct {
+	// GetTenantId defaults to reading the tenant ID from the st-tenant-id header.
+	GetTenantId TenantIdResolver
+	// Tenants maps tenant IDs to their config. The default tenant has all login
+	// methods enabled, unless it is part of this map.
+	Tenants map[string]TenantConfig
+	// SkipTenantIdValidation stops sessions from being rejected if they were created
+	// for a different tenant than the one of the request.
+	SkipTenantIdValidation bool
+	Override               *OverrideStruct
+}
+
+type TypeNormalisedInput struct {
+	GetTenantId            TenantIdResolver
+	Tenants                map[string]TenantConfig
+	SkipTenantIdValidation bool
+	Override               OverrideStruct
+}
+
+type OverrideStruct struct {
+	Functions func(originalImplementation RecipeInterface) RecipeInterface
+	APIs      func(originalImplementation APIInterface) APIInterface
+}
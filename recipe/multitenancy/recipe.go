@@ -0,0 +1,186 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	defaultErrors "errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/api"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancyclaims"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const RECIPE_ID = "multitenancy"
+
+type Recipe struct {
+	RecipeModule supertokens.RecipeModule
+	Config       multitenancymodels.TypeNormalisedInput
+	RecipeImpl   multitenancymodels.RecipeInterface
+	APIImpl      multitenancymodels.APIInterface
+
+	staticThirdPartyProviders []tpmodels.TypeProvider
+}
+
+func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *multitenancymodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+	r := &Recipe{}
+	verifiedConfig, err := validateAndNormaliseUserInput(appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
+	r.Config = verifiedConfig
+	r.RecipeImpl = verifiedConfig.Override.Functions(makeRecipeImplementation(verifiedConfig))
+	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
+
+	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
+	r.RecipeModule = recipeModuleInstance
+
+	return *r, nil
+}
+
+func getRecipeInstanceOrThrowError() (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
+	}
+	return nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+// GetRecipeInstanceFor returns the multitenancy recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+func recipeInit(config *multitenancymodels.TypeInput) supertokens.Recipe {
+	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onSuperTokensAPIError)
+			if err != nil {
+				return nil, err
+			}
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			stInstance.SetTenantIdResolver(recipe.resolveTenantId)
+
+			supertokens.AddPostInitCallback(func() error {
+				sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+				if sessionRecipe == nil {
+					return nil
+				}
+
+				err := sessionRecipe.AddClaimFromOtherRecipe(multitenancyclaims.TenantIdClaim)
+				if err != nil {
+					return err
+				}
+
+				if !recipe.Config.SkipTenantIdValidation {
+					return sessionRecipe.AddClaimValidatorFromOtherRecipe(NewTenantIdMatchesRequestValidator())
+				}
+				return nil
+			})
+
+			return &recipe.RecipeModule, nil
+		}
+		return nil, defaultErrors.New("Multitenancy recipe has already been initialised. Please check your code for bugs.")
+	}
+}
+
+// AddStaticThirdPartyProviders is called by the thirdparty recipes, so that their providers
+// can be listed for tenants that do not have their own.
+func (r *Recipe) AddStaticThirdPartyProviders(providers []tpmodels.TypeProvider) {
+	r.staticThirdPartyProviders = append(r.staticThirdPartyProviders, providers...)
+}
+
+func (r *Recipe) resolveTenantId(req *http.Request) (string, *http.Request, error) {
+	tenantId, newReq, err := r.Config.GetTenantId(req, supertokens.MakeDefaultUserContextFromAPI(req))
+	if err != nil {
+		return "", nil, err
+	}
+	if tenantId == "" {
+		return supertokens.DefaultTenantId, newReq, nil
+	}
+	if !isValidTenantId(tenantId) {
+		return "", nil, supertokens.BadInputError{Msg: "Invalid tenant ID"}
+	}
+	return tenantId, newReq, nil
+}
+
+// implement RecipeModule
+
+func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
+	loginMethodsAPINormalised, err := supertokens.NewNormalisedURLPath(loginMethodsAPI)
+	if err != nil {
+		return nil, err
+	}
+	return []supertokens.APIHandled{{
+		Method:                 http.MethodGet,
+		PathWithoutAPIBasePath: loginMethodsAPINormalised,
+		ID:                     loginMethodsAPI,
+		Disabled:               r.APIImpl.LoginMethodsGET == nil,
+	}}, nil
+}
+
+func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, _ supertokens.NormalisedURLPath, _ string) error {
+	options := multitenancymodels.APIOptions{
+		RecipeImplementation:      r.RecipeImpl,
+		Config:                    r.Config,
+		RecipeID:                  r.RecipeModule.GetRecipeID(),
+		Req:                       req,
+		Res:                       res,
+		OtherHandler:              theirHandler,
+		StaticThirdPartyProviders: r.staticThirdPartyProviders,
+	}
+	if id == loginMethodsAPI {
+		return api.LoginMethodsAPI(r.APIImpl, options)
+	}
+	return defaultErrors.New("should never come here")
+}
+
+func (r *Recipe) getAllCORSHeaders() []string {
+	return []string{tenantIdHeader}
+}
+
+func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+	if defaultErrors.As(err, &UnknownTenantError{}) {
+		return true, supertokens.SendNon200ResponseWithMessage(res, err.Error(), 400)
+	}
+	if defaultErrors.As(err, &LoginMethodNotEnabledError{}) {
+		return true, supertokens.SendNon200ResponseWithMessage(res, err.Error(), 403)
+	}
+	return false, nil
+}
+
+func ResetForTest() {
+}
@@ -0,0 +1,41 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func makeRecipeImplementation(config multitenancymodels.TypeNormalisedInput) multitenancymodels.RecipeInterface {
+	getTenantConfig := func(tenantId string, userContext supertokens.UserContext) (*multitenancymodels.TenantConfig, error) {
+		if tenantConfig, ok := config.Tenants[tenantId]; ok {
+			return &tenantConfig, nil
+		}
+		if tenantId == supertokens.DefaultTenantId {
+			return &multitenancymodels.TenantConfig{
+				EmailPassword: multitenancymodels.EmailPasswordConfig{Enabled: true},
+				Passwordless:  multitenancymodels.PasswordlessConfig{Enabled: true},
+				ThirdParty:    multitenancymodels.ThirdPartyConfig{Enabled: true},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	return multitenancymodels.RecipeInterface{
+		GetTenantConfig: &getTenantConfig,
+	}
+}
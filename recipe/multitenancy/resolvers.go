@@ -0,0 +1,94 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// FromHeader reads the tenant ID from a request header.
+func FromHeader(headerName string) multitenancymodels.TenantIdResolver {
+	return func(req *http.Request, userContext supertokens.UserContext) (string, *http.Request, error) {
+		return strings.TrimSpace(req.Header.Get(headerName)), nil, nil
+	}
+}
+
+// FromSubdomain uses the subdomain of parentDomain that a request was sent to, so that
+// requests to acme.example.com are for the tenant acme. Requests to other hosts are for
+// the default tenant.
+func FromSubdomain(parentDomain string) multitenancymodels.TenantIdResolver {
+	suffix := "." + strings.ToLower(strings.Trim(parentDomain, "."))
+	return func(req *http.Request, userContext supertokens.UserContext) (string, *http.Request, error) {
+		host := strings.ToLower(req.Host)
+		if hostWithoutPort, _, err := net.SplitHostPort(host); err == nil {
+			host = hostWithoutPort
+		}
+		if !strings.HasSuffix(host, suffix) {
+			return "", nil, nil
+		}
+		subdomain := strings.TrimSuffix(host, suffix)
+		if strings.Contains(subdomain, ".") {
+			return "", nil, nil
+		}
+		return subdomain, nil, nil
+	}
+}
+
+// FromPathPrefix reads the tenant ID from the first path segment after the API base path,
+// so that /auth/acme/signin is handled as /auth/signin for the tenant acme. The segment is
+// only used if a tenant with that ID exists.
+func FromPathPrefix() multitenancymodels.TenantIdResolver {
+	return func(req *http.Request, userContext supertokens.UserContext) (string, *http.Request, error) {
+		stInstance := supertokens.GetInstanceFromContext(req.Context())
+		recipe := GetRecipeInstanceFor(stInstance)
+		if recipe == nil {
+			return "", nil, nil
+		}
+		gatewayPath := stInstance.AppInfo.APIGatewayPath.GetAsStringDangerous()
+		basePath := stInstance.AppInfo.APIBasePath.GetAsStringDangerous()
+
+		path := gatewayPath + req.URL.Path
+		if !strings.HasPrefix(path, basePath+"/") {
+			return "", nil, nil
+		}
+		segments := strings.SplitN(strings.TrimPrefix(path, basePath+"/"), "/", 2)
+		tenantId := segments[0]
+		if !isValidTenantId(tenantId) {
+			return "", nil, nil
+		}
+		tenantConfig, err := (*recipe.RecipeImpl.GetTenantConfig)(tenantId, userContext)
+		if err != nil {
+			return "", nil, err
+		}
+		if tenantConfig == nil {
+			return "", nil, nil
+		}
+
+		newPath := basePath
+		if len(segments) == 2 {
+			newPath += "/" + segments[1]
+		}
+		newReq := req.Clone(req.Context())
+		newReq.URL.Path = strings.TrimPrefix(newPath, gatewayPath)
+		newReq.URL.RawPath = ""
+		return tenantId, newReq, nil
+	}
+}
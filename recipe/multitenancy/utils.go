@@ -0,0 +1,75 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package multitenancy
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// tenant IDs are part of the paths of requests to the core, so they are limited to
+// characters that don't need escaping. "recipe" is reserved since recipe paths start with it.
+var tenantIdRegex = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,63}$")
+
+func isValidTenantId(tenantId string) bool {
+	return tenantId != "recipe" && tenantIdRegex.MatchString(tenantId)
+}
+
+func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config *multitenancymodels.TypeInput) (multitenancymodels.TypeNormalisedInput, error) {
+	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
+
+	if config != nil {
+		if config.GetTenantId != nil {
+			typeNormalisedInput.GetTenantId = config.GetTenantId
+		}
+		for tenantId, tenantConfig := range config.Tenants {
+			if !isValidTenantId(tenantId) {
+				return multitenancymodels.TypeNormalisedInput{}, errors.New("invalid tenant ID: " + tenantId + ". Tenant IDs can only contain lowercase letters, numbers and dashes")
+			}
+			typeNormalisedInput.Tenants[tenantId] = tenantConfig
+		}
+		typeNormalisedInput.SkipTenantIdValidation = config.SkipTenantIdValidation
+
+		if config.Override != nil {
+			if config.Override.Functions != nil {
+				typeNormalisedInput.Override.Functions = config.Override.Functions
+			}
+			if config.Override.APIs != nil {
+				typeNormalisedInput.Override.APIs = config.Override.APIs
+			}
+		}
+	}
+
+	return typeNormalisedInput, nil
+}
+
+func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) multitenancymodels.TypeNormalisedInput {
+	return multitenancymodels.TypeNormalisedInput{
+		GetTenantId: FromHeader(tenantIdHeader),
+		Tenants:     map[string]multitenancymodels.TenantConfig{},
+		Override: multitenancymodels.OverrideStruct{
+			Functions: func(originalImplementation multitenancymodels.RecipeInterface) multitenancymodels.RecipeInterface {
+				return originalImplementation
+			},
+			APIs: func(originalImplementation multitenancymodels.APIInterface) multitenancymodels.APIInterface {
+				return originalImplementation
+			},
+		},
+	}
+}
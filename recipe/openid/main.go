@@ -26,7 +26,7 @@ func Init(config *openidmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer *uint64, userContext supertokens.UserContext) (jwtmodels.CreateJWTResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.CreateJWTResponse{}, err
 	}
@@ -34,7 +34,7 @@ func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer
 }
 
 func GetJWKSWithContext(userContext supertokens.UserContext) (jwtmodels.GetJWKSResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.GetJWKSResponse{}, err
 	}
@@ -42,7 +42,7 @@ func GetJWKSWithContext(userContext supertokens.UserContext) (jwtmodels.GetJWKSR
 }
 
 func GetOpenIdDiscoveryConfigurationWithContext(userContext supertokens.UserContext) (openidmodels.GetOpenIdDiscoveryConfigurationResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return openidmodels.GetOpenIdDiscoveryConfigurationResponse{}, err
 	}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/ingredients/smsdelivery"
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
@@ -46,7 +47,7 @@ func MakeAPIImplementation() plessmodels.APIInterface {
 		user := response.OK.User
 
 		if user.Email != nil {
-			evInstance := emailverification.GetRecipeInstance()
+			evInstance := emailverification.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
 			if evInstance != nil {
 				tokenResponse, err := (*evInstance.RecipeImpl.CreateEmailVerificationToken)(user.ID, *user.Email, userContext)
 				if err != nil {
@@ -61,7 +62,11 @@ func MakeAPIImplementation() plessmodels.APIInterface {
 			}
 		}
 
-		session, err := session.CreateNewSessionWithContext(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		sessionUserID, err := accountlinking.GetPrimaryUserIdAfterSignInUp(user.ID, userContext)
+		if err != nil {
+			return plessmodels.ConsumeCodePOSTResponse{}, err
+		}
+		session, err := session.CreateNewSessionOrReauthenticateWithContext(options.Req, options.Res, sessionUserID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
 			return plessmodels.ConsumeCodePOSTResponse{}, err
 		}
@@ -24,13 +24,16 @@ import (
 )
 
 func MakeServiceImplementation(settings emaildelivery.SMTPSettings) emaildelivery.SMTPInterface {
+	sender := emaildelivery.MakeSMTPSender(settings)
 	sendRawEmail := func(input emaildelivery.EmailContent, userContext supertokens.UserContext) error {
-		return emaildelivery.SendSMTPEmail(settings, input)
+		return sender.Send(input)
 	}
 
+	renderer := emaildelivery.MakeTemplateRenderer(settings.Templates)
+
 	getContent := func(input emaildelivery.EmailType, userContext supertokens.UserContext) (emaildelivery.EmailContent, error) {
 		if input.PasswordlessLogin != nil {
-			return getPasswordlessLoginEmailContent(*input.PasswordlessLogin)
+			return getPasswordlessLoginEmailContent(*input.PasswordlessLogin, renderer, userContext)
 		} else {
 			return emaildelivery.EmailContent{}, errors.New("should never come here")
 		}
@@ -360,6 +360,54 @@ func TestSmsTwilioOverridePasswordlessLogin(t *testing.T) {
 	assert.Equal(t, sendRawSmsCalled, true)
 }
 
+func TestSmsProviderServicePasswordlessLogin(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	var sentSms *smsdelivery.SMSContent
+	smsService := MakeSMSProviderService(smsdelivery.ProviderServiceConfig{
+		Provider: smsdelivery.SmsProvider{
+			SendRawSms: func(input smsdelivery.SMSContent, userContext supertokens.UserContext) error {
+				sentSms = &input
+				return nil
+			},
+		},
+	})
+
+	plessConfig := plessmodels.TypeInput{
+		FlowType: "USER_INPUT_CODE",
+		ContactMethodPhone: plessmodels.ContactMethodPhoneConfig{
+			Enabled: true,
+		},
+		SmsDelivery: &smsdelivery.TypeInput{
+			Service: smsService,
+		},
+	}
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(plessConfig))
+	defer testServer.Close()
+
+	querier, err := supertokens.GetNewQuerierInstanceOrThrowError("")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	cdiVersion, err := querier.GetQuerierAPIVersion()
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if unittesting.MaxVersion("2.10", cdiVersion) == "2.10" {
+		return
+	}
+
+	resp, err := unittesting.PasswordlessPhoneLoginRequest("+919876543210", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.NotNil(t, sentSms)
+	assert.Equal(t, "+919876543210", sentSms.ToPhoneNumber)
+	assert.Regexp(t, `^OTP to login is [0-9]+ for SuperTokens\n\nThis is valid for 15 minutes\.$`, sentSms.Body)
+}
+
 // func TestSupertokensServiceManually(t *testing.T) {
 // 	serviceImpl := supertokensService.MakeSupertokensSMSService("...")
 
@@ -24,8 +24,11 @@ import (
 	"github.com/supertokens/supertokens-golang/ingredients/smsdelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
 	"github.com/supertokens/supertokens-golang/recipe/passwordless/api"
 	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -40,8 +43,6 @@ type Recipe struct {
 	SmsDelivery   smsdelivery.Ingredient
 }
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config plessmodels.TypeInput, emailDeliveryIngredient *emaildelivery.Ingredient, smsDeliveryIngredient *smsdelivery.Ingredient, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	r := &Recipe{}
 	verifiedConfig := validateAndNormaliseUserInput(appInfo, config)
@@ -72,10 +73,18 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config p
 	}
 
 	supertokens.AddPostInitCallback(func() error {
-		emailVerificationRecipe := emailverification.GetRecipeInstance()
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return err
+		}
+		emailVerificationRecipe := emailverification.GetRecipeInstanceFor(stInstance)
 		if emailVerificationRecipe != nil {
 			emailVerificationRecipe.AddGetEmailForUserIdFunc(r.getEmailForUserId)
 		}
+		sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+		if sessionRecipe != nil {
+			sessionRecipe.AddUserExistsFunc(r.userExists)
+		}
 		return nil
 	})
 
@@ -83,25 +92,48 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config p
 }
 
 func GetRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
 	}
 	return nil, errors.New("initialisation not done. Did you forget to call the init function?")
 }
 
 func GetRecipeInstance() *Recipe {
-	return singletonInstance
+	return GetRecipeInstanceFor(supertokens.GetDefaultInstance())
+}
+
+// GetRecipeInstanceFor returns the passwordless recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("initialisation not done. Did you forget to call the init function?")
 }
 
 func recipeInit(config plessmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, nil, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("passwordless recipe has already been initialised. Please check your code for bugs")
 	}
@@ -160,6 +192,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 }
 
 func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, _ supertokens.NormalisedURLPath, _ string) error {
+	err := multitenancy.ValidateLoginMethodForRequest(req, multitenancymodels.LoginMethodPasswordless)
+	if err != nil {
+		return err
+	}
+
 	options := plessmodels.APIOptions{
 		Config:               r.Config,
 		RecipeID:             r.RecipeModule.GetRecipeID(),
@@ -193,7 +230,7 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 }
 
 func (r *Recipe) CreateMagicLink(email *string, phoneNumber *string, userContext supertokens.UserContext) (string, error) {
-	stInstance, err := supertokens.GetInstanceOrThrowError()
+	stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return "", err
 	}
@@ -272,6 +309,14 @@ func (r *Recipe) SignInUp(email *string, phoneNumber *string, userContext supert
 	}
 }
 
+func (r *Recipe) userExists(userID string, userContext supertokens.UserContext) (bool, error) {
+	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
+	if err != nil {
+		return false, err
+	}
+	return userInfo != nil, nil
+}
+
 func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserContext) (evmodels.TypeEmailInfo, error) {
 	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
 	if err != nil {
@@ -295,7 +340,6 @@ func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserCo
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 	PasswordlessLoginEmailSentForTest = false
 	PasswordlessLoginEmailDataForTest = struct {
 		Email            string
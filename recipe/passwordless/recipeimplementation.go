@@ -33,7 +33,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		if userInputCode != nil {
 			body["userInputCode"] = *userInputCode
 		}
-		response, err := querier.SendPostRequest("/recipe/signinup/code", body)
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/code", body)
 		if err != nil {
 			return plessmodels.CreateCodeResponse{}, err
 		}
@@ -60,12 +60,13 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		} else if linkCode != nil {
 			body["linkCode"] = *linkCode
 		}
-		response, err := querier.SendPostRequest("/recipe/signinup/code/consume", body)
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/code/consume", body)
 		if err != nil {
 			return plessmodels.ConsumeCodeResponse{}, err
 		}
 		status := response["status"].(string)
 		if status == "OK" {
+			recordSignInUp(querier.GetInstrumentation(), userContext, response["createdNewUser"].(bool))
 			return plessmodels.ConsumeCodeResponse{
 				OK: &struct {
 					CreatedNewUser bool
@@ -76,6 +77,14 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 				},
 			}, nil
 		} else if status == "INCORRECT_USER_INPUT_CODE_ERROR" {
+			supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+				Type:     supertokens.SecurityEventSignInFailed,
+				RecipeID: RECIPE_ID,
+				Details: map[string]interface{}{
+					"preAuthSessionId":            preAuthSessionID,
+					"failedCodeInputAttemptCount": int(response["failedCodeInputAttemptCount"].(float64)),
+				},
+			}, userContext)
 			return plessmodels.ConsumeCodeResponse{
 				IncorrectUserInputCodeError: &struct {
 					FailedCodeInputAttemptCount int
@@ -112,7 +121,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 			body["userInputCode"] = *userInputCode
 		}
 
-		response, err := querier.SendPostRequest("/recipe/signinup/code", body)
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/code", body)
 		if err != nil {
 			return plessmodels.ResendCodeResponse{}, err
 		}
@@ -143,7 +152,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	getUserByEmail := func(email string, userContext supertokens.UserContext) (*plessmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"email": email,
 		})
 		if err != nil {
@@ -159,7 +168,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	getUserByID := func(userID string, userContext supertokens.UserContext) (*plessmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"userId": userID,
 		})
 		if err != nil {
@@ -175,7 +184,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	getUserByPhoneNumber := func(phoneNumber string, userContext supertokens.UserContext) (*plessmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"phoneNumber": phoneNumber,
 		})
 		if err != nil {
@@ -191,7 +200,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	listCodesByDeviceID := func(deviceID string, userContext supertokens.UserContext) (*plessmodels.DeviceType, error) {
-		response, err := querier.SendGetRequest("/recipe/signinup/codes", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/codes", map[string]string{
 			"deviceId": deviceID,
 		})
 
@@ -209,7 +218,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	listCodesByEmail := func(email string, userContext supertokens.UserContext) ([]plessmodels.DeviceType, error) {
-		response, err := querier.SendGetRequest("/recipe/signinup/codes", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/codes", map[string]string{
 			"email": email,
 		})
 
@@ -221,7 +230,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	listCodesByPhoneNumber := func(phoneNumber string, userContext supertokens.UserContext) ([]plessmodels.DeviceType, error) {
-		response, err := querier.SendGetRequest("/recipe/signinup/codes", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/codes", map[string]string{
 			"phoneNumber": phoneNumber,
 		})
 
@@ -233,7 +242,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 
 	listCodesByPreAuthSessionID := func(preAuthSessionID string, userContext supertokens.UserContext) (*plessmodels.DeviceType, error) {
-		response, err := querier.SendGetRequest("/recipe/signinup/codes", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/codes", map[string]string{
 			"preAuthSessionId": preAuthSessionID,
 		})
 
@@ -257,7 +266,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		} else if phoneNumber != nil {
 			body["phoneNumber"] = *phoneNumber
 		}
-		_, err := querier.SendPostRequest("/recipe/signinup/codes/remove", body)
+		_, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/codes/remove", body)
 		if err != nil {
 			return err
 		}
@@ -268,7 +277,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		body := map[string]interface{}{
 			"codeId": codeID,
 		}
-		_, err := querier.SendPostRequest("/recipe/signinup/code/remove", body)
+		_, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup/code/remove", body)
 		if err != nil {
 			return err
 		}
@@ -285,8 +294,17 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		if phoneNumber != nil {
 			body["phoneNumber"] = *phoneNumber
 		}
+		var userBeforeUpdate *plessmodels.User = nil
+		// the user is only fetched to report the old email in the EMAIL_CHANGED event
+		if email != nil && supertokens.HasSecurityEventListeners(userContext) {
+			user, err := getUserByID(userID, userContext)
+			if err != nil {
+				return plessmodels.UpdateUserResponse{}, err
+			}
+			userBeforeUpdate = user
+		}
 
-		response, err := querier.SendPutRequest("/recipe/user", body)
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", body)
 		if err != nil {
 			return plessmodels.UpdateUserResponse{}, err
 		}
@@ -294,6 +312,20 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 		status := response["status"].(string)
 
 		if status == "OK" {
+			if userBeforeUpdate != nil && (userBeforeUpdate.Email == nil || *userBeforeUpdate.Email != *email) {
+				details := map[string]interface{}{
+					"newEmail": *email,
+				}
+				if userBeforeUpdate.Email != nil {
+					details["oldEmail"] = *userBeforeUpdate.Email
+				}
+				supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+					Type:     supertokens.SecurityEventEmailChanged,
+					RecipeID: RECIPE_ID,
+					UserID:   userID,
+					Details:  details,
+				}, userContext)
+			}
 			return plessmodels.UpdateUserResponse{
 				OK: &struct{}{},
 			}, nil
@@ -318,7 +350,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 			"email":  nil,
 		}
 
-		response, err := querier.SendPutRequest("/recipe/user", body)
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", body)
 		if err != nil {
 			return plessmodels.DeleteUserResponse{}, err
 		}
@@ -344,7 +376,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 			"phoneNumber": nil,
 		}
 
-		response, err := querier.SendPutRequest("/recipe/user", body)
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", body)
 		if err != nil {
 			return plessmodels.DeleteUserResponse{}, err
 		}
@@ -383,6 +415,16 @@ func MakeRecipeImplementation(querier supertokens.Querier) plessmodels.RecipeInt
 	}
 }
 
+func recordSignInUp(instrumentation supertokens.Instrumentation, userContext supertokens.UserContext, createdNewUser bool) {
+	metric := supertokens.MetricSignIns
+	if createdNewUser {
+		metric = supertokens.MetricSignUps
+	}
+	instrumentation.AddToCounter(supertokens.GetContextFromUserContext(userContext), metric, 1, map[string]interface{}{
+		"supertokens.login_method": "passwordless",
+	})
+}
+
 func getDevicesFromResponse(devicesJSON []interface{}) []plessmodels.DeviceType {
 	result := []plessmodels.DeviceType{}
 	for _, deviceJSON := range devicesJSON {
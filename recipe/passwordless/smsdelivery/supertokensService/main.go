@@ -32,7 +32,7 @@ const SUPERTOKENS_SMS_SERVICE_URL = "https://api.supertokens.com/0/services/sms"
 
 func MakeSupertokensSMSService(apiKey string) *smsdelivery.SmsDeliveryInterface {
 	sendPasswordlessLoginSms := func(input smsdelivery.PasswordlessLoginType, userContext supertokens.UserContext) error {
-		instance, err := supertokens.GetInstanceOrThrowError()
+		instance, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
 		if err != nil {
 			return err
 		}
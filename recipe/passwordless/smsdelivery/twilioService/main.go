@@ -36,6 +36,26 @@ func MakeTwilioService(config smsdelivery.TwilioServiceConfig) (*smsdelivery.Sms
 		serviceImpl = config.Override(serviceImpl)
 	}
 
+	return makeSmsDeliveryInterface(serviceImpl), nil
+}
+
+// MakeProviderService returns a service that sends SMSs with the API of an SMS provider, like
+// smsdelivery.VonageProvider, instead of Twilio.
+func MakeProviderService(config smsdelivery.ProviderServiceConfig) *smsdelivery.SmsDeliveryInterface {
+	serviceImpl := MakeServiceImplementation(smsdelivery.TwilioSettings{
+		Templates: config.Templates,
+	})
+	sendRawSms := config.Provider.SendRawSms
+	serviceImpl.SendRawSms = &sendRawSms
+
+	if config.Override != nil {
+		serviceImpl = config.Override(serviceImpl)
+	}
+
+	return makeSmsDeliveryInterface(serviceImpl)
+}
+
+func makeSmsDeliveryInterface(serviceImpl smsdelivery.TwilioInterface) *smsdelivery.SmsDeliveryInterface {
 	sendSms := func(input smsdelivery.SmsType, userContext supertokens.UserContext) error {
 		if input.PasswordlessLogin != nil {
 			content, err := (*serviceImpl.GetContent)(input, userContext)
@@ -51,5 +71,5 @@ func MakeTwilioService(config smsdelivery.TwilioServiceConfig) (*smsdelivery.Sms
 
 	return &smsdelivery.SmsDeliveryInterface{
 		SendSms: &sendSms,
-	}, nil
+	}
 }
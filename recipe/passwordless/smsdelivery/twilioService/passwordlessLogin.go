@@ -17,59 +17,40 @@
 package twilioService
 
 import (
-	"strings"
+	"errors"
 
 	"github.com/supertokens/supertokens-golang/ingredients/smsdelivery"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-const magicLinkLoginTemplate = `Click ${magicLink} to login to ${appname}
-
-This is valid for ${time}.`
-const otpLoginTemplate = `OTP to login is ${otp} for ${appname}
-
-This is valid for ${time}.`
-const magicLinkAndOtpLoginTemplate = `OTP to login is ${otp} for ${appname}
-
-Or click ${magicLink} to login.
-
-This is valid for ${time}.`
-
-func getPasswordlessLoginSmsContent(input smsdelivery.PasswordlessLoginType) smsdelivery.SMSContent {
-	stInstance, err := supertokens.GetInstanceOrThrowError()
+func getPasswordlessLoginSmsContent(input smsdelivery.PasswordlessLoginType, renderer *smsdelivery.TemplateRenderer, userContext supertokens.UserContext) (smsdelivery.SMSContent, error) {
+	stInstance, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		panic("Please call supertokens.Init function before using the Middleware")
 	}
-	return smsdelivery.SMSContent{
-		Body:          getPasswordlessLoginSmsBody(stInstance.AppInfo.AppName, input.CodeLifetime, input.UrlWithLinkCode, input.UserInputCode),
+
+	data := smsdelivery.TemplateData{
+		AppName:       stInstance.AppInfo.AppName,
 		ToPhoneNumber: input.PhoneNumber,
+		CodeLifetime:  supertokens.HumaniseMilliseconds(input.CodeLifetime),
 	}
-}
-
-func getPasswordlessLoginSmsBody(appName string, codeLifetime uint64, urlWithLinkCode *string, userInputCode *string) string {
-	var smsBody string
-
-	if urlWithLinkCode != nil && userInputCode != nil {
-		smsBody = magicLinkAndOtpLoginTemplate
-	} else if urlWithLinkCode != nil {
-		smsBody = magicLinkLoginTemplate
-	} else if userInputCode != nil {
-		smsBody = otpLoginTemplate
-	} else {
-		// Should never come here
+	if input.UrlWithLinkCode != nil {
+		data.Link = *input.UrlWithLinkCode
 	}
-
-	humanisedCodeLifetime := supertokens.HumaniseMilliseconds(codeLifetime)
-
-	smsBody = strings.Replace(smsBody, "*|MC:SUBJECT|*", "Login to your account", -1)
-	smsBody = strings.Replace(smsBody, "${appname}", appName, -1)
-	smsBody = strings.Replace(smsBody, "${time}", humanisedCodeLifetime, -1)
-	if urlWithLinkCode != nil {
-		smsBody = strings.Replace(smsBody, "${magicLink}", *urlWithLinkCode, -1)
+	if input.UserInputCode != nil {
+		data.OTP = *input.UserInputCode
 	}
-	if userInputCode != nil {
-		smsBody = strings.Replace(smsBody, "${otp}", *userInputCode, -1)
+
+	var templateName string
+	if input.UrlWithLinkCode != nil && input.UserInputCode != nil {
+		templateName = smsdelivery.TemplatePasswordlessLoginMagicLinkAndOTP
+	} else if input.UrlWithLinkCode != nil {
+		templateName = smsdelivery.TemplatePasswordlessLoginMagicLink
+	} else if input.UserInputCode != nil {
+		templateName = smsdelivery.TemplatePasswordlessLoginOTP
+	} else {
+		return smsdelivery.SMSContent{}, errors.New("should never come here")
 	}
 
-	return smsBody
+	return renderer.Render(templateName, data, nil, userContext)
 }
@@ -26,9 +26,10 @@ func MakeServiceImplementation(config smsdelivery.TwilioSettings) smsdelivery.Tw
 		return smsdelivery.SendTwilioSms(config, input)
 	}
 
+	renderer := smsdelivery.MakeTemplateRenderer(config.Templates)
+
 	getContent := func(input smsdelivery.SmsType, userContext supertokens.UserContext) (smsdelivery.SMSContent, error) {
-		result := getPasswordlessLoginSmsContent(*input.PasswordlessLogin)
-		return result, nil
+		return getPasswordlessLoginSmsContent(*input.PasswordlessLogin, renderer, userContext)
 	}
 
 	return smsdelivery.TwilioInterface{
@@ -40,6 +40,21 @@ func getInfoFromAccessToken(token string, jwtSigningPublicKey string, doAntiCsrf
 		}
 	}
 
+	accessTokenInfo, err := getInfoFromAccessTokenPayload(payload, doAntiCsrfCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessTokenInfo.expiryTime < getCurrTimeInMS() {
+		return nil, errors.TryRefreshTokenError{
+			Msg: "Access token expired",
+		}
+	}
+
+	return accessTokenInfo, nil
+}
+
+func getInfoFromAccessTokenPayload(payload map[string]interface{}, doAntiCsrfCheck bool) (*accessTokenInfoStruct, error) {
 	sessionHandle := sanitizeStringInput(payload["sessionHandle"])
 	userID := sanitizeStringInput(payload["userId"])
 	refreshTokenHash1 := sanitizeStringInput(payload["refreshTokenHash1"])
@@ -77,12 +92,6 @@ func getInfoFromAccessToken(token string, jwtSigningPublicKey string, doAntiCsrf
 		}
 	}
 
-	if *expiryTime < getCurrTimeInMS() {
-		return nil, errors.TryRefreshTokenError{
-			Msg: "Access token expired",
-		}
-	}
-
 	return &accessTokenInfoStruct{
 		sessionHandle:           *sessionHandle,
 		userID:                  *userID,
@@ -0,0 +1,237 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// ImpersonationBlockedMessage is the message in the reason of the claim validation error returned
+// by MakeImpersonationValidator.
+const ImpersonationBlockedMessage = "not allowed during impersonation"
+
+// GetImpersonationInfoFromPayload returns the impersonation info in an access token payload, or
+// nil if the payload is not of an impersonation session.
+func GetImpersonationInfoFromPayload(accessTokenPayload map[string]interface{}) *sessmodels.ImpersonationInfo {
+	value, ok := accessTokenPayload[sessmodels.ImpersonationKey]
+	if !ok || value == nil {
+		return nil
+	}
+	// the value is a map after being read from a token, and a struct before that
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var info sessmodels.ImpersonationInfo
+	err = json.Unmarshal(valueBytes, &info)
+	if err != nil || info.ImpersonatorID == "" {
+		return nil
+	}
+	return &info
+}
+
+// MakeImpersonationValidator returns a validator that fails for impersonation sessions, or only
+// for read only ones if onlyReadOnly is true.
+func MakeImpersonationValidator(onlyReadOnly bool) claims.SessionClaimValidator {
+	return claims.SessionClaimValidator{
+		ID: sessmodels.ImpersonationKey,
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) claims.ClaimValidationResult {
+			info := GetImpersonationInfoFromPayload(payload)
+			if info == nil || (onlyReadOnly && !info.ReadOnly) {
+				return claims.ClaimValidationResult{
+					IsValid: true,
+				}
+			}
+			return claims.ClaimValidationResult{
+				IsValid: false,
+				Reason: map[string]interface{}{
+					"message":        ImpersonationBlockedMessage,
+					"impersonatorId": info.ImpersonatorID,
+					"readOnly":       info.ReadOnly,
+				},
+			}
+		},
+	}
+}
+
+// CreateImpersonationSession creates a session of targetUserID in which impersonatorID acts as
+// that user. The session expires after the configured impersonation lifetime. It returns an
+// UnknownUserIDError if none of the login recipes has targetUserID.
+func CreateImpersonationSession(options sessmodels.APIOptions, impersonatorID string, targetUserID string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	if options.UserExists != nil {
+		exists, err := options.UserExists(targetUserID, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.UnknownUserIDError{
+				Msg:    "Unknown user ID: " + targetUserID,
+				UserID: targetUserID,
+			}
+		}
+	}
+
+	info := sessmodels.ImpersonationInfo{
+		ImpersonatorID: impersonatorID,
+		ReadOnly:       options.Config.Impersonation.ReadOnly,
+		ExpiresAt:      uint64(time.Now().Add(options.Config.Impersonation.Lifetime).UnixNano() / 1000000),
+	}
+	accessTokenPayload := map[string]interface{}{
+		sessmodels.ImpersonationKey: info,
+	}
+	var err error
+	for _, claim := range options.ClaimsAddedByOtherRecipes {
+		accessTokenPayload, err = claim.Build(targetUserID, accessTokenPayload, userContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sessionContainer, err := (*options.RecipeImplementation.CreateNewSession)(options.Res, targetUserID, accessTokenPayload, map[string]interface{}{}, userContext)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := supertokens.GetLogger()
+	if options.Req != nil {
+		logger = supertokens.GetLoggerFromContext(options.Req.Context())
+	}
+	logger.Info("Impersonation session created", "recipeId", options.RecipeID, "sessionHandle", sessionContainer.GetHandle(), "impersonatorId", impersonatorID)
+	supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+		Type:          supertokens.SecurityEventImpersonationStarted,
+		RecipeID:      options.RecipeID,
+		UserID:        targetUserID,
+		SessionHandle: sessionContainer.GetHandle(),
+		Details: map[string]interface{}{
+			"impersonatorId": impersonatorID,
+			"readOnly":       info.ReadOnly,
+			"expiresAt":      info.ExpiresAt,
+		},
+	}, userContext)
+	return sessionContainer, nil
+}
+
+// StopImpersonation revokes an impersonation session. It returns false if sessionContainer is
+// not an impersonation session.
+func StopImpersonation(sessionContainer sessmodels.SessionContainer, recipeID string, userContext supertokens.UserContext) (bool, error) {
+	info := GetImpersonationInfoFromPayload(sessionContainer.GetAccessTokenPayloadWithContext(userContext))
+	if info == nil {
+		return false, nil
+	}
+	err := sessionContainer.RevokeSessionWithContext(userContext)
+	if err != nil {
+		return false, err
+	}
+	supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+		Type:          supertokens.SecurityEventImpersonationStopped,
+		RecipeID:      recipeID,
+		UserID:        sessionContainer.GetUserID(),
+		SessionHandle: sessionContainer.GetHandle(),
+		Details: map[string]interface{}{
+			"impersonatorId": info.ImpersonatorID,
+			"reason":         "stopped",
+		},
+	}, userContext)
+	return true, nil
+}
+
+func ImpersonateAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.ImpersonatePOST == nil || (*apiImplementation.ImpersonatePOST == nil) {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON body"}
+	}
+	targetUserID, ok := readBody["userId"].(string)
+	if !ok || targetUserID == "" {
+		return supertokens.BadInputError{Msg: "Please provide the userId as a string"}
+	}
+
+	sessionContainer, err := getSessionForAPI(apiImplementation, nil, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.ImpersonatePOST)(targetUserID, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else if resp.NotAllowedError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "IMPERSONATION_NOT_ALLOWED_ERROR",
+		})
+	} else if resp.UnknownUserIDError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNKNOWN_USER_ID_ERROR",
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func StopImpersonationAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.StopImpersonationPOST == nil || (*apiImplementation.StopImpersonationPOST == nil) {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	sessionContainer, err := getSessionForAPI(apiImplementation, nil, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.StopImpersonationPOST)(sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else if resp.NotImpersonatingError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "NOT_IMPERSONATING_ERROR",
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
@@ -16,9 +16,11 @@
 package api
 
 import (
+	defaultErrors "errors"
 	"net/http"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -94,9 +96,118 @@ func MakeAPIImplementation() sessmodels.APIInterface {
 		}, nil
 	}
 
+	sessionsGET := func(sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionsGETResponse, error) {
+		sessions, err := (*options.RecipeImplementation.GetSessionsForUser)(sessionContainer.GetUserID(), userContext)
+		if err != nil {
+			return sessmodels.SessionsGETResponse{}, err
+		}
+		return sessmodels.SessionsGETResponse{
+			OK: &struct {
+				Sessions []sessmodels.SessionInformation
+			}{
+				Sessions: sessions,
+			},
+		}, nil
+	}
+
+	revokeSessionPOST := func(sessionHandle string, sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.RevokeSessionPOSTResponse, error) {
+		if sessionHandle == sessionContainer.GetHandle() {
+			err := sessionContainer.RevokeSessionWithContext(userContext)
+			if err != nil {
+				return sessmodels.RevokeSessionPOSTResponse{}, err
+			}
+			return sessmodels.RevokeSessionPOSTResponse{
+				OK: &struct{}{},
+			}, nil
+		}
+
+		sessionInformation, err := (*options.RecipeImplementation.GetSessionInformation)(sessionHandle, userContext)
+		if err != nil {
+			return sessmodels.RevokeSessionPOSTResponse{}, err
+		}
+		// users must not be able to find out about or revoke sessions of other users
+		if sessionInformation == nil || sessionInformation.UserId != sessionContainer.GetUserID() {
+			return sessmodels.RevokeSessionPOSTResponse{
+				UnknownSessionError: &struct{}{},
+			}, nil
+		}
+
+		revoked, err := (*options.RecipeImplementation.RevokeSession)(sessionHandle, userContext)
+		if err != nil {
+			return sessmodels.RevokeSessionPOSTResponse{}, err
+		}
+		if !revoked {
+			return sessmodels.RevokeSessionPOSTResponse{
+				UnknownSessionError: &struct{}{},
+			}, nil
+		}
+		return sessmodels.RevokeSessionPOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
+	impersonatePOST := func(targetUserID string, sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.ImpersonatePOSTResponse, error) {
+		if options.Config.Impersonation.IsAllowed == nil {
+			return sessmodels.ImpersonatePOSTResponse{
+				NotAllowedError: &struct{}{},
+			}, nil
+		}
+		// impersonation sessions must not be used to start another impersonation
+		if GetImpersonationInfoFromPayload(sessionContainer.GetAccessTokenPayloadWithContext(userContext)) != nil {
+			return sessmodels.ImpersonatePOSTResponse{
+				NotAllowedError: &struct{}{},
+			}, nil
+		}
+		allowed, err := options.Config.Impersonation.IsAllowed(sessionContainer, targetUserID, userContext)
+		if err != nil {
+			return sessmodels.ImpersonatePOSTResponse{}, err
+		}
+		if !allowed {
+			return sessmodels.ImpersonatePOSTResponse{
+				NotAllowedError: &struct{}{},
+			}, nil
+		}
+
+		impersonationSession, err := CreateImpersonationSession(options, sessionContainer.GetUserID(), targetUserID, userContext)
+		if err != nil {
+			if defaultErrors.As(err, &errors.UnknownUserIDError{}) {
+				return sessmodels.ImpersonatePOSTResponse{
+					UnknownUserIDError: &struct{}{},
+				}, nil
+			}
+			return sessmodels.ImpersonatePOSTResponse{}, err
+		}
+		return sessmodels.ImpersonatePOSTResponse{
+			OK: &struct {
+				Session sessmodels.SessionContainer
+			}{
+				Session: impersonationSession,
+			},
+		}, nil
+	}
+
+	stopImpersonationPOST := func(sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.StopImpersonationPOSTResponse, error) {
+		stopped, err := StopImpersonation(sessionContainer, options.RecipeID, userContext)
+		if err != nil {
+			return sessmodels.StopImpersonationPOSTResponse{}, err
+		}
+		if !stopped {
+			return sessmodels.StopImpersonationPOSTResponse{
+				NotImpersonatingError: &struct{}{},
+			}, nil
+		}
+		return sessmodels.StopImpersonationPOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
 	return sessmodels.APIInterface{
-		RefreshPOST:   &refreshPOST,
-		VerifySession: &verifySession,
-		SignOutPOST:   &signOutPOST,
+		RefreshPOST:           &refreshPOST,
+		VerifySession:         &verifySession,
+		SignOutPOST:           &signOutPOST,
+		SessionsGET:           &sessionsGET,
+		RevokeSessionPOST:     &revokeSessionPOST,
+		ImpersonatePOST:       &impersonatePOST,
+		StopImpersonationPOST: &stopImpersonationPOST,
 	}
 }
@@ -0,0 +1,121 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func getSessionForAPI(apiImplementation sessmodels.APIInterface, verifySessionOptions *sessmodels.VerifySessionOptions, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	if apiImplementation.VerifySession != nil && *apiImplementation.VerifySession != nil {
+		return (*apiImplementation.VerifySession)(verifySessionOptions, options, userContext)
+	}
+	return (*options.RecipeImplementation.GetSession)(options.Req, options.Res, verifySessionOptions, userContext)
+}
+
+func SessionsAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.SessionsGET == nil || (*apiImplementation.SessionsGET == nil) {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	sessionContainer, err := getSessionForAPI(apiImplementation, nil, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.SessionsGET)(sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		sessions := []map[string]interface{}{}
+		for _, session := range resp.OK.Sessions {
+			sessions = append(sessions, map[string]interface{}{
+				"sessionHandle": session.SessionHandle,
+				"timeCreated":   session.TimeCreated,
+				"expiry":        session.Expiry,
+				"current":       session.SessionHandle == sessionContainer.GetHandle(),
+				"metadata":      session.Metadata,
+			})
+		}
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":   "OK",
+			"sessions": sessions,
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func RevokeSessionAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.RevokeSessionPOST == nil || (*apiImplementation.RevokeSessionPOST == nil) {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON body"}
+	}
+	sessionHandle, ok := readBody["sessionHandle"].(string)
+	if !ok || sessionHandle == "" {
+		return supertokens.BadInputError{Msg: "Please provide the sessionHandle as a string"}
+	}
+
+	// read only impersonation sessions must not sign the user out of their other sessions
+	sessionContainer, err := getSessionForAPI(apiImplementation, &sessmodels.VerifySessionOptions{
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return append(globalClaimValidators, MakeImpersonationValidator(true)), nil
+		},
+	}, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.RevokeSessionPOST)(sessionHandle, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else if resp.UnknownSessionError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNKNOWN_SESSION_ERROR",
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
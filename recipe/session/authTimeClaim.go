@@ -0,0 +1,122 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// ReauthenticationRequiredMessage is the message in the reason of the claim validation error
+// returned by RequireRecentAuth, so that the frontend can ask the user to sign in again.
+const ReauthenticationRequiredMessage = "re-authentication required"
+
+// AuthTimeClaim holds the time (in milliseconds since epoch) at which the user last signed in.
+// It is set by the sign in and sign up APIs of the login recipes, via
+// CreateNewSessionOrReauthenticateWithContext. Sessions created by calling CreateNewSession
+// directly only have it if it is built into their access token payload. Fetching the value of this
+// claim counts as authenticating the user, so it should not be refetched outside of a sign in.
+var AuthTimeClaim, _ = claims.PrimitiveClaim("st-auth-time", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+	return time.Now().UnixNano() / 1000000, nil
+}, nil)
+
+// RequireRecentAuth returns a validator that fails if the user has not signed in within maxAge,
+// for example to protect APIs that change the password of the user. It is meant to be added in
+// VerifySessionOptions.OverrideGlobalClaimValidators of the APIs that need it.
+func RequireRecentAuth(maxAge time.Duration) claims.SessionClaimValidator {
+	maxAgeInSeconds := int64(maxAge / time.Second)
+	return claims.SessionClaimValidator{
+		ID:    AuthTimeClaim.Key,
+		Claim: AuthTimeClaim,
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			// refetching would set the auth time to now without the user signing in again
+			return false
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) claims.ClaimValidationResult {
+			authTime, ok := AuthTimeClaim.GetValueFromPayload(payload, userContext).(float64)
+			if !ok {
+				return claims.ClaimValidationResult{
+					IsValid: false,
+					Reason: map[string]interface{}{
+						"message":         ReauthenticationRequiredMessage,
+						"maxAgeInSeconds": maxAgeInSeconds,
+					},
+				}
+			}
+			ageInMs := time.Now().UnixNano()/1000000 - int64(authTime)
+			if ageInMs > maxAge.Milliseconds() {
+				return claims.ClaimValidationResult{
+					IsValid: false,
+					Reason: map[string]interface{}{
+						"message":         ReauthenticationRequiredMessage,
+						"authTime":        int64(authTime),
+						"maxAgeInSeconds": maxAgeInSeconds,
+					},
+				}
+			}
+			return claims.ClaimValidationResult{
+				IsValid: true,
+			}
+		},
+	}
+}
+
+// CreateNewSessionOrReauthenticateWithContext is used by the sign in APIs of the login recipes. If
+// the request already has a valid session of userID, the AuthTimeClaim of that session is updated
+// and the session is returned, so signing in again does not create another session. Otherwise, a
+// new session is created with the AuthTimeClaim set. The existing session is read with the usual
+// anti-csrf check, and a session that fails it is not reused.
+func CreateNewSessionOrReauthenticateWithContext(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+
+	False := false
+	existingSession, err := (*instance.RecipeImpl.GetSession)(req, res, &sessmodels.VerifySessionOptions{
+		SessionRequired: &False,
+	}, userContext)
+	if err != nil {
+		// an expired or invalid session is replaced by the new one
+		if !defaultErrors.As(err, &errors.TryRefreshTokenError{}) && !defaultErrors.As(err, &errors.UnauthorizedError{}) {
+			return nil, err
+		}
+		existingSession = nil
+	}
+	if existingSession != nil && existingSession.GetUserID() == userID {
+		err := existingSession.FetchAndSetClaimWithContext(AuthTimeClaim, userContext)
+		if err != nil {
+			return nil, err
+		}
+		return existingSession, nil
+	}
+
+	finalAccessTokenPayload := map[string]interface{}{}
+	for key, value := range accessTokenPayload {
+		finalAccessTokenPayload[key] = value
+	}
+	finalAccessTokenPayload, err = AuthTimeClaim.Build(userID, finalAccessTokenPayload, userContext)
+	if err != nil {
+		return nil, err
+	}
+	return CreateNewSessionWithContext(res, userID, finalAccessTokenPayload, sessionData, userContext)
+}
@@ -0,0 +1,44 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRecentAuthValidator(t *testing.T) {
+	userContext := &map[string]interface{}{}
+	validator := RequireRecentAuth(time.Minute)
+
+	result := validator.Validate(map[string]interface{}{}, userContext)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, ReauthenticationRequiredMessage, result.Reason.(map[string]interface{})["message"])
+	assert.False(t, validator.ShouldRefetch(map[string]interface{}{}, userContext))
+
+	now := float64(time.Now().UnixNano() / 1000000)
+	payload := map[string]interface{}{
+		AuthTimeClaim.Key: map[string]interface{}{"v": now - 1000, "t": now},
+	}
+	assert.True(t, validator.Validate(payload, userContext).IsValid)
+
+	payload[AuthTimeClaim.Key] = map[string]interface{}{"v": now - 2*60*1000, "t": now}
+	result = validator.Validate(payload, userContext)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, int64(60), result.Reason.(map[string]interface{})["maxAgeInSeconds"])
+}
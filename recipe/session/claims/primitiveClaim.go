@@ -45,7 +45,9 @@ func PrimitiveClaim(key string, fetchValue FetchValueFunc, defaultMaxAgeInSecond
 				return &it
 			}
 		}
-		return nil
+		// a missing refetch time is treated as a very old value, so that it is refetched
+		t := int64(0)
+		return &t
 	}
 
 	validators := PrimitiveClaimValidators{
@@ -0,0 +1,302 @@
+package claims
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Ordered is the set of types that Range can compare.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~string
+}
+
+// Primitive is a claim with a single value of type T. Its TypeSessionClaim can be used with all
+// the functions that take an untyped claim, like session.FetchAndSetClaim.
+type Primitive[T comparable] struct {
+	*TypeSessionClaim
+	defaultMaxAgeInSeconds *int64
+}
+
+// NewPrimitive creates a claim with a single value of type T. If fetchValue returns nil, the
+// claim is not added to the payload.
+func NewPrimitive[T comparable](key string, fetchValue func(userId string, userContext supertokens.UserContext) (*T, error), defaultMaxAgeInSeconds *int64) *Primitive[T] {
+	return &Primitive[T]{
+		TypeSessionClaim:       typedSessionClaim(key, fetchValue),
+		defaultMaxAgeInSeconds: defaultMaxAgeInSeconds,
+	}
+}
+
+// GetValue returns the value of the claim in the access token payload. It returns false if the
+// value does not exist or cannot be decoded into T.
+func (c *Primitive[T]) GetValue(payload map[string]interface{}) (T, bool) {
+	return decodeClaimValue[T](getRawClaimValue(payload, c.Key))
+}
+
+func (c *Primitive[T]) HasValue(val T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return typedClaimValidator(c.TypeSessionClaim, c.GetValue, c.maxAge(maxAgeInSeconds), id, map[string]interface{}{
+		"expectedValue": val,
+	}, func(value T) bool {
+		return value == val
+	})
+}
+
+// Range returns a validator that checks that the value of the claim is between min and max,
+// both included.
+func Range[T Ordered](claim *Primitive[T], min T, max T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return typedClaimValidator(claim.TypeSessionClaim, claim.GetValue, claim.maxAge(maxAgeInSeconds), id, map[string]interface{}{
+		"expectedMin": min,
+		"expectedMax": max,
+	}, func(value T) bool {
+		return value >= min && value <= max
+	})
+}
+
+func (c *Primitive[T]) maxAge(maxAgeInSeconds *int64) *int64 {
+	if maxAgeInSeconds == nil {
+		return c.defaultMaxAgeInSeconds
+	}
+	return maxAgeInSeconds
+}
+
+// Array is a claim with a list of values of type T, like roles or permissions.
+type Array[T comparable] struct {
+	*TypeSessionClaim
+	defaultMaxAgeInSeconds *int64
+}
+
+// NewArray creates a claim with a list of values of type T. If fetchValue returns a nil slice,
+// the claim is not added to the payload.
+func NewArray[T comparable](key string, fetchValue func(userId string, userContext supertokens.UserContext) ([]T, error), defaultMaxAgeInSeconds *int64) *Array[T] {
+	return &Array[T]{
+		TypeSessionClaim: typedSessionClaim(key, func(userId string, userContext supertokens.UserContext) (*[]T, error) {
+			values, err := fetchValue(userId, userContext)
+			if err != nil || values == nil {
+				return nil, err
+			}
+			return &values, nil
+		}),
+		defaultMaxAgeInSeconds: defaultMaxAgeInSeconds,
+	}
+}
+
+// GetValue returns the values of the claim in the access token payload. It returns false if the
+// values do not exist or cannot be decoded into []T.
+func (c *Array[T]) GetValue(payload map[string]interface{}) ([]T, bool) {
+	values, ok := decodeClaimValue[[]T](getRawClaimValue(payload, c.Key))
+	return values, ok && values != nil
+}
+
+func (c *Array[T]) Includes(val T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.validator(maxAgeInSeconds, id, "expectedToInclude", val, func(values []T) bool {
+		return countIncluded(values, []T{val}) == 1
+	})
+}
+
+func (c *Array[T]) Excludes(val T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.validator(maxAgeInSeconds, id, "expectedToNotInclude", val, func(values []T) bool {
+		return countIncluded(values, []T{val}) == 0
+	})
+}
+
+func (c *Array[T]) IncludesAll(vals []T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.validator(maxAgeInSeconds, id, "expectedToInclude", vals, func(values []T) bool {
+		return countIncluded(values, vals) == len(vals)
+	})
+}
+
+func (c *Array[T]) IncludesAny(vals []T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.validator(maxAgeInSeconds, id, "expectedToIncludeAny", vals, func(values []T) bool {
+		return countIncluded(values, vals) > 0
+	})
+}
+
+func (c *Array[T]) ExcludesAll(vals []T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.validator(maxAgeInSeconds, id, "expectedToNotInclude", vals, func(values []T) bool {
+		return countIncluded(values, vals) == 0
+	})
+}
+
+func (c *Array[T]) validator(maxAgeInSeconds *int64, id *string, expectationKey string, expectation interface{}, isValid func(values []T) bool) SessionClaimValidator {
+	if maxAgeInSeconds == nil {
+		maxAgeInSeconds = c.defaultMaxAgeInSeconds
+	}
+	return typedClaimValidator(c.TypeSessionClaim, c.GetValue, maxAgeInSeconds, id, map[string]interface{}{
+		expectationKey: expectation,
+	}, isValid)
+}
+
+// Object is a claim with a value of any type that can be encoded to JSON, like a struct.
+type Object[T any] struct {
+	*TypeSessionClaim
+	defaultMaxAgeInSeconds *int64
+}
+
+// NewObject creates a claim with a value of type T, which is stored in the payload in its JSON
+// form. If fetchValue returns nil, the claim is not added to the payload.
+func NewObject[T any](key string, fetchValue func(userId string, userContext supertokens.UserContext) (*T, error), defaultMaxAgeInSeconds *int64) *Object[T] {
+	return &Object[T]{
+		TypeSessionClaim:       typedSessionClaim(key, fetchValue),
+		defaultMaxAgeInSeconds: defaultMaxAgeInSeconds,
+	}
+}
+
+// GetValue returns the value of the claim in the access token payload. It returns false if the
+// value does not exist or cannot be decoded into T.
+func (c *Object[T]) GetValue(payload map[string]interface{}) (T, bool) {
+	return decodeClaimValue[T](getRawClaimValue(payload, c.Key))
+}
+
+func (c *Object[T]) HasValue(val T, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	return c.Matches(func(value T) bool {
+		return reflect.DeepEqual(value, val)
+	}, maxAgeInSeconds, id)
+}
+
+// Matches returns a validator that checks the value of the claim with isValid.
+func (c *Object[T]) Matches(isValid func(value T) bool, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+	if maxAgeInSeconds == nil {
+		maxAgeInSeconds = c.defaultMaxAgeInSeconds
+	}
+	return typedClaimValidator(c.TypeSessionClaim, c.GetValue, maxAgeInSeconds, id, map[string]interface{}{}, isValid)
+}
+
+func typedSessionClaim[T any](key string, fetchValue func(userId string, userContext supertokens.UserContext) (*T, error)) *TypeSessionClaim {
+	sessionClaim, _ := PrimitiveClaim(key, func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		value, err := fetchValue(userId, userContext)
+		if err != nil || value == nil {
+			return nil, err
+		}
+		return encodeClaimValue(*value)
+	}, nil)
+	return sessionClaim
+}
+
+// encodeClaimValue converts value to the form it has after the payload is decoded from JSON, so
+// that the payload looks the same before and after it is sent to the core.
+func encodeClaimValue(value interface{}) (interface{}, error) {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var encoded interface{}
+	err = json.Unmarshal(valueJSON, &encoded)
+	return encoded, err
+}
+
+func decodeClaimValue[T any](raw interface{}) (T, bool) {
+	var value T
+	if raw == nil {
+		return value, false
+	}
+	if typedValue, ok := raw.(T); ok {
+		return typedValue, true
+	}
+	valueJSON, err := json.Marshal(raw)
+	if err != nil {
+		return value, false
+	}
+	if json.Unmarshal(valueJSON, &value) != nil {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
+func getRawClaimValue(payload map[string]interface{}, key string) interface{} {
+	if value, ok := payload[key].(map[string]interface{}); ok {
+		return value["v"]
+	}
+	return nil
+}
+
+func getClaimRefetchTime(payload map[string]interface{}, key string) (int64, bool) {
+	if value, ok := payload[key].(map[string]interface{}); ok {
+		switch t := value["t"].(type) {
+		case int64:
+			return t, true
+		case float64:
+			return int64(t), true
+		}
+	}
+	return 0, false
+}
+
+func typedClaimValidator[V any](sessionClaim *TypeSessionClaim, getValue func(payload map[string]interface{}) (V, bool), maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, isValid func(value V) bool) SessionClaimValidator {
+	validatorId := sessionClaim.Key
+	if id != nil {
+		validatorId = *id
+	}
+	failure := func(message string, actualValue interface{}) ClaimValidationResult {
+		reason := map[string]interface{}{
+			"message":     message,
+			"actualValue": actualValue,
+		}
+		for k, v := range expectation {
+			reason[k] = v
+		}
+		return ClaimValidationResult{
+			IsValid: false,
+			Reason:  reason,
+		}
+	}
+
+	return SessionClaimValidator{
+		ID:    validatorId,
+		Claim: sessionClaim,
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			if _, ok := getValue(payload); !ok {
+				return true
+			}
+			refetchTime, ok := getClaimRefetchTime(payload, sessionClaim.Key)
+			if !ok {
+				return true
+			}
+			return maxAgeInSeconds != nil && refetchTime < time.Now().UnixNano()/1000000-*maxAgeInSeconds*1000
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+			value, ok := getValue(payload)
+			if !ok {
+				return failure("value does not exist", getRawClaimValue(payload, sessionClaim.Key))
+			}
+			if maxAgeInSeconds != nil {
+				refetchTime, _ := getClaimRefetchTime(payload, sessionClaim.Key)
+				ageInSeconds := (time.Now().UnixNano()/1000000 - refetchTime) / 1000
+				if ageInSeconds > *maxAgeInSeconds {
+					return ClaimValidationResult{
+						IsValid: false,
+						Reason: map[string]interface{}{
+							"message":         "expired",
+							"ageInSeconds":    ageInSeconds,
+							"maxAgeInSeconds": *maxAgeInSeconds,
+						},
+					}
+				}
+			}
+			if !isValid(value) {
+				return failure("wrong value", value)
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		},
+	}
+}
+
+func countIncluded[T comparable](values []T, vals []T) int {
+	valuesMap := map[T]bool{}
+	for _, v := range values {
+		valuesMap[v] = true
+	}
+	count := 0
+	for _, v := range vals {
+		if valuesMap[v] {
+			count++
+		}
+	}
+	return count
+}
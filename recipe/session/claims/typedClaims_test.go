@@ -0,0 +1,136 @@
+package claims
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// toJWTPayload sends the payload through JSON, the way it is after being read from an access token
+func toJWTPayload(t *testing.T, payload map[string]interface{}) map[string]interface{} {
+	payloadJSON, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	result := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(payloadJSON, &result))
+	return result
+}
+
+func TestTypedPrimitiveClaim(t *testing.T) {
+	ageClaim := NewPrimitive("age", func(userId string, userContext supertokens.UserContext) (*int, error) {
+		age := 30
+		return &age, nil
+	}, nil)
+
+	payload, err := ageClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	payload = toJWTPayload(t, payload)
+
+	age, ok := ageClaim.GetValue(payload)
+	assert.True(t, ok)
+	assert.Equal(t, 30, age)
+
+	assert.True(t, ageClaim.HasValue(30, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, ageClaim.HasValue(31, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, Range(ageClaim, 18, 30, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, Range(ageClaim, 31, 99, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, ageClaim.HasValue(30, nil, nil).ShouldRefetch(payload, nil))
+}
+
+func TestTypedPrimitiveClaimWithoutValue(t *testing.T) {
+	ageClaim := NewPrimitive("age", func(userId string, userContext supertokens.UserContext) (*int, error) {
+		return nil, nil
+	}, nil)
+
+	payload, err := ageClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, payload, "age")
+
+	_, ok := ageClaim.GetValue(payload)
+	assert.False(t, ok)
+	assert.True(t, ageClaim.HasValue(30, nil, nil).ShouldRefetch(payload, nil))
+	assert.Equal(t, "value does not exist", ageClaim.HasValue(30, nil, nil).Validate(payload, nil).Reason.(map[string]interface{})["message"])
+}
+
+func TestTypedArrayClaim(t *testing.T) {
+	roleClaim := NewArray("roles", func(userId string, userContext supertokens.UserContext) ([]string, error) {
+		return []string{"admin", "user"}, nil
+	}, nil)
+
+	payload, err := roleClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	payload = toJWTPayload(t, payload)
+
+	roles, ok := roleClaim.GetValue(payload)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admin", "user"}, roles)
+
+	assert.True(t, roleClaim.Includes("admin", nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, roleClaim.Includes("owner", nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, roleClaim.Excludes("owner", nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, roleClaim.IncludesAll([]string{"admin", "user"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, roleClaim.IncludesAll([]string{"admin", "owner"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, roleClaim.IncludesAny([]string{"admin", "owner"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, roleClaim.IncludesAny([]string{"owner", "guest"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, roleClaim.ExcludesAll([]string{"owner", "guest"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, roleClaim.ExcludesAll([]string{"owner", "user"}, nil, nil).Validate(payload, nil).IsValid)
+}
+
+func TestTypedObjectClaim(t *testing.T) {
+	type plan struct {
+		Name  string `json:"name"`
+		Seats int    `json:"seats"`
+	}
+	planClaim := NewObject("plan", func(userId string, userContext supertokens.UserContext) (*plan, error) {
+		return &plan{Name: "pro", Seats: 5}, nil
+	}, nil)
+
+	payload, err := planClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "pro", "seats": float64(5)}, payload["plan"].(map[string]interface{})["v"])
+	payload = toJWTPayload(t, payload)
+
+	value, ok := planClaim.GetValue(payload)
+	assert.True(t, ok)
+	assert.Equal(t, plan{Name: "pro", Seats: 5}, value)
+
+	assert.True(t, planClaim.HasValue(plan{Name: "pro", Seats: 5}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, planClaim.HasValue(plan{Name: "free", Seats: 1}, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, planClaim.Matches(func(value plan) bool {
+		return value.Seats >= 5
+	}, nil, nil).Validate(payload, nil).IsValid)
+}
+
+func TestTypedClaimsDoNotPanicOnMalformedPayloads(t *testing.T) {
+	ageClaim := NewPrimitive[int]("age", nil, nil)
+	roleClaim := NewArray[string]("roles", nil, nil)
+	maxAge := int64(60)
+
+	malformedPayloads := []map[string]interface{}{
+		{},
+		{"age": "30", "roles": "admin"},
+		{"age": map[string]interface{}{"v": "thirty"}, "roles": map[string]interface{}{"v": []interface{}{1, 2}}},
+		{"age": map[string]interface{}{"v": 30.5, "t": "now"}, "roles": map[string]interface{}{"v": "admin", "t": 1}},
+	}
+	for _, payload := range malformedPayloads {
+		for _, validator := range []SessionClaimValidator{
+			ageClaim.HasValue(30, &maxAge, nil),
+			Range(ageClaim, 0, 100, &maxAge, nil),
+			roleClaim.Includes("admin", &maxAge, nil),
+			roleClaim.IncludesAny([]string{"admin"}, &maxAge, nil),
+		} {
+			assert.True(t, validator.ShouldRefetch(payload, nil))
+			assert.False(t, validator.Validate(payload, nil).IsValid)
+		}
+	}
+}
+
+func TestPrimitiveArrayClaimDoesNotPanicOnMalformedPayloads(t *testing.T) {
+	_, validators := PrimitiveArrayClaim("roles", nil, nil)
+	payload := map[string]interface{}{"roles": map[string]interface{}{"v": "admin"}}
+
+	assert.True(t, validators.Includes("admin", nil, nil).ShouldRefetch(payload, nil))
+	assert.False(t, validators.Includes("admin", nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IncludesAll([]interface{}{"admin"}, nil, nil).Validate(payload, nil).IsValid)
+}
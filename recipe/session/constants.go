@@ -16,8 +16,13 @@
 package session
 
 const (
-	refreshAPIPath = "/session/refresh"
-	signoutAPIPath = "/signout"
+	refreshAPIPath       = "/session/refresh"
+	signoutAPIPath       = "/signout"
+	sessionsAPIPath      = "/session/list"
+	revokeSessionAPIPath = "/session/revoke"
+
+	impersonateAPIPath       = "/session/impersonate"
+	stopImpersonationAPIPath = "/session/impersonate/stop"
 
 	antiCSRF_VIA_TOKEN         = "VIA_TOKEN"
 	antiCSRF_VIA_CUSTOM_HEADER = "VIA_CUSTOM_HEADER"
@@ -26,4 +31,8 @@ const (
 	cookieSameSite_NONE   = "none"
 	cookieSameSite_LAX    = "lax"
 	cookieSameSite_STRICT = "strict"
+
+	// maxConcurrentSessionInformationRequests limits the requests to the core made by
+	// GetSessionsForUser for users with many sessions.
+	maxConcurrentSessionInformationRequests = 10
 )
@@ -41,6 +41,11 @@ const (
 
 	frontTokenHeaderKey = "front-token"
 
+	authorizationHeaderKey = "authorization"
+	accessTokenHeaderKey   = "st-access-token"
+	refreshTokenHeaderKey  = "st-refresh-token"
+	authModeHeaderKey      = "st-auth-mode"
+
 	frontendSDKNameHeaderKey    = "supertokens-sdk-name"
 	frontendSDKVersionHeaderKey = "supertokens-sdk-version"
 )
@@ -59,6 +64,39 @@ func clearSessionFromCookie(config sessmodels.TypeNormalisedInput, res http.Resp
 	setHeader(res, "Access-Control-Expose-Headers", idRefreshTokenHeaderKey, true)
 }
 
+func clearSessionFromHeaders(res http.ResponseWriter) {
+	setTokenInHeaders(res, accessTokenHeaderKey, "")
+	setTokenInHeaders(res, refreshTokenHeaderKey, "")
+	setHeader(res, frontTokenHeaderKey, "remove", false)
+	setHeader(res, "Access-Control-Expose-Headers", frontTokenHeaderKey, true)
+}
+
+func setTokenInHeaders(res http.ResponseWriter, key string, token string) {
+	setHeader(res, key, token, false)
+	setHeader(res, "Access-Control-Expose-Headers", key, true)
+}
+
+// getTokenFromAuthorizationHeader returns the token of an "Authorization: Bearer <token>" header.
+func getTokenFromAuthorizationHeader(req *http.Request) *string {
+	value := getHeader(req, authorizationHeaderKey)
+	if value == nil {
+		return nil
+	}
+	parts := strings.SplitN(strings.TrimSpace(*value), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return nil
+	}
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return nil
+	}
+	return &token
+}
+
+func getAuthModeFromHeader(req *http.Request) *string {
+	return getHeader(req, authModeHeaderKey)
+}
+
 func attachAccessTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64) {
 	setCookie(config, res, accessTokenCookieKey, token, expiry, "accessTokenPath")
 }
@@ -113,7 +151,7 @@ func setFrontTokenInHeaders(res http.ResponseWriter, userId string, atExpiry uin
 
 func getCORSAllowedHeaders() []string {
 	return []string{
-		antiCsrfHeaderKey, ridHeaderKey,
+		antiCsrfHeaderKey, ridHeaderKey, authorizationHeaderKey, authModeHeaderKey,
 	}
 }
 
@@ -22,6 +22,7 @@ const (
 	TryRefreshTokenErrorStr    = "TRY_REFRESH_TOKEN"
 	TokenTheftDetectedErrorStr = "TOKEN_THEFT_DETECTED"
 	InvalidClaimsErrorStr      = "INVALID_CLAIMS"
+	MaxSessionsReachedErrorStr = "MAX_SESSIONS_REACHED"
 )
 
 // TryRefreshTokenError used for when the refresh API needs to be called
@@ -66,3 +67,24 @@ type InvalidClaimError struct {
 func (err InvalidClaimError) Error() string {
 	return err.Msg
 }
+
+// MaxSessionsReachedError used for when a session cannot be created because the user
+// already has as many sessions as their session policy allows
+type MaxSessionsReachedError struct {
+	Msg    string
+	UserID string
+}
+
+func (err MaxSessionsReachedError) Error() string {
+	return err.Msg
+}
+
+// UnknownUserIDError used for when a session is created on behalf of a user that does not exist
+type UnknownUserIDError struct {
+	Msg    string
+	UserID string
+}
+
+func (err UnknownUserIDError) Error() string {
+	return err.Msg
+}
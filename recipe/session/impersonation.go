@@ -0,0 +1,116 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// ImpersonationBlockedMessage is the message in the reason of the claim validation error returned
+// by BlockImpersonation and BlockReadOnlyImpersonation.
+const ImpersonationBlockedMessage = api.ImpersonationBlockedMessage
+
+// GetImpersonationInfo returns who is impersonating the user of sessionContainer, or nil if it is
+// not an impersonation session.
+func GetImpersonationInfo(sessionContainer sessmodels.SessionContainer) *sessmodels.ImpersonationInfo {
+	return api.GetImpersonationInfoFromPayload(sessionContainer.GetAccessTokenPayload())
+}
+
+// BlockImpersonation returns a validator that fails for impersonation sessions. It is meant to be
+// added in VerifySessionOptions.OverrideGlobalClaimValidators of APIs that support staff should
+// never use on behalf of a user, like changing their password.
+func BlockImpersonation() claims.SessionClaimValidator {
+	return api.MakeImpersonationValidator(false)
+}
+
+// BlockReadOnlyImpersonation returns a validator that fails for read only impersonation sessions.
+// It is meant for APIs that change the data of the user.
+func BlockReadOnlyImpersonation() claims.SessionClaimValidator {
+	return api.MakeImpersonationValidator(true)
+}
+
+// CreateImpersonationSessionWithContext creates a session of targetUserID in which impersonatorID
+// acts as that user, and attaches its tokens to res. This replaces any session that the client of
+// res already has, so the impersonator is signed out of their own session on that client. Checking
+// that impersonatorID is allowed to do so is up to the caller. It fails if Impersonation is not
+// configured in the session recipe, and returns an errors.UnknownUserIDError if none of the login
+// recipes has targetUserID.
+func CreateImpersonationSessionWithContext(res http.ResponseWriter, impersonatorID string, targetUserID string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	if !instance.Config.Impersonation.Enabled {
+		return nil, defaultErrors.New("Impersonation is not enabled. Please set Impersonation in the session recipe config")
+	}
+	return api.CreateImpersonationSession(sessmodels.APIOptions{
+		RecipeImplementation:      instance.RecipeImpl,
+		Config:                    instance.Config,
+		RecipeID:                  instance.RecipeModule.GetRecipeID(),
+		Req:                       supertokens.GetRequestFromUserContext(userContext),
+		Res:                       res,
+		ClaimsAddedByOtherRecipes: instance.getClaimsAddedByOtherRecipes(),
+		UserExists:                instance.UserExists,
+	}, impersonatorID, targetUserID, userContext)
+}
+
+func CreateImpersonationSession(res http.ResponseWriter, impersonatorID string, targetUserID string) (sessmodels.SessionContainer, error) {
+	return CreateImpersonationSessionWithContext(res, impersonatorID, targetUserID, &map[string]interface{}{})
+}
+
+// StopImpersonationWithContext revokes an impersonation session and clears its tokens. It returns
+// false if sessionContainer is not an impersonation session.
+func StopImpersonationWithContext(sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) (bool, error) {
+	return api.StopImpersonation(sessionContainer, RECIPE_ID, userContext)
+}
+
+func StopImpersonation(sessionContainer sessmodels.SessionContainer) (bool, error) {
+	return StopImpersonationWithContext(sessionContainer, &map[string]interface{}{})
+}
+
+// enforceImpersonationExpiry revokes impersonation sessions after their fixed lifetime, since
+// refreshing would otherwise keep them alive.
+func enforceImpersonationExpiry(querier supertokens.Querier, sessionHandle string, userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+	info := api.GetImpersonationInfoFromPayload(accessTokenPayload)
+	if info == nil || uint64(time.Now().UnixNano()/1000000) < info.ExpiresAt {
+		return nil
+	}
+	revoked, err := revokeSessionHelper(querier, sessionHandle, userContext)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+			Type:          supertokens.SecurityEventImpersonationStopped,
+			RecipeID:      RECIPE_ID,
+			UserID:        userID,
+			SessionHandle: sessionHandle,
+			Details: map[string]interface{}{
+				"impersonatorId": info.ImpersonatorID,
+				"reason":         "expired",
+			},
+		}, userContext)
+	}
+	return errors.UnauthorizedError{Msg: "The impersonation session has expired"}
+}
@@ -0,0 +1,324 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func initForImpersonationTest(t *testing.T, impersonation *sessmodels.ImpersonationConfig, events *[]supertokens.SecurityEvent) *httptest.Server {
+	testServer := initForSecurityEventTest(t, &sessmodels.TypeInput{
+		Impersonation: impersonation,
+	}, events)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSessionWithContext(rw, "userId", map[string]interface{}{}, map[string]interface{}{}, supertokens.MakeDefaultUserContextFromAPI(r))
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/protected", VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {}))
+	mux.HandleFunc("/sensitive", VerifySession(&sessmodels.VerifySessionOptions{
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return append(globalClaimValidators, BlockImpersonation()), nil
+		},
+	}, func(rw http.ResponseWriter, r *http.Request) {}))
+	mux.HandleFunc("/write", VerifySession(&sessmodels.VerifySessionOptions{
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return append(globalClaimValidators, BlockReadOnlyImpersonation()), nil
+		},
+	}, func(rw http.ResponseWriter, r *http.Request) {}))
+	testServer.Config.Handler = supertokens.Middleware(mux)
+	return testServer
+}
+
+func allowImpersonationByUserID(impersonatorSession sessmodels.SessionContainer, targetUserID string, userContext supertokens.UserContext) (bool, error) {
+	return impersonatorSession.GetUserID() == "userId", nil
+}
+
+func impersonateInHeaderMode(t *testing.T, url string, accessToken string, targetUserID string) (string, map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodPost, url+"/auth/session/impersonate", strings.NewReader(`{"userId":"`+targetUserID+`"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("st-auth-mode", "header")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &result))
+	return res.Header.Get("st-access-token"), result
+}
+
+func TestImpersonationCreatesReadOnlySessionOfTargetUser(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: allowImpersonationByUserID,
+	}, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "OK", result["status"])
+	assert.NotEmpty(t, accessToken)
+
+	handles, err := GetAllSessionHandlesForUser("customer")
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+	sessionInformation, err := GetSessionInformation(handles[0])
+	assert.NoError(t, err)
+	info := getImpersonationInfoFromSessionInformation(t, sessionInformation)
+	assert.Equal(t, "userId", info.ImpersonatorID)
+	assert.True(t, info.ReadOnly)
+	expectedExpiry := uint64(time.Now().Add(15*time.Minute).UnixNano() / 1000000)
+	assert.InDelta(t, expectedExpiry, info.ExpiresAt, 60000)
+
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/sensitive", accessToken)
+	assert.Equal(t, 403, res.StatusCode)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/write", accessToken)
+	assert.Equal(t, 403, res.StatusCode)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/sensitive", adminAccessToken)
+	assert.Equal(t, 200, res.StatusCode)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, supertokens.SecurityEventImpersonationStarted, events[0].Type)
+	assert.Equal(t, "customer", events[0].UserID)
+	assert.Equal(t, handles[0], events[0].SessionHandle)
+	assert.Equal(t, "userId", events[0].Details["impersonatorId"])
+}
+
+func TestImpersonationWithWritesAllowed(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed:   allowImpersonationByUserID,
+		AllowWrites: true,
+	}, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "OK", result["status"])
+
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/write", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/sensitive", accessToken)
+	assert.Equal(t, 403, res.StatusCode)
+}
+
+func TestImpersonationIsRejectedIfNotAllowed(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: func(impersonatorSession sessmodels.SessionContainer, targetUserID string, userContext supertokens.UserContext) (bool, error) {
+			return targetUserID != "otherAdmin", nil
+		},
+	}, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	_, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "otherAdmin")
+	assert.Equal(t, "IMPERSONATION_NOT_ALLOWED_ERROR", result["status"])
+
+	// an impersonation session cannot be used to impersonate someone else
+	accessToken, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "OK", result["status"])
+	_, result = impersonateInHeaderMode(t, testServer.URL, accessToken, "anotherCustomer")
+	assert.Equal(t, "IMPERSONATION_NOT_ALLOWED_ERROR", result["status"])
+
+	handles, err := GetAllSessionHandlesForUser("otherAdmin")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+	handles, err = GetAllSessionHandlesForUser("anotherCustomer")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+	assert.Len(t, events, 1)
+}
+
+func TestImpersonationIsRejectedWithoutIsAllowed(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, nil, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	_, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "IMPERSONATION_NOT_ALLOWED_ERROR", result["status"])
+
+	_, err := CreateImpersonationSession(httptest.NewRecorder(), "support", "customer")
+	assert.Error(t, err)
+}
+
+func TestImpersonationOfUnknownUserIsRejected(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: allowImpersonationByUserID,
+	}, &events)
+	defer testServer.Close()
+	recipe, err := getRecipeInstanceOrThrowError()
+	assert.NoError(t, err)
+	recipe.AddUserExistsFunc(func(userID string, userContext supertokens.UserContext) (bool, error) {
+		return userID == "customer", nil
+	})
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "unknownUser")
+	assert.Equal(t, "UNKNOWN_USER_ID_ERROR", result["status"])
+	assert.Empty(t, accessToken)
+
+	_, err = CreateImpersonationSession(httptest.NewRecorder(), "support", "unknownUser")
+	assert.ErrorAs(t, err, &errors.UnknownUserIDError{})
+
+	handles, err := GetAllSessionHandlesForUser("unknownUser")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+	assert.Empty(t, events)
+
+	_, result = impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "OK", result["status"])
+}
+
+func TestReadOnlyImpersonationCannotRevokeSessions(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: allowImpersonationByUserID,
+	}, &events)
+	defer testServer.Close()
+
+	customerSession, err := CreateNewSession(httptest.NewRecorder(), "customer", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, result := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	assert.Equal(t, "OK", result["status"])
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/session/revoke", strings.NewReader(`{"sessionHandle":"`+customerSession.GetHandle()+`"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 403, res.StatusCode)
+
+	sessionInformation, err := GetSessionInformation(customerSession.GetHandle())
+	assert.NoError(t, err)
+	assert.NotNil(t, sessionInformation)
+}
+
+func TestStopImpersonation(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: allowImpersonationByUserID,
+	}, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, _ := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/impersonate/stop", adminAccessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":"NOT_IMPERSONATING_ERROR"}`, string(body))
+
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/impersonate/stop", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err = ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":"OK"}`, string(body))
+
+	handles, err := GetAllSessionHandlesForUser("customer")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, supertokens.SecurityEventImpersonationStopped, events[1].Type)
+	assert.Equal(t, "customer", events[1].UserID)
+	assert.Equal(t, "stopped", events[1].Details["reason"])
+}
+
+func TestImpersonationSessionExpires(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForImpersonationTest(t, &sessmodels.ImpersonationConfig{
+		IsAllowed: allowImpersonationByUserID,
+		Lifetime:  time.Second,
+	}, &events)
+	defer testServer.Close()
+
+	adminAccessToken, _ := createSessionInHeaderMode(t, testServer.URL)
+	accessToken, _ := impersonateInHeaderMode(t, testServer.URL, adminAccessToken, "customer")
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+
+	time.Sleep(1500 * time.Millisecond)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 401, res.StatusCode)
+
+	handles, err := GetAllSessionHandlesForUser("customer")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", adminAccessToken)
+	assert.Equal(t, 200, res.StatusCode)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, supertokens.SecurityEventImpersonationStopped, events[1].Type)
+	assert.Equal(t, "expired", events[1].Details["reason"])
+}
+
+func getImpersonationInfoFromSessionInformation(t *testing.T, sessionInformation *sessmodels.SessionInformation) *sessmodels.ImpersonationInfo {
+	if !assert.NotNil(t, sessionInformation) {
+		t.FailNow()
+	}
+	info := api.GetImpersonationInfoFromPayload(sessionInformation.AccessTokenPayload)
+	if !assert.NotNil(t, info) {
+		t.FailNow()
+	}
+	return info
+}
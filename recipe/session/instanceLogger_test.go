@@ -0,0 +1,109 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+type messageRecordingLogger struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (l *messageRecordingLogger) record(msg string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *messageRecordingLogger) Debug(msg string, keysAndValues ...interface{}) { l.record(msg) }
+func (l *messageRecordingLogger) Info(msg string, keysAndValues ...interface{})  { l.record(msg) }
+func (l *messageRecordingLogger) Warn(msg string, keysAndValues ...interface{})  { l.record(msg) }
+func (l *messageRecordingLogger) Error(msg string, keysAndValues ...interface{}) { l.record(msg) }
+
+func (l *messageRecordingLogger) contains(msg string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, m := range l.messages {
+		if m == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func newInstanceWithLogger(t *testing.T, logger supertokens.Logger) *httptest.Server {
+	instance, err := supertokens.New(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+		},
+		Logger: logger,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSessionWithContext(rw, "userId", map[string]interface{}{}, map[string]interface{}{}, supertokens.MakeDefaultUserContextFromAPI(r))
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/protected", VerifySessionHelper(*GetRecipeInstanceFor(instance), nil, func(rw http.ResponseWriter, r *http.Request) {}))
+	return httptest.NewServer(instance.Middleware(mux))
+}
+
+func TestRecipesLogToTheLoggerOfTheirInstance(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	first := &messageRecordingLogger{}
+	second := &messageRecordingLogger{}
+	firstServer := newInstanceWithLogger(t, first)
+	defer firstServer.Close()
+	secondServer := newInstanceWithLogger(t, second)
+	defer secondServer.Close()
+
+	assert.True(t, first.contains("session init: AntiCsrf: NONE"))
+	assert.True(t, second.contains("session init: AntiCsrf: NONE"))
+
+	res := sendWithBearerToken(t, http.MethodPost, firstServer.URL+"/auth/session/refresh", "")
+	assert.Equal(t, 401, res.StatusCode)
+	assert.True(t, first.contains("errorHandler: returning UNAUTHORISED"))
+	assert.False(t, second.contains("errorHandler: returning UNAUTHORISED"))
+
+	accessToken, _ := createSessionInHeaderMode(t, secondServer.URL)
+	res = sendWithBearerToken(t, http.MethodGet, secondServer.URL+"/protected", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.True(t, second.contains("getSession: Success!"))
+	assert.False(t, first.contains("getSession: Success!"))
+}
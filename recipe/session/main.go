@@ -32,7 +32,7 @@ func Init(config *sessmodels.TypeInput) supertokens.Recipe {
 }
 
 func CreateNewSessionWithContext(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,17 @@ func CreateNewSessionWithContext(res http.ResponseWriter, userID string, accessT
 }
 
 func GetSessionWithContext(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	_, span := supertokens.GetInstrumentationFromContext(req.Context()).StartSpan(req.Context(), supertokens.SpanVerifySession, map[string]interface{}{
+		"http.method": req.Method,
+		"http.target": req.URL.Path,
+	})
+	sessionContainer, err := getSessionWithContext(req, res, options, userContext)
+	endVerifySessionSpan(span, sessionContainer, err)
+	return sessionContainer, err
+}
+
+func getSessionWithContext(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +91,7 @@ func GetSessionWithContext(req *http.Request, res http.ResponseWriter, options *
 }
 
 func GetSessionInformationWithContext(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +99,7 @@ func GetSessionInformationWithContext(sessionHandle string, userContext supertok
 }
 
 func RefreshSessionWithContext(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +107,7 @@ func RefreshSessionWithContext(req *http.Request, res http.ResponseWriter, userC
 }
 
 func RevokeAllSessionsForUserWithContext(userID string, userContext supertokens.UserContext) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -105,15 +115,23 @@ func RevokeAllSessionsForUserWithContext(userID string, userContext supertokens.
 }
 
 func GetAllSessionHandlesForUserWithContext(userID string, userContext supertokens.UserContext) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
 	return (*instance.RecipeImpl.GetAllSessionHandlesForUser)(userID, userContext)
 }
 
+func GetSessionsForUserWithContext(userID string, userContext supertokens.UserContext) ([]sessmodels.SessionInformation, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.GetSessionsForUser)(userID, userContext)
+}
+
 func RevokeSessionWithContext(sessionHandle string, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -121,7 +139,7 @@ func RevokeSessionWithContext(sessionHandle string, userContext supertokens.User
 }
 
 func RevokeMultipleSessionsWithContext(sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +147,7 @@ func RevokeMultipleSessionsWithContext(sessionHandles []string, userContext supe
 }
 
 func UpdateSessionDataWithContext(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -138,7 +156,7 @@ func UpdateSessionDataWithContext(sessionHandle string, newSessionData map[strin
 
 // Deprecated: use MergeIntoAccessTokenPayloadWithContext instead
 func UpdateAccessTokenPayloadWithContext(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -146,7 +164,7 @@ func UpdateAccessTokenPayloadWithContext(sessionHandle string, newAccessTokenPay
 }
 
 func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer *uint64, userContext supertokens.UserContext) (jwtmodels.CreateJWTResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.CreateJWTResponse{}, err
 	}
@@ -157,7 +175,7 @@ func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer
 }
 
 func GetJWKSWithContext(userContext supertokens.UserContext) (jwtmodels.GetJWKSResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return jwtmodels.GetJWKSResponse{}, err
 	}
@@ -168,7 +186,7 @@ func GetJWKSWithContext(userContext supertokens.UserContext) (jwtmodels.GetJWKSR
 }
 
 func GetOpenIdDiscoveryConfigurationWithContext(userContext supertokens.UserContext) (openidmodels.GetOpenIdDiscoveryConfigurationResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return openidmodels.GetOpenIdDiscoveryConfigurationResponse{}, err
 	}
@@ -179,7 +197,7 @@ func GetOpenIdDiscoveryConfigurationWithContext(userContext supertokens.UserCont
 }
 
 func RegenerateAccessTokenWithContext(accessToken string, newAccessTokenPayload *map[string]interface{}, sessionHandle string, userContext supertokens.UserContext) (*sessmodels.RegenerateAccessTokenResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +210,7 @@ func ValidateClaimsForSessionHandleWithContext(
 	userContext supertokens.UserContext,
 ) (sessmodels.ValidateClaimsResponse, error) {
 
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return sessmodels.ValidateClaimsResponse{}, err
 	}
@@ -250,7 +268,7 @@ func ValidateClaimsInJWTPayloadWithContext(
 	userContext supertokens.UserContext,
 ) ([]claims.ClaimValidationError, error) {
 
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -274,7 +292,7 @@ func ValidateClaimsInJWTPayloadWithContext(
 }
 
 func MergeIntoAccessTokenPayloadWithContext(sessionHandle string, accessTokenPayloadUpdate map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -283,7 +301,7 @@ func MergeIntoAccessTokenPayloadWithContext(sessionHandle string, accessTokenPay
 }
 
 func FetchAndSetClaimWithContext(sessionHandle string, claim *claims.TypeSessionClaim, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -292,7 +310,7 @@ func FetchAndSetClaimWithContext(sessionHandle string, claim *claims.TypeSession
 }
 
 func SetClaimValueWithContext(sessionHandle string, claim *claims.TypeSessionClaim, value interface{}, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -301,7 +319,7 @@ func SetClaimValueWithContext(sessionHandle string, claim *claims.TypeSessionCla
 }
 
 func GetClaimValueWithContext(sessionHandle string, claim *claims.TypeSessionClaim, userContext supertokens.UserContext) (sessmodels.GetClaimValueResult, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return sessmodels.GetClaimValueResult{}, err
 	}
@@ -310,7 +328,7 @@ func GetClaimValueWithContext(sessionHandle string, claim *claims.TypeSessionCla
 }
 
 func RemoveClaimWithContext(sessionHandle string, claim *claims.TypeSessionClaim, userContext supertokens.UserContext) (bool, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return false, err
 	}
@@ -359,6 +377,10 @@ func GetAllSessionHandlesForUser(userID string) ([]string, error) {
 	return GetAllSessionHandlesForUserWithContext(userID, &map[string]interface{}{})
 }
 
+func GetSessionsForUser(userID string) ([]sessmodels.SessionInformation, error) {
+	return GetSessionsForUserWithContext(userID, &map[string]interface{}{})
+}
+
 func RevokeSession(sessionHandle string) (bool, error) {
 	return RevokeSessionWithContext(sessionHandle, &map[string]interface{}{})
 }
@@ -26,15 +26,13 @@ import (
 func VerifySessionHelper(recipeInstance Recipe, options *sessmodels.VerifySessionOptions, otherHandler http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		dw := supertokens.MakeDoneWriter(w)
-		userContext := supertokens.MakeDefaultUserContextFromAPI(r)
-		session, err := (*recipeInstance.APIImpl.VerifySession)(options, sessmodels.APIOptions{
-			Config:               recipeInstance.Config,
-			OtherHandler:         otherHandler,
-			Req:                  r,
-			Res:                  dw,
-			RecipeID:             recipeInstance.RecipeModule.GetRecipeID(),
-			RecipeImplementation: recipeInstance.RecipeImpl,
-		}, userContext)
+		spanCtx, span := supertokens.GetInstrumentationFromContext(r.Context()).StartSpan(r.Context(), supertokens.SpanVerifySession, map[string]interface{}{
+			"http.method": r.Method,
+			"http.target": r.URL.Path,
+		})
+		userContext := supertokens.MakeDefaultUserContextFromAPI(r.WithContext(spanCtx))
+		session, err := verifySessionWithRecipe(recipeInstance, options, otherHandler, r, dw, userContext)
+		endVerifySessionSpan(span, session, err)
 		if err != nil {
 			err = supertokens.ErrorHandler(err, r, dw)
 			if err != nil {
@@ -50,3 +48,27 @@ func VerifySessionHelper(recipeInstance Recipe, options *sessmodels.VerifySessio
 		}
 	})
 }
+
+func verifySessionWithRecipe(recipeInstance Recipe, options *sessmodels.VerifySessionOptions, otherHandler http.HandlerFunc, req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	return (*recipeInstance.APIImpl.VerifySession)(options, sessmodels.APIOptions{
+		Config:                             recipeInstance.Config,
+		OtherHandler:                       otherHandler,
+		Req:                                req,
+		Res:                                res,
+		RecipeID:                           recipeInstance.RecipeModule.GetRecipeID(),
+		RecipeImplementation:               recipeInstance.RecipeImpl,
+		ClaimsAddedByOtherRecipes:          recipeInstance.getClaimsAddedByOtherRecipes(),
+		ClaimValidatorsAddedByOtherRecipes: recipeInstance.getClaimValidatorsAddedByOtherRecipes(),
+	}, userContext)
+}
+
+func endVerifySessionSpan(span supertokens.Span, session sessmodels.SessionContainer, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else if session != nil {
+		span.SetAttributes(map[string]interface{}{
+			"supertokens.session_handle": session.GetHandle(),
+		})
+	}
+	span.End()
+}
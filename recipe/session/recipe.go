@@ -39,13 +39,18 @@ type Recipe struct {
 
 	claimsAddedByOtherRecipes          []*claims.TypeSessionClaim
 	claimValidatorsAddedByOtherRecipes []claims.SessionClaimValidator
+
+	// UserExists returns true if one of the login recipes has the user, or if no login recipe
+	// has added a function to look them up.
+	UserExists        sessmodels.TypeUserExists
+	AddUserExistsFunc func(function sessmodels.TypeUserExists)
 }
 
 const RECIPE_ID = "session"
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *sessmodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+	userExistsFuncsFromOtherRecipes := []sessmodels.TypeUserExists{}
+
 	r := &Recipe{
 		claimsAddedByOtherRecipes:          []*claims.TypeSessionClaim{},
 		claimValidatorsAddedByOtherRecipes: []claims.SessionClaimValidator{},
@@ -92,12 +97,32 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 	}
 
+	r.UserExists = func(userID string, userContext supertokens.UserContext) (bool, error) {
+		if len(userExistsFuncsFromOtherRecipes) == 0 {
+			return true, nil
+		}
+		for _, userExistsFunc := range userExistsFuncsFromOtherRecipes {
+			exists, err := userExistsFunc(userID, userContext)
+			if err != nil {
+				return false, err
+			}
+			if exists {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	r.AddUserExistsFunc = func(function sessmodels.TypeUserExists) {
+		userExistsFuncsFromOtherRecipes = append(userExistsFuncsFromOtherRecipes, function)
+	}
+
 	return *r, nil
 }
 
 func getRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
 	}
 	return nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
 }
@@ -106,15 +131,38 @@ func GetRecipeInstanceOrThrowError() (*Recipe, error) {
 	return getRecipeInstanceOrThrowError()
 }
 
+// GetRecipeInstanceFor returns the session recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
 func recipeInit(config *sessmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, defaultErrors.New("Session recipe has already been initialised. Please check your code for bugs.")
 	}
@@ -131,6 +179,22 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	sessionsAPIPathNormalised, err := supertokens.NewNormalisedURLPath(sessionsAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	revokeSessionAPIPathNormalised, err := supertokens.NewNormalisedURLPath(revokeSessionAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	impersonateAPIPathNormalised, err := supertokens.NewNormalisedURLPath(impersonateAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	stopImpersonationAPIPathNormalised, err := supertokens.NewNormalisedURLPath(stopImpersonationAPIPath)
+	if err != nil {
+		return nil, err
+	}
 	resp := []supertokens.APIHandled{{
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: refreshAPIPathNormalised,
@@ -141,6 +205,26 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: signoutAPIPathNormalised,
 		ID:                     signoutAPIPath,
 		Disabled:               r.APIImpl.SignOutPOST == nil,
+	}, {
+		Method:                 http.MethodGet,
+		PathWithoutAPIBasePath: sessionsAPIPathNormalised,
+		ID:                     sessionsAPIPath,
+		Disabled:               r.APIImpl.SessionsGET == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: revokeSessionAPIPathNormalised,
+		ID:                     revokeSessionAPIPath,
+		Disabled:               r.APIImpl.RevokeSessionPOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: impersonateAPIPathNormalised,
+		ID:                     impersonateAPIPath,
+		Disabled:               r.APIImpl.ImpersonatePOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: stopImpersonationAPIPathNormalised,
+		ID:                     stopImpersonationAPIPath,
+		Disabled:               r.APIImpl.StopImpersonationPOST == nil,
 	}}
 
 	if r.OpenIdRecipe != nil {
@@ -163,12 +247,22 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		Res:                  res,
 		OtherHandler:         theirhandler,
 
+		ClaimsAddedByOtherRecipes:          r.getClaimsAddedByOtherRecipes(),
 		ClaimValidatorsAddedByOtherRecipes: r.getClaimValidatorsAddedByOtherRecipes(),
+		UserExists:                         r.UserExists,
 	}
 	if id == refreshAPIPath {
 		return api.HandleRefreshAPI(r.APIImpl, options)
 	} else if id == signoutAPIPath {
 		return api.SignOutAPI(r.APIImpl, options)
+	} else if id == sessionsAPIPath {
+		return api.SessionsAPI(r.APIImpl, options)
+	} else if id == revokeSessionAPIPath {
+		return api.RevokeSessionAPI(r.APIImpl, options)
+	} else if id == impersonateAPIPath {
+		return api.ImpersonateAPI(r.APIImpl, options)
+	} else if id == stopImpersonationAPIPath {
+		return api.StopImpersonationAPI(r.APIImpl, options)
 	} else if r.OpenIdRecipe != nil {
 		return r.OpenIdRecipe.RecipeModule.HandleAPIRequest(id, req, res, theirhandler, path, method)
 	}
@@ -185,25 +279,29 @@ func (r *Recipe) getAllCORSHeaders() []string {
 
 func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
 	if defaultErrors.As(err, &errors.UnauthorizedError{}) {
-		supertokens.LogDebugMessage("errorHandler: returning UNAUTHORISED")
+		supertokens.GetLoggerFromContext(req.Context()).Debug("errorHandler: returning UNAUTHORISED", "recipeId", RECIPE_ID, "path", req.URL.Path)
 		unauthErr := err.(errors.UnauthorizedError)
 		if unauthErr.ClearCookies == nil || *unauthErr.ClearCookies {
 			supertokens.LogDebugMessage("errorHandler: Clearing cookies because of UNAUTHORISED response")
-			clearSessionFromCookie(r.Config, res)
+			clearSession(r.Config, res, getTransferMethodUsedByRequest(r.Config, req, supertokens.MakeDefaultUserContextFromAPI(req)))
 		}
 		return true, r.Config.ErrorHandlers.OnUnauthorised(err.Error(), req, res)
 	} else if defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
-		supertokens.LogDebugMessage("errorHandler: returning TRY_REFRESH_TOKEN")
+		supertokens.GetLoggerFromContext(req.Context()).Debug("errorHandler: returning TRY_REFRESH_TOKEN", "recipeId", RECIPE_ID, "path", req.URL.Path)
 		return true, r.Config.ErrorHandlers.OnTryRefreshToken(err.Error(), req, res)
 	} else if defaultErrors.As(err, &errors.TokenTheftDetectedError{}) {
-		supertokens.LogDebugMessage("errorHandler: clearing cookies because of TOKEN_THEFT_DETECTED response")
-		clearSessionFromCookie(r.Config, res)
+		supertokens.GetLoggerFromContext(req.Context()).Debug("errorHandler: clearing cookies because of TOKEN_THEFT_DETECTED response", "recipeId", RECIPE_ID, "path", req.URL.Path)
+		clearSession(r.Config, res, getTransferMethodUsedByRequest(r.Config, req, supertokens.MakeDefaultUserContextFromAPI(req)))
 		errs := err.(errors.TokenTheftDetectedError)
 		return true, r.Config.ErrorHandlers.OnTokenTheftDetected(errs.Payload.SessionHandle, errs.Payload.UserID, req, res)
 	} else if defaultErrors.As(err, &errors.InvalidClaimError{}) {
-		supertokens.LogDebugMessage("errorHandler: returning INVALID_CLAIMS")
+		supertokens.GetLoggerFromContext(req.Context()).Debug("errorHandler: returning INVALID_CLAIMS", "recipeId", RECIPE_ID, "path", req.URL.Path)
 		errs := err.(errors.InvalidClaimError)
 		return true, r.Config.ErrorHandlers.OnInvalidClaim(errs.InvalidClaims, req, res)
+	} else if defaultErrors.As(err, &errors.MaxSessionsReachedError{}) {
+		supertokens.GetLoggerFromContext(req.Context()).Debug("errorHandler: returning MAX_SESSIONS_REACHED", "recipeId", RECIPE_ID, "path", req.URL.Path)
+		errs := err.(errors.MaxSessionsReachedError)
+		return true, r.Config.ErrorHandlers.OnMaxSessionsReached(errs.UserID, req, res)
 	} else if r.OpenIdRecipe != nil {
 		return r.OpenIdRecipe.RecipeModule.HandleError(err, req, res)
 	}
@@ -235,5 +333,4 @@ func (r *Recipe) getClaimValidatorsAddedByOtherRecipes() []claims.SessionClaimVa
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 }
@@ -37,15 +37,41 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	var result sessmodels.RecipeInterface
 
 	var recipeImplHandshakeInfo *sessmodels.HandshakeInfo = nil
-	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, &map[string]interface{}{})
 
 	createNewSession := func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-		response, err := createNewSessionHelper(recipeImplHandshakeInfo, config, querier, userID, accessTokenPayload, sessionData)
+		transferMethod := config.GetTokenTransferMethod(supertokens.GetRequestFromUserContext(userContext), true, userContext)
+		if transferMethod == sessmodels.AnyTransferMethod {
+			transferMethod = sessmodels.CookieTransferMethod
+		}
+
+		// impersonation sessions must not sign the user out of their own sessions
+		if _, ok := accessTokenPayload[sessmodels.ImpersonationKey]; !ok {
+			err := enforceConcurrentSessionLimit(recipeImplHandshakeInfo, config, querier, userID, userContext)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		req := supertokens.GetRequestFromUserContext(userContext)
+		if config.SessionMetadata.Enabled && req != nil {
+			metadata, err := makeSessionMetadata(config, req, userContext)
+			if err != nil {
+				supertokens.LogDebugMessage("createNewSession: Not saving session metadata because of error: " + err.Error())
+			} else {
+				sessionData, err = addSessionMetadataToSessionData(sessionData, metadata)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		response, err := createNewSessionHelper(recipeImplHandshakeInfo, config, querier, userID, accessTokenPayload, sessionData, transferMethod == sessmodels.HeaderTransferMethod, userContext)
 		if err != nil {
 			return nil, err
 		}
-		attachCreateOrRefreshSessionResponseToRes(config, res, response)
-		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		attachCreateOrRefreshSessionResponseToRes(config, res, response, transferMethod)
+		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result, transferMethod)
 		return newSessionContainer(config, &sessionContainerInput), nil
 	}
 
@@ -59,32 +85,75 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			doAntiCsrfCheck = options.AntiCsrfCheck
 		}
 
-		idRefreshToken := getIDRefreshTokenFromCookie(req)
-		if idRefreshToken == nil {
+		allowedTransferMethod := config.GetTokenTransferMethod(req, false, userContext)
+		transferMethod := sessmodels.CookieTransferMethod
+		var accessToken *string = nil
+		if allowedTransferMethod != sessmodels.CookieTransferMethod {
+			headerToken := getTokenFromAuthorizationHeader(req)
+			if headerToken != nil {
+				useHeader := isHeaderTransferRequested(allowedTransferMethod, req) || !hasSessionCookies(req)
+				if !useHeader {
+					// the header may be used by something other than SuperTokens, so it only takes
+					// precedence over the cookies if it holds an access token signed by the core
+					isSignedByCore, err := isAccessTokenSignedByCore(recipeImplHandshakeInfo, config, querier, *headerToken, userContext)
+					if err != nil {
+						return nil, err
+					}
+					useHeader = isSignedByCore
+				}
+				if useHeader {
+					accessToken = headerToken
+					transferMethod = sessmodels.HeaderTransferMethod
+				} else {
+					supertokens.LogDebugMessage("getSession: ignoring the Authorization header because it does not contain an access token")
+				}
+			}
+		}
+
+		if accessToken == nil && allowedTransferMethod == sessmodels.HeaderTransferMethod {
 			if options != nil && options.SessionRequired != nil &&
 				!(*options.SessionRequired) {
-				supertokens.LogDebugMessage("getSession: returning nil because idRefreshToken is nil and sessionRequired is false")
+				supertokens.LogDebugMessage("getSession: returning nil because the Authorization header is missing and sessionRequired is false")
 				return nil, nil
 			}
-			supertokens.LogDebugMessage("getSession: UNAUTHORISED because idRefreshToken from cookies is nil")
+			supertokens.LogDebugMessage("getSession: UNAUTHORISED because the Authorization header is missing")
 
 			clearCookies := false
-			return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?", ClearCookies: &clearCookies}
+			return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the access token in the Authorization header?", ClearCookies: &clearCookies}
 		}
 
-		accessToken := getAccessTokenFromCookie(req)
 		if accessToken == nil {
-			if options == nil || (options.SessionRequired != nil && *options.SessionRequired) || frontendHasInterceptor(req) || req.Method == http.MethodGet {
-				supertokens.LogDebugMessage("getSession: Returning try refresh token because access token from cookies is nil")
-				return nil, errors.TryRefreshTokenError{
-					Msg: "Access token has expired. Please call the refresh API",
+			idRefreshToken := getIDRefreshTokenFromCookie(req)
+			if idRefreshToken == nil {
+				if options != nil && options.SessionRequired != nil &&
+					!(*options.SessionRequired) {
+					supertokens.LogDebugMessage("getSession: returning nil because idRefreshToken is nil and sessionRequired is false")
+					return nil, nil
+				}
+				supertokens.LogDebugMessage("getSession: UNAUTHORISED because idRefreshToken from cookies is nil")
+
+				clearCookies := false
+				return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?", ClearCookies: &clearCookies}
+			}
+
+			accessToken = getAccessTokenFromCookie(req)
+			if accessToken == nil {
+				if options == nil || (options.SessionRequired != nil && *options.SessionRequired) || frontendHasInterceptor(req) || req.Method == http.MethodGet {
+					supertokens.LogDebugMessage("getSession: Returning try refresh token because access token from cookies is nil")
+					return nil, errors.TryRefreshTokenError{
+						Msg: "Access token has expired. Please call the refresh API",
+					}
 				}
+				return nil, nil
 			}
-			return nil, nil
 		}
 
 		antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
-		if doAntiCsrfCheck == nil {
+		if transferMethod == sessmodels.HeaderTransferMethod {
+			// tokens in headers are not sent by the browser on its own, so there is nothing to protect against
+			doAntiCsrfCheckBool := false
+			doAntiCsrfCheck = &doAntiCsrfCheckBool
+		} else if doAntiCsrfCheck == nil {
 			doAntiCsrfCheckBool := req.Method != http.MethodGet
 			doAntiCsrfCheck = &doAntiCsrfCheckBool
 		}
@@ -95,80 +164,174 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			supertokens.LogDebugMessage("getSession: Value of doAntiCsrfCheck is: nil")
 		}
 
-		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
+		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil, userContext)
+		if err != nil {
+			return nil, err
+		}
+		err = enforceImpersonationExpiry(querier, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, userContext)
 		if err != nil {
 			return nil, err
 		}
 
 		if !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 			setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
-			attachAccessTokenToCookie(config, res, response.AccessToken.Token, response.AccessToken.Expiry)
+			attachAccessToken(config, res, response.AccessToken.Token, response.AccessToken.Expiry, transferMethod)
 			accessToken = &response.AccessToken.Token
 		}
-		sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result, transferMethod)
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
 
-		supertokens.LogDebugMessage("getSession: Success!")
+		querier.GetLogger().Debug("getSession: Success!", "recipeId", RECIPE_ID, "sessionHandle", response.Session.Handle, "path", req.URL.Path)
 		return sessionContainer, nil
 	}
 
 	getSessionInformation := func(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
-		return getSessionInformationHelper(querier, sessionHandle)
+		return getSessionInformationHelper(querier, sessionHandle, userContext)
 	}
 
 	refreshSession := func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
 		supertokens.LogDebugMessage("refreshSession: Started")
-		inputIdRefreshToken := getIDRefreshTokenFromCookie(req)
-		if inputIdRefreshToken == nil {
-			supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because idRefreshToken from cookies is nil")
-			return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+		allowedTransferMethod := config.GetTokenTransferMethod(req, false, userContext)
+		transferMethod := sessmodels.CookieTransferMethod
+		var inputRefreshToken *string = nil
+		if allowedTransferMethod != sessmodels.CookieTransferMethod {
+			headerToken := getTokenFromAuthorizationHeader(req)
+			// refresh tokens can not be verified here, so the header is only used instead of the
+			// cookies if the frontend asked for it
+			if headerToken != nil && (isHeaderTransferRequested(allowedTransferMethod, req) || !hasSessionCookies(req)) {
+				inputRefreshToken = headerToken
+				transferMethod = sessmodels.HeaderTransferMethod
+			} else if headerToken != nil {
+				supertokens.LogDebugMessage("refreshSession: ignoring the Authorization header because the request has session cookies")
+			}
+		}
+
+		if inputRefreshToken == nil && allowedTransferMethod == sessmodels.HeaderTransferMethod {
+			supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because the Authorization header is missing")
+			clearCookies := false
+			return nil, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the Authorization header?", ClearCookies: &clearCookies}
 		}
 
-		inputRefreshToken := getRefreshTokenFromCookie(req)
 		if inputRefreshToken == nil {
-			supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because refresh token from cookies is undefined")
-			return nil, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the request as a cookie?"}
+			inputIdRefreshToken := getIDRefreshTokenFromCookie(req)
+			if inputIdRefreshToken == nil {
+				supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because idRefreshToken from cookies is nil")
+				return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+			}
+
+			inputRefreshToken = getRefreshTokenFromCookie(req)
+			if inputRefreshToken == nil {
+				supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because refresh token from cookies is undefined")
+				return nil, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the request as a cookie?"}
+			}
 		}
 
 		antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
-		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, getRidFromHeader(req) != nil)
+		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, getRidFromHeader(req) != nil, transferMethod == sessmodels.HeaderTransferMethod, userContext)
+		if err != nil {
+			return nil, err
+		}
+		err = enforceSessionLifetime(recipeImplHandshakeInfo, config, querier, &response, userContext)
 		if err != nil {
 			return nil, err
 		}
-		attachCreateOrRefreshSessionResponseToRes(config, res, response)
-		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		err = enforceImpersonationExpiry(querier, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, userContext)
+		if err != nil {
+			return nil, err
+		}
+		attachCreateOrRefreshSessionResponseToRes(config, res, response, transferMethod)
+		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result, transferMethod)
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
 
-		supertokens.LogDebugMessage("refreshSession: Success!")
+		querier.GetInstrumentation().AddToCounter(supertokens.GetContextFromUserContext(userContext), supertokens.MetricSessionRefreshes, 1, map[string]interface{}{
+			"supertokens.recipe_id": RECIPE_ID,
+		})
+		querier.GetLogger().Info("refreshSession: Success!", "recipeId", RECIPE_ID, "sessionHandle", response.Session.Handle, "path", req.URL.Path)
 		return sessionContainer, nil
 	}
 
 	revokeAllSessionsForUser := func(userID string, userContext supertokens.UserContext) ([]string, error) {
-		return revokeAllSessionsForUserHelper(querier, userID)
+		return revokeAllSessionsForUserHelper(querier, userID, userContext)
 	}
 
 	getAllSessionHandlesForUser := func(userID string, userContext supertokens.UserContext) ([]string, error) {
-		return getAllSessionHandlesForUserHelper(querier, userID)
+		return getAllSessionHandlesForUserHelper(querier, userID, userContext)
 	}
 
 	revokeSession := func(sessionHandle string, userContext supertokens.UserContext) (bool, error) {
-		return revokeSessionHelper(querier, sessionHandle)
+		return revokeSessionHelper(querier, sessionHandle, userContext)
 	}
 
 	revokeMultipleSessions := func(sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
-		return revokeMultipleSessionsHelper(querier, sessionHandles)
+		return revokeMultipleSessionsHelper(querier, sessionHandles, userContext)
+	}
+
+	getSessionsForUser := func(userID string, userContext supertokens.UserContext) ([]sessmodels.SessionInformation, error) {
+		sessionHandles, err := (*result.GetAllSessionHandlesForUser)(userID, userContext)
+		if err != nil {
+			return nil, err
+		}
+		// the core has no API to fetch many sessions at once, so they are fetched concurrently. Each
+		// request writes to its own index, which keeps the sessions in the order of their handles.
+		sessionInformations := make([]*sessmodels.SessionInformation, len(sessionHandles))
+		errs := make([]error, len(sessionHandles))
+		semaphore := make(chan struct{}, maxConcurrentSessionInformationRequests)
+		var wg sync.WaitGroup
+		for i, sessionHandle := range sessionHandles {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			// overrides may write to the user context, so every request gets its own copy of it
+			requestUserContext := copyUserContext(userContext)
+			go func(i int, sessionHandle string) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				sessionInformations[i], errs[i] = (*result.GetSessionInformation)(sessionHandle, requestUserContext)
+			}(i, sessionHandle)
+		}
+		wg.Wait()
+
+		sessions := []sessmodels.SessionInformation{}
+		for i, sessionInformation := range sessionInformations {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			// the session may have been revoked since the handles were fetched
+			if sessionInformation != nil {
+				sessions = append(sessions, *sessionInformation)
+			}
+		}
+		return sessions, nil
 	}
 
 	updateSessionData := func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-		return updateSessionDataHelper(querier, sessionHandle, newSessionData)
+		_, hasMetadata := newSessionData[sessmodels.SessionMetadataKey]
+		if !hasMetadata && config.SessionMetadata.Enabled {
+			// the metadata is stored in the session data, so it has to be carried over
+			sessionInformation, err := getSessionInformationHelper(querier, sessionHandle, userContext)
+			if err != nil {
+				return false, err
+			}
+			if sessionInformation == nil {
+				return false, nil
+			}
+			if sessionInformation.Metadata != nil {
+				newSessionData, err = addSessionMetadataToSessionData(newSessionData, sessionInformation.Metadata)
+				if err != nil {
+					return false, err
+				}
+			}
+		}
+		return updateSessionDataHelper(querier, sessionHandle, newSessionData, userContext)
 	}
 
 	updateAccessTokenPayload := func(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-		return updateAccessTokenPayloadHelper(querier, sessionHandle, newAccessTokenPayload)
+		return updateAccessTokenPayloadHelper(querier, sessionHandle, newAccessTokenPayload, userContext)
 	}
 
 	getAccessTokenLifeTimeMS := func(userContext supertokens.UserContext) (uint64, error) {
-		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 		if err != nil {
 			return 0, err
 		}
@@ -176,7 +339,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	}
 
 	getRefreshTokenLifeTimeMS := func(userContext supertokens.UserContext) (uint64, error) {
-		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 		if err != nil {
 			return 0, err
 		}
@@ -184,7 +347,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	}
 
 	regenerateAccessToken := func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (*sessmodels.RegenerateAccessTokenResponse, error) {
-		return regenerateAccessTokenHelper(querier, newAccessTokenPayload, accessToken)
+		return regenerateAccessTokenHelper(querier, newAccessTokenPayload, accessToken, userContext)
 	}
 
 	mergeIntoAccessTokenPayload := func(sessionHandle string, accessTokenPayloadUpdate map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
@@ -312,6 +475,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		GetSessionInformation:       &getSessionInformation,
 		RevokeAllSessionsForUser:    &revokeAllSessionsForUser,
 		GetAllSessionHandlesForUser: &getAllSessionHandlesForUser,
+		GetSessionsForUser:          &getSessionsForUser,
 		RevokeSession:               &revokeSession,
 		RevokeMultipleSessions:      &revokeMultipleSessions,
 		UpdateSessionData:           &updateSessionData,
@@ -334,13 +498,13 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 }
 
 // updates recipeImplHandshakeInfo in place.
-func getHandshakeInfo(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool) error {
+func getHandshakeInfo(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool, userContext supertokens.UserContext) error {
 	handshakeInfoLock.Lock()
 	defer handshakeInfoLock.Unlock()
 	if *recipeImplHandshakeInfo == nil ||
 		len((*recipeImplHandshakeInfo).GetJwtSigningPublicKeyList()) == 0 ||
 		forceFetch {
-		response, err := querier.SendPostRequest("/recipe/handshake", nil)
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/handshake", nil)
 		if err != nil {
 			return err
 		}
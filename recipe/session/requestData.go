@@ -0,0 +1,90 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	defaultErrors "errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// VerifyFromRequestData verifies the session of a request that is not a http.Request, like a gRPC
+// call, and validates its claims. The returned headers, like new tokens, must be sent back to the
+// client, also if an error is returned. Headers set by later calls on the returned session are
+// added to them as well.
+func VerifyFromRequestData(requestData sessmodels.RequestData, options *sessmodels.VerifySessionOptions) (sessmodels.SessionContainer, http.Header, error) {
+	ctx := requestData.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	method := requestData.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestData.Path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.RequestURI = requestData.Path
+	if requestData.Header != nil {
+		req.Header = requestData.Header.Clone()
+	}
+
+	instance := supertokens.GetInstanceFromContext(ctx)
+	if instance == nil {
+		instance = supertokens.GetDefaultInstance()
+	}
+	recipeInstance := GetRecipeInstanceFor(instance)
+	if recipeInstance == nil {
+		return nil, nil, defaultErrors.New("Initialisation not done. Did you forget to call the init function?")
+	}
+
+	res := &headerRecorder{header: http.Header{}}
+	spanCtx, span := instance.GetInstrumentation().StartSpan(ctx, supertokens.SpanVerifySession, map[string]interface{}{
+		"http.method": method,
+		"http.target": requestData.Path,
+	})
+	userContext := supertokens.MakeDefaultUserContextFromAPI(req.WithContext(spanCtx))
+	sessionContainer, err := verifySessionWithRecipe(*recipeInstance, options, nil, req, res, userContext)
+	endVerifySessionSpan(span, sessionContainer, err)
+	if err != nil {
+		unauthorisedErr := errors.UnauthorizedError{}
+		if defaultErrors.As(err, &unauthorisedErr) && (unauthorisedErr.ClearCookies == nil || *unauthorisedErr.ClearCookies) {
+			clearSession(recipeInstance.Config, res, getTransferMethodUsedByRequest(recipeInstance.Config, req, userContext))
+		}
+	}
+	return sessionContainer, res.header, err
+}
+
+// headerRecorder collects the headers that the session recipe sets on a response, so that RPC
+// transports can send them in their own way.
+type headerRecorder struct {
+	header http.Header
+}
+
+func (h *headerRecorder) Header() http.Header {
+	return h.header
+}
+
+func (h *headerRecorder) Write(body []byte) (int, error) {
+	return len(body), nil
+}
+
+func (h *headerRecorder) WriteHeader(statusCode int) {}
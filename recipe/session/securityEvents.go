@@ -0,0 +1,57 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// revokeSessionsOnTokenTheft is used by the default OnTokenTheftDetected handler to revoke
+// the sessions that may be used by an attacker.
+func revokeSessionsOnTokenTheft(recipeInstance Recipe, sessionHandle string, userID string, userContext supertokens.UserContext) error {
+	if recipeInstance.Config.TokenTheftPolicy == sessmodels.RevokeAllSessionsOnTokenTheft {
+		_, err := (*recipeInstance.RecipeImpl.RevokeAllSessionsForUser)(userID, userContext)
+		return err
+	}
+	_, err := (*recipeInstance.RecipeImpl.RevokeSession)(sessionHandle, userContext)
+	return err
+}
+
+func emitTokenTheftDetectedEvent(sessionInfo errors.TokenTheftDetectedErrorPayload, userContext supertokens.UserContext) {
+	supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+		Type:          supertokens.SecurityEventTokenTheftDetected,
+		RecipeID:      RECIPE_ID,
+		UserID:        sessionInfo.UserID,
+		SessionHandle: sessionInfo.SessionHandle,
+	}, userContext)
+}
+
+func emitAntiCsrfFailedEvent(accessTokenInfo *accessTokenInfoStruct, reason string, userContext supertokens.UserContext) {
+	event := supertokens.SecurityEvent{
+		Type:     supertokens.SecurityEventAntiCsrfFailed,
+		RecipeID: RECIPE_ID,
+		Details: map[string]interface{}{
+			"reason": reason,
+		},
+	}
+	if accessTokenInfo != nil {
+		event.UserID = accessTokenInfo.userID
+		event.SessionHandle = accessTokenInfo.sessionHandle
+	}
+	supertokens.EmitSecurityEvent(event, userContext)
+}
@@ -0,0 +1,122 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func initForSecurityEventTest(t *testing.T, config *sessmodels.TypeInput, events *[]supertokens.SecurityEvent) *httptest.Server {
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(config),
+		},
+		SecurityEventListeners: []supertokens.SecurityEventListener{
+			supertokens.SecurityEventListenerFunc(func(event supertokens.SecurityEvent, userContext supertokens.UserContext) {
+				*events = append(*events, event)
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSessionWithContext(rw, "userId", map[string]interface{}{}, map[string]interface{}{}, supertokens.MakeDefaultUserContextFromAPI(r))
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/protected", VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {}))
+	return httptest.NewServer(supertokens.Middleware(mux))
+}
+
+func TestTokenTheftRevokesAllSessionsOfTheUser(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	testServer := initForSecurityEventTest(t, &sessmodels.TypeInput{
+		TokenTheftPolicy: sessmodels.RevokeAllSessionsOnTokenTheft,
+	}, &events)
+	defer testServer.Close()
+
+	_, otherRefreshToken := createSessionInHeaderMode(t, testServer.URL)
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 200, res.StatusCode)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", res.Header.Get("st-access-token"))
+	assert.Equal(t, 200, res.StatusCode)
+
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 401, res.StatusCode)
+
+	handles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", otherRefreshToken)
+	assert.Equal(t, 401, res.StatusCode)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, supertokens.SecurityEventTokenTheftDetected, events[0].Type)
+	assert.Equal(t, "userId", events[0].UserID)
+	assert.Equal(t, "/auth/session/refresh", events[0].Request.Path)
+}
+
+func TestAntiCsrfFailureIsReported(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	events := []supertokens.SecurityEvent{}
+	antiCsrf := "VIA_CUSTOM_HEADER"
+	testServer := initForSecurityEventTest(t, &sessmodels.TypeInput{
+		AntiCsrf: &antiCsrf,
+	}, &events)
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	// the rid header is missing, so the anti-csrf check fails
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/protected", nil)
+	assert.NoError(t, err)
+	req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	req.Header.Set("User-Agent", "test-agent")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, supertokens.SecurityEventAntiCsrfFailed, events[0].Type)
+	assert.Equal(t, "userId", events[0].UserID)
+	assert.Equal(t, "test-agent", events[0].Request.UserAgent)
+}
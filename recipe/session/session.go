@@ -32,9 +32,10 @@ type SessionContainerInput struct {
 	res                   http.ResponseWriter
 	accessToken           string
 	recipeImpl            sessmodels.RecipeInterface
+	transferMethod        sessmodels.TokenTransferMethod
 }
 
-func makeSessionContainerInput(accessToken string, sessionHandle string, userID string, userDataInAccessToken map[string]interface{}, res http.ResponseWriter, recipeImpl sessmodels.RecipeInterface) SessionContainerInput {
+func makeSessionContainerInput(accessToken string, sessionHandle string, userID string, userDataInAccessToken map[string]interface{}, res http.ResponseWriter, recipeImpl sessmodels.RecipeInterface, transferMethod sessmodels.TokenTransferMethod) SessionContainerInput {
 	return SessionContainerInput{
 		sessionHandle:         sessionHandle,
 		userID:                userID,
@@ -42,6 +43,7 @@ func makeSessionContainerInput(accessToken string, sessionHandle string, userID
 		res:                   res,
 		accessToken:           accessToken,
 		recipeImpl:            recipeImpl,
+		transferMethod:        transferMethod,
 	}
 }
 
@@ -53,7 +55,7 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		if err != nil {
 			return err
 		}
-		clearSessionFromCookie(config, session.res)
+		clearSession(config, session.res, session.transferMethod)
 		return nil
 	}
 
@@ -99,7 +101,7 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		if !reflect.DeepEqual(resp.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 			session.accessToken = resp.AccessToken.Token
 			setFrontTokenInHeaders(session.res, resp.Session.UserID, resp.AccessToken.Expiry, resp.Session.UserDataInAccessToken)
-			attachAccessTokenToCookie(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry)
+			attachAccessToken(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry, session.transferMethod)
 		}
 		return nil
 	}
@@ -24,7 +24,7 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, AccessTokenPayload, sessionData map[string]interface{}) (sessmodels.CreateOrRefreshAPIResponse, error) {
+func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, AccessTokenPayload, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
 	if AccessTokenPayload == nil {
 		AccessTokenPayload = map[string]interface{}{}
 	}
@@ -36,12 +36,12 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 		"userDataInJWT":      AccessTokenPayload,
 		"userDataInDatabase": sessionData,
 	}
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
-	requestBody["enableAntiCsrf"] = recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN
-	response, err := querier.SendPostRequest("/recipe/session", requestBody)
+	requestBody["enableAntiCsrf"] = !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session", requestBody)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
@@ -64,8 +64,8 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 	return resp, nil
 }
 
-func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool, userContext supertokens.UserContext) (sessmodels.GetSessionResponse, error) {
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.GetSessionResponse{}, err
 	}
@@ -87,14 +87,18 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 				return sessmodels.GetSessionResponse{}, err
 			}
 
-			expiryTime := uint64(payload["expiryTime"].(float64))
-			timeCreated := uint64(payload["timeCreated"].(float64))
+			expiryTime, hasExpiryTime := payload["expiryTime"].(float64)
+			timeCreated, hasTimeCreated := payload["timeCreated"].(float64)
+			if !hasExpiryTime || !hasTimeCreated {
+				// not an access token of SuperTokens
+				return sessmodels.GetSessionResponse{}, err
+			}
 
-			if expiryTime < getCurrTimeInMS() {
+			if uint64(expiryTime) < getCurrTimeInMS() {
 				return sessmodels.GetSessionResponse{}, err
 			}
 
-			if timeCreated >= key.CreatedAt {
+			if uint64(timeCreated) >= key.CreatedAt {
 				foundASigningKeyThatIsOlderThanTheAccessToken = true
 				break
 			}
@@ -115,9 +119,11 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 				if antiCsrfToken == nil || *antiCsrfToken != *accessTokenInfo.antiCsrfToken {
 					if antiCsrfToken == nil {
 						supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because antiCsrfToken is missing from request")
+						emitAntiCsrfFailedEvent(accessTokenInfo, "anti-csrf token is missing", userContext)
 						return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "Provided antiCsrfToken is undefined. If you do not want anti-csrf check for this API, please set doAntiCsrfCheck to false for this API"}
 					} else {
 						supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because the passed antiCsrfToken is not the same as in the access token")
+						emitAntiCsrfFailedEvent(accessTokenInfo, "anti-csrf token does not match", userContext)
 						return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed"}
 					}
 				}
@@ -125,6 +131,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 		} else if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
 			if !containsCustomHeader {
 				supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because custom header (rid) was not passed")
+				emitAntiCsrfFailedEvent(accessTokenInfo, "custom header (rid) is missing", userContext)
 				return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request, or set doAntiCsrfCheck to false for this API"}
 			}
 		}
@@ -150,7 +157,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
 
-	response, err := querier.SendPostRequest("/recipe/session/verify", requestBody)
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/verify", requestBody)
 	if err != nil {
 		return sessmodels.GetSessionResponse{}, err
 	}
@@ -182,8 +189,23 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 }
 
-func getSessionInformationHelper(querier supertokens.Querier, sessionHandle string) (*sessmodels.SessionInformation, error) {
-	response, err := querier.SendGetRequest("/recipe/session",
+// isAccessTokenSignedByCore reports whether accessToken is signed with one of the signing keys of
+// the core, which tells SuperTokens access tokens apart from other JWTs in the Authorization header.
+func isAccessTokenSignedByCore(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, userContext supertokens.UserContext) (bool, error) {
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range recipeImplHandshakeInfo.GetJwtSigningPublicKeyList() {
+		if _, err := verifyJWTAndGetPayload(accessToken, key.PublicKey); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func getSessionInformationHelper(querier supertokens.Querier, sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
+	response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session",
 		map[string]string{
 			"sessionHandle": sessionHandle,
 		})
@@ -191,25 +213,29 @@ func getSessionInformationHelper(querier supertokens.Querier, sessionHandle stri
 		return nil, err
 	}
 	if response["status"] == "OK" {
+		sessionData, metadata := extractSessionMetadata(response["userDataInDatabase"].(map[string]interface{}))
+		accessTokenPayload := response["userDataInJWT"].(map[string]interface{})
 		return &sessmodels.SessionInformation{
 			SessionHandle:      response["sessionHandle"].(string),
 			UserId:             response["userId"].(string),
-			SessionData:        response["userDataInDatabase"].(map[string]interface{}),
+			SessionData:        sessionData,
 			Expiry:             uint64(response["expiry"].(float64)),
 			TimeCreated:        uint64(response["timeCreated"].(float64)),
-			AccessTokenPayload: response["userDataInJWT"].(map[string]interface{}),
+			AccessTokenPayload: accessTokenPayload,
+			Metadata:           metadata,
+			TimeLastRefreshed:  getTimeLastRefreshedFromPayload(accessTokenPayload),
 		}, nil
 	}
 	return nil, nil
 }
 
-func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, refreshToken string, antiCsrfToken *string, containsCustomHeader bool) (sessmodels.CreateOrRefreshAPIResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, refreshToken string, antiCsrfToken *string, containsCustomHeader bool, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
 
-	if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
+	if !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
 		if !containsCustomHeader {
 			clearCookies := false
 			supertokens.LogDebugMessage("refreshSession: Returning UNAUTHORISED because custom header (rid) was not passed")
@@ -222,12 +248,12 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 
 	requestBody := map[string]interface{}{
 		"refreshToken":   refreshToken,
-		"enableAntiCsrf": recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
+		"enableAntiCsrf": !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
 	}
 	if antiCsrfToken != nil {
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
-	response, err := querier.SendPostRequest("/recipe/session/refresh", requestBody)
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/refresh", requestBody)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
@@ -252,7 +278,11 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 			UserID:        (response["session"].(map[string]interface{}))["userId"].(string),
 		}
 
-		supertokens.LogDebugMessage("refreshSession: Returning TOKEN_THEFT_DETECTED because of core response")
+		querier.GetInstrumentation().AddToCounter(supertokens.GetContextFromUserContext(userContext), supertokens.MetricTokenTheftDetections, 1, map[string]interface{}{
+			"supertokens.recipe_id": RECIPE_ID,
+		})
+		querier.GetLogger().Warn("refreshSession: Returning TOKEN_THEFT_DETECTED because of core response", "recipeId", RECIPE_ID, "sessionHandle", sessionInfo.SessionHandle)
+		emitTokenTheftDetectedEvent(sessionInfo, userContext)
 		return sessmodels.CreateOrRefreshAPIResponse{}, errors.TokenTheftDetectedError{
 			Msg:     "Token theft detected",
 			Payload: sessionInfo,
@@ -260,8 +290,8 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 	}
 }
 
-func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove", map[string]interface{}{
+func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string, userContext supertokens.UserContext) ([]string, error) {
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/remove", map[string]interface{}{
 		"userId": userID,
 	})
 	if err != nil {
@@ -279,8 +309,8 @@ func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string)
 	return result, nil
 }
 
-func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
-	response, err := querier.SendGetRequest("/recipe/session/user", map[string]string{
+func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID string, userContext supertokens.UserContext) ([]string, error) {
+	response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/user", map[string]string{
 		"userId": userID,
 	})
 	if err != nil {
@@ -298,8 +328,8 @@ func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID strin
 	return result, nil
 }
 
-func revokeSessionHelper(querier supertokens.Querier, sessionHandle string) (bool, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove",
+func revokeSessionHelper(querier supertokens.Querier, sessionHandle string, userContext supertokens.UserContext) (bool, error) {
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": [1]string{sessionHandle},
 		})
@@ -309,8 +339,8 @@ func revokeSessionHelper(querier supertokens.Querier, sessionHandle string) (boo
 	return len(response["sessionHandlesRevoked"].([]interface{})) == 1, nil
 }
 
-func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []string) ([]string, error) {
-	response, err := querier.SendPostRequest("/recipe/session/remove",
+func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": sessionHandles,
 		})
@@ -328,11 +358,11 @@ func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []
 	return result, nil
 }
 
-func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}) (bool, error) {
+func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
 	if newSessionData == nil {
 		newSessionData = map[string]interface{}{}
 	}
-	response, err := querier.SendPutRequest("/recipe/session/data",
+	response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/data",
 		map[string]interface{}{
 			"sessionHandle":      sessionHandle,
 			"userDataInDatabase": newSessionData,
@@ -346,11 +376,11 @@ func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string,
 	return true, nil
 }
 
-func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle string, newAccessTokenPayload map[string]interface{}) (bool, error) {
+func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
 	if newAccessTokenPayload == nil {
 		newAccessTokenPayload = map[string]interface{}{}
 	}
-	response, err := querier.SendPutRequest("/recipe/jwt/data", map[string]interface{}{
+	response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/jwt/data", map[string]interface{}{
 		"sessionHandle": sessionHandle,
 		"userDataInJWT": newAccessTokenPayload,
 	})
@@ -363,11 +393,11 @@ func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle s
 	return true, nil
 }
 
-func regenerateAccessTokenHelper(querier supertokens.Querier, newAccessTokenPayload *map[string]interface{}, accessToken string) (*sessmodels.RegenerateAccessTokenResponse, error) {
+func regenerateAccessTokenHelper(querier supertokens.Querier, newAccessTokenPayload *map[string]interface{}, accessToken string, userContext supertokens.UserContext) (*sessmodels.RegenerateAccessTokenResponse, error) {
 	if newAccessTokenPayload == nil {
 		newAccessTokenPayload = &map[string]interface{}{}
 	}
-	response, err := querier.SendPostRequest("/recipe/session/regenerate", map[string]interface{}{
+	response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/session/regenerate", map[string]interface{}{
 		"accessToken":   accessToken,
 		"userDataInJWT": newAccessTokenPayload,
 	})
@@ -0,0 +1,206 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func normaliseTrustedProxies(trustedProxies []string) ([]*net.IPNet, error) {
+	result := []*net.IPNet{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, supertokens.BadInputError{Msg: "Invalid trusted proxy: " + proxy}
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, supertokens.BadInputError{Msg: "Invalid trusted proxy: " + proxy}
+		}
+		result = append(result, ipNet)
+	}
+	return result, nil
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIP returns the remote address of the request, unless it is a trusted proxy. In that
+// case X-Forwarded-For is read from right to left, and the first address that is not a trusted
+// proxy is returned, since the entries to the left of it could be set by the client.
+func getClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	remoteAddr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	ip := net.ParseIP(remoteAddr)
+	if ip == nil || !isTrustedProxy(ip, trustedProxies) {
+		return remoteAddr
+	}
+
+	forwardedFor := []string{}
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		forwardedFor = append(forwardedFor, strings.Split(header, ",")...)
+	}
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwardedFor[i]))
+		if forwardedIP == nil {
+			break
+		}
+		remoteAddr = forwardedIP.String()
+		if !isTrustedProxy(forwardedIP, trustedProxies) {
+			break
+		}
+	}
+	return remoteAddr
+}
+
+// parseUserAgent returns the device type, OS and browser of a user agent. It only knows the
+// common browsers, since the result is meant to be shown to users in a list of their sessions.
+func parseUserAgent(userAgent string) (device string, os string, browser string) {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case ua == "":
+		device = "unknown"
+	case strings.Contains(ua, "bot") || strings.Contains(ua, "crawler") || strings.Contains(ua, "spider"):
+		device = "bot"
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		device = "tablet"
+	case strings.Contains(ua, "mobile") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		device = "mobile"
+	default:
+		device = "desktop"
+	}
+
+	switch {
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod"):
+		os = "iOS"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "cros"):
+		os = "ChromeOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	default:
+		os = "Other"
+	}
+
+	// the order matters, since most browsers also claim to be Chrome and Safari
+	switch {
+	case strings.Contains(ua, "edg/") || strings.Contains(ua, "edga/") || strings.Contains(ua, "edgios/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/") || strings.Contains(ua, "fxios/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	default:
+		browser = "Other"
+	}
+	return device, os, browser
+}
+
+func makeSessionMetadata(config sessmodels.TypeNormalisedInput, req *http.Request, userContext supertokens.UserContext) (*sessmodels.SessionMetadata, error) {
+	userAgent := req.Header.Get("User-Agent")
+	device, os, browser := parseUserAgent(userAgent)
+	metadata := &sessmodels.SessionMetadata{
+		IP:        getClientIP(req, config.SessionMetadata.TrustedProxies),
+		UserAgent: userAgent,
+		Device:    device,
+		OS:        os,
+		Browser:   browser,
+	}
+	if config.SessionMetadata.LookupLocation != nil {
+		location, err := config.SessionMetadata.LookupLocation(metadata.IP, userContext)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Location = location
+	}
+	return metadata, nil
+}
+
+// addSessionMetadataToSessionData returns a copy of sessionData with the metadata of the
+// request. The metadata is stored as a map, so that it looks the same after being read back from the core.
+func addSessionMetadataToSessionData(sessionData map[string]interface{}, metadata *sessmodels.SessionMetadata) (map[string]interface{}, error) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	var metadataMap map[string]interface{}
+	err = json.Unmarshal(metadataJSON, &metadataMap)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	for key, value := range sessionData {
+		result[key] = value
+	}
+	result[sessmodels.SessionMetadataKey] = metadataMap
+	return result, nil
+}
+
+// extractSessionMetadata removes the metadata from the session data as read from the core.
+func extractSessionMetadata(sessionData map[string]interface{}) (map[string]interface{}, *sessmodels.SessionMetadata) {
+	value, ok := sessionData[sessmodels.SessionMetadataKey]
+	if !ok {
+		return sessionData, nil
+	}
+	result := map[string]interface{}{}
+	for key, value := range sessionData {
+		if key != sessmodels.SessionMetadataKey {
+			result[key] = value
+		}
+	}
+	metadataJSON, err := json.Marshal(value)
+	if err != nil {
+		return result, nil
+	}
+	var metadata sessmodels.SessionMetadata
+	if json.Unmarshal(metadataJSON, &metadata) != nil {
+		return result, nil
+	}
+	return result, &metadata
+}
@@ -0,0 +1,222 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	cases := []struct {
+		userAgent string
+		device    string
+		os        string
+		browser   string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36 Edg/118.0.2088.46", "desktop", "Windows", "Edge"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15", "desktop", "macOS", "Safari"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/118.0.5993.69 Mobile/15E148 Safari/604.1", "mobile", "iOS", "Chrome"},
+		{"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36", "tablet", "Android", "Chrome"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0", "desktop", "Linux", "Firefox"},
+		{"Googlebot/2.1 (+http://www.google.com/bot.html)", "bot", "Other", "Other"},
+		{"", "unknown", "Other", "Other"},
+	}
+	for _, c := range cases {
+		device, os, browser := parseUserAgent(c.userAgent)
+		assert.Equal(t, c.device, device, c.userAgent)
+		assert.Equal(t, c.os, os, c.userAgent)
+		assert.Equal(t, c.browser, browser, c.userAgent)
+	}
+}
+
+func TestGetClientIPOnlyTrustsForwardedForFromTrustedProxies(t *testing.T) {
+	trustedProxies, err := normaliseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req.RemoteAddr = "203.0.113.9:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	assert.Equal(t, "203.0.113.9", getClientIP(req, trustedProxies))
+
+	req.RemoteAddr = "192.168.1.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 198.51.100.1, 10.1.2.3")
+	assert.Equal(t, "198.51.100.1", getClientIP(req, trustedProxies))
+
+	_, err = normaliseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
+
+func createSessionWithUserAgent(t *testing.T, url string, userAgent string) string {
+	req, err := http.NewRequest(http.MethodPost, url+"/create", nil)
+	assert.NoError(t, err)
+	req.Header.Set("st-auth-mode", "header")
+	req.Header.Set("User-Agent", userAgent)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	return res.Header.Get("st-access-token")
+}
+
+func TestListingAndRevokingSessions(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, &sessmodels.TypeInput{
+		SessionMetadata: &sessmodels.SessionMetadataConfig{
+			Enable: true,
+		},
+	})
+	defer testServer.Close()
+
+	firefoxToken := createSessionWithUserAgent(t, testServer.URL, "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0")
+	createSessionWithUserAgent(t, testServer.URL, "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1")
+
+	res := sendWithBearerToken(t, http.MethodGet, testServer.URL+"/auth/session/list", firefoxToken)
+	assert.Equal(t, 200, res.StatusCode)
+	var listResponse struct {
+		Status   string `json:"status"`
+		Sessions []struct {
+			SessionHandle string                      `json:"sessionHandle"`
+			Current       bool                        `json:"current"`
+			Metadata      *sessmodels.SessionMetadata `json:"metadata"`
+		} `json:"sessions"`
+	}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&listResponse))
+	assert.Equal(t, "OK", listResponse.Status)
+	assert.Len(t, listResponse.Sessions, 2)
+
+	iPhoneHandle := ""
+	for _, s := range listResponse.Sessions {
+		assert.NotNil(t, s.Metadata)
+		assert.Equal(t, "127.0.0.1", s.Metadata.IP)
+		if s.Current {
+			assert.Equal(t, "Firefox", s.Metadata.Browser)
+		} else {
+			assert.Equal(t, "mobile", s.Metadata.Device)
+			assert.Equal(t, "iOS", s.Metadata.OS)
+			iPhoneHandle = s.SessionHandle
+		}
+	}
+	assert.NotEmpty(t, iPhoneHandle)
+
+	// the metadata is not part of the session data seen by the app
+	sessionInformation, err := GetSessionInformation(iPhoneHandle)
+	assert.NoError(t, err)
+	assert.NotContains(t, sessionInformation.SessionData, sessmodels.SessionMetadataKey)
+	_, err = UpdateSessionData(iPhoneHandle, map[string]interface{}{"key": "value"})
+	assert.NoError(t, err)
+	sessionInformation, err = GetSessionInformation(iPhoneHandle)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, sessionInformation.SessionData)
+	assert.Equal(t, "iOS", sessionInformation.Metadata.OS)
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/session/revoke", strings.NewReader(`{"sessionHandle":"`+iPhoneHandle+`"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+firefoxToken)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"OK"}`, strings.TrimSpace(string(body)))
+
+	sessions, err := GetSessionsForUser("userId")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	sessionInformation, err = GetSessionInformation(iPhoneHandle)
+	assert.NoError(t, err)
+	assert.Nil(t, sessionInformation)
+}
+
+func TestRevokingSessionOfAnotherUserFails(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	accessToken := createSessionWithUserAgent(t, testServer.URL, "")
+	otherSession, err := CreateNewSession(httptest.NewRecorder(), "otherUserId", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/session/revoke", strings.NewReader(`{"sessionHandle":"`+otherSession.GetHandle()+`"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"UNKNOWN_SESSION_ERROR"}`, strings.TrimSpace(string(body)))
+
+	sessionInformation, err := GetSessionInformation(otherSession.GetHandle())
+	assert.NoError(t, err)
+	assert.NotNil(t, sessionInformation)
+}
+
+func TestSessionMetadataIsNotStoredByDefault(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	createSessionWithUserAgent(t, testServer.URL, "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0")
+
+	sessions, err := GetSessionsForUser("userId")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Nil(t, sessions[0].Metadata)
+	assert.Empty(t, sessions[0].SessionData)
+}
+
+func TestGetSessionsForUserGivesEveryRequestItsOwnUserContext(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, &sessmodels.TypeInput{
+		Override: &sessmodels.OverrideStruct{
+			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+				originalGetSessionInformation := *originalImplementation.GetSessionInformation
+				*originalImplementation.GetSessionInformation = func(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
+					(*userContext)["sessionHandle"] = sessionHandle
+					return originalGetSessionInformation(sessionHandle, userContext)
+				}
+				return originalImplementation
+			},
+		},
+	})
+	defer testServer.Close()
+
+	for i := 0; i < 5; i++ {
+		createSessionWithUserAgent(t, testServer.URL, "")
+	}
+
+	userContext := &map[string]interface{}{}
+	sessions, err := GetSessionsForUserWithContext("userId", userContext)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 5)
+	assert.NotContains(t, *userContext, "sessionHandle")
+}
@@ -0,0 +1,207 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"sort"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func getSessionPolicy(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, userContext supertokens.UserContext) (*sessmodels.SessionPolicy, error) {
+	if config.SessionPolicy.GetPolicy == nil {
+		return nil, nil
+	}
+	policy, err := config.SessionPolicy.GetPolicy(userID, userContext)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	if policy.ConcurrentSessionStrategy == "" {
+		policy.ConcurrentSessionStrategy = sessmodels.EvictOldestSession
+	}
+	if policy.ConcurrentSessionStrategy != sessmodels.EvictOldestSession && policy.ConcurrentSessionStrategy != sessmodels.RejectNewSession {
+		return nil, supertokens.BadInputError{Msg: "ConcurrentSessionStrategy must be one of EVICT_OLDEST or REJECT_NEW"}
+	}
+	if policy.IdleTimeout > 0 {
+		// the time of the last refresh only changes when the access token has expired, so a shorter
+		// timeout would revoke sessions that are in use
+		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
+		if err != nil {
+			return nil, err
+		}
+		accessTokenValidity := time.Duration(recipeImplHandshakeInfo.AccessTokenValidity) * time.Millisecond
+		if policy.IdleTimeout <= accessTokenValidity {
+			return nil, supertokens.BadInputError{Msg: "IdleTimeout must be longer than the access token validity of " + accessTokenValidity.String()}
+		}
+	}
+	return policy, nil
+}
+
+func evictSession(config sessmodels.TypeNormalisedInput, querier supertokens.Querier, sessionInformation sessmodels.SessionInformation, reason sessmodels.SessionEvictionReason, userContext supertokens.UserContext) error {
+	revoked, err := revokeSessionHelper(querier, sessionInformation.SessionHandle, userContext)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		querier.GetLogger().Info("Session evicted because of the session policy", "recipeId", RECIPE_ID, "sessionHandle", sessionInformation.SessionHandle, "reason", string(reason))
+		config.SessionPolicy.OnSessionEvicted(sessionInformation, reason, userContext)
+	}
+	return nil
+}
+
+// enforceConcurrentSessionLimit makes room for a new session of the user, either by revoking their
+// oldest sessions or by returning a MaxSessionsReachedError, depending on their policy.
+func enforceConcurrentSessionLimit(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, userContext supertokens.UserContext) error {
+	policy, err := getSessionPolicy(recipeImplHandshakeInfo, config, querier, userID, userContext)
+	if err != nil {
+		return err
+	}
+	if policy == nil || policy.MaxConcurrentSessions <= 0 {
+		return nil
+	}
+
+	sessionHandles, err := getAllSessionHandlesForUserHelper(querier, userID, userContext)
+	if err != nil {
+		return err
+	}
+	if len(sessionHandles) < policy.MaxConcurrentSessions {
+		return nil
+	}
+	if policy.ConcurrentSessionStrategy == sessmodels.RejectNewSession {
+		return errors.MaxSessionsReachedError{
+			Msg:    "The user already has the maximum number of sessions",
+			UserID: userID,
+		}
+	}
+
+	sessions := []sessmodels.SessionInformation{}
+	for _, sessionHandle := range sessionHandles {
+		sessionInformation, err := getSessionInformationHelper(querier, sessionHandle, userContext)
+		if err != nil {
+			return err
+		}
+		if sessionInformation != nil {
+			sessions = append(sessions, *sessionInformation)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].TimeCreated < sessions[j].TimeCreated
+	})
+	for i := 0; i <= len(sessions)-policy.MaxConcurrentSessions; i++ {
+		err := evictSession(config, querier, sessions[i], sessmodels.EvictedForMaxConcurrentSessions, userContext)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enforceSessionLifetime is called after a session has been refreshed. It revokes the session if it
+// has been idle or alive for longer than the policy of the user allows, and otherwise records the
+// time of the refresh in the access token payload of response.
+func enforceSessionLifetime(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, response *sessmodels.CreateOrRefreshAPIResponse, userContext supertokens.UserContext) error {
+	policy, err := getSessionPolicy(recipeImplHandshakeInfo, config, querier, response.Session.UserID, userContext)
+	if err != nil {
+		return err
+	}
+	if policy == nil || (policy.IdleTimeout <= 0 && policy.MaxSessionAge <= 0) {
+		return nil
+	}
+
+	// the time of the last refresh comes with the refreshed session, so the session only has to be
+	// fetched for the time it was created
+	timeLastRefreshed := getTimeLastRefreshedFromPayload(response.Session.UserDataInAccessToken)
+	var sessionInformation *sessmodels.SessionInformation
+	if policy.MaxSessionAge > 0 || timeLastRefreshed == 0 {
+		sessionInformation, err = getSessionInformationHelper(querier, response.Session.Handle, userContext)
+		if err != nil {
+			return err
+		}
+		if sessionInformation == nil {
+			return errors.UnauthorizedError{Msg: "Session has been revoked"}
+		}
+	}
+
+	now := time.Now()
+	var reason sessmodels.SessionEvictionReason
+	if sessionInformation != nil && policy.MaxSessionAge > 0 && now.Sub(timeFromMillis(sessionInformation.TimeCreated)) > policy.MaxSessionAge {
+		reason = sessmodels.EvictedForMaxSessionAge
+	} else if policy.IdleTimeout > 0 {
+		lastActive := timeLastRefreshed
+		if lastActive == 0 {
+			lastActive = sessionInformation.TimeCreated
+		}
+		if now.Sub(timeFromMillis(lastActive)) > policy.IdleTimeout {
+			reason = sessmodels.EvictedForIdleTimeout
+		}
+	}
+	if reason != "" {
+		if sessionInformation == nil {
+			sessionInformation, err = getSessionInformationHelper(querier, response.Session.Handle, userContext)
+			if err != nil {
+				return err
+			}
+			if sessionInformation == nil {
+				return errors.UnauthorizedError{Msg: "Session has been revoked"}
+			}
+		}
+		err := evictSession(config, querier, *sessionInformation, reason, userContext)
+		if err != nil {
+			return err
+		}
+		return errors.UnauthorizedError{Msg: "Session has expired because of the session policy"}
+	}
+
+	if policy.IdleTimeout <= 0 {
+		return nil
+	}
+	accessTokenPayload := map[string]interface{}{}
+	for key, value := range response.Session.UserDataInAccessToken {
+		accessTokenPayload[key] = value
+	}
+	accessTokenPayload[sessmodels.SessionLastRefreshedKey] = uint64(now.UnixNano() / 1000000)
+	regenerateResponse, err := regenerateAccessTokenHelper(querier, &accessTokenPayload, response.AccessToken.Token, userContext)
+	if err != nil {
+		return err
+	}
+	if regenerateResponse == nil {
+		return errors.UnauthorizedError{Msg: "Session has been revoked"}
+	}
+	response.Session.UserDataInAccessToken = regenerateResponse.Session.UserDataInAccessToken
+	if regenerateResponse.AccessToken.Token != "" {
+		response.AccessToken = regenerateResponse.AccessToken
+	}
+	return nil
+}
+
+func timeFromMillis(millis uint64) time.Time {
+	return time.Unix(0, int64(millis)*int64(time.Millisecond))
+}
+
+// getTimeLastRefreshedFromPayload returns the time of the last refresh recorded in an access token
+// payload, or 0 if there is none.
+func getTimeLastRefreshedFromPayload(accessTokenPayload map[string]interface{}) uint64 {
+	switch value := accessTokenPayload[sessmodels.SessionLastRefreshedKey].(type) {
+	case float64:
+		return uint64(value)
+	case uint64:
+		return value
+	}
+	return 0
+}
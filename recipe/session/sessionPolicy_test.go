@@ -0,0 +1,262 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+type evictedSession struct {
+	sessionHandle string
+	reason        sessmodels.SessionEvictionReason
+}
+
+func makeSessionPolicyConfig(policy sessmodels.SessionPolicy, evicted *[]evictedSession) *sessmodels.TypeInput {
+	return &sessmodels.TypeInput{
+		SessionPolicy: &sessmodels.SessionPolicyConfig{
+			GetPolicy: func(userID string, userContext supertokens.UserContext) (*sessmodels.SessionPolicy, error) {
+				if userID != "userId" {
+					return nil, nil
+				}
+				return &policy, nil
+			},
+			OnSessionEvicted: func(session sessmodels.SessionInformation, reason sessmodels.SessionEvictionReason, userContext supertokens.UserContext) {
+				*evicted = append(*evicted, evictedSession{session.SessionHandle, reason})
+			},
+		},
+	}
+}
+
+func TestOldestSessionIsEvictedWhenTheLimitIsReached(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		MaxConcurrentSessions: 2,
+	}, &evicted))
+	defer testServer.Close()
+
+	handles := []string{}
+	for i := 0; i < 3; i++ {
+		session, err := CreateNewSession(httptest.NewRecorder(), "userId", map[string]interface{}{}, map[string]interface{}{})
+		assert.NoError(t, err)
+		handles = append(handles, session.GetHandle())
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	remaining, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, handles[1:], remaining)
+	assert.Equal(t, []evictedSession{{handles[0], sessmodels.EvictedForMaxConcurrentSessions}}, evicted)
+
+	// users without a policy are not limited
+	for i := 0; i < 3; i++ {
+		_, err := CreateNewSession(httptest.NewRecorder(), "otherUserId", map[string]interface{}{}, map[string]interface{}{})
+		assert.NoError(t, err)
+	}
+	remaining, err = GetAllSessionHandlesForUser("otherUserId")
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 3)
+}
+
+func TestNewSessionIsRejectedWhenTheLimitIsReached(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		MaxConcurrentSessions:     1,
+		ConcurrentSessionStrategy: sessmodels.RejectNewSession,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, err := CreateNewSession(httptest.NewRecorder(), "userId", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	_, err = CreateNewSession(httptest.NewRecorder(), "userId", map[string]interface{}{}, map[string]interface{}{})
+	assert.True(t, defaultErrors.As(err, &errors.MaxSessionsReachedError{}))
+	assert.Empty(t, evicted)
+
+	handles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+}
+
+func createSessionInHeaderMode(t *testing.T, url string) (string, string) {
+	req, err := http.NewRequest(http.MethodPost, url+"/create", nil)
+	assert.NoError(t, err)
+	req.Header.Set("st-auth-mode", "header")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	return res.Header.Get("st-access-token"), res.Header.Get("st-refresh-token")
+}
+
+func TestRefreshRecordsTimeOfLastRefresh(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout:   2 * time.Hour,
+		MaxSessionAge: 2 * time.Hour,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 200, res.StatusCode)
+
+	sessions, err := GetSessionsForUser("userId")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Greater(t, sessions[0].TimeLastRefreshed, uint64(0))
+	assert.Equal(t, map[string]interface{}{}, sessions[0].SessionData)
+
+	// the time is recorded in the access token sent to the client, so that it is kept when the
+	// payload of that token is changed
+	payload, err := getPayloadWithoutVerifying(res.Header.Get("st-access-token"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(sessions[0].TimeLastRefreshed), payload["userData"].(map[string]interface{})[sessmodels.SessionLastRefreshedKey])
+
+	// updating the session data keeps the time of the last refresh
+	_, err = UpdateSessionData(sessions[0].SessionHandle, map[string]interface{}{"key": "value"})
+	assert.NoError(t, err)
+	sessionInformation, err := GetSessionInformation(sessions[0].SessionHandle)
+	assert.NoError(t, err)
+	assert.Equal(t, sessions[0].TimeLastRefreshed, sessionInformation.TimeLastRefreshed)
+	assert.Empty(t, evicted)
+}
+
+func TestIdleSessionIsRevokedOnRefresh(t *testing.T) {
+	BeforeEach()
+	unittesting.SetKeyValueInConfig("access_token_validity", "1")
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout: 1500 * time.Millisecond,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+	time.Sleep(1600 * time.Millisecond)
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 401, res.StatusCode)
+
+	assert.Len(t, evicted, 1)
+	assert.Equal(t, sessmodels.EvictedForIdleTimeout, evicted[0].reason)
+	handles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.Empty(t, handles)
+}
+
+func TestSessionIsKeptWhileItIsRefreshedWithinTheIdleTimeout(t *testing.T) {
+	BeforeEach()
+	unittesting.SetKeyValueInConfig("access_token_validity", "1")
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout: 1500 * time.Millisecond,
+	}, &evicted))
+	defer testServer.Close()
+
+	// the session lives longer than the idle timeout, but is refreshed whenever its access token expires
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Second)
+		res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+		assert.Equal(t, 200, res.StatusCode)
+		refreshToken = res.Header.Get("st-refresh-token")
+	}
+	assert.Empty(t, evicted)
+}
+
+func TestIdleTimeoutMustBeLongerThanTheAccessTokenValidity(t *testing.T) {
+	BeforeEach()
+	unittesting.SetKeyValueInConfig("access_token_validity", "1")
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout: time.Second,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, err := CreateNewSession(httptest.NewRecorder(), "userId", map[string]interface{}{}, map[string]interface{}{})
+	assert.ErrorAs(t, err, &supertokens.BadInputError{})
+	assert.Contains(t, err.Error(), "IdleTimeout must be longer than the access token validity of 1s")
+}
+
+func TestSessionIsRevokedAfterMaxSessionAge(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout:   2 * time.Hour,
+		MaxSessionAge: 100 * time.Millisecond,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 200, res.StatusCode)
+	refreshToken = res.Header.Get("st-refresh-token")
+
+	time.Sleep(150 * time.Millisecond)
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 401, res.StatusCode)
+	assert.Len(t, evicted, 1)
+	assert.Equal(t, sessmodels.EvictedForMaxSessionAge, evicted[0].reason)
+}
+
+func TestRefreshDoesNotChangeSessionData(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	evicted := []evictedSession{}
+	testServer := initForTokenTransferTest(t, makeSessionPolicyConfig(sessmodels.SessionPolicy{
+		IdleTimeout: 2 * time.Hour,
+	}, &evicted))
+	defer testServer.Close()
+
+	_, refreshToken := createSessionInHeaderMode(t, testServer.URL)
+	handles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.Len(t, handles, 1)
+	_, err = UpdateSessionData(handles[0], map[string]interface{}{"key": "value"})
+	assert.NoError(t, err)
+
+	res := sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 200, res.StatusCode)
+
+	sessionInformation, err := GetSessionInformation(handles[0])
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, sessionInformation.SessionData)
+	assert.Greater(t, sessionInformation.TimeLastRefreshed, uint64(0))
+}
@@ -26,9 +26,43 @@ type APIInterface struct {
 	RefreshPOST   *func(options APIOptions, userContext supertokens.UserContext) (SessionContainer, error)
 	SignOutPOST   *func(sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (SignOutPOSTResponse, error)
 	VerifySession *func(verifySessionOptions *VerifySessionOptions, options APIOptions, userContext supertokens.UserContext) (SessionContainer, error)
+
+	SessionsGET       *func(sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (SessionsGETResponse, error)
+	RevokeSessionPOST *func(sessionHandle string, sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (RevokeSessionPOSTResponse, error)
+
+	ImpersonatePOST       *func(targetUserID string, sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (ImpersonatePOSTResponse, error)
+	StopImpersonationPOST *func(sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (StopImpersonationPOSTResponse, error)
 }
 
 type SignOutPOSTResponse struct {
 	OK           *struct{}
 	GeneralError *supertokens.GeneralErrorResponse
 }
+
+type SessionsGETResponse struct {
+	OK *struct {
+		Sessions []SessionInformation
+	}
+	GeneralError *supertokens.GeneralErrorResponse
+}
+
+type RevokeSessionPOSTResponse struct {
+	OK                  *struct{}
+	UnknownSessionError *struct{}
+	GeneralError        *supertokens.GeneralErrorResponse
+}
+
+type ImpersonatePOSTResponse struct {
+	OK *struct {
+		Session SessionContainer
+	}
+	NotAllowedError    *struct{}
+	UnknownUserIDError *struct{}
+	GeneralError       *supertokens.GeneralErrorResponse
+}
+
+type StopImpersonationPOSTResponse struct {
+	OK                    *struct{}
+	NotImpersonatingError *struct{}
+	GeneralError          *supertokens.GeneralErrorResponse
+}
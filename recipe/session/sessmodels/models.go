@@ -16,6 +16,8 @@
 package sessmodels
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -87,6 +89,14 @@ type RegenerateAccessTokenResponse struct {
 	AccessToken CreateOrRefreshAPIResponseToken `json:"accessToken"`
 }
 
+type TokenTransferMethod string
+
+const (
+	CookieTransferMethod TokenTransferMethod = "cookie"
+	HeaderTransferMethod TokenTransferMethod = "header"
+	AnyTransferMethod    TokenTransferMethod = "any"
+)
+
 type TypeInput struct {
 	CookieSecure             *bool
 	CookieSameSite           *string
@@ -97,6 +107,89 @@ type TypeInput struct {
 	Override                 *OverrideStruct
 	ErrorHandlers            *ErrorHandlers
 	Jwt                      *JWTInputConfig
+	// GetTokenTransferMethod decides if the session tokens of a request are sent as cookies
+	// or via the Authorization header. For new sessions, req is nil if the session is not
+	// created while handling a request.
+	GetTokenTransferMethod func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) TokenTransferMethod
+	SessionMetadata        *SessionMetadataConfig
+	SessionPolicy          *SessionPolicyConfig
+	// TokenTheftPolicy decides which sessions are revoked by the default OnTokenTheftDetected
+	// error handler. It defaults to RevokeSessionOnTokenTheft.
+	TokenTheftPolicy TokenTheftPolicy
+	// Impersonation enables sessions in which support staff act as another user.
+	Impersonation *ImpersonationConfig
+}
+
+type TokenTheftPolicy string
+
+const (
+	RevokeSessionOnTokenTheft     TokenTheftPolicy = "REVOKE_SESSION"
+	RevokeAllSessionsOnTokenTheft TokenTheftPolicy = "REVOKE_ALL_SESSIONS"
+)
+
+type SessionPolicyConfig struct {
+	// GetPolicy returns the policy for the sessions of a user. If it returns nil, the
+	// sessions of the user are not limited.
+	GetPolicy func(userID string, userContext supertokens.UserContext) (*SessionPolicy, error)
+	// OnSessionEvicted is called after a session has been revoked because of the policy,
+	// for example to let the user know that they were signed out on another device.
+	OnSessionEvicted func(session SessionInformation, reason SessionEvictionReason, userContext supertokens.UserContext)
+}
+
+type SessionPolicy struct {
+	// MaxConcurrentSessions is the number of sessions a user can have at once. 0 means no limit.
+	MaxConcurrentSessions int
+	// ConcurrentSessionStrategy decides what happens when a session is created for a user that
+	// already has MaxConcurrentSessions sessions. It defaults to EvictOldestSession.
+	ConcurrentSessionStrategy ConcurrentSessionStrategy
+	// IdleTimeout revokes sessions that have not been refreshed for this long. Since sessions are
+	// only refreshed when their access token expires, it has to be longer than the access token
+	// validity, and should be much longer, since a user that is away for a moment after the access
+	// token expired is still active. Shorter timeouts are rejected with a BadInputError. It is only
+	// checked when a session is refreshed, not by GetSession, so an idle session can still be used
+	// until its current access token expires. 0 means no timeout.
+	IdleTimeout time.Duration
+	// MaxSessionAge revokes sessions this long after they were created, however often they are
+	// refreshed. 0 means no limit.
+	MaxSessionAge time.Duration
+}
+
+type ConcurrentSessionStrategy string
+
+const (
+	EvictOldestSession ConcurrentSessionStrategy = "EVICT_OLDEST"
+	RejectNewSession   ConcurrentSessionStrategy = "REJECT_NEW"
+)
+
+type SessionEvictionReason string
+
+const (
+	EvictedForMaxConcurrentSessions SessionEvictionReason = "MAX_CONCURRENT_SESSIONS"
+	EvictedForIdleTimeout           SessionEvictionReason = "IDLE_TIMEOUT"
+	EvictedForMaxSessionAge         SessionEvictionReason = "MAX_SESSION_AGE"
+)
+
+type ImpersonationConfig struct {
+	// IsAllowed decides if the user of impersonatorSession may impersonate targetUserID. The
+	// impersonation API rejects all requests if it is nil.
+	IsAllowed func(impersonatorSession SessionContainer, targetUserID string, userContext supertokens.UserContext) (bool, error)
+	// Lifetime is how long an impersonation session lasts. Refreshing the session does not
+	// extend it. It defaults to 15 minutes.
+	Lifetime time.Duration
+	// AllowWrites creates impersonation sessions without the read only flag.
+	AllowWrites bool
+}
+
+// SessionMetadataConfig enables storing the IP address and user agent of the request in new
+// sessions. Since the metadata is kept in the session data, UpdateSessionData has to read the
+// session first to keep it, which costs an extra request to the core.
+type SessionMetadataConfig struct {
+	// Enable makes new sessions store the IP address and user agent of the request.
+	Enable bool
+	// TrustedProxies are the IP addresses or CIDR ranges of proxies whose
+	// X-Forwarded-For header is used to find the IP address of the client.
+	TrustedProxies []string
+	LookupLocation func(ip string, userContext supertokens.UserContext) (*SessionLocation, error)
 }
 
 type JWTInputConfig struct {
@@ -115,6 +208,9 @@ type ErrorHandlers struct {
 	OnUnauthorised       func(message string, req *http.Request, res http.ResponseWriter) error
 	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
 	OnInvalidClaim       func(validationErrors []claims.ClaimValidationError, req *http.Request, res http.ResponseWriter) error
+	// OnMaxSessionsReached is called when a session policy rejects a new session. By default it
+	// responds with HTTP 200 and {"status": "MAX_SESSIONS_REACHED", "message": ...}.
+	OnMaxSessionsReached func(userID string, req *http.Request, res http.ResponseWriter) error
 }
 
 type TypeNormalisedInput struct {
@@ -128,6 +224,32 @@ type TypeNormalisedInput struct {
 	Override                 OverrideStruct
 	ErrorHandlers            NormalisedErrorHandlers
 	Jwt                      JWTNormalisedConfig
+	GetTokenTransferMethod   func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) TokenTransferMethod
+	SessionMetadata          NormalisedSessionMetadataConfig
+	SessionPolicy            NormalisedSessionPolicyConfig
+	TokenTheftPolicy         TokenTheftPolicy
+	Impersonation            NormalisedImpersonationConfig
+}
+
+type NormalisedImpersonationConfig struct {
+	// Enabled is true if an impersonation config is given.
+	Enabled bool
+	// IsAllowed is nil if the impersonation API should reject all requests.
+	IsAllowed func(impersonatorSession SessionContainer, targetUserID string, userContext supertokens.UserContext) (bool, error)
+	Lifetime  time.Duration
+	ReadOnly  bool
+}
+
+type NormalisedSessionPolicyConfig struct {
+	// GetPolicy is nil if no session policy is configured.
+	GetPolicy        func(userID string, userContext supertokens.UserContext) (*SessionPolicy, error)
+	OnSessionEvicted func(session SessionInformation, reason SessionEvictionReason, userContext supertokens.UserContext)
+}
+
+type NormalisedSessionMetadataConfig struct {
+	Enabled        bool
+	TrustedProxies []*net.IPNet
+	LookupLocation func(ip string, userContext supertokens.UserContext) (*SessionLocation, error)
 }
 
 type JWTNormalisedConfig struct {
@@ -150,14 +272,21 @@ type APIOptions struct {
 	Res                  http.ResponseWriter
 	OtherHandler         http.HandlerFunc
 
+	ClaimsAddedByOtherRecipes          []*claims.TypeSessionClaim
 	ClaimValidatorsAddedByOtherRecipes []claims.SessionClaimValidator
+	UserExists                         TypeUserExists
 }
 
+// TypeUserExists is added to the session recipe by the login recipes, so that it can check that
+// a user exists before creating a session for them that they did not sign in to.
+type TypeUserExists func(userID string, userContext supertokens.UserContext) (bool, error)
+
 type NormalisedErrorHandlers struct {
 	OnUnauthorised       func(message string, req *http.Request, res http.ResponseWriter) error
 	OnTryRefreshToken    func(message string, req *http.Request, res http.ResponseWriter) error
 	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
 	OnInvalidClaim       func(validationErrors []claims.ClaimValidationError, req *http.Request, res http.ResponseWriter) error
+	OnMaxSessionsReached func(userID string, req *http.Request, res http.ResponseWriter) error
 }
 
 type TypeSessionContainer struct {
@@ -209,6 +338,85 @@ type SessionInformation struct {
 	Expiry             uint64
 	AccessTokenPayload map[string]interface{}
 	TimeCreated        uint64
+	// Metadata is nil for sessions that were not created while handling a request.
+	Metadata *SessionMetadata
+	// TimeLastRefreshed is only tracked if a session policy with an IdleTimeout is configured. It
+	// is 0 if the session has not been refreshed since then.
+	TimeLastRefreshed uint64
+}
+
+// SessionMetadataKey is the key in the session data under which the metadata of a session is stored.
+const SessionMetadataKey = "st-session-metadata"
+
+// SessionLastRefreshedKey is the key in the access token payload under which the time of the last
+// refresh of a session is stored, if a session policy with an IdleTimeout is configured. It is kept
+// out of the session data so that recording it does not overwrite concurrent changes to that data.
+const SessionLastRefreshedKey = "st-last-refreshed"
+
+// ImpersonationKey is the key in the access token payload under which the ImpersonationInfo of
+// an impersonation session is stored.
+const ImpersonationKey = "st-impersonation"
+
+// ImpersonationInfo is stored in the access token payload of sessions created for support staff
+// acting as another user.
+type ImpersonationInfo struct {
+	ImpersonatorID string `json:"impersonatorId"`
+	ReadOnly       bool   `json:"readOnly"`
+	// ExpiresAt is the time in milliseconds since epoch after which the session is revoked.
+	ExpiresAt uint64 `json:"expiresAt"`
+}
+
+type SessionMetadata struct {
+	IP        string           `json:"ip"`
+	UserAgent string           `json:"userAgent"`
+	Device    string           `json:"device"`
+	OS        string           `json:"os"`
+	Browser   string           `json:"browser"`
+	Location  *SessionLocation `json:"location,omitempty"`
+}
+
+type SessionLocation struct {
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
 }
 
 const SessionContext int = iota
+
+// RequestData is the part of a request that is needed to verify its session, for transports that
+// do not use http.Request, like gRPC.
+type RequestData struct {
+	Context context.Context
+	// Method is the HTTP method of the request. It defaults to POST, which is what RPCs use.
+	Method string
+	// Path identifies the called API, like the full method name of a gRPC call.
+	Path string
+	// Header holds the headers of the request, or the metadata of an RPC. The tokens are read
+	// from the Authorization and Cookie entries.
+	Header http.Header
+}
+
+type VerifierConfig struct {
+	// Keys is a static list of the keys that access tokens are signed with.
+	Keys []KeyInfo
+	// GetKeys fetches the list of keys, for example from the core or a JWKS endpoint. It is
+	// called on the first verification, every KeyRefreshInterval, and when a token is signed
+	// with an unknown key.
+	GetKeys            func(ctx context.Context) ([]KeyInfo, error)
+	KeyRefreshInterval *time.Duration
+	// ClockSkew is added to the expiry time of access tokens, to allow for clocks
+	// that are not in sync with the core.
+	ClockSkew *time.Duration
+	// AntiCsrf is checked for tokens sent as cookies. It must be one of 'NONE',
+	// 'VIA_CUSTOM_HEADER' (the default) or 'VIA_TOKEN'.
+	AntiCsrf                 *string
+	SessionExpiredStatusCode *int
+}
+
+type VerifiedAccessToken struct {
+	SessionHandle      string
+	UserID             string
+	AccessTokenPayload map[string]interface{}
+	ExpiryTime         uint64
+	TimeCreated        uint64
+}
@@ -0,0 +1,239 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func initForTokenTransferTest(t *testing.T, config *sessmodels.TypeInput) *httptest.Server {
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(config),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSessionWithContext(rw, "userId", map[string]interface{}{}, map[string]interface{}{}, supertokens.MakeDefaultUserContextFromAPI(r))
+		assert.NoError(t, err)
+	})
+	mux.HandleFunc("/protected", VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(GetSessionFromRequestContext(r.Context()).GetUserID()))
+	}))
+	return httptest.NewServer(supertokens.Middleware(mux))
+}
+
+func sendWithBearerToken(t *testing.T, method string, url string, token string) *http.Response {
+	req, err := http.NewRequest(method, url, nil)
+	assert.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestSessionWithHeaderBasedTokenTransfer(t *testing.T) {
+	antiCsrf := "VIA_TOKEN"
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, &sessmodels.TypeInput{
+		AntiCsrf: &antiCsrf,
+	})
+	defer testServer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/create", nil)
+	assert.NoError(t, err)
+	req.Header.Set("st-auth-mode", "header")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Values("Set-Cookie"))
+	assert.Empty(t, res.Header.Get("anti-csrf"))
+	assert.NotEmpty(t, res.Header.Get("front-token"))
+	accessToken := res.Header.Get("st-access-token")
+	refreshToken := res.Header.Get("st-refresh-token")
+	assert.NotEmpty(t, accessToken)
+	assert.NotEmpty(t, refreshToken)
+
+	// no anti-csrf token is needed for tokens sent in headers
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", string(body))
+
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Values("Set-Cookie"))
+	newAccessToken := res.Header.Get("st-access-token")
+	assert.NotEmpty(t, newAccessToken)
+	assert.NotEqual(t, accessToken, newAccessToken)
+	assert.NotEmpty(t, res.Header.Get("st-refresh-token"))
+
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/signout", newAccessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Values("Set-Cookie"))
+	assert.Contains(t, res.Header.Values("st-access-token"), "")
+	assert.Equal(t, "remove", res.Header.Get("front-token"))
+
+	res = sendWithBearerToken(t, http.MethodPost, testServer.URL+"/auth/session/refresh", refreshToken)
+	assert.Equal(t, 401, res.StatusCode)
+}
+
+func TestSessionsAreCreatedWithCookiesByDefault(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Get("st-access-token"))
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+	assert.NotEmpty(t, cookieData["sAccessToken"])
+	assert.NotEmpty(t, cookieData["sIdRefreshToken"])
+}
+
+func TestAuthorizationHeaderIsIgnoredInCookieMode(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, &sessmodels.TypeInput{
+		GetTokenTransferMethod: func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+			if req != nil && req.URL.Path == "/create" {
+				return sessmodels.HeaderTransferMethod
+			}
+			return sessmodels.CookieTransferMethod
+		},
+	})
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	accessToken := res.Header.Get("st-access-token")
+	assert.NotEmpty(t, accessToken)
+
+	res = sendWithBearerToken(t, http.MethodGet, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 401, res.StatusCode)
+}
+
+func TestRefreshUsesCookiesIfAuthorizationHeaderHasNoRefreshToken(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+	assert.NotEmpty(t, cookieData["sRefreshToken"])
+
+	// a JWT of another service, for example one sent by an API gateway
+	foreignToken := "eyJhbGciOiJIUzI1NiJ9." + base64.StdEncoding.EncodeToString([]byte(`{"sub":"other"}`)) + ".c2lnbmF0dXJl"
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/session/refresh", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Cookie", "sRefreshToken="+cookieData["sRefreshToken"]+";sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	req.Header.Set("anti-csrf", cookieData["antiCsrf"])
+	req.Header.Set("Authorization", "Bearer "+foreignToken)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Get("st-access-token"))
+	refreshedCookieData := unittesting.ExtractInfoFromResponse(res)
+	assert.NotEmpty(t, refreshedCookieData["sAccessToken"])
+	assert.NotEqual(t, cookieData["sRefreshToken"], refreshedCookieData["sRefreshToken"])
+}
+
+func TestRefreshUsesCookiesIfAuthorizationHeaderHasAnOpaqueToken(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	// refresh tokens can not be told apart from other opaque tokens, so only cookies are used
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/session/refresh", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Cookie", "sRefreshToken="+cookieData["sRefreshToken"]+";sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	req.Header.Set("anti-csrf", cookieData["antiCsrf"])
+	req.Header.Set("Authorization", "Bearer some-api-key")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Empty(t, res.Header.Get("st-access-token"))
+	assert.NotEmpty(t, unittesting.ExtractInfoFromResponse(res)["sAccessToken"])
+}
+
+func TestGetSessionUsesCookiesIfAuthorizationHeaderHasAForeignJWT(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	foreignToken := "eyJhbGciOiJIUzI1NiJ9." + base64.StdEncoding.EncodeToString([]byte(`{"sub":"other"}`)) + ".c2lnbmF0dXJl"
+	req, err := http.NewRequest(http.MethodGet, testServer.URL+"/protected", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	req.Header.Set("Authorization", "Bearer "+foreignToken)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", string(body))
+
+	// the frontend asks for the header to be used
+	req.Header.Set("st-auth-mode", "header")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+}
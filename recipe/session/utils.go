@@ -18,12 +18,14 @@ package session
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessionwithjwt"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -116,33 +118,36 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	errorHandlers := sessmodels.NormalisedErrorHandlers{
 		OnTokenTheftDetected: func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := GetRecipeInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(req))
 			if err != nil {
 				return err
 			}
 			return sendTokenTheftDetectedResponse(*recipeInstance, sessionHandle, userID, req, res)
 		},
 		OnTryRefreshToken: func(message string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := GetRecipeInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(req))
 			if err != nil {
 				return err
 			}
 			return sendTryRefreshTokenResponse(*recipeInstance, message, req, res)
 		},
 		OnUnauthorised: func(message string, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := GetRecipeInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(req))
 			if err != nil {
 				return err
 			}
 			return sendUnauthorisedResponse(*recipeInstance, message, req, res)
 		},
 		OnInvalidClaim: func(validationErrors []claims.ClaimValidationError, req *http.Request, res http.ResponseWriter) error {
-			recipeInstance, err := getRecipeInstanceOrThrowError()
+			recipeInstance, err := GetRecipeInstanceFromUserContextOrThrowError(supertokens.MakeDefaultUserContextFromAPI(req))
 			if err != nil {
 				return err
 			}
 			return sendInvalidClaimResponse(*recipeInstance, validationErrors, req, res)
 		},
+		OnMaxSessionsReached: func(userID string, req *http.Request, res http.ResponseWriter) error {
+			return sendMaxSessionsReachedResponse(userID, req, res)
+		},
 	}
 
 	if config != nil && config.ErrorHandlers != nil {
@@ -155,6 +160,9 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		if config.ErrorHandlers.OnInvalidClaim != nil {
 			errorHandlers.OnInvalidClaim = config.ErrorHandlers.OnInvalidClaim
 		}
+		if config.ErrorHandlers.OnMaxSessionsReached != nil {
+			errorHandlers.OnMaxSessionsReached = config.ErrorHandlers.OnMaxSessionsReached
+		}
 	}
 
 	IsAnIPAPIDomain, err := supertokens.IsAnIPAddress(topLevelAPIDomain)
@@ -191,6 +199,59 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		return sessmodels.TypeNormalisedInput{}, errors.New(sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY + " is a reserved property name, please use a different key name for the jwt")
 	}
 
+	sessionMetadata := sessmodels.NormalisedSessionMetadataConfig{
+		Enabled:        false,
+		TrustedProxies: []*net.IPNet{},
+	}
+	if config != nil && config.SessionMetadata != nil {
+		sessionMetadata.Enabled = config.SessionMetadata.Enable
+		sessionMetadata.TrustedProxies, err = normaliseTrustedProxies(config.SessionMetadata.TrustedProxies)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+		sessionMetadata.LookupLocation = config.SessionMetadata.LookupLocation
+	}
+
+	sessionPolicy := sessmodels.NormalisedSessionPolicyConfig{
+		OnSessionEvicted: func(session sessmodels.SessionInformation, reason sessmodels.SessionEvictionReason, userContext supertokens.UserContext) {
+		},
+	}
+	if config != nil && config.SessionPolicy != nil {
+		sessionPolicy.GetPolicy = config.SessionPolicy.GetPolicy
+		if config.SessionPolicy.OnSessionEvicted != nil {
+			sessionPolicy.OnSessionEvicted = config.SessionPolicy.OnSessionEvicted
+		}
+	}
+
+	tokenTheftPolicy := sessmodels.RevokeSessionOnTokenTheft
+	if config != nil && config.TokenTheftPolicy != "" {
+		if config.TokenTheftPolicy != sessmodels.RevokeSessionOnTokenTheft && config.TokenTheftPolicy != sessmodels.RevokeAllSessionsOnTokenTheft {
+			return sessmodels.TypeNormalisedInput{}, errors.New("TokenTheftPolicy must be one of REVOKE_SESSION or REVOKE_ALL_SESSIONS")
+		}
+		tokenTheftPolicy = config.TokenTheftPolicy
+	}
+
+	impersonation := sessmodels.NormalisedImpersonationConfig{
+		Lifetime: 15 * time.Minute,
+		ReadOnly: true,
+	}
+	if config != nil && config.Impersonation != nil {
+		impersonation.Enabled = true
+		impersonation.IsAllowed = config.Impersonation.IsAllowed
+		if config.Impersonation.Lifetime < 0 {
+			return sessmodels.TypeNormalisedInput{}, errors.New("Impersonation Lifetime must not be negative")
+		}
+		if config.Impersonation.Lifetime > 0 {
+			impersonation.Lifetime = config.Impersonation.Lifetime
+		}
+		impersonation.ReadOnly = !config.Impersonation.AllowWrites
+	}
+
+	getTokenTransferMethod := defaultGetTokenTransferMethod
+	if config != nil && config.GetTokenTransferMethod != nil {
+		getTokenTransferMethod = config.GetTokenTransferMethod
+	}
+
 	typeNormalisedInput := sessmodels.TypeNormalisedInput{
 		RefreshTokenPath:         appInfo.APIBasePath.AppendPath(refreshAPIPath),
 		CookieDomain:             cookieDomain,
@@ -201,6 +262,11 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		AntiCsrf:                 antiCsrf,
 		ErrorHandlers:            errorHandlers,
 		Jwt:                      Jwt,
+		GetTokenTransferMethod:   getTokenTransferMethod,
+		SessionMetadata:          sessionMetadata,
+		SessionPolicy:            sessionPolicy,
+		TokenTheftPolicy:         tokenTheftPolicy,
+		Impersonation:            impersonation,
 		Override: sessmodels.OverrideStruct{
 			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
 				return originalImplementation
@@ -296,11 +362,80 @@ func getCurrTimeInMS() uint64 {
 	return uint64(time.Now().UnixNano() / 1000000)
 }
 
-func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse) {
+// defaultGetTokenTransferMethod lets the frontend pick the transfer method of new sessions
+// via the st-auth-mode header, and accepts tokens from both cookies and headers.
+func defaultGetTokenTransferMethod(req *http.Request, forCreateNewSession bool, _ supertokens.UserContext) sessmodels.TokenTransferMethod {
+	if !forCreateNewSession || req == nil {
+		return sessmodels.AnyTransferMethod
+	}
+	authMode := getAuthModeFromHeader(req)
+	if authMode != nil {
+		switch sessmodels.TokenTransferMethod(strings.ToLower(*authMode)) {
+		case sessmodels.CookieTransferMethod:
+			return sessmodels.CookieTransferMethod
+		case sessmodels.HeaderTransferMethod:
+			return sessmodels.HeaderTransferMethod
+		}
+	}
+	return sessmodels.AnyTransferMethod
+}
+
+// getTransferMethodUsedByRequest returns the transfer method with which the request sent its
+// session tokens. Requests without tokens are treated as using cookies.
+func getTransferMethodUsedByRequest(config sessmodels.TypeNormalisedInput, req *http.Request, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+	allowedTransferMethod := config.GetTokenTransferMethod(req, false, userContext)
+	if allowedTransferMethod == sessmodels.CookieTransferMethod || getTokenFromAuthorizationHeader(req) == nil {
+		return sessmodels.CookieTransferMethod
+	}
+	if isHeaderTransferRequested(allowedTransferMethod, req) || !hasSessionCookies(req) {
+		return sessmodels.HeaderTransferMethod
+	}
+	return sessmodels.CookieTransferMethod
+}
+
+// isHeaderTransferRequested reports whether the session tokens of req are in the Authorization
+// header because the app only allows headers, or because the frontend asked for them via the
+// st-auth-mode header.
+func isHeaderTransferRequested(allowedTransferMethod sessmodels.TokenTransferMethod, req *http.Request) bool {
+	if allowedTransferMethod == sessmodels.HeaderTransferMethod {
+		return true
+	}
+	authMode := getAuthModeFromHeader(req)
+	return allowedTransferMethod == sessmodels.AnyTransferMethod && authMode != nil &&
+		sessmodels.TokenTransferMethod(strings.ToLower(*authMode)) == sessmodels.HeaderTransferMethod
+}
+
+func hasSessionCookies(req *http.Request) bool {
+	return getIDRefreshTokenFromCookie(req) != nil || getRefreshTokenFromCookie(req) != nil
+}
+
+func attachAccessToken(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64, transferMethod sessmodels.TokenTransferMethod) {
+	if transferMethod == sessmodels.HeaderTransferMethod {
+		setTokenInHeaders(res, accessTokenHeaderKey, token)
+	} else {
+		attachAccessTokenToCookie(config, res, token, expiry)
+	}
+}
+
+func clearSession(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, transferMethod sessmodels.TokenTransferMethod) {
+	if transferMethod == sessmodels.HeaderTransferMethod {
+		clearSessionFromHeaders(res)
+	} else {
+		clearSessionFromCookie(config, res)
+	}
+}
+
+func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse, transferMethod sessmodels.TokenTransferMethod) {
 	accessToken := response.AccessToken
 	refreshToken := response.RefreshToken
 	idRefreshToken := response.IDRefreshToken
 	setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
+	if transferMethod == sessmodels.HeaderTransferMethod {
+		// the id refresh token and anti-csrf token are only needed to protect cookies
+		setTokenInHeaders(res, accessTokenHeaderKey, accessToken.Token)
+		setTokenInHeaders(res, refreshTokenHeaderKey, refreshToken.Token)
+		return
+	}
 	attachAccessTokenToCookie(config, res, accessToken.Token, accessToken.Expiry)
 	attachRefreshTokenToCookie(config, res, refreshToken.Token, refreshToken.Expiry)
 	setIDRefreshTokenInHeaderAndCookie(config, res, idRefreshToken.Token, idRefreshToken.Expiry)
@@ -324,8 +459,18 @@ func sendInvalidClaimResponse(recipeInstance Recipe, claimValidationErrors []cla
 	})
 }
 
-func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string, _ string, _ *http.Request, response http.ResponseWriter) error {
-	_, err := (*recipeInstance.RecipeImpl.RevokeSession)(sessionHandle, &map[string]interface{}{})
+// sendMaxSessionsReachedResponse is the default OnMaxSessionsReached handler. Like the other
+// expected failures of the sign in APIs, it responds with HTTP 200 and a status of
+// MAX_SESSIONS_REACHED, so that the frontend can show the message to the user.
+func sendMaxSessionsReachedResponse(_ string, _ *http.Request, response http.ResponseWriter) error {
+	return supertokens.Send200Response(response, map[string]interface{}{
+		"status":  sessionErrors.MaxSessionsReachedErrorStr,
+		"message": "You are signed in on too many devices. Please sign out on one of them and try again.",
+	})
+}
+
+func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string, userID string, req *http.Request, response http.ResponseWriter) error {
+	err := revokeSessionsOnTokenTheft(recipeInstance, sessionHandle, userID, supertokens.MakeDefaultUserContextFromAPI(req))
 	if err != nil {
 		return err
 	}
@@ -374,7 +519,7 @@ func getRequiredClaimValidators(
 	overrideGlobalClaimValidators func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error),
 	userContext supertokens.UserContext,
 ) ([]claims.SessionClaimValidator, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -391,3 +536,14 @@ func getRequiredClaimValidators(
 	}
 	return globalClaimValidators, nil
 }
+
+// copyUserContext returns a shallow copy of userContext, for passing it to concurrent calls.
+func copyUserContext(userContext supertokens.UserContext) supertokens.UserContext {
+	result := map[string]interface{}{}
+	if userContext != nil {
+		for key, value := range *userContext {
+			result[key] = value
+		}
+	}
+	return &result
+}
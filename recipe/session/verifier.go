@@ -0,0 +1,363 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/json"
+	defaultErrors "errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	defaultVerifierKeyRefreshInterval = time.Hour
+	// keys are fetched at most this often because of tokens signed with unknown keys
+	minVerifierKeyRefreshInterval = time.Minute
+)
+
+var defaultJWKSHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+type verifiedAccessTokenContextKey int
+
+const verifiedAccessTokenKey verifiedAccessTokenContextKey = iota
+
+// Verifier verifies access tokens locally, without calling the core. Since the core is
+// not asked, revoked sessions and blacklisted access tokens are accepted until the access
+// token expires.
+type Verifier struct {
+	getKeys            func(ctx context.Context) ([]sessmodels.KeyInfo, error)
+	keyRefreshInterval time.Duration
+	clockSkew          time.Duration
+	antiCsrf           string
+	statusCode         int
+
+	keysLock      sync.RWMutex
+	keys          []sessmodels.KeyInfo
+	keysFetchedAt time.Time
+}
+
+func NewVerifier(config sessmodels.VerifierConfig) (*Verifier, error) {
+	if len(config.Keys) == 0 && config.GetKeys == nil {
+		return nil, defaultErrors.New("please provide either Keys or GetKeys to the verifier")
+	}
+	verifier := &Verifier{
+		getKeys:            config.GetKeys,
+		keyRefreshInterval: defaultVerifierKeyRefreshInterval,
+		antiCsrf:           antiCSRF_VIA_CUSTOM_HEADER,
+		statusCode:         401,
+		keys:               config.Keys,
+	}
+	if config.KeyRefreshInterval != nil {
+		verifier.keyRefreshInterval = *config.KeyRefreshInterval
+	}
+	if config.ClockSkew != nil {
+		verifier.clockSkew = *config.ClockSkew
+	}
+	if config.AntiCsrf != nil {
+		if *config.AntiCsrf != antiCSRF_NONE && *config.AntiCsrf != antiCSRF_VIA_CUSTOM_HEADER && *config.AntiCsrf != antiCSRF_VIA_TOKEN {
+			return nil, defaultErrors.New("antiCsrf config must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN'")
+		}
+		verifier.antiCsrf = *config.AntiCsrf
+	}
+	if config.SessionExpiredStatusCode != nil {
+		verifier.statusCode = *config.SessionExpiredStatusCode
+	}
+	return verifier, nil
+}
+
+// VerifyAccessToken returns a TryRefreshTokenError if the token is invalid or expired.
+func (v *Verifier) VerifyAccessToken(ctx context.Context, accessToken string) (*sessmodels.VerifiedAccessToken, error) {
+	keys, err := v.getCurrentKeys(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	accessTokenInfo, err := v.verifyWithKeys(accessToken, keys)
+	if err != nil {
+		return nil, err
+	}
+	if accessTokenInfo == nil {
+		// the token may be signed with a key that is newer than our list
+		keys, err = v.getCurrentKeys(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		accessTokenInfo, err = v.verifyWithKeys(accessToken, keys)
+		if err != nil {
+			return nil, err
+		}
+		if accessTokenInfo == nil {
+			return nil, errors.TryRefreshTokenError{Msg: "Access token is not signed with any of the known keys"}
+		}
+	}
+	return &sessmodels.VerifiedAccessToken{
+		SessionHandle:      accessTokenInfo.sessionHandle,
+		UserID:             accessTokenInfo.userID,
+		AccessTokenPayload: accessTokenInfo.userData,
+		ExpiryTime:         accessTokenInfo.expiryTime,
+		TimeCreated:        accessTokenInfo.timeCreated,
+	}, nil
+}
+
+// VerifyRequest verifies the access token sent in the Authorization header or in the
+// sAccessToken cookie of the request. It returns an UnauthorizedError if there is no token.
+func (v *Verifier) VerifyRequest(req *http.Request) (*sessmodels.VerifiedAccessToken, error) {
+	accessToken := getTokenFromAuthorizationHeader(req)
+	fromCookie := false
+	if accessToken == nil {
+		accessToken = getAccessTokenFromCookie(req)
+		fromCookie = true
+	}
+	if accessToken == nil {
+		if fromCookie && getIDRefreshTokenFromCookie(req) != nil {
+			return nil, errors.TryRefreshTokenError{Msg: "Access token has expired. Please call the refresh API"}
+		}
+		return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the access token in the request?"}
+	}
+
+	verified, err := v.VerifyAccessToken(req.Context(), *accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromCookie && req.Method != http.MethodGet {
+		if v.antiCsrf == antiCSRF_VIA_CUSTOM_HEADER && !frontendHasInterceptor(req) {
+			return nil, errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request"}
+		}
+		if v.antiCsrf == antiCSRF_VIA_TOKEN {
+			payload, err := getPayloadWithoutVerifying(*accessToken)
+			if err != nil {
+				return nil, errors.TryRefreshTokenError{Msg: err.Error()}
+			}
+			antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
+			expected := sanitizeStringInput(payload["antiCsrfToken"])
+			if antiCsrfToken == nil || expected == nil || *antiCsrfToken != *expected {
+				return nil, errors.TryRefreshTokenError{Msg: "anti-csrf check failed"}
+			}
+		}
+	}
+	return verified, nil
+}
+
+// VerifySession is a net/http middleware that never calls the core. The verified token
+// can be read in the handler with GetVerifiedAccessTokenFromRequestContext.
+func (v *Verifier) VerifySession(otherHandler http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		verified, err := v.VerifyRequest(r)
+		if err != nil {
+			if defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
+				supertokens.SendNon200ResponseWithMessage(rw, "try refresh token", v.statusCode)
+			} else if defaultErrors.As(err, &errors.UnauthorizedError{}) {
+				supertokens.SendNon200ResponseWithMessage(rw, "unauthorised", v.statusCode)
+			} else {
+				supertokens.SendNon200ResponseWithMessage(rw, err.Error(), 500)
+			}
+			return
+		}
+		ctx := context.WithValue(r.Context(), verifiedAccessTokenKey, verified)
+		otherHandler.ServeHTTP(rw, r.WithContext(ctx))
+	}
+}
+
+func GetVerifiedAccessTokenFromRequestContext(ctx context.Context) *sessmodels.VerifiedAccessToken {
+	value := ctx.Value(verifiedAccessTokenKey)
+	if value == nil {
+		return nil
+	}
+	return value.(*sessmodels.VerifiedAccessToken)
+}
+
+// verifyWithKeys returns nil if the token is not signed with any of the keys.
+func (v *Verifier) verifyWithKeys(accessToken string, keys []sessmodels.KeyInfo) (*accessTokenInfoStruct, error) {
+	now := getCurrTimeInMS()
+	for _, key := range keys {
+		if key.ExpiryTime != 0 && key.ExpiryTime < now {
+			continue
+		}
+		payload, err := verifyJWTAndGetPayload(accessToken, key.PublicKey)
+		if err != nil {
+			continue
+		}
+		accessTokenInfo, err := getInfoFromAccessTokenPayload(payload, false)
+		if err != nil {
+			return nil, err
+		}
+		if accessTokenInfo.expiryTime+uint64(v.clockSkew.Milliseconds()) < now {
+			return nil, errors.TryRefreshTokenError{Msg: "Access token expired"}
+		}
+		return accessTokenInfo, nil
+	}
+	return nil, nil
+}
+
+func (v *Verifier) getCurrentKeys(ctx context.Context, unknownKey bool) ([]sessmodels.KeyInfo, error) {
+	if v.getKeys == nil {
+		return v.keys, nil
+	}
+
+	v.keysLock.RLock()
+	keys := v.keys
+	sinceFetch := time.Since(v.keysFetchedAt)
+	v.keysLock.RUnlock()
+	if !v.needsKeyRefresh(keys, sinceFetch, unknownKey) {
+		return keys, nil
+	}
+
+	v.keysLock.Lock()
+	defer v.keysLock.Unlock()
+	// check again in case the keys were fetched while we were waiting for the lock
+	if !v.needsKeyRefresh(v.keys, time.Since(v.keysFetchedAt), unknownKey) {
+		return v.keys, nil
+	}
+	newKeys, err := v.getKeys(ctx)
+	if err != nil {
+		if len(v.keys) > 0 {
+			// keep using the old keys until the source is reachable again
+			supertokens.LogDebugMessage("verifier: could not refresh keys: " + err.Error())
+			return v.keys, nil
+		}
+		return nil, err
+	}
+	v.keys = newKeys
+	v.keysFetchedAt = time.Now()
+	return v.keys, nil
+}
+
+func (v *Verifier) needsKeyRefresh(keys []sessmodels.KeyInfo, sinceFetch time.Duration, unknownKey bool) bool {
+	if len(keys) == 0 || sinceFetch >= v.keyRefreshInterval {
+		return true
+	}
+	return unknownKey && sinceFetch >= minVerifierKeyRefreshInterval
+}
+
+// KeysFromCore returns a key source for the verifier that fetches the keys from the core
+// of the instance, or of the default instance if it is nil.
+func KeysFromCore(instance *supertokens.Instance) func(ctx context.Context) ([]sessmodels.KeyInfo, error) {
+	return func(ctx context.Context) ([]sessmodels.KeyInfo, error) {
+		// the default instance is looked up on every call, since it may change after the key source is made
+		currentInstance := instance
+		if currentInstance == nil {
+			currentInstance = supertokens.GetDefaultInstance()
+		}
+		if currentInstance == nil {
+			return nil, defaultErrors.New("please call the supertokens.init function before using SuperTokens")
+		}
+		querier, err := currentInstance.GetQuerier(RECIPE_ID)
+		if err != nil {
+			return nil, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/handshake", nil)
+		if err != nil {
+			return nil, err
+		}
+		keyList := getKeyInfoFromJson(response)
+		if len(keyList) == 0 {
+			publicKey, ok := response["jwtSigningPublicKey"].(string)
+			if !ok {
+				return nil, defaultErrors.New("the handshake response of the core does not contain any signing key")
+			}
+			expiryTime, ok := response["jwtSigningPublicKeyExpiryTime"].(float64)
+			if !ok {
+				return nil, defaultErrors.New("the handshake response of the core does not contain the expiry time of the signing key")
+			}
+			keyList = []sessmodels.KeyInfo{
+				{
+					PublicKey:  publicKey,
+					ExpiryTime: uint64(expiryTime),
+					CreatedAt:  getCurrTimeInMS(),
+				},
+			}
+		}
+		return keyList, nil
+	}
+}
+
+// KeysFromJWKS returns a key source for the verifier that reads the RSA keys of a JWKS endpoint.
+// If client is nil, a client with a timeout of 10 seconds is used.
+func KeysFromJWKS(jwksURL string, client *http.Client) func(ctx context.Context) ([]sessmodels.KeyInfo, error) {
+	if client == nil {
+		client = defaultJWKSHTTPClient
+	}
+	return func(ctx context.Context) ([]sessmodels.KeyInfo, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("JWKS endpoint returned response with status `%s` and body `%s`", resp.Status, string(body))
+		}
+
+		var jwks struct {
+			Keys []struct {
+				Kty string `json:"kty"`
+				N   string `json:"n"`
+				E   string `json:"e"`
+			} `json:"keys"`
+		}
+		err = json.Unmarshal(body, &jwks)
+		if err != nil {
+			return nil, err
+		}
+
+		keyList := []sessmodels.KeyInfo{}
+		for _, key := range jwks.Keys {
+			if key.Kty != "RSA" {
+				continue
+			}
+			n, err := b64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := b64.RawURLEncoding.DecodeString(key.E)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, err := x509.MarshalPKIXPublicKey(&rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			})
+			if err != nil {
+				return nil, err
+			}
+			keyList = append(keyList, sessmodels.KeyInfo{
+				PublicKey: b64.StdEncoding.EncodeToString(publicKey),
+			})
+		}
+		return keyList, nil
+	}
+}
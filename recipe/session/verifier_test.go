@@ -0,0 +1,164 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+// createSessionForVerifierTest returns an access token of a new session and the keys of the core.
+func createSessionForVerifierTest(t *testing.T) (string, []sessmodels.KeyInfo) {
+	testServer := initForTokenTransferTest(t, nil)
+	defer testServer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/create", nil)
+	assert.NoError(t, err)
+	req.Header.Set("st-auth-mode", "header")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	accessToken := res.Header.Get("st-access-token")
+	assert.NotEmpty(t, accessToken)
+
+	keys, err := KeysFromCore(nil)(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, keys)
+	return accessToken, keys
+}
+
+func makeOtherKeyForVerifierTest(t *testing.T) sessmodels.KeyInfo {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+	return sessmodels.KeyInfo{PublicKey: b64.StdEncoding.EncodeToString(publicKey)}
+}
+
+func TestVerifierWithStaticKeys(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	accessToken, keys := createSessionForVerifierTest(t)
+
+	verifier, err := NewVerifier(sessmodels.VerifierConfig{
+		Keys: keys,
+	})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/protected", verifier.VerifySession(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(GetVerifiedAccessTokenFromRequestContext(r.Context()).UserID))
+	}))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	res := sendWithBearerToken(t, http.MethodGet, testServer.URL+"/protected", accessToken)
+	assert.Equal(t, 200, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", string(body))
+
+	res = sendWithBearerToken(t, http.MethodGet, testServer.URL+"/protected", "")
+	assert.Equal(t, 401, res.StatusCode)
+	assert.Equal(t, "unauthorised", (*unittesting.HttpResponseToConsumableInformation(res.Body))["message"])
+
+	res = sendWithBearerToken(t, http.MethodGet, testServer.URL+"/protected", accessToken+"a")
+	assert.Equal(t, 401, res.StatusCode)
+	assert.Equal(t, "try refresh token", (*unittesting.HttpResponseToConsumableInformation(res.Body))["message"])
+
+	// tokens in cookies need the custom header for anti-csrf by default
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/protected", nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "sAccessToken", Value: url.QueryEscape(accessToken)})
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+	req.Header.Set("rid", "session")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestVerifierRefreshesKeysForUnknownKeys(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	accessToken, keys := createSessionForVerifierTest(t)
+
+	// serve the keys of the core as a JWKS, after a key the token is not signed with
+	fetchCount := 0
+	otherKey := makeOtherKeyForVerifierTest(t)
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fetchCount++
+		jwks := []map[string]interface{}{}
+		for _, key := range append([]sessmodels.KeyInfo{otherKey}, keys...) {
+			der, err := b64.StdEncoding.DecodeString(key.PublicKey)
+			assert.NoError(t, err)
+			publicKey, err := x509.ParsePKIXPublicKey(der)
+			assert.NoError(t, err)
+			rsaKey := publicKey.(*rsa.PublicKey)
+			jwks = append(jwks, map[string]interface{}{
+				"kty": "RSA",
+				"n":   b64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   b64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{"keys": jwks})
+	}))
+	defer jwksServer.Close()
+
+	verifier, err := NewVerifier(sessmodels.VerifierConfig{
+		Keys:    []sessmodels.KeyInfo{otherKey},
+		GetKeys: KeysFromJWKS(jwksServer.URL, nil),
+	})
+	assert.NoError(t, err)
+	verified, err := verifier.VerifyAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", verified.UserID)
+	assert.Equal(t, 1, fetchCount)
+
+	// a second unknown key does not lead to another fetch right away
+	_, err = verifier.VerifyAccessToken(context.Background(), "a.b.c")
+	assert.ErrorAs(t, err, &errors.TryRefreshTokenError{})
+	assert.Equal(t, 1, fetchCount)
+}
+
+func TestVerifierNeedsKeys(t *testing.T) {
+	_, err := NewVerifier(sessmodels.VerifierConfig{})
+	assert.Error(t, err)
+
+	antiCsrf := "INVALID"
+	_, err = NewVerifier(sessmodels.VerifierConfig{
+		Keys:     []sessmodels.KeyInfo{{PublicKey: "key"}},
+		AntiCsrf: &antiCsrf,
+	})
+	assert.Error(t, err)
+}
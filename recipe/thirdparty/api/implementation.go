@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/derekstavis/go-qs"
+	"github.com/supertokens/supertokens-golang/recipe/accountlinking"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
@@ -150,7 +151,7 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 		}
 
 		if emailInfo.IsVerified {
-			evInstance := emailverification.GetRecipeInstance()
+			evInstance := emailverification.GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext))
 			if evInstance != nil {
 				tokenResponse, err := (*evInstance.RecipeImpl.CreateEmailVerificationToken)(response.OK.User.ID, response.OK.User.Email, userContext)
 				if err != nil {
@@ -165,7 +166,11 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 			}
 		}
 
-		session, err := session.CreateNewSessionWithContext(options.Res, response.OK.User.ID, nil, nil, userContext)
+		sessionUserID, err := accountlinking.GetPrimaryUserIdAfterSignInUp(response.OK.User.ID, userContext)
+		if err != nil {
+			return tpmodels.SignInUpPOSTResponse{}, err
+		}
+		session, err := session.CreateNewSessionOrReauthenticateWithContext(options.Req, options.Res, sessionUserID, nil, nil, userContext)
 		if err != nil {
 			return tpmodels.SignInUpPOSTResponse{}, err
 		}
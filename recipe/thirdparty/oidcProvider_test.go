@@ -0,0 +1,265 @@
+/*
+ * Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+// fakeOIDCIssuer serves the discovery document, keys, token and userinfo endpoints
+// of an identity provider. The token endpoint returns an id_token with idTokenClaims.
+type fakeOIDCIssuer struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	idTokenClaims jwt.MapClaims
+	userInfo      map[string]interface{}
+}
+
+func newFakeOIDCIssuer(t *testing.T) *fakeOIDCIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	issuer := &fakeOIDCIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"userinfo_endpoint":      issuer.server.URL + "/userinfo",
+			"jwks_uri":               issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(rw http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"keys": []map[string]interface{}{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, r *http.Request) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.idTokenClaims)
+		token.Header["kid"] = "test-key"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			rw.WriteHeader(500)
+			return
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"id_token":     idToken,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			rw.WriteHeader(401)
+			return
+		}
+		json.NewEncoder(rw).Encode(issuer.userInfo)
+	})
+	issuer.server = httptest.NewServer(mux)
+	return issuer
+}
+
+func initWithOIDCProvider(t *testing.T, config tpmodels.OIDCConfig) *httptest.Server {
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+			Init(
+				&tpmodels.TypeInput{
+					SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
+						Providers: []tpmodels.TypeProvider{
+							OIDC(config),
+						},
+					},
+				},
+			),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+}
+
+func signInUpWithOIDC(t *testing.T, testServer *httptest.Server) (int, map[string]interface{}) {
+	postBody, err := json.Marshal(map[string]string{
+		"thirdPartyId": "keycloak",
+		"code":         "abcdefghj",
+		"redirectURI":  "http://127.0.0.1/callback",
+	})
+	assert.NoError(t, err)
+	resp, err := http.Post(testServer.URL+"/auth/signinup", "application/json", bytes.NewBuffer(postBody))
+	assert.NoError(t, err)
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, *unittesting.HttpResponseToConsumableInformation(resp.Body)
+}
+
+func TestOIDCProviderUsesTheDiscoveryDocument(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+
+	testServer := initWithOIDCProvider(t, tpmodels.OIDCConfig{
+		ID:           "keycloak",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Issuer:       issuer.server.URL,
+	})
+	defer testServer.Close()
+
+	recipe, err := GetRecipeInstanceOrThrowError()
+	assert.NoError(t, err)
+	providerInfo := recipe.Providers[0].Get(nil, nil, nil)
+	assert.Equal(t, issuer.server.URL+"/token", providerInfo.AccessTokenAPI.URL)
+	assert.Equal(t, issuer.server.URL+"/authorize", providerInfo.AuthorisationRedirect.URL)
+	assert.Equal(t, map[string]interface{}{
+		"scope":         "openid email",
+		"response_type": "code",
+		"client_id":     "client",
+	}, providerInfo.AuthorisationRedirect.Params)
+}
+
+func TestOIDCProviderSignInUpWithVerifiedIdToken(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+	issuer.idTokenClaims = jwt.MapClaims{
+		"iss": issuer.server.URL,
+		"aud": []string{"client", "other-client"},
+		"sub": "oidc-user",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	// the email is only returned by the userinfo endpoint
+	issuer.userInfo = map[string]interface{}{
+		"sub":           "oidc-user",
+		"mail":          "test@example.com",
+		"mail_verified": "true",
+	}
+
+	testServer := initWithOIDCProvider(t, tpmodels.OIDCConfig{
+		ID:           "keycloak",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Issuer:       issuer.server.URL,
+		UserInfoMapping: &tpmodels.OIDCUserInfoMapping{
+			Email:         "mail",
+			EmailVerified: "mail_verified",
+		},
+	})
+	defer testServer.Close()
+
+	status, result := signInUpWithOIDC(t, testServer)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "OK", result["status"])
+	user := result["user"].(map[string]interface{})
+	assert.Equal(t, "test@example.com", user["email"])
+	assert.Equal(t, "keycloak", user["thirdParty"].(map[string]interface{})["id"])
+	assert.Equal(t, "oidc-user", user["thirdParty"].(map[string]interface{})["userId"])
+}
+
+func TestOIDCProviderRejectsIdTokenForAnotherClient(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	issuer := newFakeOIDCIssuer(t)
+	defer issuer.server.Close()
+	issuer.idTokenClaims = jwt.MapClaims{
+		"iss":   issuer.server.URL,
+		"aud":   "other-client",
+		"sub":   "oidc-user",
+		"email": "test@example.com",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+
+	testServer := initWithOIDCProvider(t, tpmodels.OIDCConfig{
+		ID:       "keycloak",
+		ClientID: "client",
+		DiscoveryDocument: &tpmodels.OIDCDiscoveryDocument{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JWKSURI:               issuer.server.URL + "/keys",
+		},
+	})
+	defer testServer.Close()
+
+	status, _ := signInUpWithOIDC(t, testServer)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestOIDCProviderReturnsAnErrorIfTheDiscoveryDocumentCanNotBeFetched(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	issuer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer issuer.Close()
+
+	testServer := initWithOIDCProvider(t, tpmodels.OIDCConfig{
+		ID:           "keycloak",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Issuer:       issuer.URL,
+	})
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/auth/authorisationurl?thirdPartyId=keycloak")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	status, _ := signInUpWithOIDC(t, testServer)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
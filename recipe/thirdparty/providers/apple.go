@@ -112,7 +112,7 @@ func Apple(config tpmodels.AppleConfig) tpmodels.TypeProvider {
 					return config.ClientID
 				},
 				GetRedirectURI: func(userContext supertokens.UserContext) (string, error) {
-					supertokens, err := supertokens.GetInstanceOrThrowError()
+					supertokens, err := supertokens.GetInstanceFromUserContextOrThrowError(userContext)
 					if err != nil {
 						return "", err
 					}
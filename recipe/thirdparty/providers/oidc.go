@@ -0,0 +1,269 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func OIDC(config tpmodels.OIDCConfig) tpmodels.TypeProvider {
+	mapping := tpmodels.OIDCUserInfoMapping{
+		UserID:        "sub",
+		Email:         "email",
+		EmailVerified: "email_verified",
+	}
+	if config.UserInfoMapping != nil {
+		if config.UserInfoMapping.UserID != "" {
+			mapping.UserID = config.UserInfoMapping.UserID
+		}
+		if config.UserInfoMapping.Email != "" {
+			mapping.Email = config.UserInfoMapping.Email
+		}
+		if config.UserInfoMapping.EmailVerified != "" {
+			mapping.EmailVerified = config.UserInfoMapping.EmailVerified
+		}
+	}
+
+	return tpmodels.TypeProvider{
+		ID: config.ID,
+		Get: func(redirectURI, authCodeFromRequest *string, userContext supertokens.UserContext) tpmodels.TypeProviderGetResponse {
+			discoveryDocument, err := getOIDCDiscoveryDocument(config)
+			if err != nil {
+				// Get can not return the error, so the APIs get it from GetRedirectURI or GetProfileInfo,
+				// before they use the endpoints of the discovery document.
+				return tpmodels.TypeProviderGetResponse{
+					GetProfileInfo: func(authCodeResponse interface{}, userContext supertokens.UserContext) (tpmodels.UserInfo, error) {
+						return tpmodels.UserInfo{}, err
+					},
+					GetClientId: func(userContext supertokens.UserContext) string {
+						return config.ClientID
+					},
+					GetRedirectURI: func(userContext supertokens.UserContext) (string, error) {
+						return "", err
+					},
+				}
+			}
+
+			accessTokenAPIParams := map[string]string{
+				"client_id":     config.ClientID,
+				"client_secret": config.ClientSecret,
+				"grant_type":    "authorization_code",
+			}
+			if authCodeFromRequest != nil {
+				accessTokenAPIParams["code"] = *authCodeFromRequest
+			}
+			if redirectURI != nil {
+				accessTokenAPIParams["redirect_uri"] = *redirectURI
+			}
+
+			scopes := []string{"openid", "email"}
+			if config.Scope != nil {
+				scopes = config.Scope
+			}
+
+			var additionalParams map[string]interface{} = nil
+			if config.AuthorisationRedirect != nil && config.AuthorisationRedirect.Params != nil {
+				additionalParams = config.AuthorisationRedirect.Params
+			}
+
+			authorizationRedirectParams := map[string]interface{}{
+				"scope":         strings.Join(scopes, " "),
+				"response_type": "code",
+				"client_id":     config.ClientID,
+			}
+			for key, value := range additionalParams {
+				authorizationRedirectParams[key] = value
+			}
+
+			return tpmodels.TypeProviderGetResponse{
+				AccessTokenAPI: tpmodels.AccessTokenAPI{
+					URL:    discoveryDocument.TokenEndpoint,
+					Params: accessTokenAPIParams,
+				},
+				AuthorisationRedirect: tpmodels.AuthorisationRedirect{
+					URL:    discoveryDocument.AuthorizationEndpoint,
+					Params: authorizationRedirectParams,
+				},
+				GetProfileInfo: func(authCodeResponse interface{}, userContext supertokens.UserContext) (tpmodels.UserInfo, error) {
+					claims, err := getOIDCUserClaims(authCodeResponse, discoveryDocument, config.ClientID)
+					if err != nil {
+						return tpmodels.UserInfo{}, err
+					}
+
+					ID, ok := claims[mapping.UserID].(string)
+					if !ok || ID == "" {
+						return tpmodels.UserInfo{}, errors.New("Provider did not return the claim " + mapping.UserID)
+					}
+					email, _ := claims[mapping.Email].(string)
+					if email == "" {
+						return tpmodels.UserInfo{
+							ID: ID,
+						}, nil
+					}
+					// some providers send email_verified as a string
+					isVerified := false
+					switch value := claims[mapping.EmailVerified].(type) {
+					case bool:
+						isVerified = value
+					case string:
+						isVerified = value == "true"
+					}
+					return tpmodels.UserInfo{
+						ID: ID,
+						Email: &tpmodels.EmailStruct{
+							ID:         email,
+							IsVerified: isVerified,
+						},
+					}, nil
+				},
+				GetClientId: func(userContext supertokens.UserContext) string {
+					return config.ClientID
+				},
+			}
+		},
+		IsDefault: config.IsDefault,
+	}
+}
+
+var oidcDiscoveryDocuments = map[string]tpmodels.OIDCDiscoveryDocument{}
+var oidcDiscoveryDocumentsLock = sync.Mutex{}
+
+func getOIDCDiscoveryDocument(config tpmodels.OIDCConfig) (tpmodels.OIDCDiscoveryDocument, error) {
+	if config.DiscoveryDocument != nil {
+		return *config.DiscoveryDocument, nil
+	}
+	if config.Issuer == "" {
+		return tpmodels.OIDCDiscoveryDocument{}, errors.New("Please provide either the Issuer or the DiscoveryDocument for the " + config.ID + " provider")
+	}
+
+	oidcDiscoveryDocumentsLock.Lock()
+	document, ok := oidcDiscoveryDocuments[config.Issuer]
+	oidcDiscoveryDocumentsLock.Unlock()
+	if ok {
+		return document, nil
+	}
+
+	// the document is fetched without holding the lock, so that an issuer that does not respond
+	// does not block the other providers. Concurrent requests may fetch it more than once.
+	req, err := http.NewRequest("GET", strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return tpmodels.OIDCDiscoveryDocument{}, err
+	}
+	response, err := doGetRequest(req)
+	if err != nil {
+		return tpmodels.OIDCDiscoveryDocument{}, err
+	}
+	responseJson, err := json.Marshal(response)
+	if err != nil {
+		return tpmodels.OIDCDiscoveryDocument{}, err
+	}
+	err = json.Unmarshal(responseJson, &document)
+	if err != nil {
+		return tpmodels.OIDCDiscoveryDocument{}, err
+	}
+	if strings.TrimSuffix(document.Issuer, "/") != strings.TrimSuffix(config.Issuer, "/") {
+		return tpmodels.OIDCDiscoveryDocument{}, errors.New("the issuer in the discovery document does not match " + config.Issuer)
+	}
+
+	oidcDiscoveryDocumentsLock.Lock()
+	oidcDiscoveryDocuments[config.Issuer] = document
+	oidcDiscoveryDocumentsLock.Unlock()
+	return document, nil
+}
+
+// getOIDCUserClaims returns the claims of the verified id_token. Claims missing from the
+// id_token (some providers only put the subject in there) are taken from the userinfo endpoint.
+func getOIDCUserClaims(authCodeResponse interface{}, discoveryDocument tpmodels.OIDCDiscoveryDocument, clientId string) (map[string]interface{}, error) {
+	tokens, _ := authCodeResponse.(map[string]interface{})
+	claims := map[string]interface{}{}
+
+	if idToken, ok := tokens["id_token"].(string); ok {
+		verifiedClaims, err := verifyOIDCIdToken(idToken, discoveryDocument, clientId)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range verifiedClaims {
+			claims[key] = value
+		}
+	}
+
+	accessToken, ok := tokens["access_token"].(string)
+	if !ok || discoveryDocument.UserInfoEndpoint == "" {
+		if len(claims) == 0 {
+			return nil, errors.New("Provider did not return an id_token")
+		}
+		return claims, nil
+	}
+
+	req, err := http.NewRequest("GET", discoveryDocument.UserInfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	response, err := doGetRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	userInfo, _ := response.(map[string]interface{})
+	if sub, ok := claims["sub"]; ok && userInfo["sub"] != sub {
+		return nil, errors.New("the sub returned by the userinfo endpoint does not match the id_token")
+	}
+	for key, value := range userInfo {
+		if _, ok := claims[key]; !ok {
+			claims[key] = value
+		}
+	}
+	return claims, nil
+}
+
+func verifyOIDCIdToken(idToken string, discoveryDocument tpmodels.OIDCDiscoveryDocument, clientId string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if discoveryDocument.JWKSURI == "" {
+		return claims, errors.New("the discovery document does not have a jwks_uri")
+	}
+
+	jwks, err := getJWKSFromURL(discoveryDocument.JWKSURI)
+	if err != nil {
+		return claims, err
+	}
+
+	token, err := jwt.ParseWithClaims(idToken, claims, jwks.Keyfunc)
+	if err != nil {
+		return claims, err
+	}
+
+	if !token.Valid {
+		return claims, errors.New("invalid id_token supplied")
+	}
+
+	if !claims.VerifyIssuer(discoveryDocument.Issuer, true) {
+		return claims, errors.New("invalid iss field")
+	}
+
+	if !claims.VerifyAudience(clientId, true) {
+		return claims, errors.New("the client for whom this key is for is different than the one provided")
+	}
+
+	return claims, nil
+}
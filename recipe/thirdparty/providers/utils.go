@@ -27,8 +27,14 @@ import (
 	"github.com/MicahParks/keyfunc"
 )
 
+// providerRequestTimeout bounds the requests to the provider, so that a provider that does
+// not respond can not hold up the API calls waiting for it.
+const providerRequestTimeout = 10 * time.Second
+
 func doGetRequest(req *http.Request) (interface{}, error) {
-	client := &http.Client{}
+	client := &http.Client{
+		Timeout: providerRequestTimeout,
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -22,6 +22,9 @@ import (
 	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy"
+	"github.com/supertokens/supertokens-golang/recipe/multitenancy/multitenancymodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/api"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -37,8 +40,6 @@ type Recipe struct {
 	Providers    []tpmodels.TypeProvider
 }
 
-var singletonInstance *Recipe
-
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *tpmodels.TypeInput, emailDeliveryIngredient *emaildelivery.Ingredient, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	r := &Recipe{}
 
@@ -58,33 +59,68 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	r.Providers = config.SignInAndUpFeature.Providers
 
 	supertokens.AddPostInitCallback(func() error {
-		evRecipe := emailverification.GetRecipeInstance()
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return err
+		}
+		evRecipe := emailverification.GetRecipeInstanceFor(stInstance)
 		if evRecipe != nil {
 			evRecipe.AddGetEmailForUserIdFunc(r.getEmailForUserId)
 		}
+		sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+		if sessionRecipe != nil {
+			sessionRecipe.AddUserExistsFunc(r.userExists)
+		}
+		mtRecipe := multitenancy.GetRecipeInstanceFor(stInstance)
+		if mtRecipe != nil {
+			mtRecipe.AddStaticThirdPartyProviders(r.Providers)
+		}
 		return nil
 	})
 
 	return *r, nil
 }
 
+// GetRecipeInstanceFor returns the thirdparty recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
 func recipeInit(config *tpmodels.TypeInput) supertokens.Recipe {
 	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
-		if singletonInstance == nil {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
 			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, nil, onSuperTokensAPIError)
 			if err != nil {
 				return nil, err
 			}
-			singletonInstance = &recipe
-			return &singletonInstance.RecipeModule, nil
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+			return &recipe.RecipeModule, nil
 		}
 		return nil, errors.New("ThirdParty recipe has already been initialised. Please check your code for bugs.")
 	}
 }
 
 func GetRecipeInstanceOrThrowError() (*Recipe, error) {
-	if singletonInstance != nil {
-		return singletonInstance, nil
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
 	}
 	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
 }
@@ -123,12 +159,24 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 }
 
 func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path supertokens.NormalisedURLPath, method string) error {
+	// the apple redirect is sent by apple, so there is no tenant to check it against
+	if id != AppleRedirectHandlerAPI {
+		err := multitenancy.ValidateLoginMethodForRequest(req, multitenancymodels.LoginMethodThirdParty)
+		if err != nil {
+			return err
+		}
+	}
+	providers, err := multitenancy.GetThirdPartyProvidersForRequest(req, r.Providers)
+	if err != nil {
+		return err
+	}
+
 	options := tpmodels.APIOptions{
 		Config:               r.Config,
 		OtherHandler:         theirHandler,
 		RecipeID:             r.RecipeModule.GetRecipeID(),
 		RecipeImplementation: r.RecipeImpl,
-		Providers:            r.Providers,
+		Providers:            providers,
 		Req:                  req,
 		Res:                  res,
 		AppInfo:              r.RecipeModule.GetAppInfo(),
@@ -151,6 +199,14 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	return false, nil
 }
 
+func (r *Recipe) userExists(userID string, userContext supertokens.UserContext) (bool, error) {
+	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
+	if err != nil {
+		return false, err
+	}
+	return userInfo != nil, nil
+}
+
 func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserContext) (evmodels.TypeEmailInfo, error) {
 	userInfo, err := (*r.RecipeImpl.GetUserByID)(userID, userContext)
 	if err != nil {
@@ -169,5 +225,4 @@ func (r *Recipe) getEmailForUserId(userID string, userContext supertokens.UserCo
 }
 
 func ResetForTest() {
-	singletonInstance = nil
 }
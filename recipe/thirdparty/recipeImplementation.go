@@ -22,7 +22,7 @@ import (
 
 func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterface {
 	signInUp := func(thirdPartyID, thirdPartyUserID string, email string, userContext supertokens.UserContext) (tpmodels.SignInUpResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/signinup", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/signinup", map[string]interface{}{
 			"thirdPartyId":     thirdPartyID,
 			"thirdPartyUserId": thirdPartyUserID,
 			"email":            map[string]interface{}{"id": email},
@@ -34,6 +34,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 		if err != nil {
 			return tpmodels.SignInUpResponse{}, err
 		}
+		recordSignInUp(querier.GetInstrumentation(), userContext, response["createdNewUser"].(bool), thirdPartyID)
 		return tpmodels.SignInUpResponse{
 			OK: &struct {
 				CreatedNewUser bool
@@ -46,7 +47,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 	}
 
 	getUserByID := func(userID string, userContext supertokens.UserContext) (*tpmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"userId": userID,
 		})
 		if err != nil {
@@ -63,7 +64,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 	}
 
 	getUserByThirdPartyInfo := func(thirdPartyID, thirdPartyUserID string, userContext supertokens.UserContext) (*tpmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user", map[string]string{
 			"thirdPartyId":     thirdPartyID,
 			"thirdPartyUserId": thirdPartyUserID,
 		})
@@ -81,7 +82,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 	}
 
 	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tpmodels.User, error) {
-		response, err := querier.SendGetRequest("/recipe/users/by-email", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/users/by-email", map[string]string{
 			"email": email,
 		})
 		if err != nil {
@@ -101,3 +102,14 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 		SignInUp:                &signInUp,
 	}
 }
+
+func recordSignInUp(instrumentation supertokens.Instrumentation, userContext supertokens.UserContext, createdNewUser bool, thirdPartyID string) {
+	metric := supertokens.MetricSignIns
+	if createdNewUser {
+		metric = supertokens.MetricSignUps
+	}
+	instrumentation.AddToCounter(supertokens.GetContextFromUserContext(userContext), metric, 1, map[string]interface{}{
+		"supertokens.login_method":   "thirdparty",
+		"supertokens.third_party_id": thirdPartyID,
+	})
+}
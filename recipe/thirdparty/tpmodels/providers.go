@@ -78,3 +78,36 @@ type AppleClientSecret struct {
 	PrivateKey string
 	TeamId     string
 }
+
+type OIDCConfig struct {
+	// ID is the thirdPartyId used by the frontend for this provider, for example "okta".
+	ID           string
+	ClientID     string
+	ClientSecret string
+	Scope        []string
+	// Issuer is used to fetch the discovery document from
+	// {Issuer}/.well-known/openid-configuration, unless DiscoveryDocument is set.
+	Issuer            string
+	DiscoveryDocument *OIDCDiscoveryDocument
+	// UserInfoMapping maps claims of the id_token (or of the userinfo
+	// endpoint's response) to the user info. Unset fields use the standard claims.
+	UserInfoMapping       *OIDCUserInfoMapping
+	AuthorisationRedirect *struct {
+		Params map[string]interface{}
+	}
+	IsDefault bool
+}
+
+type OIDCDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type OIDCUserInfoMapping struct {
+	UserID        string
+	Email         string
+	EmailVerified string
+}
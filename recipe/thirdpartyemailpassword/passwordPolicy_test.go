@@ -0,0 +1,89 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+type inMemoryPasswordHistoryStore map[string][]string
+
+func (store inMemoryPasswordHistoryStore) GetPasswordHashes(userID string, userContext supertokens.UserContext) ([]string, error) {
+	return store[userID], nil
+}
+
+func (store inMemoryPasswordHistoryStore) SetPasswordHashes(userID string, hashes []string, userContext supertokens.UserContext) error {
+	store[userID] = hashes
+	return nil
+}
+
+func TestPasswordPolicyIsEnforcedWhenPasswordsAreSet(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+
+	store := inMemoryPasswordHistoryStore{}
+	testServer := supertokensInitForTest(t, session.Init(nil), Init(&tpepmodels.TypeInput{
+		PasswordPolicy: &epmodels.PasswordPolicy{
+			RequireNumber: true,
+			History:       &epmodels.PasswordHistory{Size: 2, Store: store},
+		},
+	}))
+	defer testServer.Close()
+
+	signUpResponse, err := EmailPasswordSignUp("test@example.com", "password1")
+	assert.NoError(t, err)
+	userId := signUpResponse.OK.User.ID
+	assert.Len(t, store[userId], 1)
+
+	newPassword := "password"
+	updateResponse, err := UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	if assert.NotNil(t, updateResponse.PasswordPolicyViolatedError) {
+		assert.Equal(t, "Password must contain at least one number", updateResponse.PasswordPolicyViolatedError.FailureReason)
+	}
+
+	newPassword = "password1"
+	updateResponse, err = UpdateEmailOrPassword(userId, nil, &newPassword)
+	assert.NoError(t, err)
+	if assert.NotNil(t, updateResponse.PasswordPolicyViolatedError) {
+		assert.Equal(t, "Password must be different from your last 2 passwords", updateResponse.PasswordPolicyViolatedError.FailureReason)
+	}
+
+	tokenResponse, err := CreateResetPasswordToken(userId)
+	assert.NoError(t, err)
+	resetResponse, err := ResetPasswordUsingToken(tokenResponse.OK.Token, "password1")
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.PasswordPolicyViolatedError)
+
+	tokenResponse, err = CreateResetPasswordToken(userId)
+	assert.NoError(t, err)
+	resetResponse, err = ResetPasswordUsingToken(tokenResponse.OK.Token, "password2")
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.OK)
+	assert.Len(t, store[userId], 2)
+
+	signInResponse, err := EmailPasswordSignIn("test@example.com", "password2")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+}
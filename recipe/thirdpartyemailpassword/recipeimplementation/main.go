@@ -27,9 +27,18 @@ import (
 )
 
 func MakeRecipeImplementation(emailPasswordQuerier supertokens.Querier, thirdPartyQuerier *supertokens.Querier) tpepmodels.RecipeInterface {
+	return MakeRecipeImplementationWithPasswordPolicy(emailPasswordQuerier, thirdPartyQuerier, nil)
+}
+
+// MakeRecipeImplementationWithPasswordPolicy is like MakeRecipeImplementation, but enforces the
+// normalised password policy when passwords are set.
+func MakeRecipeImplementationWithPasswordPolicy(emailPasswordQuerier supertokens.Querier, thirdPartyQuerier *supertokens.Querier, passwordPolicy *epmodels.PasswordPolicy) tpepmodels.RecipeInterface {
 	result := tpepmodels.RecipeInterface{}
 
 	emailPasswordImplementation := emailpassword.MakeRecipeImplementation(emailPasswordQuerier)
+	if passwordPolicy != nil {
+		emailPasswordImplementation = emailpassword.MakePasswordPolicyRecipeImplementation(emailPasswordImplementation, emailPasswordQuerier, passwordPolicy)
+	}
 	var thirdPartyImplementation *tpmodels.RecipeInterface
 	if thirdPartyQuerier != nil {
 		thirdPartyImplementationTemp := thirdparty.MakeRecipeImplementation(*thirdPartyQuerier)
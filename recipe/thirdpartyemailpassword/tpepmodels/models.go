@@ -40,6 +40,7 @@ type TypeInput struct {
 	SignUpFeature                  *epmodels.TypeInputSignUp
 	Providers                      []tpmodels.TypeProvider
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
+	PasswordPolicy                 *epmodels.PasswordPolicy
 	Override                       *OverrideStruct
 	EmailDelivery                  *emaildelivery.TypeInput
 }
@@ -48,6 +49,7 @@ type TypeNormalisedInput struct {
 	SignUpFeature                  *epmodels.TypeInputSignUp
 	Providers                      []tpmodels.TypeProvider
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
+	PasswordPolicy                 *epmodels.PasswordPolicy
 	Override                       OverrideStruct
 	GetEmailDeliveryConfig         func(recipeImpl RecipeInterface, epRecipeImpl epmodels.RecipeInterface) emaildelivery.TypeInputWithService
 }
@@ -39,6 +39,14 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = config.ResetPasswordUsingTokenFeature
 	}
 
+	if config != nil && config.PasswordPolicy != nil {
+		passwordPolicy, err := emailpassword.NormalisePasswordPolicy(*config.PasswordPolicy)
+		if err != nil {
+			return tpepmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordPolicy = passwordPolicy
+	}
+
 	typeNormalisedInput.GetEmailDeliveryConfig = func(recipeImpl tpepmodels.RecipeInterface, epRecipeImpl epmodels.RecipeInterface) emaildelivery.TypeInputWithService {
 		sendPasswordResetEmail := emailpassword.DefaultCreateAndSendCustomPasswordResetEmail(appInfo)
 		if config != nil && config.ResetPasswordUsingTokenFeature != nil && config.ResetPasswordUsingTokenFeature.CreateAndSendCustomEmail != nil {
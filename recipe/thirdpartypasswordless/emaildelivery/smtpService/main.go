@@ -49,3 +49,9 @@ func MakeSMTPService(config emaildelivery.SMTPServiceConfig) *emaildelivery.Emai
 		SendEmail: &sendEmail,
 	}
 }
+
+// MakeProviderService returns a service that sends emails with the HTTP API of an email provider,
+// like emaildelivery.SendGridProvider, instead of SMTP.
+func MakeProviderService(config emaildelivery.ProviderServiceConfig) *emaildelivery.EmailDeliveryInterface {
+	return MakeSMTPService(config.ToSMTPServiceConfig())
+}
@@ -25,8 +25,9 @@ import (
 )
 
 func makeServiceImplementation(settings emaildelivery.SMTPSettings) emaildelivery.SMTPInterface {
+	sender := emaildelivery.MakeSMTPSender(settings)
 	sendRawEmail := func(input emaildelivery.EmailContent, userContext supertokens.UserContext) error {
-		return emaildelivery.SendSMTPEmail(settings, input)
+		return sender.Send(input)
 	}
 
 	plessServiceImpl := plesssmtpService.MakeServiceImplementation(settings)
@@ -31,7 +31,7 @@ func Init(config tplmodels.TypeInput) supertokens.Recipe {
 }
 
 func ThirdPartySignInUpWithContext(thirdPartyID string, thirdPartyUserID string, email string, userContext supertokens.UserContext) (tplmodels.ThirdPartySignInUp, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return tplmodels.ThirdPartySignInUp{}, err
 	}
@@ -39,7 +39,7 @@ func ThirdPartySignInUpWithContext(thirdPartyID string, thirdPartyUserID string,
 }
 
 func GetUserByThirdPartyInfoWithContext(thirdPartyID string, thirdPartyUserID string, userContext supertokens.UserContext) (*tplmodels.User, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,7 @@ func GetUserByThirdPartyInfoWithContext(thirdPartyID string, thirdPartyUserID st
 }
 
 func GetUserByIdWithContext(userID string, userContext supertokens.UserContext) (*tplmodels.User, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,7 @@ func GetUserByIdWithContext(userID string, userContext supertokens.UserContext)
 }
 
 func GetUsersByEmailWithContext(email string, userContext supertokens.UserContext) ([]tplmodels.User, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +63,7 @@ func GetUsersByEmailWithContext(email string, userContext supertokens.UserContex
 }
 
 func CreateCodeWithEmailWithContext(email string, userInputCode *string, userContext supertokens.UserContext) (plessmodels.CreateCodeResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.CreateCodeResponse{}, err
 	}
@@ -74,7 +74,7 @@ func CreateCodeWithEmailWithContext(email string, userInputCode *string, userCon
 }
 
 func CreateCodeWithPhoneNumberWithContext(phoneNumber string, userInputCode *string, userContext supertokens.UserContext) (plessmodels.CreateCodeResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.CreateCodeResponse{}, err
 	}
@@ -85,7 +85,7 @@ func CreateCodeWithPhoneNumberWithContext(phoneNumber string, userInputCode *str
 }
 
 func CreateNewCodeForDeviceWithContext(deviceID string, userInputCode *string, userContext supertokens.UserContext) (plessmodels.ResendCodeResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.ResendCodeResponse{}, err
 	}
@@ -96,7 +96,7 @@ func CreateNewCodeForDeviceWithContext(deviceID string, userInputCode *string, u
 }
 
 func ConsumeCodeWithUserInputCodeWithContext(deviceID string, userInputCode string, preAuthSessionID string, userContext supertokens.UserContext) (tplmodels.ConsumeCodeResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return tplmodels.ConsumeCodeResponse{}, err
 	}
@@ -110,7 +110,7 @@ func ConsumeCodeWithUserInputCodeWithContext(deviceID string, userInputCode stri
 }
 
 func ConsumeCodeWithLinkCodeWithContext(linkCode string, preAuthSessionID string, userContext supertokens.UserContext) (tplmodels.ConsumeCodeResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return tplmodels.ConsumeCodeResponse{}, err
 	}
@@ -121,7 +121,7 @@ func ConsumeCodeWithLinkCodeWithContext(linkCode string, preAuthSessionID string
 }
 
 func GetUserByIDWithContext(userID string, userContext supertokens.UserContext) (*tplmodels.User, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +132,7 @@ func GetUserByIDWithContext(userID string, userContext supertokens.UserContext)
 }
 
 func GetUserByPhoneNumberWithContext(phoneNumber string, userContext supertokens.UserContext) (*tplmodels.User, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +143,7 @@ func GetUserByPhoneNumberWithContext(phoneNumber string, userContext supertokens
 }
 
 func UpdatePasswordlessUserWithContext(userID string, email *string, phoneNumber *string, userContext supertokens.UserContext) (plessmodels.UpdateUserResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.UpdateUserResponse{}, err
 	}
@@ -154,7 +154,7 @@ func UpdatePasswordlessUserWithContext(userID string, email *string, phoneNumber
 }
 
 func DeleteEmailForPasswordlessUserWithContext(userID string, userContext supertokens.UserContext) (plessmodels.DeleteUserResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.DeleteUserResponse{}, err
 	}
@@ -165,7 +165,7 @@ func DeleteEmailForPasswordlessUserWithContext(userID string, userContext supert
 }
 
 func DeletePhoneNumberForUserWithContext(userID string, userContext supertokens.UserContext) (plessmodels.DeleteUserResponse, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return plessmodels.DeleteUserResponse{}, err
 	}
@@ -176,7 +176,7 @@ func DeletePhoneNumberForUserWithContext(userID string, userContext supertokens.
 }
 
 func RevokeAllCodesByEmailWithContext(email string, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -187,7 +187,7 @@ func RevokeAllCodesByEmailWithContext(email string, userContext supertokens.User
 }
 
 func RevokeAllCodesByPhoneNumberWithContext(phoneNumber string, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -198,7 +198,7 @@ func RevokeAllCodesByPhoneNumberWithContext(phoneNumber string, userContext supe
 }
 
 func RevokeCodeWithContext(codeID string, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -209,7 +209,7 @@ func RevokeCodeWithContext(codeID string, userContext supertokens.UserContext) e
 }
 
 func ListCodesByEmailWithContext(email string, userContext supertokens.UserContext) ([]plessmodels.DeviceType, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return []plessmodels.DeviceType{}, err
 	}
@@ -220,7 +220,7 @@ func ListCodesByEmailWithContext(email string, userContext supertokens.UserConte
 }
 
 func ListCodesByPhoneNumberWithContext(phoneNumber string, userContext supertokens.UserContext) ([]plessmodels.DeviceType, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return []plessmodels.DeviceType{}, err
 	}
@@ -231,7 +231,7 @@ func ListCodesByPhoneNumberWithContext(phoneNumber string, userContext supertoke
 }
 
 func ListCodesByDeviceIDWithContext(deviceID string, userContext supertokens.UserContext) (*plessmodels.DeviceType, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -242,7 +242,7 @@ func ListCodesByDeviceIDWithContext(deviceID string, userContext supertokens.Use
 }
 
 func ListCodesByPreAuthSessionIDWithContext(preAuthSessionID string, userContext supertokens.UserContext) (*plessmodels.DeviceType, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +253,7 @@ func ListCodesByPreAuthSessionIDWithContext(preAuthSessionID string, userContext
 }
 
 func CreateMagicLinkByEmailWithContext(email string, userContext supertokens.UserContext) (string, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return "", err
 	}
@@ -264,7 +264,7 @@ func CreateMagicLinkByEmailWithContext(email string, userContext supertokens.Use
 }
 
 func CreateMagicLinkByPhoneNumberWithContext(phoneNumber string, userContext supertokens.UserContext) (string, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return "", err
 	}
@@ -279,7 +279,7 @@ func PasswordlessSignInUpByEmailWithContext(email string, userContext supertoken
 	CreatedNewUser   bool
 	User             tplmodels.User
 }, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return struct {
 			PreAuthSessionID string
@@ -320,7 +320,7 @@ func PasswordlessSignInUpByPhoneNumberWithContext(phoneNumber string, userContex
 	CreatedNewUser   bool
 	User             tplmodels.User
 }, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return struct {
 			PreAuthSessionID string
@@ -357,7 +357,7 @@ func PasswordlessSignInUpByPhoneNumberWithContext(phoneNumber string, userContex
 }
 
 func SendEmailWithContext(input emaildelivery.EmailType, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -365,7 +365,7 @@ func SendEmailWithContext(input emaildelivery.EmailType, userContext supertokens
 }
 
 func SendSmsWithContext(input smsdelivery.SmsType, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
@@ -492,10 +492,18 @@ func MakeSMTPService(config emaildelivery.SMTPServiceConfig) *emaildelivery.Emai
 	return smtpService.MakeSMTPService(config)
 }
 
+func MakeProviderService(config emaildelivery.ProviderServiceConfig) *emaildelivery.EmailDeliveryInterface {
+	return smtpService.MakeProviderService(config)
+}
+
 func MakeTwilioService(config smsdelivery.TwilioServiceConfig) (*smsdelivery.SmsDeliveryInterface, error) {
 	return twilioService.MakeTwilioService(config)
 }
 
+func MakeSMSProviderService(config smsdelivery.ProviderServiceConfig) *smsdelivery.SmsDeliveryInterface {
+	return twilioService.MakeProviderService(config)
+}
+
 func MakeSupertokensSMSService(apiKey string) *smsdelivery.SmsDeliveryInterface {
 	return supertokensService.MakeSupertokensSMSService(apiKey)
 }
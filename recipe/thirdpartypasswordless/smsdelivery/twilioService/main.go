@@ -37,3 +37,9 @@ func MakeTwilioService(config smsdelivery.TwilioServiceConfig) (*smsdelivery.Sms
 		SendSms: &sendSms,
 	}, nil
 }
+
+// MakeProviderService returns a service that sends SMSs with the API of an SMS provider, like
+// smsdelivery.VonageProvider, instead of Twilio.
+func MakeProviderService(config smsdelivery.ProviderServiceConfig) *smsdelivery.SmsDeliveryInterface {
+	return twilioService.MakeProviderService(config)
+}
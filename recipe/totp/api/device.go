@@ -0,0 +1,177 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func CreateDevice(apiImplementation totpmodels.APIInterface, options totpmodels.APIOptions) error {
+	if apiImplementation.CreateDevicePOST == nil || (*apiImplementation.CreateDevicePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	sessionContainer, err := getSessionToChangeDevices(options, userContext)
+	if err != nil {
+		return err
+	}
+
+	readBody, err := readBody(options)
+	if err != nil {
+		return err
+	}
+	deviceName := ""
+	if _, ok := readBody["deviceName"]; ok {
+		deviceName, err = getStringFromBody(readBody, "deviceName")
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := (*apiImplementation.CreateDevicePOST)(deviceName, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":       "OK",
+			"deviceName":   response.OK.DeviceName,
+			"secret":       response.OK.Secret,
+			"qrCodeString": response.OK.QRCodeString,
+		})
+	} else if response.DeviceAlreadyExistsError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "DEVICE_ALREADY_EXISTS_ERROR",
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func ListDevices(apiImplementation totpmodels.APIInterface, options totpmodels.APIOptions) error {
+	if apiImplementation.ListDevicesGET == nil || (*apiImplementation.ListDevicesGET) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	sessionContainer, err := getSessionWithMFACompleted(options, userContext)
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.ListDevicesGET)(sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":  "OK",
+			"devices": response.OK.Devices,
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func RemoveDevice(apiImplementation totpmodels.APIInterface, options totpmodels.APIOptions) error {
+	if apiImplementation.RemoveDevicePOST == nil || (*apiImplementation.RemoveDevicePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	sessionContainer, err := getSessionToChangeDevices(options, userContext)
+	if err != nil {
+		return err
+	}
+
+	readBody, err := readBody(options)
+	if err != nil {
+		return err
+	}
+	deviceName, err := getStringFromBody(readBody, "deviceName")
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.RemoveDevicePOST)(deviceName, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":         "OK",
+			"didDeviceExist": response.OK.DidDeviceExist,
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func VerifyDevice(apiImplementation totpmodels.APIInterface, options totpmodels.APIOptions) error {
+	if apiImplementation.VerifyDevicePOST == nil || (*apiImplementation.VerifyDevicePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	sessionContainer, err := getSessionToChangeDevices(options, userContext)
+	if err != nil {
+		return err
+	}
+
+	readBody, err := readBody(options)
+	if err != nil {
+		return err
+	}
+	deviceName, err := getStringFromBody(readBody, "deviceName")
+	if err != nil {
+		return err
+	}
+	totp, err := getStringFromBody(readBody, "totp")
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.VerifyDevicePOST)(deviceName, totp, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":             "OK",
+			"wasAlreadyVerified": response.OK.WasAlreadyVerified,
+		})
+	} else if response.UnknownDeviceError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNKNOWN_DEVICE_ERROR",
+		})
+	} else if response.InvalidTOTPError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "INVALID_TOTP_ERROR",
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
@@ -0,0 +1,139 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpclaims"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func MakeAPIImplementation() totpmodels.APIInterface {
+	createDevicePOST := func(deviceName string, sessionContainer sessmodels.SessionContainer, options totpmodels.APIOptions, userContext supertokens.UserContext) (totpmodels.CreateDevicePOSTResponse, error) {
+		userID := sessionContainer.GetUserIDWithContext(userContext)
+		if deviceName == "" {
+			devices, err := (*options.RecipeImplementation.ListDevices)(userID, userContext)
+			if err != nil {
+				return totpmodels.CreateDevicePOSTResponse{}, err
+			}
+			deviceName = getUnusedDeviceName(devices)
+		}
+
+		response, err := (*options.RecipeImplementation.CreateDevice)(userID, deviceName, userContext)
+		if err != nil {
+			return totpmodels.CreateDevicePOSTResponse{}, err
+		}
+		if response.DeviceAlreadyExistsError != nil {
+			return totpmodels.CreateDevicePOSTResponse{
+				DeviceAlreadyExistsError: response.DeviceAlreadyExistsError,
+			}, nil
+		}
+		return totpmodels.CreateDevicePOSTResponse{
+			OK: response.OK,
+		}, nil
+	}
+
+	listDevicesGET := func(sessionContainer sessmodels.SessionContainer, options totpmodels.APIOptions, userContext supertokens.UserContext) (totpmodels.ListDevicesGETResponse, error) {
+		devices, err := (*options.RecipeImplementation.ListDevices)(sessionContainer.GetUserIDWithContext(userContext), userContext)
+		if err != nil {
+			return totpmodels.ListDevicesGETResponse{}, err
+		}
+		return totpmodels.ListDevicesGETResponse{
+			OK: &struct{ Devices []totpmodels.Device }{
+				Devices: devices,
+			},
+		}, nil
+	}
+
+	removeDevicePOST := func(deviceName string, sessionContainer sessmodels.SessionContainer, options totpmodels.APIOptions, userContext supertokens.UserContext) (totpmodels.RemoveDevicePOSTResponse, error) {
+		response, err := (*options.RecipeImplementation.RemoveDevice)(sessionContainer.GetUserIDWithContext(userContext), deviceName, userContext)
+		if err != nil {
+			return totpmodels.RemoveDevicePOSTResponse{}, err
+		}
+		return totpmodels.RemoveDevicePOSTResponse{
+			OK: response.OK,
+		}, nil
+	}
+
+	verifyDevicePOST := func(deviceName string, totp string, sessionContainer sessmodels.SessionContainer, options totpmodels.APIOptions, userContext supertokens.UserContext) (totpmodels.VerifyDevicePOSTResponse, error) {
+		response, err := (*options.RecipeImplementation.VerifyDevice)(sessionContainer.GetUserIDWithContext(userContext), deviceName, totp, userContext)
+		if err != nil {
+			return totpmodels.VerifyDevicePOSTResponse{}, err
+		}
+		if response.OK == nil {
+			return totpmodels.VerifyDevicePOSTResponse{
+				UnknownDeviceError: response.UnknownDeviceError,
+				InvalidTOTPError:   response.InvalidTOTPError,
+			}, nil
+		}
+		if !response.OK.WasAlreadyVerified {
+			// the user just proved that they have the device
+			err = sessionContainer.SetClaimValueWithContext(totpclaims.MFAClaim, true, userContext)
+			if err != nil {
+				return totpmodels.VerifyDevicePOSTResponse{}, err
+			}
+		}
+		return totpmodels.VerifyDevicePOSTResponse{
+			OK: response.OK,
+		}, nil
+	}
+
+	verifyTOTPPOST := func(totp string, sessionContainer sessmodels.SessionContainer, options totpmodels.APIOptions, userContext supertokens.UserContext) (totpmodels.VerifyTOTPPOSTResponse, error) {
+		response, err := (*options.RecipeImplementation.VerifyTOTP)(sessionContainer.GetUserIDWithContext(userContext), totp, userContext)
+		if err != nil {
+			return totpmodels.VerifyTOTPPOSTResponse{}, err
+		}
+		if response.OK == nil {
+			return totpmodels.VerifyTOTPPOSTResponse{
+				InvalidTOTPError:    response.InvalidTOTPError,
+				TOTPNotEnabledError: response.TOTPNotEnabledError,
+			}, nil
+		}
+		err = sessionContainer.SetClaimValueWithContext(totpclaims.MFAClaim, true, userContext)
+		if err != nil {
+			return totpmodels.VerifyTOTPPOSTResponse{}, err
+		}
+		return totpmodels.VerifyTOTPPOSTResponse{
+			OK: response.OK,
+		}, nil
+	}
+
+	return totpmodels.APIInterface{
+		CreateDevicePOST: &createDevicePOST,
+		ListDevicesGET:   &listDevicesGET,
+		RemoveDevicePOST: &removeDevicePOST,
+		VerifyDevicePOST: &verifyDevicePOST,
+		VerifyTOTPPOST:   &verifyTOTPPOST,
+	}
+}
+
+func getUnusedDeviceName(devices []totpmodels.Device) string {
+	for i := len(devices) + 1; ; i++ {
+		name := fmt.Sprintf("TOTP Device %d", i)
+		isUsed := false
+		for _, device := range devices {
+			if device.Name == name {
+				isUsed = true
+			}
+		}
+		if !isUsed {
+			return name
+		}
+	}
+}
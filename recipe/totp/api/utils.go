@@ -0,0 +1,100 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpclaims"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// getSessionForSecondFactor returns the session of a user that may not have completed
+// the second factor yet, which is what the verify APIs are for.
+func getSessionForSecondFactor(options totpmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	return session.GetSessionWithContext(
+		options.Req, options.Res,
+		&sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return withoutMFAClaimValidators(globalClaimValidators), nil
+			},
+		},
+		userContext,
+	)
+}
+
+// getSessionWithMFACompleted is used by the APIs that manage devices,
+// so that they can not be used by someone who only knows the first factor.
+func getSessionWithMFACompleted(options totpmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	return getSessionWithMFACompletedAndValidators(options, []claims.SessionClaimValidator{}, userContext)
+}
+
+// getSessionToChangeDevices is getSessionWithMFACompleted for the APIs that change devices,
+// which read only impersonation sessions must not be able to use.
+func getSessionToChangeDevices(options totpmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	return getSessionWithMFACompletedAndValidators(options, []claims.SessionClaimValidator{session.BlockReadOnlyImpersonation()}, userContext)
+}
+
+func getSessionWithMFACompletedAndValidators(options totpmodels.APIOptions, extraValidators []claims.SessionClaimValidator, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	return session.GetSessionWithContext(
+		options.Req, options.Res,
+		&sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				validators := append(withoutMFAClaimValidators(globalClaimValidators), extraValidators...)
+				return append(validators, totpclaims.MFAClaimValidators.IsCompleted()), nil
+			},
+		},
+		userContext,
+	)
+}
+
+// withoutMFAClaimValidators returns the validators except the ones of the MFA claim, which the
+// verify APIs are there to satisfy.
+func withoutMFAClaimValidators(globalClaimValidators []claims.SessionClaimValidator) []claims.SessionClaimValidator {
+	validators := []claims.SessionClaimValidator{}
+	for _, validator := range globalClaimValidators {
+		if validator.ID != totpclaims.MFAClaim.Key {
+			validators = append(validators, validator)
+		}
+	}
+	return validators
+}
+
+func readBody(options totpmodels.APIOptions) (map[string]interface{}, error) {
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return nil, err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return nil, err
+	}
+	return readBody, nil
+}
+
+func getStringFromBody(readBody map[string]interface{}, key string) (string, error) {
+	value, ok := readBody[key]
+	if !ok || reflect.ValueOf(value).Kind() != reflect.String {
+		return "", supertokens.BadInputError{Msg: "Please provide " + key + " as a string"}
+	}
+	return value.(string), nil
+}
@@ -0,0 +1,64 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func VerifyTOTP(apiImplementation totpmodels.APIInterface, options totpmodels.APIOptions) error {
+	if apiImplementation.VerifyTOTPPOST == nil || (*apiImplementation.VerifyTOTPPOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+	sessionContainer, err := getSessionForSecondFactor(options, userContext)
+	if err != nil {
+		return err
+	}
+
+	readBody, err := readBody(options)
+	if err != nil {
+		return err
+	}
+	totp, err := getStringFromBody(readBody, "totp")
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.VerifyTOTPPOST)(totp, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else if response.InvalidTOTPError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "INVALID_TOTP_ERROR",
+		})
+	} else if response.TOTPNotEnabledError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "TOTP_NOT_ENABLED_ERROR",
+		})
+	} else if response.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
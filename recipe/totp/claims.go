@@ -0,0 +1,60 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpclaims"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// NewMFAClaim returns the claim that tracks whether the second factor was completed for
+// a session. It is set to true by the TOTP APIs, so it has no max age: refetching it would
+// undo that.
+func NewMFAClaim() (*claims.TypeSessionClaim, totpclaims.TypeMFAClaimValidators) {
+	fetchValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		recipe, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+		if err != nil {
+			return nil, err
+		}
+		devices, err := (*recipe.RecipeImpl.ListDevices)(userId, userContext)
+		if err != nil {
+			return nil, err
+		}
+		for _, device := range devices {
+			if device.Verified {
+				return false, nil
+			}
+		}
+		// users without a second factor are not asked for one
+		return true, nil
+	}
+
+	mfaClaim, booleanClaimValidators := claims.BooleanClaim("st-mfa", fetchValue, nil)
+
+	validators := totpclaims.TypeMFAClaimValidators{
+		BooleanClaimValidators: booleanClaimValidators,
+		IsCompleted: func() claims.SessionClaimValidator {
+			return booleanClaimValidators.IsTrue(nil, nil)
+		},
+	}
+	return mfaClaim, validators
+}
+
+func init() {
+	// this function is called automatically when the package is imported
+	totpclaims.MFAClaim, totpclaims.MFAClaimValidators = NewMFAClaim()
+}
@@ -0,0 +1,115 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpclaims"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func Init(config *totpmodels.TypeInput) supertokens.Recipe {
+	return recipeInit(config)
+}
+
+// WithMFARequired returns a copy of options for session.VerifySession and session.GetSession
+// that also rejects sessions for which the second factor was not completed. options can be nil.
+func WithMFARequired(options *sessmodels.VerifySessionOptions) *sessmodels.VerifySessionOptions {
+	result := sessmodels.VerifySessionOptions{}
+	if options != nil {
+		result = *options
+	}
+	overrideGlobalClaimValidators := result.OverrideGlobalClaimValidators
+	result.OverrideGlobalClaimValidators = func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+		validators := globalClaimValidators
+		if overrideGlobalClaimValidators != nil {
+			var err error
+			validators, err = overrideGlobalClaimValidators(globalClaimValidators, sessionContainer, userContext)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, validator := range validators {
+			if validator.ID == totpclaims.MFAClaim.Key {
+				return validators, nil
+			}
+		}
+		return append(validators, totpclaims.MFAClaimValidators.IsCompleted()), nil
+	}
+	return &result
+}
+
+func CreateDevice(userID string, deviceName string) (totpmodels.CreateDeviceResponse, error) {
+	return CreateDeviceWithContext(userID, deviceName, &map[string]interface{}{})
+}
+
+func CreateDeviceWithContext(userID string, deviceName string, userContext supertokens.UserContext) (totpmodels.CreateDeviceResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return totpmodels.CreateDeviceResponse{}, err
+	}
+	return (*instance.RecipeImpl.CreateDevice)(userID, deviceName, userContext)
+}
+
+func ListDevices(userID string) ([]totpmodels.Device, error) {
+	return ListDevicesWithContext(userID, &map[string]interface{}{})
+}
+
+func ListDevicesWithContext(userID string, userContext supertokens.UserContext) ([]totpmodels.Device, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.ListDevices)(userID, userContext)
+}
+
+func RemoveDevice(userID string, deviceName string) (totpmodels.RemoveDeviceResponse, error) {
+	return RemoveDeviceWithContext(userID, deviceName, &map[string]interface{}{})
+}
+
+func RemoveDeviceWithContext(userID string, deviceName string, userContext supertokens.UserContext) (totpmodels.RemoveDeviceResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return totpmodels.RemoveDeviceResponse{}, err
+	}
+	return (*instance.RecipeImpl.RemoveDevice)(userID, deviceName, userContext)
+}
+
+func VerifyDevice(userID string, deviceName string, totp string) (totpmodels.VerifyDeviceResponse, error) {
+	return VerifyDeviceWithContext(userID, deviceName, totp, &map[string]interface{}{})
+}
+
+func VerifyDeviceWithContext(userID string, deviceName string, totp string, userContext supertokens.UserContext) (totpmodels.VerifyDeviceResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return totpmodels.VerifyDeviceResponse{}, err
+	}
+	return (*instance.RecipeImpl.VerifyDevice)(userID, deviceName, totp, userContext)
+}
+
+func VerifyTOTP(userID string, totp string) (totpmodels.VerifyTOTPResponse, error) {
+	return VerifyTOTPWithContext(userID, totp, &map[string]interface{}{})
+}
+
+func VerifyTOTPWithContext(userID string, totp string, userContext supertokens.UserContext) (totpmodels.VerifyTOTPResponse, error) {
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
+	if err != nil {
+		return totpmodels.VerifyTOTPResponse{}, err
+	}
+	return (*instance.RecipeImpl.VerifyTOTP)(userID, totp, userContext)
+}
@@ -0,0 +1,199 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/totp/api"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpclaims"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const RECIPE_ID = "totp"
+
+// minCDIVersion is the first version of the core driver interface with the TOTP APIs.
+const minCDIVersion = "2.21"
+
+type Recipe struct {
+	RecipeModule supertokens.RecipeModule
+	Config       totpmodels.TypeNormalisedInput
+	RecipeImpl   totpmodels.RecipeInterface
+	APIImpl      totpmodels.APIInterface
+}
+
+func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *totpmodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
+	r := &Recipe{}
+	verifiedConfig, err := validateAndNormaliseUserInput(appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
+	r.Config = verifiedConfig
+	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
+
+	querierInstance, err := supertokens.GetNewQuerierInstanceOrThrowError(recipeId)
+	if err != nil {
+		return Recipe{}, err
+	}
+	recipeImplementation := makeRecipeImplementation(*querierInstance, verifiedConfig)
+	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
+
+	supertokens.AddPostInitCallback(func() error {
+		err := requireCoreWithTOTP(context.Background(), *querierInstance)
+		if errors.As(err, &supertokens.CoreAPIVersionError{}) {
+			return err
+		}
+		if err != nil {
+			// the core may just be unreachable right now, the version is checked again before every request
+			supertokens.LogDebugMessage("totp: could not check the version of the core: " + err.Error())
+		}
+		return nil
+	})
+
+	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
+	r.RecipeModule = recipeModuleInstance
+
+	return *r, nil
+}
+
+func GetRecipeInstanceOrThrowError() (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetDefaultInstance()); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+// GetRecipeInstanceFor returns the TOTP recipe of the given instance, or nil if the
+// instance was not initialised with it.
+func GetRecipeInstanceFor(instance *supertokens.Instance) *Recipe {
+	if instance == nil {
+		return nil
+	}
+	recipe, _ := instance.GetRecipeInstance(RECIPE_ID).(*Recipe)
+	return recipe
+}
+
+// GetRecipeInstanceFromUserContextOrThrowError returns the recipe of the instance that
+// is handling the request in userContext, or of the default instance.
+func GetRecipeInstanceFromUserContextOrThrowError(userContext supertokens.UserContext) (*Recipe, error) {
+	if recipe := GetRecipeInstanceFor(supertokens.GetInstanceFromUserContext(userContext)); recipe != nil {
+		return recipe, nil
+	}
+	return nil, errors.New("Initialisation not done. Did you forget to call the init function?")
+}
+
+func recipeInit(config *totpmodels.TypeInput) supertokens.Recipe {
+	return func(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
+		stInstance, err := supertokens.GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		if GetRecipeInstanceFor(stInstance) == nil {
+			recipe, err := MakeRecipe(RECIPE_ID, appInfo, config, onSuperTokensAPIError)
+			if err != nil {
+				return nil, err
+			}
+			stInstance.SetRecipeInstance(RECIPE_ID, &recipe)
+
+			supertokens.AddPostInitCallback(func() error {
+				sessionRecipe := session.GetRecipeInstanceFor(stInstance)
+				if sessionRecipe == nil {
+					return errors.New("Initialisation not done. Did you forget to call the init function?")
+				}
+
+				sessionRecipe.AddClaimFromOtherRecipe(totpclaims.MFAClaim)
+
+				if recipe.Config.Mode == totpmodels.ModeRequired {
+					sessionRecipe.AddClaimValidatorFromOtherRecipe(
+						totpclaims.MFAClaimValidators.IsCompleted(),
+					)
+				}
+				return nil
+			})
+			return &recipe.RecipeModule, nil
+		}
+		return nil, errors.New("TOTP recipe has already been initialised. Please check your code for bugs.")
+	}
+}
+
+// implement RecipeModule
+
+func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
+	apis := []struct {
+		method   string
+		path     string
+		disabled bool
+	}{
+		{http.MethodPost, createDeviceAPI, r.APIImpl.CreateDevicePOST == nil},
+		{http.MethodGet, listDevicesAPI, r.APIImpl.ListDevicesGET == nil},
+		{http.MethodPost, removeDeviceAPI, r.APIImpl.RemoveDevicePOST == nil},
+		{http.MethodPost, verifyDeviceAPI, r.APIImpl.VerifyDevicePOST == nil},
+		{http.MethodPost, verifyTOTPAPI, r.APIImpl.VerifyTOTPPOST == nil},
+	}
+	result := []supertokens.APIHandled{}
+	for _, a := range apis {
+		normalisedPath, err := supertokens.NewNormalisedURLPath(a.path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, supertokens.APIHandled{
+			Method:                 a.method,
+			PathWithoutAPIBasePath: normalisedPath,
+			ID:                     a.path,
+			Disabled:               a.disabled,
+		})
+	}
+	return result, nil
+}
+
+func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, _ supertokens.NormalisedURLPath, _ string) error {
+	options := totpmodels.APIOptions{
+		Config:               r.Config,
+		RecipeID:             r.RecipeModule.GetRecipeID(),
+		RecipeImplementation: r.RecipeImpl,
+		Req:                  req,
+		Res:                  res,
+		OtherHandler:         theirHandler,
+	}
+	switch id {
+	case createDeviceAPI:
+		return api.CreateDevice(r.APIImpl, options)
+	case listDevicesAPI:
+		return api.ListDevices(r.APIImpl, options)
+	case removeDeviceAPI:
+		return api.RemoveDevice(r.APIImpl, options)
+	case verifyDeviceAPI:
+		return api.VerifyDevice(r.APIImpl, options)
+	case verifyTOTPAPI:
+		return api.VerifyTOTP(r.APIImpl, options)
+	}
+	return errors.New("should never come here")
+}
+
+func (r *Recipe) getAllCORSHeaders() []string {
+	return []string{}
+}
+
+func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+	return false, nil
+}
+
+func ResetForTest() {
+}
@@ -0,0 +1,181 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func makeRecipeImplementation(querier supertokens.Querier, config totpmodels.TypeNormalisedInput) totpmodels.RecipeInterface {
+	createDevice := func(userID string, deviceName string, userContext supertokens.UserContext) (totpmodels.CreateDeviceResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithTOTP(ctx, querier)
+		if err != nil {
+			return totpmodels.CreateDeviceResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/totp/device", map[string]interface{}{
+			"userId":     userID,
+			"deviceName": deviceName,
+			"period":     config.DefaultPeriod,
+			"skew":       config.DefaultSkew,
+		})
+		if err != nil {
+			return totpmodels.CreateDeviceResponse{}, err
+		}
+		if response["status"] != "OK" {
+			return totpmodels.CreateDeviceResponse{
+				DeviceAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		secret, ok := response["secret"].(string)
+		if !ok {
+			return totpmodels.CreateDeviceResponse{}, errors.New("the core did not return the secret of the new TOTP device")
+		}
+		userIdentifier, err := config.GetUserIdentifier(userID, userContext)
+		if err != nil {
+			return totpmodels.CreateDeviceResponse{}, err
+		}
+		return totpmodels.CreateDeviceResponse{
+			OK: &struct {
+				DeviceName   string
+				Secret       string
+				QRCodeString string
+			}{
+				DeviceName:   deviceName,
+				Secret:       secret,
+				QRCodeString: makeOTPAuthURI(config.Issuer, userIdentifier, secret, config.DefaultPeriod),
+			},
+		}, nil
+	}
+
+	listDevices := func(userID string, userContext supertokens.UserContext) ([]totpmodels.Device, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithTOTP(ctx, querier)
+		if err != nil {
+			return nil, err
+		}
+		response, err := querier.SendGetRequestWithContext(ctx, "/recipe/totp/device/list", map[string]string{
+			"userId": userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return parseDevices(response["devices"])
+	}
+
+	removeDevice := func(userID string, deviceName string, userContext supertokens.UserContext) (totpmodels.RemoveDeviceResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithTOTP(ctx, querier)
+		if err != nil {
+			return totpmodels.RemoveDeviceResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/totp/device/remove", map[string]interface{}{
+			"userId":     userID,
+			"deviceName": deviceName,
+		})
+		if err != nil {
+			return totpmodels.RemoveDeviceResponse{}, err
+		}
+		return totpmodels.RemoveDeviceResponse{
+			OK: &struct{ DidDeviceExist bool }{
+				DidDeviceExist: response["didDeviceExist"] == true,
+			},
+		}, nil
+	}
+
+	verifyDevice := func(userID string, deviceName string, totp string, userContext supertokens.UserContext) (totpmodels.VerifyDeviceResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithTOTP(ctx, querier)
+		if err != nil {
+			return totpmodels.VerifyDeviceResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/totp/device/verify", map[string]interface{}{
+			"userId":     userID,
+			"deviceName": deviceName,
+			"totp":       totp,
+		})
+		if err != nil {
+			return totpmodels.VerifyDeviceResponse{}, err
+		}
+		switch response["status"] {
+		case "OK":
+			return totpmodels.VerifyDeviceResponse{
+				OK: &struct{ WasAlreadyVerified bool }{
+					WasAlreadyVerified: response["wasAlreadyVerified"] == true,
+				},
+			}, nil
+		case "UNKNOWN_DEVICE_ERROR":
+			return totpmodels.VerifyDeviceResponse{
+				UnknownDeviceError: &struct{}{},
+			}, nil
+		}
+		emitMFAFailedEvent(userID, "totp", userContext)
+		return totpmodels.VerifyDeviceResponse{
+			InvalidTOTPError: &struct{}{},
+		}, nil
+	}
+
+	verifyTOTP := func(userID string, totp string, userContext supertokens.UserContext) (totpmodels.VerifyTOTPResponse, error) {
+		ctx := supertokens.GetContextFromUserContext(userContext)
+		err := requireCoreWithTOTP(ctx, querier)
+		if err != nil {
+			return totpmodels.VerifyTOTPResponse{}, err
+		}
+		response, err := querier.SendPostRequestWithContext(ctx, "/recipe/totp/verify", map[string]interface{}{
+			"userId": userID,
+			"totp":   totp,
+		})
+		if err != nil {
+			return totpmodels.VerifyTOTPResponse{}, err
+		}
+		switch response["status"] {
+		case "OK":
+			return totpmodels.VerifyTOTPResponse{
+				OK: &struct{}{},
+			}, nil
+		case "TOTP_NOT_ENABLED_ERROR":
+			return totpmodels.VerifyTOTPResponse{
+				TOTPNotEnabledError: &struct{}{},
+			}, nil
+		}
+		emitMFAFailedEvent(userID, "totp", userContext)
+		return totpmodels.VerifyTOTPResponse{
+			InvalidTOTPError: &struct{}{},
+		}, nil
+	}
+
+	return totpmodels.RecipeInterface{
+		CreateDevice: &createDevice,
+		ListDevices:  &listDevices,
+		RemoveDevice: &removeDevice,
+		VerifyDevice: &verifyDevice,
+		VerifyTOTP:   &verifyTOTP,
+	}
+}
+
+func emitMFAFailedEvent(userID string, factor string, userContext supertokens.UserContext) {
+	supertokens.EmitSecurityEvent(supertokens.SecurityEvent{
+		Type:     supertokens.SecurityEventMFAFailed,
+		RecipeID: RECIPE_ID,
+		UserID:   userID,
+		Details: map[string]interface{}{
+			"factor": factor,
+		},
+	}, userContext)
+}
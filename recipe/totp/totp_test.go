@@ -0,0 +1,280 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+// generateTotpForTest implements RFC 6238 with the defaults used by authenticator apps.
+func generateTotpForTest(t *testing.T, secret string, offsetInPeriods int64) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	assert.NoError(t, err)
+	counter := time.Now().Unix()/30 + offsetInPeriods
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func initForTest(t *testing.T, config *totpmodels.TypeInput) *httptest.Server {
+	return initForTestWithSessionConfig(t, config, &sessmodels.TypeInput{})
+}
+
+func initForTestWithSessionConfig(t *testing.T, config *totpmodels.TypeInput, sessionConfig *sessmodels.TypeInput) *httptest.Server {
+	customAntiCsrfVal := "NONE"
+	sessionConfig.AntiCsrf = &customAntiCsrfVal
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(sessionConfig),
+			Init(config),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		session.CreateNewSession(rw, "userId", map[string]interface{}{}, map[string]interface{}{})
+	})
+	mux.HandleFunc("/protected", session.VerifySession(WithMFARequired(nil), func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(200)
+	}))
+	return httptest.NewServer(supertokens.Middleware(mux))
+}
+
+func sendRequest(t *testing.T, method string, url string, body map[string]interface{}, cookieData map[string]string) *http.Response {
+	bodyBytes, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyBytes))
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "sAccessToken", Value: cookieData["sAccessToken"]})
+	req.AddCookie(&http.Cookie{Name: "sIdRefreshToken", Value: cookieData["sIdRefreshToken"]})
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return resp
+}
+
+func TestMakeOTPAuthURI(t *testing.T) {
+	assert.Equal(t,
+		"otpauth://totp/My%20App:user@example.com?algorithm=SHA1&digits=6&issuer=My+App&period=30&secret=JBSWY3DPEHPK3PXP",
+		makeOTPAuthURI("My App", "user@example.com", "JBSWY3DPEHPK3PXP", 30))
+}
+
+func TestCreateAndListDevices(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTest(t, &totpmodels.TypeInput{Issuer: "Test"})
+	defer testServer.Close()
+
+	created, err := CreateDevice("userId", "phone")
+	assert.NoError(t, err)
+	assert.Contains(t, created.OK.QRCodeString, "otpauth://totp/Test:userId?")
+	created, err = CreateDevice("userId", "phone")
+	assert.NoError(t, err)
+	assert.NotNil(t, created.DeviceAlreadyExistsError)
+
+	devices, err := ListDevices("userId")
+	assert.NoError(t, err)
+	assert.Equal(t, []totpmodels.Device{{Name: "phone", Period: 30, Skew: 1, Verified: false}}, devices)
+
+	verifyResponse, err := VerifyTOTP("userId", "123456")
+	assert.NoError(t, err)
+	assert.NotNil(t, verifyResponse.TOTPNotEnabledError)
+}
+
+func TestMFAClaimIsCompletedByVerifyingTOTP(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTest(t, nil)
+	defer testServer.Close()
+
+	created, err := CreateDevice("userId", "phone")
+	assert.NoError(t, err)
+	secret := created.OK.Secret
+
+	deviceResponse, err := VerifyDevice("userId", "phone", generateTotpForTest(t, secret, 0))
+	assert.NoError(t, err)
+	assert.False(t, deviceResponse.OK.WasAlreadyVerified)
+
+	resp, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponseWhenAntiCSRFisNone(resp)
+
+	resp = sendRequest(t, http.MethodGet, testServer.URL+"/protected", nil, cookieData)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	// the code that verified the device can not be used again
+	resp = sendRequest(t, http.MethodPost, testServer.URL+"/auth/totp/verify", map[string]interface{}{
+		"totp": generateTotpForTest(t, secret, 0),
+	}, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "INVALID_TOTP_ERROR", result["status"])
+
+	// codes of the next period are accepted because of the default skew
+	resp = sendRequest(t, http.MethodPost, testServer.URL+"/auth/totp/verify", map[string]interface{}{
+		"totp": generateTotpForTest(t, secret, 1),
+	}, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+	cookieData["sAccessToken"] = unittesting.ExtractInfoFromResponseWhenAntiCSRFisNone(resp)["sAccessToken"]
+
+	resp = sendRequest(t, http.MethodGet, testServer.URL+"/protected", nil, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp = sendRequest(t, http.MethodGet, testServer.URL+"/auth/totp/device/list", nil, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+	result = *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Len(t, result["devices"], 1)
+}
+
+func TestVerifyTOTPKeepsOtherGlobalClaimValidators(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	isBlocked := true
+	blockedValidator := claims.SessionClaimValidator{
+		ID: "test-blocked",
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			return false
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) claims.ClaimValidationResult {
+			return claims.ClaimValidationResult{IsValid: !isBlocked}
+		},
+	}
+	testServer := initForTestWithSessionConfig(t, &totpmodels.TypeInput{Mode: totpmodels.ModeRequired}, &sessmodels.TypeInput{
+		Override: &sessmodels.OverrideStruct{
+			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+				*originalImplementation.GetGlobalClaimValidators = func(userId string, claimValidatorsAddedByOtherRecipes []claims.SessionClaimValidator, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+					return append(claimValidatorsAddedByOtherRecipes, blockedValidator), nil
+				}
+				return originalImplementation
+			},
+		},
+	})
+	defer testServer.Close()
+
+	created, err := CreateDevice("userId", "phone")
+	assert.NoError(t, err)
+	_, err = VerifyDevice("userId", "phone", generateTotpForTest(t, created.OK.Secret, 0))
+	assert.NoError(t, err)
+
+	resp, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponseWhenAntiCSRFisNone(resp)
+
+	resp = sendRequest(t, http.MethodPost, testServer.URL+"/auth/totp/verify", map[string]interface{}{
+		"totp": generateTotpForTest(t, created.OK.Secret, 1),
+	}, cookieData)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	// only the validator of the MFA claim is skipped
+	isBlocked = false
+	resp = sendRequest(t, http.MethodPost, testServer.URL+"/auth/totp/verify", map[string]interface{}{
+		"totp": generateTotpForTest(t, created.OK.Secret, 1),
+	}, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+	result := *unittesting.HttpResponseToConsumableInformation(resp.Body)
+	assert.Equal(t, "OK", result["status"])
+}
+
+func TestInitFailsWithCoreWithoutTOTP(t *testing.T) {
+	resetAll()
+	defer resetAll()
+	core := fakecore.NewServer(fakecore.Config{
+		CDIVersions: []string{"2.14", "2.15"},
+	})
+	defer core.Close()
+
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: core.URL,
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+			Init(nil),
+		},
+	})
+	assert.ErrorAs(t, err, &supertokens.CoreAPIVersionError{})
+	assert.Contains(t, err.Error(), "TOTP needs a SuperTokens core that supports CDI version 2.21 or later")
+}
+
+func TestReadOnlyImpersonationCannotChangeDevices(t *testing.T) {
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	testServer := initForTestWithSessionConfig(t, nil, &sessmodels.TypeInput{
+		Impersonation: &sessmodels.ImpersonationConfig{},
+	})
+	defer testServer.Close()
+
+	res := httptest.NewRecorder()
+	_, err := session.CreateImpersonationSession(res, "support", "userId")
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponseWhenAntiCSRFisNone(res.Result())
+
+	resp := sendRequest(t, http.MethodPost, testServer.URL+"/auth/totp/device", map[string]interface{}{
+		"deviceName": "phone",
+	}, cookieData)
+	assert.Equal(t, 403, resp.StatusCode)
+	devices, err := ListDevices("userId")
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+
+	resp = sendRequest(t, http.MethodGet, testServer.URL+"/auth/totp/device/list", nil, cookieData)
+	assert.Equal(t, 200, resp.StatusCode)
+}
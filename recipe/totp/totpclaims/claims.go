@@ -0,0 +1,32 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totpclaims
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+)
+
+type TypeMFAClaimValidators struct {
+	claims.BooleanClaimValidators
+	// IsCompleted rejects sessions for which the second factor was not completed.
+	IsCompleted func() claims.SessionClaimValidator
+}
+
+// MFAClaim is true if the second factor was completed for the session, or if the user
+// did not have a verified TOTP device when the session was created.
+var MFAClaim *claims.TypeSessionClaim
+
+var MFAClaimValidators TypeMFAClaimValidators
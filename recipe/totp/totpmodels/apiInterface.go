@@ -0,0 +1,80 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totpmodels
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type APIOptions struct {
+	RecipeImplementation RecipeInterface
+	Config               TypeNormalisedInput
+	RecipeID             string
+	Req                  *http.Request
+	Res                  http.ResponseWriter
+	OtherHandler         http.HandlerFunc
+}
+
+type APIInterface struct {
+	CreateDevicePOST *func(deviceName string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (CreateDevicePOSTResponse, error)
+	ListDevicesGET   *func(sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (ListDevicesGETResponse, error)
+	RemoveDevicePOST *func(deviceName string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (RemoveDevicePOSTResponse, error)
+	VerifyDevicePOST *func(deviceName string, totp string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (VerifyDevicePOSTResponse, error)
+	VerifyTOTPPOST   *func(totp string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (VerifyTOTPPOSTResponse, error)
+}
+
+type CreateDevicePOSTResponse struct {
+	OK *struct {
+		DeviceName   string
+		Secret       string
+		QRCodeString string
+	}
+	DeviceAlreadyExistsError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
+}
+
+type ListDevicesGETResponse struct {
+	OK *struct {
+		Devices []Device
+	}
+	GeneralError *supertokens.GeneralErrorResponse
+}
+
+type RemoveDevicePOSTResponse struct {
+	OK *struct {
+		DidDeviceExist bool
+	}
+	GeneralError *supertokens.GeneralErrorResponse
+}
+
+type VerifyDevicePOSTResponse struct {
+	OK *struct {
+		WasAlreadyVerified bool
+	}
+	UnknownDeviceError *struct{}
+	InvalidTOTPError   *struct{}
+	GeneralError       *supertokens.GeneralErrorResponse
+}
+
+type VerifyTOTPPOSTResponse struct {
+	OK                  *struct{}
+	InvalidTOTPError    *struct{}
+	TOTPNotEnabledError *struct{}
+	GeneralError        *supertokens.GeneralErrorResponse
+}
@@ -0,0 +1,65 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totpmodels
+
+import "github.com/supertokens/supertokens-golang/supertokens"
+
+type TypeMode string
+
+const (
+	// ModeRequired rejects every session for which the second factor was not completed,
+	// unless the claim validators are overridden for an API.
+	ModeRequired TypeMode = "REQUIRED"
+	// ModeOptional only rejects such sessions for APIs that ask for it, see totp.WithMFARequired.
+	ModeOptional TypeMode = "OPTIONAL"
+)
+
+type Device struct {
+	Name     string `json:"name"`
+	Period   int64  `json:"period"`
+	Skew     int64  `json:"skew"`
+	Verified bool   `json:"verified"`
+}
+
+type TypeInput struct {
+	// Mode defaults to ModeOptional.
+	Mode TypeMode
+	// Issuer is shown next to the code in authenticator apps. Defaults to the app name.
+	Issuer string
+	// DefaultPeriod is the number of seconds that a code is valid for. Defaults to 30.
+	DefaultPeriod int64
+	// DefaultSkew is the number of periods before and after the current one for which codes
+	// are also accepted, to allow for clock drift. Defaults to 1.
+	DefaultSkew *int64
+	// GetUserIdentifier returns the name of the account shown in authenticator apps, for
+	// example the user's email. Defaults to the user ID.
+	GetUserIdentifier func(userID string, userContext supertokens.UserContext) (string, error)
+	Override          *OverrideStruct
+}
+
+type TypeNormalisedInput struct {
+	Mode              TypeMode
+	Issuer            string
+	DefaultPeriod     int64
+	DefaultSkew       int64
+	GetUserIdentifier func(userID string, userContext supertokens.UserContext) (string, error)
+	Override          OverrideStruct
+}
+
+type OverrideStruct struct {
+	Functions func(originalImplementation RecipeInterface) RecipeInterface
+	APIs      func(originalImplementation APIInterface) APIInterface
+}
@@ -0,0 +1,58 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totpmodels
+
+import "github.com/supertokens/supertokens-golang/supertokens"
+
+type RecipeInterface struct {
+	CreateDevice *func(userID string, deviceName string, userContext supertokens.UserContext) (CreateDeviceResponse, error)
+	ListDevices  *func(userID string, userContext supertokens.UserContext) ([]Device, error)
+	RemoveDevice *func(userID string, deviceName string, userContext supertokens.UserContext) (RemoveDeviceResponse, error)
+	VerifyDevice *func(userID string, deviceName string, totp string, userContext supertokens.UserContext) (VerifyDeviceResponse, error)
+	VerifyTOTP   *func(userID string, totp string, userContext supertokens.UserContext) (VerifyTOTPResponse, error)
+}
+
+type CreateDeviceResponse struct {
+	OK *struct {
+		DeviceName string
+		Secret     string
+		// QRCodeString is the otpauth:// URI of the device, which authenticator apps
+		// can read from a QR code.
+		QRCodeString string
+	}
+	DeviceAlreadyExistsError *struct{}
+}
+
+type RemoveDeviceResponse struct {
+	OK *struct {
+		DidDeviceExist bool
+	}
+}
+
+type VerifyDeviceResponse struct {
+	OK *struct {
+		WasAlreadyVerified bool
+	}
+	UnknownDeviceError *struct{}
+	InvalidTOTPError   *struct{}
+}
+
+type VerifyTOTPResponse struct {
+	OK *struct{}
+	// InvalidTOTPError is also returned for codes that were used before.
+	InvalidTOTPError    *struct{}
+	TOTPNotEnabledError *struct{}
+}
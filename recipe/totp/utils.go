@@ -0,0 +1,123 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package totp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/recipe/totp/totpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	defaultPeriod int64 = 30
+	defaultSkew   int64 = 1
+)
+
+func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config *totpmodels.TypeInput) (totpmodels.TypeNormalisedInput, error) {
+	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
+
+	if config != nil {
+		if config.Mode != "" {
+			if config.Mode != totpmodels.ModeRequired && config.Mode != totpmodels.ModeOptional {
+				return totpmodels.TypeNormalisedInput{}, errors.New("mode must be either ModeRequired or ModeOptional")
+			}
+			typeNormalisedInput.Mode = config.Mode
+		}
+		if config.Issuer != "" {
+			typeNormalisedInput.Issuer = config.Issuer
+		}
+		if config.DefaultPeriod < 0 {
+			return totpmodels.TypeNormalisedInput{}, errors.New("DefaultPeriod must be positive")
+		}
+		if config.DefaultPeriod != 0 {
+			typeNormalisedInput.DefaultPeriod = config.DefaultPeriod
+		}
+		if config.DefaultSkew != nil {
+			if *config.DefaultSkew < 0 {
+				return totpmodels.TypeNormalisedInput{}, errors.New("DefaultSkew must not be negative")
+			}
+			typeNormalisedInput.DefaultSkew = *config.DefaultSkew
+		}
+		if config.GetUserIdentifier != nil {
+			typeNormalisedInput.GetUserIdentifier = config.GetUserIdentifier
+		}
+		if config.Override != nil {
+			if config.Override.Functions != nil {
+				typeNormalisedInput.Override.Functions = config.Override.Functions
+			}
+			if config.Override.APIs != nil {
+				typeNormalisedInput.Override.APIs = config.Override.APIs
+			}
+		}
+	}
+
+	return typeNormalisedInput, nil
+}
+
+func makeTypeNormalisedInput(appInfo supertokens.NormalisedAppinfo) totpmodels.TypeNormalisedInput {
+	return totpmodels.TypeNormalisedInput{
+		Mode:          totpmodels.ModeOptional,
+		Issuer:        appInfo.AppName,
+		DefaultPeriod: defaultPeriod,
+		DefaultSkew:   defaultSkew,
+		GetUserIdentifier: func(userID string, userContext supertokens.UserContext) (string, error) {
+			return userID, nil
+		},
+		Override: totpmodels.OverrideStruct{
+			Functions: func(originalImplementation totpmodels.RecipeInterface) totpmodels.RecipeInterface {
+				return originalImplementation
+			},
+			APIs: func(originalImplementation totpmodels.APIInterface) totpmodels.APIInterface {
+				return originalImplementation
+			},
+		},
+	}
+}
+
+// makeOTPAuthURI returns the URI described in
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func makeOTPAuthURI(issuer string, userIdentifier string, secret string, period int64) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", "6")
+	query.Set("period", strconv.FormatInt(period, 10))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(userIdentifier)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func parseDevices(value interface{}) ([]totpmodels.Device, error) {
+	respJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var devices []totpmodels.Device
+	err = json.Unmarshal(respJSON, &devices)
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func requireCoreWithTOTP(ctx context.Context, querier supertokens.Querier) error {
+	return querier.RequireCoreAPIVersionWithContext(ctx, minCDIVersion, "TOTP")
+}
@@ -33,7 +33,7 @@ func GetUserMetadata(userID string) (map[string]interface{}, error) {
 }
 
 func GetUserMetadataWithContext(userID string, userContext supertokens.UserContext) (map[string]interface{}, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -49,7 +49,7 @@ func UpdateUserMetadata(userID string, metadataUpdate map[string]interface{}) (m
 }
 
 func UpdateUserMetadataWithContext(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -65,9 +65,53 @@ func ClearUserMetadata(userID string) error {
 }
 
 func ClearUserMetadataWithContext(userID string, userContext supertokens.UserContext) error {
-	instance, err := GetRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return err
 	}
 	return (*instance.RecipeImpl.ClearUserMetadata)(userID, userContext)
 }
+
+// GetEmailLocaleFromMetadata returns a function for emaildelivery.TemplateConfig.GetLocale that
+// reads the locale of a user from the given key of their metadata.
+func GetEmailLocaleFromMetadata(metadataKey string) func(userID *string, email string, userContext supertokens.UserContext) (string, error) {
+	return func(userID *string, email string, userContext supertokens.UserContext) (string, error) {
+		if userID == nil {
+			return "", nil
+		}
+		metadata, err := GetUserMetadataWithContext(*userID, userContext)
+		if err != nil {
+			return "", err
+		}
+		locale, _ := metadata[metadataKey].(string)
+		return locale, nil
+	}
+}
+
+// PasswordHistoryStore stores the password history of the emailpassword recipe's PasswordPolicy
+// under a key of the users' metadata. Make sure that this key is not exposed by your APIs.
+type PasswordHistoryStore struct {
+	MetadataKey string
+}
+
+func (store PasswordHistoryStore) GetPasswordHashes(userID string, userContext supertokens.UserContext) ([]string, error) {
+	metadata, err := GetUserMetadataWithContext(userID, userContext)
+	if err != nil {
+		return nil, err
+	}
+	storedHashes, _ := metadata[store.MetadataKey].([]interface{})
+	hashes := make([]string, 0, len(storedHashes))
+	for _, hash := range storedHashes {
+		if hash, ok := hash.(string); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
+func (store PasswordHistoryStore) SetPasswordHashes(userID string, hashes []string, userContext supertokens.UserContext) error {
+	_, err := UpdateUserMetadataWithContext(userID, map[string]interface{}{
+		store.MetadataKey: hashes,
+	}, userContext)
+	return err
+}
@@ -22,7 +22,7 @@ import (
 
 func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamodels.TypeNormalisedInput, appInfo supertokens.NormalisedAppinfo) usermetadatamodels.RecipeInterface {
 	getUserMetadata := func(userID string, userContext supertokens.UserContext) (map[string]interface{}, error) {
-		response, err := querier.SendGetRequest("/recipe/user/metadata", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/metadata", map[string]string{
 			"userId": userID,
 		})
 		if err != nil {
@@ -33,7 +33,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 	}
 
 	updateUserMetadata := func(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
-		response, err := querier.SendPutRequest("/recipe/user/metadata", map[string]interface{}{
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/metadata", map[string]interface{}{
 			"userId":         userID,
 			"metadataUpdate": metadataUpdate,
 		})
@@ -45,7 +45,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 	}
 
 	clearUserMetadata := func(userID string, userContext supertokens.UserContext) error {
-		_, err := querier.SendPostRequest("/recipe/user/metadata/remove", map[string]interface{}{
+		_, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/metadata/remove", map[string]interface{}{
 			"userId": userID,
 		})
 		return err
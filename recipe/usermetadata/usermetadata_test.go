@@ -406,3 +406,81 @@ func TestGetUserMetadataWithUTF8Encoding(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEmailLocaleFromMetadata(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = UpdateUserMetadata("userId", map[string]interface{}{
+		"locale": "de",
+	})
+	assert.NoError(t, err)
+
+	getLocale := GetEmailLocaleFromMetadata("locale")
+	userID := "userId"
+	locale, err := getLocale(&userID, "test@example.com", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "de", locale)
+
+	otherUserID := "otherUserId"
+	locale, err = getLocale(&otherUserID, "test@example.com", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", locale)
+
+	locale, err = getLocale(nil, "test@example.com", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", locale)
+}
+
+func TestPasswordHistoryStore(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	store := PasswordHistoryStore{MetadataKey: "passwordHistory"}
+	hashes, err := store.GetPasswordHashes("userId", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Empty(t, hashes)
+
+	err = store.SetPasswordHashes("userId", []string{"hash2", "hash1"}, &map[string]interface{}{})
+	assert.NoError(t, err)
+
+	hashes, err = store.GetPasswordHashes("userId", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash2", "hash1"}, hashes)
+}
@@ -25,7 +25,7 @@ func Init(config *userrolesmodels.TypeInput) supertokens.Recipe {
 }
 
 func AddRoleToUser(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.AddRoleToUserResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.AddRoleToUserResponse{}, err
 	}
@@ -33,7 +33,7 @@ func AddRoleToUser(userID string, role string, userContext supertokens.UserConte
 }
 
 func RemoveUserRole(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.RemoveUserRoleResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.RemoveUserRoleResponse{}, err
 	}
@@ -41,7 +41,7 @@ func RemoveUserRole(userID string, role string, userContext supertokens.UserCont
 }
 
 func GetRolesForUser(userID string, userContext supertokens.UserContext) (userrolesmodels.GetRolesForUserResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.GetRolesForUserResponse{}, err
 	}
@@ -49,7 +49,7 @@ func GetRolesForUser(userID string, userContext supertokens.UserContext) (userro
 }
 
 func GetUsersThatHaveRole(role string, userContext supertokens.UserContext) (userrolesmodels.GetUsersThatHaveRoleResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.GetUsersThatHaveRoleResponse{}, err
 	}
@@ -57,7 +57,7 @@ func GetUsersThatHaveRole(role string, userContext supertokens.UserContext) (use
 }
 
 func CreateNewRoleOrAddPermissions(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.CreateNewRoleOrAddPermissionsResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.CreateNewRoleOrAddPermissionsResponse{}, err
 	}
@@ -65,7 +65,7 @@ func CreateNewRoleOrAddPermissions(role string, permissions []string, userContex
 }
 
 func GetPermissionsForRole(role string, userContext supertokens.UserContext) (userrolesmodels.GetPermissionsForRoleResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.GetPermissionsForRoleResponse{}, err
 	}
@@ -73,7 +73,7 @@ func GetPermissionsForRole(role string, userContext supertokens.UserContext) (us
 }
 
 func RemovePermissionsFromRole(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.RemovePermissionsFromRoleResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.RemovePermissionsFromRoleResponse{}, err
 	}
@@ -81,7 +81,7 @@ func RemovePermissionsFromRole(role string, permissions []string, userContext su
 }
 
 func GetRolesThatHavePermission(permission string, userContext supertokens.UserContext) (userrolesmodels.GetRolesThatHavePermissionResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.GetRolesThatHavePermissionResponse{}, err
 	}
@@ -89,7 +89,7 @@ func GetRolesThatHavePermission(permission string, userContext supertokens.UserC
 }
 
 func DeleteRole(role string, userContext supertokens.UserContext) (userrolesmodels.DeleteRoleResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.DeleteRoleResponse{}, err
 	}
@@ -97,7 +97,7 @@ func DeleteRole(role string, userContext supertokens.UserContext) (userrolesmode
 }
 
 func GetAllRoles(userContext supertokens.UserContext) (userrolesmodels.GetAllRolesResponse, error) {
-	instance, err := getRecipeInstanceOrThrowError()
+	instance, err := GetRecipeInstanceFromUserContextOrThrowError(userContext)
 	if err != nil {
 		return userrolesmodels.GetAllRolesResponse{}, err
 	}
@@ -23,7 +23,7 @@ import (
 func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodels.TypeNormalisedInput, appInfo supertokens.NormalisedAppinfo) userrolesmodels.RecipeInterface {
 
 	addRoleToUser := func(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.AddRoleToUserResponse, error) {
-		response, err := querier.SendPutRequest("/recipe/user/role", map[string]interface{}{
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/role", map[string]interface{}{
 			"userId": userID,
 			"role":   role,
 		})
@@ -45,7 +45,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	removeUserRole := func(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.RemoveUserRoleResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/user/role/remove", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/role/remove", map[string]interface{}{
 			"userId": userID,
 			"role":   role,
 		})
@@ -67,7 +67,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getRolesForUser := func(userID string, userContext supertokens.UserContext) (userrolesmodels.GetRolesForUserResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/user/roles", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/user/roles", map[string]string{
 			"userId": userID,
 		})
 		if err != nil {
@@ -83,7 +83,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getUsersThatHaveRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.GetUsersThatHaveRoleResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/role/users", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/role/users", map[string]string{
 			"role": role,
 		})
 		if err != nil {
@@ -104,7 +104,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	createNewRoleOrAddPermissions := func(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.CreateNewRoleOrAddPermissionsResponse, error) {
-		response, err := querier.SendPutRequest("/recipe/role", map[string]interface{}{
+		response, err := querier.SendPutRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/role", map[string]interface{}{
 			"role":        role,
 			"permissions": permissions,
 		})
@@ -120,7 +120,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getPermissionsForRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.GetPermissionsForRoleResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/role/permissions", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/role/permissions", map[string]string{
 			"role": role,
 		})
 		if err != nil {
@@ -141,7 +141,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	removePermissionsFromRole := func(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.RemovePermissionsFromRoleResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/role/permissions/remove", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/role/permissions/remove", map[string]interface{}{
 			"role":        role,
 			"permissions": permissions,
 		})
@@ -161,7 +161,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getRolesThatHavePermission := func(permission string, userContext supertokens.UserContext) (userrolesmodels.GetRolesThatHavePermissionResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/permission/roles", map[string]string{
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/permission/roles", map[string]string{
 			"permission": permission,
 		})
 		if err != nil {
@@ -176,7 +176,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	deleteRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.DeleteRoleResponse, error) {
-		response, err := querier.SendPostRequest("/recipe/role/remove", map[string]interface{}{
+		response, err := querier.SendPostRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/role/remove", map[string]interface{}{
 			"role": role,
 		})
 		if err != nil {
@@ -191,7 +191,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getAllRoles := func(userContext supertokens.UserContext) (userrolesmodels.GetAllRolesResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/roles", map[string]string{})
+		response, err := querier.SendGetRequestWithContext(supertokens.GetContextFromUserContext(userContext), "/recipe/roles", map[string]string{})
 		if err != nil {
 			return userrolesmodels.GetAllRolesResponse{}, err
 		}
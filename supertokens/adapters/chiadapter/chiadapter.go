@@ -0,0 +1,61 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package chiadapter connects SuperTokens to chi. Since chi uses net/http handlers, the session
+// stays in the request context, where session.GetSessionFromRequestContext finds it.
+package chiadapter
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Middleware serves the APIs of the SuperTokens recipes and passes all other requests on to next.
+// It is meant to be added with router.Use.
+func Middleware(next http.Handler) http.Handler {
+	return supertokens.Middleware(next)
+}
+
+// VerifySession returns a middleware that verifies the session of the request before calling
+// next. It is meant to be added with router.With or router.Use.
+func VerifySession(options *sessmodels.VerifySessionOptions) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return session.VerifySession(options, next.ServeHTTP)
+	}
+}
+
+// GetSession returns the session stored by VerifySession, or nil if there is none.
+func GetSession(r *http.Request) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(r.Context())
+}
+
+// ErrorHandler turns a handler that returns an error into a http.HandlerFunc. Errors of
+// SuperTokens functions, like a TryRefreshTokenError from session.GetSession, are sent as their
+// responses, and other errors as a 500 response.
+func ErrorHandler(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handler(w, r)
+		if err == nil {
+			return
+		}
+		err = supertokens.ErrorHandler(err, r, w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
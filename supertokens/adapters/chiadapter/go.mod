@@ -0,0 +1,27 @@
+module github.com/supertokens/supertokens-golang/supertokens/adapters/chiadapter
+
+go 1.18
+
+require (
+	github.com/go-chi/chi/v5 v5.0.4
+	github.com/stretchr/testify v1.7.0
+	github.com/supertokens/supertokens-golang v0.9.14
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang-jwt/jwt/v4 v4.1.0 // indirect
+	github.com/h2non/parth v0.0.0-20190131123155-b4df798d6542 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20220111093109-d55c255bac03 // indirect
+	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
+	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df // indirect
+	gopkg.in/h2non/gock.v1 v1.1.2 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+)
+
+// The adapters are released together with the SDK, which is required at its latest release
+// and bumped to the release that ships the adapters when it is tagged. This replace only
+// applies when developing inside this repository; go ignores it for modules that depend on
+// the adapter.
+replace github.com/supertokens/supertokens-golang => ../../..
@@ -0,0 +1,88 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package echoadapter connects SuperTokens to echo. Middleware serves the APIs of the recipes,
+// VerifySession protects routes, and ErrorHandler turns errors of SuperTokens functions that
+// handlers return into responses.
+package echoadapter
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// SessionKey is the key in the echo context under which VerifySession stores the session.
+const SessionKey = "supertokens-session"
+
+// Middleware returns a middleware that serves the APIs of the SuperTokens recipes and passes all
+// other requests on to next.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var nextErr error
+			supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				nextErr = next(c)
+			})).ServeHTTP(c.Response(), c.Request())
+			return nextErr
+		}
+	}
+}
+
+// VerifySession returns a middleware that verifies the session of the request and stores it in
+// the echo context, from where it can be read with GetSession. If the session is invalid, it sends
+// the error response and does not call next.
+func VerifySession(options *sessmodels.VerifySessionOptions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var nextErr error
+			session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				if sessionContainer := session.GetSessionFromRequestContext(r.Context()); sessionContainer != nil {
+					c.Set(SessionKey, sessionContainer)
+				}
+				nextErr = next(c)
+			}).ServeHTTP(c.Response(), c.Request())
+			return nextErr
+		}
+	}
+}
+
+// GetSession returns the session stored by VerifySession, or nil if there is none.
+func GetSession(c echo.Context) sessmodels.SessionContainer {
+	sessionContainer, _ := c.Get(SessionKey).(sessmodels.SessionContainer)
+	return sessionContainer
+}
+
+// ErrorHandler wraps an echo error handler so that it sends the responses of SuperTokens errors,
+// for example a TryRefreshTokenError from session.GetSession. Other errors are passed on to next:
+//
+//	e.HTTPErrorHandler = echoadapter.ErrorHandler(e.DefaultHTTPErrorHandler)
+func ErrorHandler(next echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			next(err, c)
+			return
+		}
+		err = supertokens.ErrorHandler(err, c.Request(), c.Response())
+		if err != nil {
+			next(err, c)
+		}
+	}
+}
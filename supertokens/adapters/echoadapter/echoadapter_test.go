@@ -0,0 +1,92 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package echoadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/test/adaptertesting"
+)
+
+func initForAdapterTest(t *testing.T) *echo.Echo {
+	adaptertesting.Setup(t)
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(e.DefaultHTTPErrorHandler)
+	e.Use(Middleware())
+	e.POST("/create", func(c echo.Context) error {
+		if err := adaptertesting.CreateSession(c.Response(), c.Request()); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/protected", func(c echo.Context) error {
+		return c.String(http.StatusOK, GetSession(c).GetUserID())
+	}, VerifySession(nil))
+	e.GET("/unprotected", func(c echo.Context) error {
+		return c.String(http.StatusOK, "unprotected")
+	})
+	e.GET("/error", func(c echo.Context) error {
+		return errors.UnauthorizedError{Msg: "not signed in"}
+	})
+	e.GET("/otherError", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "teapot")
+	})
+	return e
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	e := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(e, http.MethodPost, "/auth/signout", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, res.Body.String())
+
+	res = adaptertesting.Serve(e, http.MethodGet, "/unprotected", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "unprotected", res.Body.String())
+}
+
+func TestVerifySessionStoresSessionInEchoContext(t *testing.T) {
+	e := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(e, http.MethodGet, "/protected", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = adaptertesting.Serve(e, http.MethodPost, "/create", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	accessToken := res.Header().Get("st-access-token")
+	assert.NotEmpty(t, accessToken)
+
+	res = adaptertesting.Serve(e, http.MethodGet, "/protected", accessToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "userId", res.Body.String())
+}
+
+func TestErrorHandlerSendsSuperTokensErrorResponses(t *testing.T) {
+	e := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(e, http.MethodGet, "/error", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.JSONEq(t, `{"message":"unauthorised"}`, res.Body.String())
+
+	res = adaptertesting.Serve(e, http.MethodGet, "/otherError", "")
+	assert.Equal(t, http.StatusTeapot, res.Code)
+}
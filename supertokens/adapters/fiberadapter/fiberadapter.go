@@ -0,0 +1,98 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package fiberadapter connects SuperTokens to fiber. Middleware serves the APIs of the recipes,
+// VerifySession protects routes, and ErrorHandler turns errors of SuperTokens functions that
+// handlers return into responses.
+package fiberadapter
+
+import (
+	"net/http"
+
+	"github.com/gofiber/adaptor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// SessionKey is the key in the locals of the fiber context under which VerifySession stores the
+// session.
+const SessionKey = "supertokens-session"
+
+// Middleware returns a handler that serves the APIs of the SuperTokens recipes and passes all
+// other requests on to the next handlers.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// this must be per request, since fiber reuses handlers across requests
+		calledNext := false
+		err := adaptor.HTTPHandler(supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			c.SetUserContext(r.Context())
+		})))(c)
+		if err != nil || !calledNext {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// VerifySession returns a handler that verifies the session of the request and stores it in the
+// locals of the fiber context, from where it can be read with GetSession. If the session is
+// invalid, it sends the error response and does not call the next handlers.
+func VerifySession(options *sessmodels.VerifySessionOptions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		calledNext := false
+		err := adaptor.HTTPHandlerFunc(session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			c.SetUserContext(r.Context())
+			if sessionContainer := session.GetSessionFromRequestContext(r.Context()); sessionContainer != nil {
+				c.Locals(SessionKey, sessionContainer)
+			}
+		}))(c)
+		if err != nil || !calledNext {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// GetSession returns the session stored by VerifySession, or nil if there is none.
+func GetSession(c *fiber.Ctx) sessmodels.SessionContainer {
+	sessionContainer, _ := c.Locals(SessionKey).(sessmodels.SessionContainer)
+	return sessionContainer
+}
+
+// ErrorHandler wraps a fiber error handler so that it sends the responses of SuperTokens errors,
+// for example a TryRefreshTokenError from session.GetSession. Other errors are passed on to next:
+//
+//	app := fiber.New(fiber.Config{
+//		ErrorHandler: fiberadapter.ErrorHandler(fiber.DefaultErrorHandler),
+//	})
+func ErrorHandler(next fiber.ErrorHandler) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		var unhandledErr error
+		adaptorErr := adaptor.HTTPHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			unhandledErr = supertokens.ErrorHandler(err, r, rw)
+		})(c)
+		if adaptorErr != nil {
+			return next(c, adaptorErr)
+		}
+		if unhandledErr != nil {
+			return next(c, unhandledErr)
+		}
+		return nil
+	}
+}
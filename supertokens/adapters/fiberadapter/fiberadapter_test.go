@@ -0,0 +1,96 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fiberadapter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/test/adaptertesting"
+)
+
+func initForAdapterTest(t *testing.T) *fiber.App {
+	adaptertesting.Setup(t)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: ErrorHandler(fiber.DefaultErrorHandler),
+	})
+	app.Use(Middleware())
+	app.Get("/protected", VerifySession(nil), func(c *fiber.Ctx) error {
+		return c.SendString(GetSession(c).GetUserID())
+	})
+	app.Get("/unprotected", func(c *fiber.Ctx) error {
+		return c.SendString("unprotected")
+	})
+	app.Get("/error", func(c *fiber.Ctx) error {
+		return errors.UnauthorizedError{Msg: "not signed in"}
+	})
+	app.Get("/otherError", func(c *fiber.Ctx) error {
+		return fiber.NewError(http.StatusTeapot, "teapot")
+	})
+	return app
+}
+
+func serve(t *testing.T, app *fiber.App, method string, path string, accessToken string) (*http.Response, string) {
+	res, err := app.Test(adaptertesting.NewRequest(method, path, accessToken))
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return res, string(body)
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	app := initForAdapterTest(t)
+
+	res, body := serve(t, app, http.MethodPost, "/auth/signout", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"status":"OK"}`, body)
+
+	res, body = serve(t, app, http.MethodGet, "/unprotected", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "unprotected", body)
+}
+
+func TestVerifySessionStoresSessionInLocals(t *testing.T) {
+	app := initForAdapterTest(t)
+
+	res, _ := serve(t, app, http.MethodGet, "/protected", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	accessToken := adaptertesting.CreateAccessToken(t)
+
+	// both requests must reach the handler, since handlers are shared across requests
+	for i := 0; i < 2; i++ {
+		res, body := serve(t, app, http.MethodGet, "/protected", accessToken)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "userId", body)
+	}
+}
+
+func TestErrorHandlerSendsSuperTokensErrorResponses(t *testing.T) {
+	app := initForAdapterTest(t)
+
+	res, body := serve(t, app, http.MethodGet, "/error", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.JSONEq(t, `{"message":"unauthorised"}`, body)
+
+	res, _ = serve(t, app, http.MethodGet, "/otherError", "")
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+}
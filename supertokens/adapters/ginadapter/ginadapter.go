@@ -0,0 +1,104 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package ginadapter connects SuperTokens to gin. Middleware serves the APIs of the recipes,
+// VerifySession protects routes, and ErrorHandler turns errors of SuperTokens functions that
+// handlers add with c.Error into responses.
+package ginadapter
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// SessionKey is the key in the gin context under which VerifySession stores the session.
+const SessionKey = "supertokens-session"
+
+// Middleware returns a handler that serves the APIs of the SuperTokens recipes and passes all
+// other requests on to the next handlers.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		calledNext := false
+		supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+		if !calledNext {
+			c.Abort()
+		}
+	}
+}
+
+// VerifySession returns a handler that verifies the session of the request and stores it in the
+// gin context, from where it can be read with GetSession. If the session is invalid, it sends the
+// error response and aborts the request.
+func VerifySession(options *sessmodels.VerifySessionOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		calledNext := false
+		session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			c.Request = r
+			if sessionContainer := session.GetSessionFromRequestContext(r.Context()); sessionContainer != nil {
+				c.Set(SessionKey, sessionContainer)
+			}
+			c.Next()
+		}).ServeHTTP(c.Writer, c.Request)
+		if !calledNext {
+			c.Abort()
+		}
+	}
+}
+
+// GetSession returns the session stored by VerifySession, or nil if there is none.
+func GetSession(c *gin.Context) sessmodels.SessionContainer {
+	value, ok := c.Get(SessionKey)
+	if !ok {
+		return nil
+	}
+	sessionContainer, _ := value.(sessmodels.SessionContainer)
+	return sessionContainer
+}
+
+// ErrorHandler returns a handler that sends the response for the first SuperTokens error that the
+// next handlers add with c.Error, for example a TryRefreshTokenError from session.GetSession.
+// Other errors are left in c.Errors.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		remainingErrors := []*gin.Error{}
+		handled := false
+		for _, ginErr := range c.Errors {
+			if handled {
+				remainingErrors = append(remainingErrors, ginErr)
+				continue
+			}
+			err := supertokens.ErrorHandler(ginErr.Err, c.Request, c.Writer)
+			if err == nil {
+				handled = true
+				continue
+			}
+			remainingErrors = append(remainingErrors, ginErr)
+		}
+		c.Errors = remainingErrors
+	}
+}
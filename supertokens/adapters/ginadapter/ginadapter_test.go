@@ -0,0 +1,87 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package ginadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/test/adaptertesting"
+)
+
+func initForAdapterTest(t *testing.T) *gin.Engine {
+	adaptertesting.Setup(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(), Middleware())
+	router.POST("/create", func(c *gin.Context) {
+		if err := adaptertesting.CreateSession(c.Writer, c.Request); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.GET("/protected", VerifySession(nil), func(c *gin.Context) {
+		c.String(http.StatusOK, GetSession(c).GetUserID())
+	})
+	router.GET("/unprotected", func(c *gin.Context) {
+		c.String(http.StatusOK, "unprotected")
+	})
+	router.GET("/error", func(c *gin.Context) {
+		c.Error(errors.UnauthorizedError{Msg: "not signed in"})
+	})
+	return router
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	router := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(router, http.MethodPost, "/auth/signout", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, res.Body.String())
+
+	res = adaptertesting.Serve(router, http.MethodGet, "/unprotected", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "unprotected", res.Body.String())
+}
+
+func TestVerifySessionStoresSessionInGinContext(t *testing.T) {
+	router := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(router, http.MethodGet, "/protected", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = adaptertesting.Serve(router, http.MethodPost, "/create", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	accessToken := res.Header().Get("st-access-token")
+	assert.NotEmpty(t, accessToken)
+
+	res = adaptertesting.Serve(router, http.MethodGet, "/protected", accessToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "userId", res.Body.String())
+}
+
+func TestErrorHandlerSendsSuperTokensErrorResponses(t *testing.T) {
+	router := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(router, http.MethodGet, "/error", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.JSONEq(t, `{"message":"unauthorised"}`, res.Body.String())
+}
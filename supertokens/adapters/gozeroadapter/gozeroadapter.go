@@ -0,0 +1,84 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package gozeroadapter connects SuperTokens to go-zero. go-zero only runs middlewares for
+// requests that match a route, so the APIs of the recipes are served by the not found handler
+// that WithSuperTokens sets:
+//
+//	server := rest.MustNewServer(conf, gozeroadapter.WithSuperTokens())
+//	server.AddRoutes(rest.WithMiddleware(gozeroadapter.VerifySession(nil), rest.Route{
+//		Method:  http.MethodGet,
+//		Path:    "/sessioninfo",
+//		Handler: sessioninfo,
+//	}))
+package gozeroadapter
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+var _ rest.Middleware = Middleware
+
+// WithSuperTokens returns a RunOption that sets NotFoundHandler as the not found handler of
+// the server.
+func WithSuperTokens() rest.RunOption {
+	return rest.WithNotFoundHandler(NotFoundHandler())
+}
+
+// NotFoundHandler serves the APIs of the SuperTokens recipes, and responds with 404 to all other
+// requests that do not match a route.
+func NotFoundHandler() http.Handler {
+	return supertokens.Middleware(http.NotFoundHandler())
+}
+
+// Middleware serves the APIs of the SuperTokens recipes and passes all other requests on to next.
+// It can be added with server.Use, but it only sees requests that match a route, so
+// WithSuperTokens is needed as well.
+func Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return supertokens.Middleware(next).ServeHTTP
+}
+
+// VerifySession returns a middleware that verifies the session of the request before calling next.
+func VerifySession(options *sessmodels.VerifySessionOptions) rest.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return session.VerifySession(options, next)
+	}
+}
+
+// GetSession returns the session stored by VerifySession, or nil if there is none.
+func GetSession(r *http.Request) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(r.Context())
+}
+
+// ErrorHandler turns a handler that returns an error into a http.HandlerFunc. Errors of
+// SuperTokens functions, like a TryRefreshTokenError from session.GetSession, are sent as their
+// responses, and other errors as a 500 response.
+func ErrorHandler(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handler(w, r)
+		if err == nil {
+			return
+		}
+		err = supertokens.ErrorHandler(err, r, w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package gozeroadapter
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/test/adaptertesting"
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/router"
+)
+
+// initForAdapterTest returns the router of a go-zero server that was created with WithSuperTokens.
+// Routes are added to the router directly, since the server only adds them when it is started.
+func initForAdapterTest(t *testing.T) http.Handler {
+	adaptertesting.Setup(t)
+
+	r := router.NewRouter()
+	rest.MustNewServer(rest.RestConf{}, rest.WithRouter(r), WithSuperTokens())
+	routes := map[string]http.HandlerFunc{
+		"/protected": VerifySession(nil)(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(GetSession(r).GetUserID()))
+		}),
+		"/unprotected": Middleware(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("unprotected"))
+		}),
+		"/error": ErrorHandler(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.UnauthorizedError{Msg: "not signed in"}
+		}),
+		"/otherError": ErrorHandler(func(w http.ResponseWriter, r *http.Request) error {
+			return defaultErrors.New("something went wrong")
+		}),
+	}
+	for path, handler := range routes {
+		if err := r.Handle(http.MethodGet, path, handler); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return r
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	handler := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(handler, http.MethodPost, "/auth/signout", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, res.Body.String())
+
+	res = adaptertesting.Serve(handler, http.MethodGet, "/unknown", "")
+	assert.Equal(t, http.StatusNotFound, res.Code)
+
+	res = adaptertesting.Serve(handler, http.MethodGet, "/unprotected", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "unprotected", res.Body.String())
+}
+
+func TestVerifySessionStoresSessionInRequestContext(t *testing.T) {
+	handler := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(handler, http.MethodGet, "/protected", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	accessToken := adaptertesting.CreateAccessToken(t)
+
+	res = adaptertesting.Serve(handler, http.MethodGet, "/protected", accessToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "userId", res.Body.String())
+}
+
+func TestErrorHandlerSendsSuperTokensErrorResponses(t *testing.T) {
+	handler := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(handler, http.MethodGet, "/error", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.JSONEq(t, `{"message":"unauthorised"}`, res.Body.String())
+
+	res = adaptertesting.Serve(handler, http.MethodGet, "/otherError", "")
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+}
@@ -0,0 +1,178 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package grpcadapter verifies SuperTokens sessions in gRPC servers. The interceptors read the
+// tokens from the "authorization" or "cookie" metadata of a call, and put the session in the
+// context of the handler, from where it can be read with GetSession. Headers set by the session
+// recipe, like new tokens, are sent back as response metadata.
+package grpcadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to the status of session errors.
+// Its reason is one of UNAUTHORISED, TRY_REFRESH_TOKEN, TOKEN_THEFT_DETECTED or INVALID_CLAIMS.
+const ErrorDomain = "supertokens.com"
+
+// UnaryServerInterceptor returns an interceptor that verifies the session of unary calls before
+// calling the handler.
+func UnaryServerInterceptor(options *sessmodels.VerifySessionOptions) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		sessionContainer, responseHeaders, err := verifySession(ctx, info.FullMethod, options)
+		if err != nil {
+			sendResponseHeaders(grpc.SetHeader, ctx, responseHeaders)
+			return nil, ToStatusError(err)
+		}
+		if sessionContainer != nil {
+			ctx = context.WithValue(ctx, sessmodels.SessionContext, sessionContainer)
+		}
+		resp, err := handler(ctx, req)
+		// the handler may have changed the session, so the headers are sent after it returns
+		sendResponseHeaders(grpc.SetHeader, ctx, responseHeaders)
+		return resp, ToStatusError(err)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that verifies the session of streaming calls
+// before calling the handler. Headers set while the handler runs are sent as trailers, since the
+// response headers may already have been sent by then.
+func StreamServerInterceptor(options *sessmodels.VerifySessionOptions) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		sessionContainer, responseHeaders, err := verifySession(ctx, info.FullMethod, options)
+		sendResponseHeaders(func(ctx context.Context, md metadata.MD) error {
+			return ss.SetHeader(md)
+		}, ctx, responseHeaders)
+		if err != nil {
+			return ToStatusError(err)
+		}
+		if sessionContainer == nil {
+			return ToStatusError(handler(srv, ss))
+		}
+
+		headersBeforeHandler := responseHeaders.Clone()
+		err = handler(srv, &sessionServerStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ctx, sessmodels.SessionContext, sessionContainer),
+		})
+		newHeaders := http.Header{}
+		for key, values := range responseHeaders {
+			if strings.Join(headersBeforeHandler[key], ",") != strings.Join(values, ",") {
+				newHeaders[key] = values
+			}
+		}
+		ss.SetTrailer(headersToMetadata(newHeaders))
+		return ToStatusError(err)
+	}
+}
+
+// GetSession returns the session verified by the interceptors, or nil if there is none.
+func GetSession(ctx context.Context) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(ctx)
+}
+
+// ToStatusError converts the errors of the session recipe to gRPC status errors. Other errors are
+// returned as they are.
+func ToStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var code codes.Code
+	var message string
+	var reason string
+	errorMetadata := map[string]string{}
+	invalidClaimErr := sessionErrors.InvalidClaimError{}
+	if errors.As(err, &sessionErrors.TryRefreshTokenError{}) {
+		code, message, reason = codes.Unauthenticated, "try refresh token", "TRY_REFRESH_TOKEN"
+	} else if errors.As(err, &sessionErrors.UnauthorizedError{}) {
+		code, message, reason = codes.Unauthenticated, "unauthorised", "UNAUTHORISED"
+	} else if errors.As(err, &sessionErrors.TokenTheftDetectedError{}) {
+		code, message, reason = codes.Unauthenticated, "token theft detected", "TOKEN_THEFT_DETECTED"
+	} else if errors.As(err, &invalidClaimErr) {
+		code, message, reason = codes.PermissionDenied, "invalid claim", "INVALID_CLAIMS"
+		claimValidationErrors, jsonErr := json.Marshal(invalidClaimErr.InvalidClaims)
+		if jsonErr == nil {
+			errorMetadata["claimValidationErrors"] = string(claimValidationErrors)
+		}
+	} else {
+		return err
+	}
+
+	st, detailsErr := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: errorMetadata,
+	})
+	if detailsErr != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+func verifySession(ctx context.Context, fullMethod string, options *sessmodels.VerifySessionOptions) (sessmodels.SessionContainer, http.Header, error) {
+	header := http.Header{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	return session.VerifyFromRequestData(sessmodels.RequestData{
+		Context: ctx,
+		Path:    fullMethod,
+		Header:  header,
+	}, options)
+}
+
+func sendResponseHeaders(setHeader func(ctx context.Context, md metadata.MD) error, ctx context.Context, responseHeaders http.Header) {
+	if len(responseHeaders) == 0 {
+		return
+	}
+	err := setHeader(ctx, headersToMetadata(responseHeaders))
+	if err != nil {
+		supertokens.LogDebugMessage("grpcadapter: could not send session headers: " + err.Error())
+	}
+}
+
+func headersToMetadata(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range header {
+		md.Append(strings.ToLower(key), values...)
+	}
+	return md
+}
+
+type sessionServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *sessionServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,116 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package twirpadapter verifies SuperTokens sessions in Twirp servers. Middleware wraps the Twirp
+// server handler, and the interceptor returned by Interceptor verifies the session of each call
+// and puts it in the context of the method, from where it can be read with GetSession.
+package twirpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/twitchtv/twirp"
+)
+
+type requestDataKey int
+
+const requestDataContextKey requestDataKey = iota
+
+// Middleware serves the SuperTokens APIs and keeps the headers of other requests in their context,
+// so that Interceptor can read the session tokens from them.
+func Middleware(next http.Handler) http.Handler {
+	return supertokens.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), requestDataContextKey, sessmodels.RequestData{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Header: r.Header.Clone(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+// Interceptor returns a Twirp interceptor that verifies the session before calling the method.
+// Session errors returned by the method are converted to Twirp errors as well.
+func Interceptor(options *sessmodels.VerifySessionOptions) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestData, ok := ctx.Value(requestDataContextKey).(sessmodels.RequestData)
+			if !ok {
+				return nil, twirp.InternalError("twirpadapter: the server handler is not wrapped with twirpadapter.Middleware")
+			}
+			requestData.Context = ctx
+			sessionContainer, responseHeaders, err := session.VerifyFromRequestData(requestData, options)
+			if err != nil {
+				sendResponseHeaders(ctx, responseHeaders)
+				return nil, ToTwirpError(err)
+			}
+			if sessionContainer != nil {
+				ctx = context.WithValue(ctx, sessmodels.SessionContext, sessionContainer)
+			}
+			resp, err := next(ctx, req)
+			// the method may have changed the session, so the headers are sent after it returns
+			sendResponseHeaders(ctx, responseHeaders)
+			return resp, ToTwirpError(err)
+		}
+	}
+}
+
+// GetSession returns the session verified by the interceptor, or nil if there is none.
+func GetSession(ctx context.Context) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(ctx)
+}
+
+// ToTwirpError converts the errors of the session recipe to Twirp errors. The "reason" meta of the
+// error is one of UNAUTHORISED, TRY_REFRESH_TOKEN, TOKEN_THEFT_DETECTED or INVALID_CLAIMS. Other
+// errors are returned as they are.
+func ToTwirpError(err error) error {
+	if err == nil {
+		return nil
+	}
+	invalidClaimErr := sessionErrors.InvalidClaimError{}
+	if errors.As(err, &sessionErrors.TryRefreshTokenError{}) {
+		return twirp.NewError(twirp.Unauthenticated, "try refresh token").WithMeta("reason", "TRY_REFRESH_TOKEN")
+	} else if errors.As(err, &sessionErrors.UnauthorizedError{}) {
+		return twirp.NewError(twirp.Unauthenticated, "unauthorised").WithMeta("reason", "UNAUTHORISED")
+	} else if errors.As(err, &sessionErrors.TokenTheftDetectedError{}) {
+		return twirp.NewError(twirp.Unauthenticated, "token theft detected").WithMeta("reason", "TOKEN_THEFT_DETECTED")
+	} else if errors.As(err, &invalidClaimErr) {
+		twirpErr := twirp.NewError(twirp.PermissionDenied, "invalid claim").WithMeta("reason", "INVALID_CLAIMS")
+		claimValidationErrors, jsonErr := json.Marshal(invalidClaimErr.InvalidClaims)
+		if jsonErr == nil {
+			twirpErr = twirpErr.WithMeta("claimValidationErrors", string(claimValidationErrors))
+		}
+		return twirpErr
+	}
+	return err
+}
+
+func sendResponseHeaders(ctx context.Context, responseHeaders http.Header) {
+	for key, values := range responseHeaders {
+		for _, value := range values {
+			err := twirp.AddHTTPResponseHeader(ctx, key, value)
+			if err != nil {
+				supertokens.LogDebugMessage("twirpadapter: could not send session header: " + err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package twirpadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/test/adaptertesting"
+	"github.com/twitchtv/twirp"
+)
+
+// initForAdapterTest returns a handler that calls a Twirp method wrapped with the interceptor, and
+// writes the user ID of the session or the code of the returned Twirp error.
+func initForAdapterTest(t *testing.T) http.Handler {
+	adaptertesting.Setup(t)
+
+	method := Interceptor(nil)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return GetSession(ctx).GetUserID(), nil
+	})
+	return Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := method(r.Context(), nil)
+		if err != nil {
+			var twirpErr twirp.Error
+			if errors.As(err, &twirpErr) {
+				w.Write([]byte(twirpErr.Code()))
+				return
+			}
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Write([]byte(resp.(string)))
+	}))
+}
+
+func TestInterceptorRequiresSession(t *testing.T) {
+	handler := initForAdapterTest(t)
+
+	res := adaptertesting.Serve(handler, http.MethodPost, "/twirp/example.Service/Method", "")
+	assert.Equal(t, string(twirp.Unauthenticated), res.Body.String())
+
+	res = adaptertesting.Serve(handler, http.MethodPost, "/twirp/example.Service/Method", adaptertesting.CreateAccessToken(t))
+	assert.Equal(t, "userId", res.Body.String())
+}
+
+func TestInterceptorWithoutMiddleware(t *testing.T) {
+	method := Interceptor(nil)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	_, err := method(context.Background(), nil)
+	var twirpErr twirp.Error
+	assert.True(t, errors.As(err, &twirpErr))
+	assert.Equal(t, twirp.Internal, twirpErr.Code())
+}
+
+func TestToTwirpErrorMapsSessionErrors(t *testing.T) {
+	var twirpErr twirp.Error
+	assert.True(t, errors.As(ToTwirpError(sessionErrors.TryRefreshTokenError{Msg: "expired"}), &twirpErr))
+	assert.Equal(t, twirp.Unauthenticated, twirpErr.Code())
+	assert.Equal(t, "TRY_REFRESH_TOKEN", twirpErr.Meta("reason"))
+
+	assert.True(t, errors.As(ToTwirpError(sessionErrors.InvalidClaimError{Msg: "invalid claim"}), &twirpErr))
+	assert.Equal(t, twirp.PermissionDenied, twirpErr.Code())
+	assert.Equal(t, "INVALID_CLAIMS", twirpErr.Meta("reason"))
+
+	assert.NoError(t, ToTwirpError(nil))
+	assert.Equal(t, context.Canceled, ToTwirpError(context.Canceled))
+}
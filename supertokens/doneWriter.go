@@ -61,14 +61,29 @@ func MakeDoneWriter(w http.ResponseWriter) DoneWriter {
 
 type basicWriter struct {
 	http.ResponseWriter
-	done bool
+	done       bool
+	statusCode int
+}
+
+func (w *basicWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
 }
 
 func (w *basicWriter) Write(b []byte) (int, error) {
 	w.done = true
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
 	return w.ResponseWriter.Write(b)
 }
 
+func (w *basicWriter) getStatusCode() int {
+	return w.statusCode
+}
+
 func (w *basicWriter) IsDone() bool {
 	return w.done
 }
@@ -113,6 +128,17 @@ func (f *flushWriter) Flush() {
 
 /////////////////////////////////////////
 
+// getStatusCodeFromWriter returns the status code written to a writer created by
+// MakeDoneWriter, or 0 if no status code has been written yet.
+func getStatusCodeFromWriter(w http.ResponseWriter) int {
+	if sw, ok := w.(interface{ getStatusCode() int }); ok {
+		return sw.getStatusCode()
+	}
+	return 0
+}
+
+/////////////////////////////////////////
+
 // type checking to make sure that we have implemented all the interface functions correctly.
 var (
 	_ http.CloseNotifier = &fancyWriter{}
@@ -15,6 +15,11 @@
 
 package supertokens
 
+import (
+	"fmt"
+	"strings"
+)
+
 // BadInputError used for non specific exceptions
 type BadInputError struct {
 	Msg string
@@ -23,3 +28,15 @@ type BadInputError struct {
 func (err BadInputError) Error() string {
 	return err.Msg
 }
+
+// CoreAPIVersionError is returned if a feature needs a newer version of the core driver
+// interface (CDI) than the core supports.
+type CoreAPIVersionError struct {
+	Feature         string
+	MinVersion      string
+	SupportedByCore []string
+}
+
+func (err CoreAPIVersionError) Error() string {
+	return fmt.Sprintf("%s needs a SuperTokens core that supports CDI version %s or later, but the core only supports %s. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version", err.Feature, err.MinVersion, strings.Join(err.SupportedByCore, ", "))
+}
@@ -0,0 +1,145 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRecipeID = "testrecipe"
+
+type testRecipe struct {
+	appName string
+}
+
+func initTestRecipe() Recipe {
+	return func(appInfo NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error) {
+		stInstance, err := GetInstanceBeingInitialisedOrThrowError()
+		if err != nil {
+			return nil, err
+		}
+		stInstance.SetRecipeInstance(testRecipeID, &testRecipe{appName: appInfo.AppName})
+
+		recipeModule := MakeRecipeModule(testRecipeID, appInfo,
+			func(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path NormalisedURLPath, method string) error {
+				recipe := GetInstanceFromContext(req.Context()).GetRecipeInstance(testRecipeID).(*testRecipe)
+				_, err := res.Write([]byte(recipe.appName))
+				return err
+			},
+			func() []string {
+				return []string{}
+			},
+			func() ([]APIHandled, error) {
+				return []APIHandled{{
+					Method:                 http.MethodGet,
+					PathWithoutAPIBasePath: NormalisedURLPath{value: "/hello"},
+					ID:                     "hello",
+				}}, nil
+			},
+			nil,
+			func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+				return false, nil
+			},
+			onSuperTokensAPIError)
+		return &recipeModule, nil
+	}
+}
+
+func newTestInstance(t *testing.T, appName string) *Instance {
+	instance, err := New(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       appName,
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{initTestRecipe()},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return instance
+}
+
+func TestMultipleInstancesAreIndependent(t *testing.T) {
+	defer ResetForTest()
+
+	first := newTestInstance(t, "first")
+	second := newTestInstance(t, "second")
+
+	assert.Nil(t, GetDefaultInstance())
+	assert.Equal(t, "first", first.AppInfo.AppName)
+	assert.Equal(t, "second", second.AppInfo.AppName)
+	assert.NotEqual(t, first.GetRecipeInstance(testRecipeID), second.GetRecipeInstance(testRecipeID))
+
+	for _, instance := range []*Instance{first, second} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/auth/hello", nil)
+		instance.Middleware(nil).ServeHTTP(rec, req)
+		assert.Equal(t, instance.AppInfo.AppName, rec.Body.String())
+	}
+}
+
+func TestInitSetsDefaultInstance(t *testing.T) {
+	defer ResetForTest()
+
+	_, err := New(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       "other",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{initTestRecipe()},
+	})
+	assert.NoError(t, err)
+
+	err = Init(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       "default",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{initTestRecipe()},
+	})
+	assert.NoError(t, err)
+
+	instance, err := GetInstanceOrThrowError()
+	assert.NoError(t, err)
+	assert.Equal(t, "default", instance.AppInfo.AppName)
+	assert.Equal(t, instance, GetInstanceFromUserContext(MakeDefaultUserContextFromAPI(httptest.NewRequest(http.MethodGet, "/", nil))))
+}
+
+func TestDetachUserContextKeepsInstance(t *testing.T) {
+	defer ResetForTest()
+
+	instance := newTestInstance(t, "first")
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), instanceContextKey{}, instance))
+	req := httptest.NewRequest(http.MethodGet, "/auth/hello", nil).WithContext(ctx)
+	userContext := MakeDefaultUserContextFromAPI(req)
+	(*userContext)["locale"] = "de"
+
+	detached := DetachUserContext(userContext)
+	cancel()
+
+	assert.Equal(t, "de", (*detached)["locale"])
+	assert.Nil(t, GetRequestFromUserContext(detached))
+	assert.NoError(t, GetContextFromUserContext(detached).Err())
+	assert.Equal(t, instance, GetInstanceFromUserContext(detached))
+}
@@ -0,0 +1,105 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+)
+
+// Names of the spans created by the SDK.
+const (
+	SpanAPIRequest    = "supertokens.api"
+	SpanCoreRequest   = "supertokens.core.request"
+	SpanVerifySession = "supertokens.session.verify"
+)
+
+// Names of the counters and histograms recorded by the SDK.
+const (
+	MetricSignIns               = "supertokens.signins"
+	MetricSignUps               = "supertokens.signups"
+	MetricSessionRefreshes      = "supertokens.session.refreshes"
+	MetricTokenTheftDetections  = "supertokens.session.token_theft_detections"
+	MetricCoreRequestDurationMs = "supertokens.core.request.duration"
+)
+
+// Instrumentation receives the spans and measurements produced by the SDK. The
+// supertokens/otelinstrumentation package implements it on top of OpenTelemetry.
+type Instrumentation interface {
+	// StartSpan starts a span as a child of the span in ctx (if any) and returns
+	// a context that contains the new span.
+	StartSpan(ctx context.Context, name string, attributes map[string]interface{}) (context.Context, Span)
+	AddToCounter(ctx context.Context, name string, value int64, attributes map[string]interface{})
+	RecordHistogram(ctx context.Context, name string, value float64, attributes map[string]interface{})
+}
+
+type Span interface {
+	SetAttributes(attributes map[string]interface{})
+	RecordError(err error)
+	End()
+}
+
+type noopInstrumentation struct{}
+
+func (noopInstrumentation) StartSpan(ctx context.Context, name string, attributes map[string]interface{}) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopInstrumentation) AddToCounter(ctx context.Context, name string, value int64, attributes map[string]interface{}) {
+}
+
+func (noopInstrumentation) RecordHistogram(ctx context.Context, name string, value float64, attributes map[string]interface{}) {
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes map[string]interface{}) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
+
+// GetInstrumentation returns the instrumentation of the instance that is being initialised,
+// or else of the default instance. Code that handles a request should use
+// GetInstrumentationFromContext instead.
+func GetInstrumentation() Instrumentation {
+	instance := getInstanceBeingInitialised()
+	if instance == nil {
+		instance = GetDefaultInstance()
+	}
+	if instance == nil {
+		return noopInstrumentation{}
+	}
+	return instance.instrumentation
+}
+
+// GetInstrumentationFromContext returns the instrumentation of the instance whose middleware
+// is handling the request that ctx belongs to. If there is none, GetInstrumentation is used.
+func GetInstrumentationFromContext(ctx context.Context) Instrumentation {
+	if instance := GetInstanceFromContext(ctx); instance != nil {
+		return instance.instrumentation
+	}
+	return GetInstrumentation()
+}
+
+// GetInstrumentation returns the instrumentation that was passed to the config of this instance.
+func (s *Instance) GetInstrumentation() Instrumentation {
+	return s.instrumentation
+}
+
+// GetInstrumentation returns the instrumentation of the instance that this querier belongs to.
+func (q *Querier) GetInstrumentation() Instrumentation {
+	return q.state.instrumentation
+}
@@ -1,33 +1,117 @@
 package supertokens
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 )
 
 const supertokens_namespace = "com.supertokens"
 
+// Logger receives all log messages of the SDK. Every message has a level and an
+// optional list of alternating keys and values. A *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
 /*
- The debug logger below can be used to log debug messages in the following format
-    com.supertokens {t: "2022-03-21T17:10:42+05:30", message: "Test Message", file: "/home/supertokens-golang/supertokens/supertokens.go:51" sdkVer: "0.5.2"}
+ The default logger below logs messages only if the SUPERTOKENS_DEBUG env var is set, in the following format
+    com.supertokens {t: "2022-03-21T17:10:42+05:30", level: "debug", message: "Test Message", file: "/home/supertokens-golang/supertokens/supertokens.go:51" sdkVer: "0.5.2"}
 */
 
-var (
-	logger = log.New(os.Stdout, supertokens_namespace, 0)
-)
+var defaultStdoutLogger = log.New(os.Stdout, supertokens_namespace, 0)
+
+type defaultLogger struct{}
 
-func formatMessage(message string) string {
-	_, file, line, _ := runtime.Caller(3)
-	return fmt.Sprintf(" {t: \"%s\", message: \"%s\", file: \"%s:%d\" sdkVer: \"%s\"}\n\n", time.Now().Format(time.RFC3339), message, file, line, VERSION)
+func (l defaultLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log("debug", msg, keysAndValues)
 }
 
-func LogDebugMessage(message string) {
+func (l defaultLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("info", msg, keysAndValues)
+}
+
+func (l defaultLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("warn", msg, keysAndValues)
+}
+
+func (l defaultLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("error", msg, keysAndValues)
+}
+
+func (l defaultLogger) log(level string, msg string, keysAndValues []interface{}) {
 	_, exists := os.LookupEnv("SUPERTOKENS_DEBUG")
 	if exists {
-		logger.Printf(formatMessage(message))
+		defaultStdoutLogger.Print(formatMessage(level, msg, keysAndValues))
+	}
+}
+
+func formatMessage(level string, message string, keysAndValues []interface{}) string {
+	file, line := getCallerOutsideLogger()
+	fields := ""
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields += fmt.Sprintf(" %v: \"%v\",", keysAndValues[i], keysAndValues[i+1])
+	}
+	return fmt.Sprintf(" {t: \"%s\", level: \"%s\", message: \"%s\",%s file: \"%s:%d\" sdkVer: \"%s\"}\n\n", time.Now().Format(time.RFC3339), level, message, fields, file, line, VERSION)
+}
+
+// getCallerOutsideLogger returns the location of the first function
+// in the stack that is not part of the logging code.
+func getCallerOutsideLogger() (string, int) {
+	pcs := make([]uintptr, 10)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasSuffix(frame.File, "supertokens/logger.go") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return frame.File, frame.Line
+		}
+	}
+}
+
+// GetLogger returns the logger of the instance that is being initialised, or else of the
+// default instance. Code that handles a request should use GetLoggerFromContext instead,
+// so that messages go to the logger of the instance that is handling it.
+func GetLogger() Logger {
+	instance := getInstanceBeingInitialised()
+	if instance == nil {
+		instance = GetDefaultInstance()
+	}
+	if instance == nil {
+		return defaultLogger{}
+	}
+	return instance.logger
+}
 
+// GetLoggerFromContext returns the logger of the instance whose middleware is handling the
+// request that ctx belongs to. If there is none, GetLogger is used.
+func GetLoggerFromContext(ctx context.Context) Logger {
+	if instance := GetInstanceFromContext(ctx); instance != nil {
+		return instance.logger
 	}
+	return GetLogger()
+}
+
+// GetLogger returns the logger that was passed to the config of this instance.
+func (s *Instance) GetLogger() Logger {
+	return s.logger
+}
+
+// GetLogger returns the logger of the instance that this querier belongs to.
+func (q *Querier) GetLogger() Logger {
+	return q.state.logger
+}
+
+func LogDebugMessage(message string) {
+	GetLogger().Debug(message)
 }
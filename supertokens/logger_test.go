@@ -0,0 +1,138 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loggedMessage struct {
+	level         string
+	msg           string
+	keysAndValues map[string]interface{}
+}
+
+type recordingLogger struct {
+	lock     sync.Mutex
+	messages []loggedMessage
+}
+
+func (l *recordingLogger) record(level string, msg string, keysAndValues []interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.messages = append(l.messages, loggedMessage{level: level, msg: msg, keysAndValues: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.record("debug", msg, keysAndValues)
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.record("info", msg, keysAndValues)
+}
+
+func (l *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.record("warn", msg, keysAndValues)
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.record("error", msg, keysAndValues)
+}
+
+func (l *recordingLogger) find(msg string) *loggedMessage {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, m := range l.messages {
+		if m.msg == msg {
+			return &m
+		}
+	}
+	return nil
+}
+
+func makeTestRecipe(recipeID string, apiID string, path string) Recipe {
+	return func(appInfo NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error) {
+		recipeModule := MakeRecipeModule(recipeID, appInfo, func(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path NormalisedURLPath, method string) error {
+			return SendNon200ResponseWithMessage(res, "teapot", 418)
+		}, func() []string {
+			return []string{}
+		}, func() ([]APIHandled, error) {
+			return []APIHandled{{
+				PathWithoutAPIBasePath: NormalisedURLPath{value: path},
+				Method:                 http.MethodGet,
+				ID:                     apiID,
+			}}, nil
+		}, nil, func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+			return false, nil
+		}, onSuperTokensAPIError)
+		return &recipeModule, nil
+	}
+}
+
+func TestMiddlewareLogsStructuredEventToConfiguredLogger(t *testing.T) {
+	resetAll()
+	defer resetAll()
+
+	l := &recordingLogger{}
+	err := Init(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       "SuperTokens",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{makeTestRecipe("test", "testapi", "/test")},
+		Logger:     l,
+	})
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	Middleware(nil).ServeHTTP(rec, httptest.NewRequest("GET", "/auth/test", nil))
+	assert.Equal(t, 418, rec.Code)
+
+	m := l.find("middleware: API handled")
+	if !assert.NotNil(t, m) {
+		return
+	}
+	assert.Equal(t, "info", m.level)
+	assert.Equal(t, "test", m.keysAndValues["recipeId"])
+	assert.Equal(t, "testapi", m.keysAndValues["apiId"])
+	assert.Equal(t, "/auth/test", m.keysAndValues["path"])
+	assert.Equal(t, 418, m.keysAndValues["status"])
+	assert.Contains(t, m.keysAndValues, "latencyMs")
+
+	// debug messages that do not have fields also go to the configured logger
+	assert.NotNil(t, l.find("middleware: Started"))
+}
+
+func TestDefaultLoggerFormatsMessageWithFieldsAndCaller(t *testing.T) {
+	message := formatMessage("info", "hello", []interface{}{"recipeId", "session", "status", 200})
+	assert.Contains(t, message, `level: "info"`)
+	assert.Contains(t, message, `message: "hello"`)
+	assert.Contains(t, message, `recipeId: "session",`)
+	assert.Contains(t, message, `status: "200",`)
+	assert.Contains(t, message, "logger_test.go")
+	assert.False(t, strings.Contains(message, "supertokens/logger.go"))
+}
@@ -16,21 +16,35 @@
 package supertokens
 
 import (
+	"errors"
 	"net/http"
 )
 
+// Init creates the default instance, which is used by the package level functions
+// of this package and of the recipes. Calling Init again has no effect.
 func Init(config TypeInput) error {
-	err := supertokensInit(config)
-	if err != nil {
-		return err
+	initLock.Lock()
+	defer initLock.Unlock()
+	if GetDefaultInstance() != nil {
+		return nil
 	}
-	err = runPostInitCallbacks()
+	_, err := newInstance(config, true)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// New creates an instance that is independent of the default instance and of other
+// instances created by New, so that several apps with their own AppInfo and core can
+// be served from one process. Use the instance's Middleware to handle its APIs, and
+// the GetRecipeInstanceFor function of a recipe package to access its recipes.
+func New(config TypeInput) (*Instance, error) {
+	initLock.Lock()
+	defer initLock.Unlock()
+	return newInstance(config, false)
+}
+
 func Middleware(theirHandler http.Handler) http.Handler {
 	instance, err := GetInstanceOrThrowError()
 	if err != nil {
@@ -40,9 +54,13 @@ func Middleware(theirHandler http.Handler) http.Handler {
 }
 
 func ErrorHandler(err error, req *http.Request, res http.ResponseWriter) error {
-	instance, instanceErr := GetInstanceOrThrowError()
-	if instanceErr != nil {
-		return instanceErr
+	instance := GetInstanceFromContext(req.Context())
+	if instance == nil {
+		var instanceErr error
+		instance, instanceErr = GetInstanceOrThrowError()
+		if instanceErr != nil {
+			return instanceErr
+		}
 	}
 	return instance.errorHandler(err, req, res)
 }
@@ -56,17 +74,78 @@ func GetAllCORSHeaders() []string {
 }
 
 func GetUserCount(includeRecipeIds *[]string) (float64, error) {
-	return getUserCount(includeRecipeIds)
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return -1, err
+	}
+	return instance.GetUserCount(includeRecipeIds)
 }
 
 func GetUsersOldestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return getUsers("ASC", paginationToken, limit, includeRecipeIds)
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return UserPaginationResult{}, err
+	}
+	return instance.GetUsersOldestFirst(paginationToken, limit, includeRecipeIds)
 }
 
 func GetUsersNewestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return getUsers("DESC", paginationToken, limit, includeRecipeIds)
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return UserPaginationResult{}, err
+	}
+	return instance.GetUsersNewestFirst(paginationToken, limit, includeRecipeIds)
 }
 
 func DeleteUser(userId string) error {
-	return deleteUser(userId)
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	return instance.DeleteUser(userId)
+}
+
+// GetCoreHostsStatus returns the health of every core in ConnectionURI,
+// for example to be used in a readiness probe.
+func GetCoreHostsStatus() ([]QuerierHostStatus, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return instance.GetCoreHostsStatus()
+}
+
+func (s *Instance) Middleware(theirHandler http.Handler) http.Handler {
+	return s.middleware(theirHandler)
+}
+
+func (s *Instance) ErrorHandler(err error, req *http.Request, res http.ResponseWriter) error {
+	return s.errorHandler(err, req, res)
+}
+
+func (s *Instance) GetAllCORSHeaders() []string {
+	return s.getAllCORSHeaders()
+}
+
+func (s *Instance) GetUserCount(includeRecipeIds *[]string) (float64, error) {
+	return s.getUserCount(includeRecipeIds)
+}
+
+func (s *Instance) GetUsersOldestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	return s.getUsers("ASC", paginationToken, limit, includeRecipeIds)
+}
+
+func (s *Instance) GetUsersNewestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+	return s.getUsers("DESC", paginationToken, limit, includeRecipeIds)
+}
+
+func (s *Instance) DeleteUser(userId string) error {
+	return s.deleteUser(userId)
+}
+
+func (s *Instance) GetCoreHostsStatus() ([]QuerierHostStatus, error) {
+	if s.querier == nil {
+		return nil, errors.New("please provide config.Supertokens to use the SuperTokens core")
+	}
+	return s.querier.getHostsStatus(), nil
 }
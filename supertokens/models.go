@@ -17,6 +17,7 @@ package supertokens
 
 import (
 	"net/http"
+	"time"
 )
 
 type NormalisedAppinfo struct {
@@ -45,11 +46,28 @@ type TypeInput struct {
 	RecipeList            []Recipe
 	Telemetry             *bool
 	OnSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)
+	// Logger receives the log messages of the SDK. Defaults to a logger that writes to
+	// stdout if the SUPERTOKENS_DEBUG env var is set.
+	Logger Logger
+	// Instrumentation receives spans and metrics from the SDK. Nothing is recorded if this is nil.
+	Instrumentation Instrumentation
+	// SecurityEventListeners receive events such as token theft or failed sign ins.
+	SecurityEventListeners []SecurityEventListener
 }
 
 type ConnectionInfo struct {
 	ConnectionURI string
 	APIKey        string
+	// HTTPClient is used for all requests to the core. If this is nil,
+	// a client using Transport is created once and shared.
+	HTTPClient *http.Client
+	// Transport is used only if HTTPClient is nil. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Timeout applies to each request sent to the core. Zero means no timeout
+	// other than the one set by the context of the request.
+	Timeout time.Duration
+	// HostPool configures failover between the cores in ConnectionURI.
+	HostPool *CoreHostPoolConfig
 }
 
 type APIHandled struct {
@@ -0,0 +1,156 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package otelinstrumentation reports the spans and metrics of the SDK to OpenTelemetry.
+// It is a separate module so that applications that do not use OpenTelemetry
+// do not need to depend on it.
+package otelinstrumentation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/supertokens/supertokens-golang"
+
+type TypeInput struct {
+	// TracerProvider defaults to the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// MeterProvider defaults to the global meter provider.
+	MeterProvider metric.MeterProvider
+}
+
+type instrumentation struct {
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	lock       sync.Mutex
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// New returns an implementation of supertokens.Instrumentation that can be passed
+// to supertokens.TypeInput.Instrumentation.
+func New(config *TypeInput) supertokens.Instrumentation {
+	tracerProvider := otel.GetTracerProvider()
+	meterProvider := otel.GetMeterProvider()
+	if config != nil {
+		if config.TracerProvider != nil {
+			tracerProvider = config.TracerProvider
+		}
+		if config.MeterProvider != nil {
+			meterProvider = config.MeterProvider
+		}
+	}
+	return &instrumentation{
+		tracer:     tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(supertokens.VERSION)),
+		meter:      meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(supertokens.VERSION)),
+		counters:   map[string]metric.Int64Counter{},
+		histograms: map[string]metric.Float64Histogram{},
+	}
+}
+
+func (i *instrumentation) StartSpan(ctx context.Context, name string, attributes map[string]interface{}) (context.Context, supertokens.Span) {
+	kind := trace.SpanKindInternal
+	if name == supertokens.SpanCoreRequest {
+		kind = trace.SpanKindClient
+	}
+	ctx, span := i.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(toAttributes(attributes)...))
+	return ctx, otelSpan{span: span}
+}
+
+func (i *instrumentation) AddToCounter(ctx context.Context, name string, value int64, attributes map[string]interface{}) {
+	i.lock.Lock()
+	counter, ok := i.counters[name]
+	if !ok {
+		var err error
+		counter, err = i.meter.Int64Counter(name)
+		if err != nil {
+			i.lock.Unlock()
+			otel.Handle(err)
+			return
+		}
+		i.counters[name] = counter
+	}
+	i.lock.Unlock()
+	counter.Add(ctx, value, metric.WithAttributes(toAttributes(attributes)...))
+}
+
+func (i *instrumentation) RecordHistogram(ctx context.Context, name string, value float64, attributes map[string]interface{}) {
+	i.lock.Lock()
+	histogram, ok := i.histograms[name]
+	if !ok {
+		var err error
+		options := []metric.Float64HistogramOption{}
+		if name == supertokens.MetricCoreRequestDurationMs {
+			options = append(options, metric.WithUnit("ms"))
+		}
+		histogram, err = i.meter.Float64Histogram(name, options...)
+		if err != nil {
+			i.lock.Unlock()
+			otel.Handle(err)
+			return
+		}
+		i.histograms[name] = histogram
+	}
+	i.lock.Unlock()
+	histogram.Record(ctx, value, metric.WithAttributes(toAttributes(attributes)...))
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttributes(attributes map[string]interface{}) {
+	s.span.SetAttributes(toAttributes(attributes)...)
+}
+
+func (s otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s otelSpan) End() {
+	s.span.End()
+}
+
+func toAttributes(attributes map[string]interface{}) []attribute.KeyValue {
+	result := make([]attribute.KeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		switch value := v.(type) {
+		case string:
+			result = append(result, attribute.String(k, value))
+		case int:
+			result = append(result, attribute.Int(k, value))
+		case int64:
+			result = append(result, attribute.Int64(k, value))
+		case float64:
+			result = append(result, attribute.Float64(k, value))
+		case bool:
+			result = append(result, attribute.Bool(k, value))
+		default:
+			result = append(result, attribute.String(k, fmt.Sprint(value)))
+		}
+	}
+	return result
+}
@@ -0,0 +1,182 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package otelinstrumentation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/usermetadata"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func makeCore() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.15"]}`))
+			return
+		}
+		rw.Write([]byte(`{"status":"OK","metadata":{}}`))
+	}))
+}
+
+func makeTestRecipe(appInfo supertokens.NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*supertokens.RecipeModule, error) {
+	path, err := supertokens.NewNormalisedURLPath("/metadata")
+	if err != nil {
+		return nil, err
+	}
+	recipeModule := supertokens.MakeRecipeModule("test", appInfo, func(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path supertokens.NormalisedURLPath, method string) error {
+		metadata, err := usermetadata.GetUserMetadataWithContext("userId", supertokens.MakeDefaultUserContextFromAPI(req))
+		if err != nil {
+			return err
+		}
+		return supertokens.Send200Response(res, metadata)
+	}, func() []string {
+		return []string{}
+	}, func() ([]supertokens.APIHandled, error) {
+		return []supertokens.APIHandled{{
+			PathWithoutAPIBasePath: path,
+			Method:                 http.MethodGet,
+			ID:                     "metadata",
+		}}, nil
+	}, nil, func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+		return false, nil
+	}, onSuperTokensAPIError)
+	return &recipeModule, nil
+}
+
+func setUp(t *testing.T) (*tracetest.InMemoryExporter, *sdkmetric.ManualReader, func()) {
+	core := makeCore()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: core.URL,
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{usermetadata.Init(nil), makeTestRecipe},
+		Instrumentation: New(&TypeInput{
+			TracerProvider: tracerProvider,
+			MeterProvider:  meterProvider,
+		}),
+	})
+	assert.NoError(t, err)
+
+	return exporter, reader, func() {
+		supertokens.ResetForTest()
+		usermetadata.ResetForTest()
+		core.Close()
+	}
+}
+
+func findSpan(spans tracetest.SpanStubs, name string, path string) *tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name != name {
+			continue
+		}
+		for _, attr := range span.Attributes {
+			if (attr.Key == "supertokens.core.path" || attr.Key == "http.target") && attr.Value.AsString() == path {
+				return &span
+			}
+		}
+	}
+	return nil
+}
+
+func getAttribute(attributes []attribute.KeyValue, key string) attribute.Value {
+	for _, attr := range attributes {
+		if string(attr.Key) == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestAPIAndCoreRequestSpans(t *testing.T) {
+	exporter, reader, tearDown := setUp(t)
+	defer tearDown()
+
+	rec := httptest.NewRecorder()
+	supertokens.Middleware(nil).ServeHTTP(rec, httptest.NewRequest("GET", "/auth/metadata", nil))
+	assert.Equal(t, 200, rec.Code)
+
+	spans := exporter.GetSpans()
+	apiSpan := findSpan(spans, supertokens.SpanAPIRequest, "/auth/metadata")
+	coreSpan := findSpan(spans, supertokens.SpanCoreRequest, "/recipe/user/metadata")
+	if !assert.NotNil(t, apiSpan) || !assert.NotNil(t, coreSpan) {
+		return
+	}
+
+	assert.Equal(t, "metadata", getAttribute(apiSpan.Attributes, "supertokens.api_id").AsString())
+	assert.Equal(t, "test", getAttribute(apiSpan.Attributes, "supertokens.recipe_id").AsString())
+	assert.Equal(t, int64(200), getAttribute(apiSpan.Attributes, "http.status_code").AsInt64())
+
+	assert.Equal(t, apiSpan.SpanContext.SpanID(), coreSpan.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, coreSpan.SpanKind)
+	assert.Equal(t, "2.15", getAttribute(coreSpan.Attributes, "supertokens.cdi_version").AsString())
+	assert.Equal(t, int64(200), getAttribute(coreSpan.Attributes, "http.status_code").AsInt64())
+
+	var metrics metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &metrics))
+	found := false
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			if m.Name == supertokens.MetricCoreRequestDurationMs {
+				found = true
+				assert.Equal(t, "ms", m.Unit)
+			}
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestCounters(t *testing.T) {
+	_, reader, tearDown := setUp(t)
+	defer tearDown()
+
+	supertokens.GetInstrumentation().AddToCounter(context.Background(), supertokens.MetricSignIns, 1, map[string]interface{}{"supertokens.login_method": "emailpassword"})
+	supertokens.GetInstrumentation().AddToCounter(context.Background(), supertokens.MetricSignIns, 2, map[string]interface{}{"supertokens.login_method": "emailpassword"})
+
+	var metrics metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &metrics))
+	var total int64 = 0
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			if m.Name == supertokens.MetricSignIns {
+				for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					total += point.Value
+				}
+			}
+		}
+	}
+	assert.Equal(t, int64(3), total)
+}
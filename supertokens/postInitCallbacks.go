@@ -1,18 +1,29 @@
 package supertokens
 
+// postInitCallbacks holds the callbacks that were added while no instance was being
+// initialised. They are run by the next instance that is initialised.
 var postInitCallbacks = []func() error{}
 
+// AddPostInitCallback adds a callback that is run once all the recipes of the instance
+// being initialised have been created.
 func AddPostInitCallback(cb func() error) {
-	postInitCallbacks = append(postInitCallbacks, cb)
+	instance := getInstanceBeingInitialised()
+	if instance == nil {
+		postInitCallbacks = append(postInitCallbacks, cb)
+		return
+	}
+	instance.postInitCallbacks = append(instance.postInitCallbacks, cb)
 }
 
-func runPostInitCallbacks() error {
-	for _, cb := range postInitCallbacks {
+func (s *Instance) runPostInitCallbacks() error {
+	callbacks := append(postInitCallbacks, s.postInitCallbacks...)
+	postInitCallbacks = []func() error{}
+	s.postInitCallbacks = []func() error{}
+	for _, cb := range callbacks {
 		err := cb()
 		if err != nil {
 			return err
 		}
 	}
-	postInitCallbacks = []func() error{}
 	return nil
 }
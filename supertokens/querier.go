@@ -17,17 +17,19 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 	"sync"
+	"time"
 )
 
 type Querier struct {
 	RIDToCore string
+	state     *querierState
 }
 
 type QuerierHost struct {
@@ -36,83 +38,164 @@ type QuerierHost struct {
 }
 
 var (
-	querierInitCalled     bool          = false
-	QuerierHosts          []QuerierHost = nil
-	QuerierAPIKey         *string
-	querierAPIVersion     string
-	querierLastTriedIndex int
-	querierLock           sync.Mutex
-	querierHostLock       sync.Mutex
+	// Deprecated: QuerierHosts mirrors the cores of the default instance, which is created by
+	// Init. Changing it has no effect; use GetCoreHostsStatus to read the cores.
+	QuerierHosts []QuerierHost = nil
+	// Deprecated: QuerierAPIKey mirrors the API key of the default instance, which is created by
+	// Init. Changing it has no effect.
+	QuerierAPIKey *string
 )
 
+// querierState is shared by all the queriers of one SuperTokens instance.
+type querierState struct {
+	hosts           []QuerierHost
+	apiKey          *string
+	apiVersion      string
+	coreAPIVersions []string
+	apiVersionLock  sync.Mutex
+	httpClient      *http.Client
+	timeout         time.Duration
+	logger          Logger
+	instrumentation Instrumentation
+
+	hostLock       sync.Mutex
+	hostStates     []*querierHostState
+	hostPoolConfig normalisedCoreHostPoolConfig
+	lastTriedIndex int
+}
+
 func (q *Querier) GetQuerierAPIVersion() (string, error) {
-	querierLock.Lock()
-	defer querierLock.Unlock()
-	if querierAPIVersion != "" {
-		return querierAPIVersion, nil
+	return q.GetQuerierAPIVersionWithContext(context.Background())
+}
+
+func (q *Querier) GetQuerierAPIVersionWithContext(ctx context.Context) (string, error) {
+	q.state.apiVersionLock.Lock()
+	defer q.state.apiVersionLock.Unlock()
+	err := q.loadAPIVersionsLocked(ctx)
+	if err != nil {
+		return "", err
+	}
+	return q.state.apiVersion, nil
+}
+
+// RequireCoreAPIVersionWithContext returns a CoreAPIVersionError if the core does not support
+// version minVersion of the core driver interface (CDI) or a later one. Features that use endpoints
+// which are newer than the CDI versions of this SDK call it before querying the core.
+func (q *Querier) RequireCoreAPIVersionWithContext(ctx context.Context, minVersion string, feature string) error {
+	q.state.apiVersionLock.Lock()
+	err := q.loadAPIVersionsLocked(ctx)
+	coreAPIVersions := q.state.coreAPIVersions
+	q.state.apiVersionLock.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, version := range coreAPIVersions {
+		if maxVersion(version, minVersion) == version {
+			return nil
+		}
+	}
+	return CoreAPIVersionError{
+		Feature:         feature,
+		MinVersion:      minVersion,
+		SupportedByCore: coreAPIVersions,
+	}
+}
+
+// loadAPIVersionsLocked asks the core for the CDI versions that it supports, unless it was
+// already done. It has to be called with apiVersionLock held.
+func (q *Querier) loadAPIVersionsLocked(ctx context.Context) error {
+	if q.state.apiVersion != "" {
+		return nil
 	}
-	response, err := q.sendRequestHelper(NormalisedURLPath{value: "/apiversion"}, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
+	response, err := q.sendRequestHelper(ctx, NormalisedURLPath{value: "/apiversion"}, "GET", func(ctx context.Context, url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
-		if QuerierAPIKey != nil {
-			req.Header.Set("api-key", *QuerierAPIKey)
+		if q.state.apiKey != nil {
+			req.Header.Set("api-key", *q.state.apiKey)
 		}
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return q.state.httpClient.Do(req)
+	})
 
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	respJSON, err := json.Marshal(response)
 	if err != nil {
-		return "", err
+		return err
 	}
 	var cdiSupportedByServer struct {
 		Versions []string `json:"versions"`
 	}
 	err = json.Unmarshal(respJSON, &cdiSupportedByServer)
 	if err != nil {
-		return "", err
+		return err
 	}
 	supportedVersion := getLargestVersionFromIntersection(cdiSupportedByServer.Versions, cdiSupported)
 	if supportedVersion == nil {
-		return "", errors.New("the running SuperTokens core version is not compatible with this Golang SDK. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version")
+		return errors.New("the running SuperTokens core version is not compatible with this Golang SDK. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version")
 	}
 
-	querierAPIVersion = *supportedVersion
+	q.state.apiVersion = *supportedVersion
+	q.state.coreAPIVersions = cdiSupportedByServer.Versions
 
-	return querierAPIVersion, nil
+	return nil
 }
 
+// GetNewQuerierInstanceOrThrowError returns a querier for the instance whose recipes are
+// being created if it is called from a recipe's init function, and a querier for the
+// default instance otherwise. Use Instance.GetQuerier to query the core of a specific instance.
 func GetNewQuerierInstanceOrThrowError(rIDToCore string) (*Querier, error) {
-	if !querierInitCalled {
+	instance := getInstanceBeingInitialised()
+	if instance == nil {
+		instance = GetDefaultInstance()
+	}
+	if instance == nil || instance.querier == nil {
 		return nil, errors.New("please call the supertokens.init function before using SuperTokens")
 	}
-	return &Querier{RIDToCore: rIDToCore}, nil
+	return instance.GetQuerier(rIDToCore)
 }
 
-func initQuerier(hosts []QuerierHost, APIKey string) {
-	if !querierInitCalled {
-		querierInitCalled = true
-		QuerierHosts = hosts
-		if APIKey != "" {
-			QuerierAPIKey = &APIKey
-		}
-		querierAPIVersion = ""
-		querierLastTriedIndex = 0
+func newQuerierState(hosts []QuerierHost, APIKey string, httpClient *http.Client, timeout time.Duration, hostPoolConfig *CoreHostPoolConfig, logger Logger, instrumentation Instrumentation) *querierState {
+	state := &querierState{
+		hosts:           hosts,
+		httpClient:      httpClient,
+		timeout:         timeout,
+		logger:          logger,
+		instrumentation: instrumentation,
 	}
+	if APIKey != "" {
+		state.apiKey = &APIKey
+	}
+	state.initHostPool(hosts, hostPoolConfig)
+	return state
+}
+
+// makeQuerierHTTPClient returns the client that is shared by all requests
+// to the core, so that connections to it are reused.
+func makeQuerierHTTPClient(connectionInfo ConnectionInfo) *http.Client {
+	if connectionInfo.HTTPClient != nil {
+		return connectionInfo.HTTPClient
+	}
+	transport := connectionInfo.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &http.Client{Transport: transport}
 }
 
 func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendPostRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendPostRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	return q.sendRequestHelper(ctx, nP, "POST", func(ctx context.Context, url string) (*http.Response, error) {
 		if data == nil {
 			data = map[string]interface{}{}
 		}
@@ -120,71 +203,77 @@ func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersionWithContext(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
 
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if QuerierAPIKey != nil {
-			req.Header.Set("api-key", *QuerierAPIKey)
+		if q.state.apiKey != nil {
+			req.Header.Set("api-key", *q.state.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return q.state.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendDeleteRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendDeleteRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	return q.sendRequestHelper(ctx, nP, "DELETE", func(ctx context.Context, url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersionWithContext(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
 
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if QuerierAPIKey != nil {
-			req.Header.Set("api-key", *QuerierAPIKey)
+		if q.state.apiKey != nil {
+			req.Header.Set("api-key", *q.state.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return q.state.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendGetRequest(path string, params map[string]string) (map[string]interface{}, error) {
+	return q.SendGetRequestWithContext(context.Background(), path, params)
+}
+
+func (q *Querier) SendGetRequestWithContext(ctx context.Context, path string, params map[string]string) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
+	return q.sendRequestHelper(ctx, nP, "GET", func(ctx context.Context, url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -196,90 +285,189 @@ func (q *Querier) SendGetRequest(path string, params map[string]string) (map[str
 		}
 		req.URL.RawQuery = query.Encode()
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersionWithContext(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
 		req.Header.Set("cdi-version", apiVerion)
-		if QuerierAPIKey != nil {
-			req.Header.Set("api-key", *QuerierAPIKey)
+		if q.state.apiKey != nil {
+			req.Header.Set("api-key", *q.state.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return q.state.httpClient.Do(req)
+	})
 }
 
 func (q *Querier) SendPutRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return q.SendPutRequestWithContext(context.Background(), path, data)
+}
+
+func (q *Querier) SendPutRequestWithContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	return q.sendRequestHelper(ctx, nP, "PUT", func(ctx context.Context, url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersionWithContext(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
 
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 		req.Header.Set("cdi-version", apiVerion)
-		if QuerierAPIKey != nil {
-			req.Header.Set("api-key", *QuerierAPIKey)
+		if q.state.apiKey != nil {
+			req.Header.Set("api-key", *q.state.apiKey)
 		}
 		if nP.IsARecipePath() && q.RIDToCore != "" {
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return q.state.httpClient.Do(req)
+	})
 }
 
-type httpRequestFunction func(url string) (*http.Response, error)
+type httpRequestFunction func(ctx context.Context, url string) (*http.Response, error)
+
+func (q *Querier) sendRequestHelper(ctx context.Context, path NormalisedURLPath, method string, httpRequest httpRequestFunction) (map[string]interface{}, error) {
+	path, err := q.getPathForTenant(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	maxRetries := 0
+	if method == "GET" {
+		maxRetries = q.state.hostPoolConfig.maxGetRetries
+	}
+
+	var lastErr error = nil
+	for retry := 0; retry <= maxRetries; retry++ {
+		if retry > 0 {
+			q.state.logger.Debug("querier: Retrying request to path: " + path.GetAsStringDangerous())
+			err := q.state.waitForRetry(ctx, retry)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tried := map[int]bool{}
+		for {
+			index, needsProbe, found := q.state.pickHost(tried)
+			if !found {
+				break
+			}
+			tried[index] = true
+			if needsProbe && !q.state.probeHost(ctx, index) {
+				continue
+			}
+
+			result, retriable, err := q.sendRequestToHost(ctx, index, path, method, httpRequest)
+			if err == nil {
+				return result, nil
+			}
+			if !retriable {
+				return nil, err
+			}
+			lastErr = err
+		}
+	}
 
-func (q *Querier) sendRequestHelper(path NormalisedURLPath, httpRequest httpRequestFunction, numberOfTries int) (map[string]interface{}, error) {
-	if numberOfTries == 0 {
+	if lastErr == nil || isConnectionError(lastErr) {
 		return nil, errors.New("no SuperTokens core available to query")
 	}
+	return nil, lastErr
+}
 
-	querierHostLock.Lock()
-	currentDomain := QuerierHosts[querierLastTriedIndex].Domain.GetAsStringDangerous()
-	currentBasePath := QuerierHosts[querierLastTriedIndex].BasePath.GetAsStringDangerous()
-	querierLastTriedIndex = (querierLastTriedIndex + 1) % len(QuerierHosts)
-	querierHostLock.Unlock()
+// sendRequestToHost returns retriable as true if the request failed in a way that
+// allows it to be sent to another core.
+func (q *Querier) sendRequestToHost(ctx context.Context, index int, path NormalisedURLPath, method string, httpRequest httpRequestFunction) (result map[string]interface{}, retriable bool, err error) {
+	// the timeout applies to each attempt separately, so that a host that
+	// does not respond does not use up the time meant for the next one.
+	requestCtx := ctx
+	if q.state.timeout > 0 {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, q.state.timeout)
+		defer cancel()
+	}
 
-	resp, err := httpRequest(currentDomain + currentBasePath + path.GetAsStringDangerous())
+	host := q.state.hostStates[index].getURL()
+	spanAttributes := map[string]interface{}{
+		"supertokens.core.path": path.GetAsStringDangerous(),
+		"supertokens.core.host": host,
+		"http.method":           method,
+	}
+	requestCtx, span := q.state.instrumentation.StartSpan(requestCtx, SpanCoreRequest, spanAttributes)
+	defer span.End()
 
-	if err != nil {
-		if strings.Contains(err.Error(), "connection refused") {
-			return q.sendRequestHelper(path, httpRequest, numberOfTries-1)
+	startTime := time.Now()
+	resp, err := httpRequest(requestCtx, host+path.GetAsStringDangerous())
+	defer func() {
+		if resp != nil {
+			spanAttributes["http.status_code"] = resp.StatusCode
+			if resp.Request != nil && resp.Request.Header.Get("cdi-version") != "" {
+				spanAttributes["supertokens.cdi_version"] = resp.Request.Header.Get("cdi-version")
+			}
 		}
+		span.SetAttributes(spanAttributes)
+		q.state.instrumentation.RecordHistogram(ctx, MetricCoreRequestDurationMs, float64(time.Since(startTime).Milliseconds()), spanAttributes)
+	}()
+
+	if err != nil {
+		span.RecordError(err)
 		if resp != nil {
 			resp.Body.Close()
 		}
-		return nil, err
+		q.state.logger.Warn("querier: Request to core failed",
+			"corePath", path.GetAsStringDangerous(),
+			"method", method,
+			"host", host,
+			"latencyMs", time.Since(startTime).Milliseconds(),
+			"error", err.Error())
+		if ctx.Err() != nil {
+			// the caller gave up, this says nothing about the health of the core.
+			return nil, false, err
+		}
+		if isConnectionError(err) {
+			q.state.recordHostFailure(index, err)
+			return nil, true, err
+		}
+		if isTimeoutError(err) {
+			q.state.recordHostFailure(index, err)
+			return nil, method == "GET", err
+		}
+		return nil, false, err
 	}
 
 	defer resp.Body.Close()
 
 	body, readErr := ioutil.ReadAll(resp.Body)
 	if readErr != nil {
-		return nil, readErr
+		return nil, false, readErr
+	}
+	q.state.logger.Debug("querier: Request to core completed",
+		"corePath", path.GetAsStringDangerous(),
+		"method", method,
+		"host", host,
+		"status", resp.StatusCode,
+		"latencyMs", time.Since(startTime).Milliseconds())
+	if resp.StatusCode >= 500 {
+		err = fmt.Errorf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body)
+		span.RecordError(err)
+		q.state.recordHostFailure(index, err)
+		return nil, method == "GET", err
 	}
+	q.state.recordHostSuccess(index)
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body)
+		return nil, false, fmt.Errorf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body)
 	}
 
 	finalResult := make(map[string]interface{})
@@ -287,11 +475,11 @@ func (q *Querier) sendRequestHelper(path NormalisedURLPath, httpRequest httpRequ
 	if jsonError != nil {
 		return map[string]interface{}{
 			"result": string(body),
-		}, nil
+		}, false, nil
 	}
-	return finalResult, nil
+	return finalResult, false, nil
 }
 
-func ResetQuerierForTest() {
-	querierInitCalled = false
-}
+// Deprecated: the state of the querier belongs to its instance now, so there is nothing to reset.
+// Use ResetForTest to remove the default instance.
+func ResetQuerierForTest() {}
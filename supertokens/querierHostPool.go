@@ -0,0 +1,266 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHostFailureThreshold = 3
+	defaultHostEjectionDuration = 10 * time.Second
+	defaultMaxGetRetries        = 2
+	defaultRetryBackoff         = 100 * time.Millisecond
+	defaultProbeTimeout         = 2 * time.Second
+)
+
+type CoreHostPoolConfig struct {
+	// FailureThreshold is the number of consecutive failures after which a core is
+	// taken out of rotation. Defaults to 3.
+	FailureThreshold int
+	// EjectionDuration is how long a core is kept out of rotation before it is probed
+	// again via /hello. Defaults to 10 seconds.
+	EjectionDuration time.Duration
+	// MaxGetRetries is the number of times a GET request is retried once every core has
+	// failed it. Other methods are only sent to another core if the connection could not
+	// be established, since they are not safe to repeat. Defaults to 2.
+	MaxGetRetries *int
+	// RetryBackoff is the wait before the first retry of a GET request. It doubles with
+	// every retry. Defaults to 100 milliseconds.
+	RetryBackoff time.Duration
+	// ProbeTimeout limits the /hello request that checks whether an ejected core is back.
+	// Defaults to 2 seconds.
+	ProbeTimeout time.Duration
+}
+
+type normalisedCoreHostPoolConfig struct {
+	failureThreshold int
+	ejectionDuration time.Duration
+	maxGetRetries    int
+	retryBackoff     time.Duration
+	probeTimeout     time.Duration
+}
+
+type QuerierHostStatus struct {
+	Host                string
+	Healthy             bool
+	ConsecutiveFailures int
+	EjectedUntil        *time.Time
+	LastError           *string
+}
+
+type querierHostState struct {
+	host                QuerierHost
+	consecutiveFailures int
+	ejectedUntil        *time.Time
+	probing             bool
+	lastError           error
+}
+
+func normaliseCoreHostPoolConfig(config *CoreHostPoolConfig) normalisedCoreHostPoolConfig {
+	result := normalisedCoreHostPoolConfig{
+		failureThreshold: defaultHostFailureThreshold,
+		ejectionDuration: defaultHostEjectionDuration,
+		maxGetRetries:    defaultMaxGetRetries,
+		retryBackoff:     defaultRetryBackoff,
+		probeTimeout:     defaultProbeTimeout,
+	}
+	if config == nil {
+		return result
+	}
+	if config.FailureThreshold > 0 {
+		result.failureThreshold = config.FailureThreshold
+	}
+	if config.EjectionDuration > 0 {
+		result.ejectionDuration = config.EjectionDuration
+	}
+	if config.MaxGetRetries != nil && *config.MaxGetRetries >= 0 {
+		result.maxGetRetries = *config.MaxGetRetries
+	}
+	if config.RetryBackoff > 0 {
+		result.retryBackoff = config.RetryBackoff
+	}
+	if config.ProbeTimeout > 0 {
+		result.probeTimeout = config.ProbeTimeout
+	}
+	return result
+}
+
+func (s *querierState) initHostPool(hosts []QuerierHost, config *CoreHostPoolConfig) {
+	s.hostStates = []*querierHostState{}
+	for _, host := range hosts {
+		s.hostStates = append(s.hostStates, &querierHostState{host: host})
+	}
+	s.hostPoolConfig = normaliseCoreHostPoolConfig(config)
+}
+
+func (h *querierHostState) getURL() string {
+	return h.host.Domain.GetAsStringDangerous() + h.host.BasePath.GetAsStringDangerous()
+}
+
+// pickHost returns the next host (in round robin order) that has not been tried yet in this
+// round and is not ejected. If the ejection of a host is over, it is returned with needsProbe set,
+// and other requests skip it until the probe has finished.
+func (s *querierState) pickHost(tried map[int]bool) (index int, needsProbe bool, found bool) {
+	s.hostLock.Lock()
+	defer s.hostLock.Unlock()
+	now := time.Now()
+	for i := 0; i < len(s.hostStates); i++ {
+		index = (s.lastTriedIndex + i) % len(s.hostStates)
+		state := s.hostStates[index]
+		if tried[index] || state.probing {
+			continue
+		}
+		if state.ejectedUntil != nil {
+			if now.Before(*state.ejectedUntil) {
+				continue
+			}
+			state.probing = true
+			needsProbe = true
+		}
+		s.lastTriedIndex = (index + 1) % len(s.hostStates)
+		return index, needsProbe, true
+	}
+	return -1, false, false
+}
+
+func (s *querierState) recordHostSuccess(index int) {
+	s.hostLock.Lock()
+	defer s.hostLock.Unlock()
+	state := s.hostStates[index]
+	state.consecutiveFailures = 0
+	state.ejectedUntil = nil
+	state.probing = false
+	state.lastError = nil
+}
+
+func (s *querierState) recordHostFailure(index int, err error) {
+	s.hostLock.Lock()
+	defer s.hostLock.Unlock()
+	state := s.hostStates[index]
+	state.consecutiveFailures++
+	state.lastError = err
+	state.probing = false
+	if state.ejectedUntil != nil || state.consecutiveFailures >= s.hostPoolConfig.failureThreshold {
+		ejectedUntil := time.Now().Add(s.hostPoolConfig.ejectionDuration)
+		if state.ejectedUntil == nil {
+			s.logger.Warn("querier: Taking core out of rotation", "host", state.getURL(), "consecutiveFailures", state.consecutiveFailures)
+		}
+		state.ejectedUntil = &ejectedUntil
+	}
+}
+
+// releaseProbe lets other requests probe the host again, without counting a failure.
+func (s *querierState) releaseProbe(index int) {
+	s.hostLock.Lock()
+	defer s.hostLock.Unlock()
+	s.hostStates[index].probing = false
+}
+
+// probeHost checks whether an ejected host is reachable again before any
+// real request is sent to it. The probe has its own timeout, so that a hung
+// core does not use up the deadline of the request.
+func (s *querierState) probeHost(ctx context.Context, index int) bool {
+	state := s.hostStates[index]
+	probeCtx, cancel := context.WithTimeout(ctx, s.hostPoolConfig.probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(probeCtx, "GET", state.getURL()+"/hello", nil)
+	if err != nil {
+		s.recordHostFailure(index, err)
+		return false
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// the caller gave up, which says nothing about the health of the host
+		if ctx.Err() != nil {
+			s.releaseProbe(index)
+			return false
+		}
+		s.recordHostFailure(index, err)
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		s.recordHostFailure(index, errors.New("probe to /hello returned status code "+resp.Status))
+		return false
+	}
+	s.logger.Info("querier: Core is back in rotation", "host", state.getURL())
+	s.recordHostSuccess(index)
+	return true
+}
+
+// isConnectionError returns true if the request could not reach the core at all,
+// which means that it is safe to send it to another core, whatever its method.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection refused")
+}
+
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (s *querierState) waitForRetry(ctx context.Context, retry int) error {
+	backoff := s.hostPoolConfig.retryBackoff << (retry - 1)
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *querierState) getHostsStatus() []QuerierHostStatus {
+	s.hostLock.Lock()
+	defer s.hostLock.Unlock()
+	result := []QuerierHostStatus{}
+	now := time.Now()
+	for _, state := range s.hostStates {
+		status := QuerierHostStatus{
+			Host:                state.getURL(),
+			Healthy:             state.ejectedUntil == nil,
+			ConsecutiveFailures: state.consecutiveFailures,
+		}
+		if state.ejectedUntil != nil && now.Before(*state.ejectedUntil) {
+			ejectedUntil := *state.ejectedUntil
+			status.EjectedUntil = &ejectedUntil
+		}
+		if state.lastError != nil {
+			lastError := state.lastError.Error()
+			status.LastError = &lastError
+		}
+		result = append(result, status)
+	}
+	return result
+}
@@ -0,0 +1,252 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func initQuerierWithHostsForTest(t *testing.T, connectionURIs []string, config *CoreHostPoolConfig) {
+	resetAll()
+	hosts := []QuerierHost{}
+	for _, uri := range connectionURIs {
+		domain, err := NewNormalisedURLDomain(uri)
+		assert.NoError(t, err)
+		basePath, err := NewNormalisedURLPath(uri)
+		assert.NoError(t, err)
+		hosts = append(hosts, QuerierHost{Domain: domain, BasePath: basePath})
+	}
+	setDefaultInstance(&Instance{
+		querier: newQuerierState(hosts, "", makeQuerierHTTPClient(ConnectionInfo{}), 0, config, defaultLogger{}, noopInstrumentation{}),
+	})
+}
+
+type fakeCoreHost struct {
+	server    *httptest.Server
+	status    int32
+	calls     int32
+	helloCall int32
+}
+
+func makeFakeCoreHost() *fakeCoreHost {
+	h := &fakeCoreHost{status: 200}
+	h.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		status := int(atomic.LoadInt32(&h.status))
+		if r.URL.Path == "/hello" {
+			atomic.AddInt32(&h.helloCall, 1)
+			rw.WriteHeader(status)
+			return
+		}
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.15"]}`))
+			return
+		}
+		atomic.AddInt32(&h.calls, 1)
+		rw.WriteHeader(status)
+		rw.Write([]byte(`{"status":"OK"}`))
+	}))
+	return h
+}
+
+func TestFailsOverToNextHostWhenConnectionIsRefused(t *testing.T) {
+	up := makeFakeCoreHost()
+	defer up.server.Close()
+	down := makeFakeCoreHost()
+	down.server.Close()
+
+	initQuerierWithHostsForTest(t, []string{down.server.URL, up.server.URL}, nil)
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = q.SendPostRequest("/recipe/signin", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&up.calls))
+}
+
+func TestGetRequestIsRetriedOnServerError(t *testing.T) {
+	first := makeFakeCoreHost()
+	defer first.server.Close()
+	second := makeFakeCoreHost()
+	defer second.server.Close()
+	atomic.StoreInt32(&first.status, 500)
+
+	initQuerierWithHostsForTest(t, []string{first.server.URL, second.server.URL}, nil)
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	resp, err := q.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp["status"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&first.calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&second.calls))
+}
+
+func TestPostRequestIsNotRetriedOnServerError(t *testing.T) {
+	first := makeFakeCoreHost()
+	defer first.server.Close()
+	second := makeFakeCoreHost()
+	defer second.server.Close()
+	atomic.StoreInt32(&first.status, 500)
+
+	initQuerierWithHostsForTest(t, []string{first.server.URL, second.server.URL}, nil)
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendPostRequest("/recipe/signup", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status code: 500")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&second.calls))
+}
+
+func TestHostIsEjectedAndProbedBack(t *testing.T) {
+	flaky := makeFakeCoreHost()
+	defer flaky.server.Close()
+	healthy := makeFakeCoreHost()
+	defer healthy.server.Close()
+	atomic.StoreInt32(&flaky.status, 503)
+
+	maxGetRetries := 0
+	initQuerierWithHostsForTest(t, []string{flaky.server.URL, healthy.server.URL}, &CoreHostPoolConfig{
+		FailureThreshold: 2,
+		EjectionDuration: 200 * time.Millisecond,
+		MaxGetRetries:    &maxGetRetries,
+	})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		_, err = q.SendGetRequest("/recipe/user", nil)
+		assert.NoError(t, err)
+	}
+	// the flaky host only gets traffic until it is ejected
+	assert.Equal(t, int32(2), atomic.LoadInt32(&flaky.calls))
+
+	status, err := GetCoreHostsStatus()
+	assert.NoError(t, err)
+	assert.Len(t, status, 2)
+	assert.False(t, status[0].Healthy)
+	assert.NotNil(t, status[0].EjectedUntil)
+	assert.NotNil(t, status[0].LastError)
+	assert.True(t, status[1].Healthy)
+
+	atomic.StoreInt32(&flaky.status, 200)
+	time.Sleep(300 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		_, err = q.SendGetRequest("/recipe/user", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&flaky.helloCall))
+	assert.Greater(t, atomic.LoadInt32(&flaky.calls), int32(2))
+
+	status, err = GetCoreHostsStatus()
+	assert.NoError(t, err)
+	assert.True(t, status[0].Healthy)
+	assert.Equal(t, 0, status[0].ConsecutiveFailures)
+}
+
+func TestNoCoreAvailableWhenAllHostsAreDown(t *testing.T) {
+	down := makeFakeCoreHost()
+	down.server.Close()
+
+	maxGetRetries := 1
+	initQuerierWithHostsForTest(t, []string{down.server.URL}, &CoreHostPoolConfig{
+		MaxGetRetries: &maxGetRetries,
+		RetryBackoff:  time.Millisecond,
+	})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/recipe/user", nil)
+	assert.EqualError(t, err, "no SuperTokens core available to query")
+}
+
+func TestProbeOfHungHostTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hello" {
+			<-release
+		}
+		rw.WriteHeader(503)
+	}))
+	defer hung.Close()
+	defer close(release)
+	healthy := makeFakeCoreHost()
+	defer healthy.server.Close()
+
+	maxGetRetries := 0
+	initQuerierWithHostsForTest(t, []string{hung.URL, healthy.server.URL}, &CoreHostPoolConfig{
+		FailureThreshold: 1,
+		EjectionDuration: 50 * time.Millisecond,
+		MaxGetRetries:    &maxGetRetries,
+		ProbeTimeout:     50 * time.Millisecond,
+	})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	_, err = q.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	status, err := GetCoreHostsStatus()
+	assert.NoError(t, err)
+	assert.False(t, status[0].Healthy)
+}
+
+func TestCancelledProbeIsNotCountedAsFailure(t *testing.T) {
+	host := makeFakeCoreHost()
+	defer host.server.Close()
+
+	initQuerierWithHostsForTest(t, []string{host.server.URL}, nil)
+	defer resetAll()
+
+	state := GetDefaultInstance().querier
+	ejectedUntil := time.Now().Add(-time.Second)
+	state.hostStates[0].ejectedUntil = &ejectedUntil
+	state.hostStates[0].consecutiveFailures = 3
+
+	_, needsProbe, found := state.pickHost(map[int]bool{})
+	assert.True(t, found)
+	assert.True(t, needsProbe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, state.probeHost(ctx, 0))
+
+	assert.False(t, state.hostStates[0].probing)
+	assert.Equal(t, 3, state.hostStates[0].consecutiveFailures)
+	assert.Equal(t, ejectedUntil, *state.hostStates[0].ejectedUntil)
+}
@@ -0,0 +1,182 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func initQuerierForTest(t *testing.T, connectionURI string, connectionInfo ConnectionInfo) {
+	resetAll()
+	domain, err := NewNormalisedURLDomain(connectionURI)
+	assert.NoError(t, err)
+	basePath, err := NewNormalisedURLPath(connectionURI)
+	assert.NoError(t, err)
+	setDefaultInstance(&Instance{
+		querier: newQuerierState([]QuerierHost{{Domain: domain, BasePath: basePath}}, "", makeQuerierHTTPClient(connectionInfo), connectionInfo.Timeout, connectionInfo.HostPool, defaultLogger{}, noopInstrumentation{}),
+	})
+}
+
+func resetAll() {
+	ResetForTest()
+}
+
+func makeSlowCore(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.15"]}`))
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		rw.Write([]byte(`{"status":"OK"}`))
+	}))
+}
+
+func TestQuerierUsesCustomRoundTripper(t *testing.T) {
+	core := makeSlowCore(0)
+	defer core.Close()
+
+	numberOfCalls := 0
+	initQuerierForTest(t, core.URL, ConnectionInfo{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			numberOfCalls++
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	resp, err := q.SendGetRequest("/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", resp["status"])
+	// one call for /apiversion and one for the actual request
+	assert.Equal(t, 2, numberOfCalls)
+}
+
+func TestQuerierTimeoutAbortsSlowRequest(t *testing.T) {
+	core := makeSlowCore(2 * time.Second)
+	defer core.Close()
+
+	initQuerierForTest(t, core.URL, ConnectionInfo{
+		Timeout: 100 * time.Millisecond,
+	})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	start := time.Now()
+	_, err = q.SendPostRequest("/recipe/signin", nil)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestQuerierRequestIsCancelledWithContext(t *testing.T) {
+	core := makeSlowCore(2 * time.Second)
+	defer core.Close()
+
+	initQuerierForTest(t, core.URL, ConnectionInfo{})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	_, err = q.SendPutRequestWithContext(ctx, "/recipe/user", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestGetContextFromUserContext(t *testing.T) {
+	assert.Equal(t, context.Background(), GetContextFromUserContext(nil))
+	assert.Equal(t, context.Background(), GetContextFromUserContext(&map[string]interface{}{}))
+
+	type key struct{}
+	reqCtx := context.WithValue(context.Background(), key{}, "request")
+	req := httptest.NewRequest("GET", "/", nil).WithContext(reqCtx)
+	assert.Equal(t, "request", GetContextFromUserContext(MakeDefaultUserContextFromAPI(req)).Value(key{}))
+
+	explicitCtx := context.WithValue(context.Background(), key{}, "explicit")
+	assert.Equal(t, "explicit", GetContextFromUserContext(MakeDefaultUserContextFromContext(explicitCtx)).Value(key{}))
+}
+
+func makeCoreWithVersions(versions string, paths *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":` + versions + `}`))
+			return
+		}
+		*paths = append(*paths, r.URL.Path)
+		rw.Write([]byte(`{"status":"OK"}`))
+	}))
+}
+
+func TestRequestsForTenantNeedCoreWithTenantSupport(t *testing.T) {
+	paths := []string{}
+	core := makeCoreWithVersions(`["2.14","2.15"]`, &paths)
+	defer core.Close()
+	initQuerierForTest(t, core.URL, ConnectionInfo{})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequestWithContext(WithTenantId(context.Background(), "tenant1"), "/recipe/user", nil)
+	assert.EqualError(t, err, "tenant tenant1 needs a SuperTokens core that supports CDI version 3.0 or later, but the core only supports 2.14, 2.15. Please visit https://supertokens.io/docs/community/compatibility-table to find the right version")
+
+	// the default tenant works with every core
+	_, err = q.SendGetRequestWithContext(WithTenantId(context.Background(), DefaultTenantId), "/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/recipe/user"}, paths)
+}
+
+func TestRequestsForTenantAreSentToTheTenantPath(t *testing.T) {
+	paths := []string{}
+	core := makeCoreWithVersions(`["2.15","3.0"]`, &paths)
+	defer core.Close()
+	initQuerierForTest(t, core.URL, ConnectionInfo{})
+	defer resetAll()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	apiVersion, err := q.GetQuerierAPIVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.15", apiVersion)
+	_, err = q.SendGetRequestWithContext(WithTenantId(context.Background(), "tenant1"), "/recipe/user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/tenant1/recipe/user"}, paths)
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
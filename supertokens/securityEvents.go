@@ -0,0 +1,128 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+type SecurityEventType string
+
+// Types of the events sent to SecurityEventListener.
+const (
+	SecurityEventTokenTheftDetected     SecurityEventType = "TOKEN_THEFT_DETECTED"
+	SecurityEventSignInFailed           SecurityEventType = "SIGN_IN_FAILED"
+	SecurityEventPasswordResetRequested SecurityEventType = "PASSWORD_RESET_REQUESTED"
+	SecurityEventPasswordResetCompleted SecurityEventType = "PASSWORD_RESET_COMPLETED"
+	SecurityEventEmailChanged           SecurityEventType = "EMAIL_CHANGED"
+	SecurityEventMFAFailed              SecurityEventType = "MFA_FAILED"
+	SecurityEventAntiCsrfFailed         SecurityEventType = "ANTI_CSRF_FAILED"
+	SecurityEventImpersonationStarted   SecurityEventType = "IMPERSONATION_STARTED"
+	SecurityEventImpersonationStopped   SecurityEventType = "IMPERSONATION_STOPPED"
+)
+
+type SecurityEvent struct {
+	Type     SecurityEventType
+	Time     time.Time
+	RecipeID string
+	// UserID and SessionHandle are empty if they are not known, for example when
+	// signing in fails for an email that does not belong to any user.
+	UserID        string
+	SessionHandle string
+	// Request is nil if the event did not happen while handling a request.
+	Request *SecurityEventRequest
+	// Details contains information that depends on the type of the event, such as the email
+	// for SIGN_IN_FAILED and PASSWORD_RESET_REQUESTED, or the old and new email for EMAIL_CHANGED.
+	Details map[string]interface{}
+}
+
+type SecurityEventRequest struct {
+	Method string
+	Path   string
+	// IP is the remote address of the connection, which is the address of the proxy if
+	// there is one in front of the app.
+	IP        string
+	UserAgent string
+}
+
+// SecurityEventListener receives the security events of the SDK, for example to send them to a
+// SIEM or to alert users. It is called synchronously, so slow work should be done in the background.
+type SecurityEventListener interface {
+	OnSecurityEvent(event SecurityEvent, userContext UserContext)
+}
+
+// SecurityEventListenerFunc lets an ordinary function be used as a SecurityEventListener.
+type SecurityEventListenerFunc func(event SecurityEvent, userContext UserContext)
+
+func (f SecurityEventListenerFunc) OnSecurityEvent(event SecurityEvent, userContext UserContext) {
+	f(event, userContext)
+}
+
+// HasSecurityEventListeners reports whether EmitSecurityEvent would call any listener for the
+// user context, so that recipes can skip the work needed only to build an event.
+func HasSecurityEventListeners(userContext UserContext) bool {
+	instance := GetInstanceFromUserContext(userContext)
+	return instance != nil && len(instance.securityEventListeners) > 0
+}
+
+// EmitSecurityEvent sends event to the listeners of the instance that handles the user context.
+// Time and Request are filled in if they are not set.
+func EmitSecurityEvent(event SecurityEvent, userContext UserContext) {
+	if !HasSecurityEventListeners(userContext) {
+		return
+	}
+	instance := GetInstanceFromUserContext(userContext)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Request == nil {
+		if req := GetRequestFromUserContext(userContext); req != nil {
+			event.Request = makeSecurityEventRequest(req)
+		}
+	}
+	if event.Details == nil {
+		event.Details = map[string]interface{}{}
+	}
+
+	for _, listener := range instance.securityEventListeners {
+		callSecurityEventListener(instance, listener, event, userContext)
+	}
+}
+
+func callSecurityEventListener(instance *Instance, listener SecurityEventListener, event SecurityEvent, userContext UserContext) {
+	// a failing listener must not fail the request that caused the event
+	defer func() {
+		if r := recover(); r != nil {
+			instance.logger.Error("SecurityEventListener panicked", "eventType", string(event.Type), "panic", r)
+		}
+	}()
+	listener.OnSecurityEvent(event, userContext)
+}
+
+func makeSecurityEventRequest(req *http.Request) *SecurityEventRequest {
+	ip := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return &SecurityEventRequest{
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		IP:        ip,
+		UserAgent: req.Header.Get("User-Agent"),
+	}
+}
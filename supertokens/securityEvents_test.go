@@ -0,0 +1,87 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityEventsAreSentToAllListeners(t *testing.T) {
+	defer ResetForTest()
+
+	received := []SecurityEvent{}
+	err := Init(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       "default",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{initTestRecipe()},
+		SecurityEventListeners: []SecurityEventListener{
+			SecurityEventListenerFunc(func(event SecurityEvent, userContext UserContext) {
+				panic("listener failed")
+			}),
+			SecurityEventListenerFunc(func(event SecurityEvent, userContext UserContext) {
+				received = append(received, event)
+			}),
+		},
+	})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/signin", nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	req.Header.Set("User-Agent", "test-agent")
+	EmitSecurityEvent(SecurityEvent{
+		Type:     SecurityEventSignInFailed,
+		RecipeID: "emailpassword",
+	}, MakeDefaultUserContextFromAPI(req))
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, SecurityEventSignInFailed, received[0].Type)
+	assert.False(t, received[0].Time.IsZero())
+	assert.Equal(t, &SecurityEventRequest{
+		Method:    http.MethodPost,
+		Path:      "/auth/signin",
+		IP:        "203.0.113.9",
+		UserAgent: "test-agent",
+	}, received[0].Request)
+	assert.NotNil(t, received[0].Details)
+
+	// events outside of a request have no request metadata
+	EmitSecurityEvent(SecurityEvent{Type: SecurityEventEmailChanged}, &map[string]interface{}{})
+	assert.Len(t, received, 2)
+	assert.Nil(t, received[1].Request)
+}
+
+func TestHasSecurityEventListenersIsFalseWithoutListeners(t *testing.T) {
+	defer ResetForTest()
+
+	err := Init(TypeInput{
+		AppInfo: AppInfo{
+			AppName:       "default",
+			APIDomain:     "api.supertokens.io",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{initTestRecipe()},
+	})
+	assert.NoError(t, err)
+
+	assert.False(t, HasSecurityEventListeners(&map[string]interface{}{}))
+}
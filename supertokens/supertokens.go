@@ -17,47 +17,82 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-type superTokens struct {
+// Instance is a SuperTokens app with its own app info, core connection and recipes.
+// Several instances can be created in one process with New. The package level
+// functions, such as Middleware and GetUserCount, use the default instance that is
+// created by Init.
+type Instance struct {
 	AppInfo               NormalisedAppinfo
 	SuperTokens           ConnectionInfo
 	RecipeModules         []RecipeModule
 	OnSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)
+
+	querier           *querierState
+	logger            Logger
+	instrumentation   Instrumentation
+	recipeInstances   map[string]interface{}
+	postInitCallbacks []func() error
+	tenantIdResolver  TenantIdResolver
+
+	securityEventListeners []SecurityEventListener
 }
 
 // this will be set to true if this is used in a test app environment
 var IsTestFlag = false
 
-var superTokensInstance *superTokens
+var (
+	defaultInstance          *Instance
+	instanceBeingInitialised *Instance
+	// instancesLock guards defaultInstance and instanceBeingInitialised
+	instancesLock sync.RWMutex
+	// initLock makes sure that only one instance is initialised at a time, since
+	// recipes find the instance they belong to via instanceBeingInitialised
+	initLock sync.Mutex
+)
 
-func supertokensInit(config TypeInput) error {
-	if superTokensInstance != nil {
-		return nil
+func newInstance(config TypeInput, isDefault bool) (*Instance, error) {
+	instance := &Instance{
+		logger:          config.Logger,
+		instrumentation: config.Instrumentation,
+		recipeInstances: map[string]interface{}{},
+
+		securityEventListeners: config.SecurityEventListeners,
+	}
+	if instance.logger == nil {
+		instance.logger = defaultLogger{}
+	}
+	if instance.instrumentation == nil {
+		instance.instrumentation = noopInstrumentation{}
 	}
 
-	superTokens := &superTokens{}
+	setInstanceBeingInitialised(instance)
+	defer setInstanceBeingInitialised(nil)
 
-	superTokens.OnSuperTokensAPIError = defaultOnSuperTokensAPIError
+	instance.OnSuperTokensAPIError = defaultOnSuperTokensAPIError
 	if config.OnSuperTokensAPIError != nil {
-		superTokens.OnSuperTokensAPIError = config.OnSuperTokensAPIError
+		instance.OnSuperTokensAPIError = config.OnSuperTokensAPIError
 	}
 
-	LogDebugMessage("Started SuperTokens with debug logging (supertokens.Init called)")
+	instance.logger.Debug("Started SuperTokens with debug logging (supertokens.Init called)")
 
 	appInfoJsonString, _ := json.Marshal(config.AppInfo)
-	LogDebugMessage("AppInfo: " + string(appInfoJsonString))
+	instance.logger.Debug("AppInfo: " + string(appInfoJsonString))
 
 	var err error
-	superTokens.AppInfo, err = NormaliseInputAppInfoOrThrowError(config.AppInfo)
+	instance.AppInfo, err = NormaliseInputAppInfoOrThrowError(config.AppInfo)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if config.Supertokens != nil {
@@ -67,64 +102,162 @@ func supertokensInit(config TypeInput) error {
 			for _, h := range hostList {
 				domain, err := NewNormalisedURLDomain(h)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				basePath, err := NewNormalisedURLPath(h)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				hosts = append(hosts, QuerierHost{
 					Domain:   domain,
 					BasePath: basePath,
 				})
 			}
-			initQuerier(hosts, config.Supertokens.APIKey)
-			superTokens.SuperTokens = *config.Supertokens
+			instance.querier = newQuerierState(hosts, config.Supertokens.APIKey, makeQuerierHTTPClient(*config.Supertokens), config.Supertokens.Timeout, config.Supertokens.HostPool, instance.logger, instance.instrumentation)
+			instance.SuperTokens = *config.Supertokens
 		} else {
-			return errors.New("please provide 'ConnectionURI' value. If you do not want to provide a connection URI, then set config.Supertokens to nil")
+			return nil, errors.New("please provide 'ConnectionURI' value. If you do not want to provide a connection URI, then set config.Supertokens to nil")
 		}
 	} else {
 		// TODO: Add tests for init without supertokens core.
 	}
 
 	if config.RecipeList == nil || len(config.RecipeList) == 0 {
-		return errors.New("please provide at least one recipe to the supertokens.init function call")
+		return nil, errors.New("please provide at least one recipe to the supertokens.init function call")
 	}
 
 	for _, elem := range config.RecipeList {
-		recipeModule, err := elem(superTokens.AppInfo, superTokens.OnSuperTokensAPIError)
+		recipeModule, err := elem(instance.AppInfo, instance.OnSuperTokensAPIError)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		superTokens.RecipeModules = append(superTokens.RecipeModules, *recipeModule)
+		instance.RecipeModules = append(instance.RecipeModules, *recipeModule)
 	}
 
-	superTokensInstance = superTokens
+	if isDefault {
+		setDefaultInstance(instance)
+	}
 
 	if config.Telemetry == nil || *config.Telemetry {
-		sendTelemetry()
+		instance.sendTelemetry()
 	}
 
-	return nil
+	err = instance.runPostInitCallbacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func setInstanceBeingInitialised(instance *Instance) {
+	instancesLock.Lock()
+	defer instancesLock.Unlock()
+	instanceBeingInitialised = instance
+}
+
+func getInstanceBeingInitialised() *Instance {
+	instancesLock.RLock()
+	defer instancesLock.RUnlock()
+	return instanceBeingInitialised
+}
+
+func setDefaultInstance(instance *Instance) {
+	instancesLock.Lock()
+	defer instancesLock.Unlock()
+	defaultInstance = instance
+	QuerierHosts = nil
+	QuerierAPIKey = nil
+	if instance != nil && instance.querier != nil {
+		QuerierHosts = instance.querier.hosts
+		QuerierAPIKey = instance.querier.apiKey
+	}
+}
+
+// GetDefaultInstance returns the instance created by Init, or nil if Init has not been called.
+func GetDefaultInstance() *Instance {
+	instancesLock.RLock()
+	defer instancesLock.RUnlock()
+	return defaultInstance
+}
+
+// GetInstanceBeingInitialisedOrThrowError is meant to be called by recipes from their init
+// function, to find the instance that they are part of.
+func GetInstanceBeingInitialisedOrThrowError() (*Instance, error) {
+	instance := getInstanceBeingInitialised()
+	if instance == nil {
+		return nil, errors.New("recipes can only be initialised by supertokens.Init or supertokens.New")
+	}
+	return instance, nil
+}
+
+// GetInstanceFromContext returns the instance whose middleware is handling the request
+// that ctx belongs to, or nil if there is none.
+func GetInstanceFromContext(ctx context.Context) *Instance {
+	if ctx == nil {
+		return nil
+	}
+	instance, _ := ctx.Value(instanceContextKey{}).(*Instance)
+	return instance
+}
+
+// GetInstanceFromUserContext returns the instance that is handling the request in the user
+// context. If there is none, the default instance is returned, which is nil if Init has not
+// been called.
+func GetInstanceFromUserContext(userContext UserContext) *Instance {
+	instance := GetInstanceFromContext(GetContextFromUserContext(userContext))
+	if instance != nil {
+		return instance
+	}
+	return GetDefaultInstance()
+}
+
+func GetInstanceFromUserContextOrThrowError(userContext UserContext) (*Instance, error) {
+	instance := GetInstanceFromUserContext(userContext)
+	if instance != nil {
+		return instance, nil
+	}
+	return nil, errors.New("initialisation not done. Did you forget to call the SuperTokens.init function?")
 }
 
 func defaultOnSuperTokensAPIError(err error, req *http.Request, res http.ResponseWriter) {
 	http.Error(res, err.Error(), 500)
 }
 
-func GetInstanceOrThrowError() (*superTokens, error) {
-	if superTokensInstance != nil {
-		return superTokensInstance, nil
+func GetInstanceOrThrowError() (*Instance, error) {
+	instance := GetDefaultInstance()
+	if instance != nil {
+		return instance, nil
 	}
 	return nil, errors.New("initialisation not done. Did you forget to call the SuperTokens.init function?")
 }
 
-func sendTelemetry() {
+// GetQuerier returns a querier for the core of this instance. The rid header is
+// set to rIDToCore for requests to recipe paths.
+func (s *Instance) GetQuerier(rIDToCore string) (*Querier, error) {
+	if s.querier == nil {
+		return nil, errors.New("please provide config.Supertokens to use the SuperTokens core")
+	}
+	return &Querier{RIDToCore: rIDToCore, state: s.querier}, nil
+}
+
+// GetRecipeInstance returns the recipe that was stored with SetRecipeInstance, or nil if
+// the recipe is not part of this instance. Recipe packages provide typed accessors for this.
+func (s *Instance) GetRecipeInstance(recipeID string) interface{} {
+	return s.recipeInstances[recipeID]
+}
+
+// SetRecipeInstance is called by recipes from their init function.
+func (s *Instance) SetRecipeInstance(recipeID string, recipe interface{}) {
+	s.recipeInstances[recipeID] = recipe
+}
+
+func (s *Instance) sendTelemetry() {
 	if IsRunningInTestMode() {
 		// if running in test mode, we do not want to send this.
 		return
 	}
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return
 	}
@@ -138,8 +271,8 @@ func sendTelemetry() {
 	url := "https://api.supertokens.com/0/st/telemetry"
 
 	data := map[string]interface{}{
-		"appName":       superTokensInstance.AppInfo.AppName,
-		"websiteDomain": superTokensInstance.AppInfo.WebsiteDomain.GetAsStringDangerous(),
+		"appName":       s.AppInfo.AppName,
+		"websiteDomain": s.AppInfo.WebsiteDomain.GetAsStringDangerous(),
 		"sdk":           "golang",
 	}
 	if exists {
@@ -160,12 +293,16 @@ func sendTelemetry() {
 	client.Do(req)
 }
 
-func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
-	LogDebugMessage("middleware: Started")
+type instanceContextKey struct{}
+
+func (s *Instance) middleware(theirHandler http.Handler) http.Handler {
+	s.logger.Debug("middleware: Started")
 	if theirHandler == nil {
 		theirHandler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// recipes and their handler find this instance via the request context
+		r = r.WithContext(context.WithValue(r.Context(), instanceContextKey{}, s))
 		dw := MakeDoneWriter(w)
 		reqURL, err := NewNormalisedURLPath(r.URL.Path)
 		if err != nil {
@@ -179,12 +316,38 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 		method := r.Method
 
 		if !strings.HasPrefix(path.GetAsStringDangerous(), s.AppInfo.APIBasePath.GetAsStringDangerous()) {
-			LogDebugMessage("middleware: Not handling because request path did not start with config path. Request path: " + path.GetAsStringDangerous())
+			s.logger.Debug("middleware: Not handling because request path did not start with config path. Request path: " + path.GetAsStringDangerous())
+			// the tenant is only needed here if their handler verifies a session, so a request
+			// for which it can't be resolved is passed on for the default tenant instead of
+			// being rejected
+			if resolvedReq, err := s.resolveTenantId(r); err == nil {
+				r = resolvedReq
+			} else {
+				s.logger.Debug("middleware: Could not resolve tenant of request: " + err.Error())
+			}
 			theirHandler.ServeHTTP(dw, r)
 			return
 		}
+		r, err = s.resolveTenantId(r)
+		if err != nil {
+			err = s.errorHandler(err, r, dw)
+			if err != nil && !dw.IsDone() {
+				s.OnSuperTokensAPIError(err, r, dw)
+			}
+			return
+		}
+		// a tenant ID resolver can remove the tenant from the path
+		reqURL, err = NewNormalisedURLPath(r.URL.Path)
+		if err != nil {
+			err = s.errorHandler(err, r, dw)
+			if err != nil && !dw.IsDone() {
+				s.OnSuperTokensAPIError(err, r, dw)
+			}
+			return
+		}
+		path = s.AppInfo.APIGatewayPath.AppendPath(reqURL)
 		requestRID := getRIDFromRequest(r)
-		LogDebugMessage("middleware: requestRID is: " + requestRID)
+		s.logger.Debug("middleware: requestRID is: " + requestRID)
 		if requestRID == "anti-csrf" {
 			// See https://github.com/supertokens/supertokens-node/issues/202
 			requestRID = ""
@@ -192,19 +355,19 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 		if requestRID != "" {
 			var matchedRecipe *RecipeModule
 			for _, recipeModule := range s.RecipeModules {
-				LogDebugMessage("middleware: Checking recipe ID for match: " + recipeModule.GetRecipeID())
+				s.logger.Debug("middleware: Checking recipe ID for match: " + recipeModule.GetRecipeID())
 				if recipeModule.GetRecipeID() == requestRID {
 					matchedRecipe = &recipeModule
 					break
 				}
 			}
 			if matchedRecipe == nil {
-				LogDebugMessage("middleware: Not handling because no recipe matched")
+				s.logger.Debug("middleware: Not handling because no recipe matched")
 				theirHandler.ServeHTTP(dw, r)
 				return
 			}
 
-			LogDebugMessage("middleware: Matched with recipe ID: " + matchedRecipe.GetRecipeID())
+			s.logger.Debug("middleware: Matched with recipe ID: " + matchedRecipe.GetRecipeID())
 
 			id, err := matchedRecipe.ReturnAPIIdIfCanHandleRequest(path, method)
 
@@ -217,14 +380,14 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 			}
 
 			if id == nil {
-				LogDebugMessage("middleware: Not handling because recipe doesn't handle request path or method. Request path: " + path.GetAsStringDangerous() + ", request method: " + method)
+				s.logger.Debug("middleware: Not handling because recipe doesn't handle request path or method. Request path: " + path.GetAsStringDangerous() + ", request method: " + method)
 				theirHandler.ServeHTTP(dw, r)
 				return
 			}
 
-			LogDebugMessage("middleware: Request being handled by recipe. ID is: " + *id)
+			s.logger.Debug("middleware: Request being handled by recipe. ID is: " + *id)
 
-			apiErr := matchedRecipe.HandleAPIRequest(*id, r, dw, theirHandler.ServeHTTP, path, method)
+			apiErr := s.handleAPIRequest(*matchedRecipe, *id, r, dw, theirHandler, path, method)
 			if apiErr != nil {
 				apiErr = s.errorHandler(apiErr, r, dw)
 				if apiErr != nil && !dw.IsDone() {
@@ -232,11 +395,11 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 				}
 				return
 			}
-			LogDebugMessage("middleware: Ended")
+			s.logger.Debug("middleware: Ended")
 		} else {
 			for _, recipeModule := range s.RecipeModules {
 				id, err := recipeModule.ReturnAPIIdIfCanHandleRequest(path, method)
-				LogDebugMessage("middleware: Checking recipe ID for match: " + recipeModule.GetRecipeID())
+				s.logger.Debug("middleware: Checking recipe ID for match: " + recipeModule.GetRecipeID())
 				if err != nil {
 					err = s.errorHandler(err, r, dw)
 					if err != nil && !dw.IsDone() {
@@ -246,27 +409,61 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 				}
 
 				if id != nil {
-					LogDebugMessage("middleware: Request being handled by recipe. ID is: " + *id)
-					err := recipeModule.HandleAPIRequest(*id, r, dw, theirHandler.ServeHTTP, path, method)
+					s.logger.Debug("middleware: Request being handled by recipe. ID is: " + *id)
+					err := s.handleAPIRequest(recipeModule, *id, r, dw, theirHandler, path, method)
 					if err != nil {
 						err = s.errorHandler(err, r, dw)
 						if err != nil && !dw.IsDone() {
 							s.OnSuperTokensAPIError(err, r, dw)
 						}
 					} else {
-						LogDebugMessage("middleware: Ended")
+						s.logger.Debug("middleware: Ended")
 					}
 					return
 				}
 			}
 
-			LogDebugMessage("middleware: Not handling because no recipe matched")
+			s.logger.Debug("middleware: Not handling because no recipe matched")
 			theirHandler.ServeHTTP(dw, r)
 		}
 	})
 }
 
-func (s *superTokens) getAllCORSHeaders() []string {
+// handleAPIRequest calls the recipe's API inside a span, so that
+// the requests it makes to the core are children of that span.
+func (s *Instance) handleAPIRequest(recipeModule RecipeModule, apiID string, r *http.Request, dw DoneWriter, theirHandler http.Handler, path NormalisedURLPath, method string) error {
+	ctx, span := s.instrumentation.StartSpan(r.Context(), SpanAPIRequest, map[string]interface{}{
+		"supertokens.recipe_id": recipeModule.GetRecipeID(),
+		"supertokens.api_id":    apiID,
+		"http.method":           method,
+		"http.target":           path.GetAsStringDangerous(),
+	})
+	defer span.End()
+
+	startTime := time.Now()
+	err := recipeModule.HandleAPIRequest(apiID, r.WithContext(ctx), dw, theirHandler.ServeHTTP, path, method)
+
+	keysAndValues := []interface{}{
+		"recipeId", recipeModule.GetRecipeID(),
+		"apiId", apiID,
+		"path", path.GetAsStringDangerous(),
+		"method", method,
+		"status", getStatusCodeFromWriter(dw),
+		"latencyMs", time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Warn("middleware: API returned an error", append(keysAndValues, "error", err.Error())...)
+		return err
+	}
+	span.SetAttributes(map[string]interface{}{
+		"http.status_code": getStatusCodeFromWriter(dw),
+	})
+	s.logger.Info("middleware: API handled", keysAndValues...)
+	return nil
+}
+
+func (s *Instance) getAllCORSHeaders() []string {
 	headerMap := map[string]bool{HeaderRID: true, HeaderFDI: true}
 	for _, recipe := range s.RecipeModules {
 		headers := recipe.GetAllCORSHeaders()
@@ -281,10 +478,10 @@ func (s *superTokens) getAllCORSHeaders() []string {
 	return headers
 }
 
-func (s *superTokens) errorHandler(originalError error, req *http.Request, res http.ResponseWriter) error {
-	LogDebugMessage("errorHandler: Started")
+func (s *Instance) errorHandler(originalError error, req *http.Request, res http.ResponseWriter) error {
+	s.logger.Debug("errorHandler: Started")
 	if errors.As(originalError, &BadInputError{}) {
-		LogDebugMessage("errorHandler: Sending 400 status code response")
+		s.logger.Debug("errorHandler: Sending 400 status code response")
 		err := SendNon200ResponseWithMessage(res, originalError.Error(), 400)
 		if err != nil {
 			// this function can return an error, so we should return
@@ -296,9 +493,9 @@ func (s *superTokens) errorHandler(originalError error, req *http.Request, res h
 		return nil
 	}
 	for _, recipe := range s.RecipeModules {
-		LogDebugMessage("errorHandler: Checking recipe for match: " + recipe.recipeID)
+		s.logger.Debug("errorHandler: Checking recipe for match: " + recipe.recipeID)
 		if recipe.HandleError != nil {
-			LogDebugMessage("errorHandler: Matched with recipeId: " + recipe.recipeID)
+			s.logger.Debug("errorHandler: Matched with recipeId: " + recipe.recipeID)
 			handled, err := recipe.HandleError(originalError, req, res)
 			if err != nil {
 				return err
@@ -320,9 +517,9 @@ type UserPaginationResult struct {
 }
 
 // TODO: Add tests
-func getUsers(timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
+func (s *Instance) getUsers(timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
 
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return UserPaginationResult{}, err
 	}
@@ -363,9 +560,9 @@ func getUsers(timeJoinedOrder string, paginationToken *string, limit *int, inclu
 }
 
 // TODO: Add tests
-func getUserCount(includeRecipeIds *[]string) (float64, error) {
+func (s *Instance) getUserCount(includeRecipeIds *[]string) (float64, error) {
 
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return -1, err
 	}
@@ -385,8 +582,8 @@ func getUserCount(includeRecipeIds *[]string) (float64, error) {
 	return resp["count"].(float64), nil
 }
 
-func deleteUser(userId string) error {
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+func (s *Instance) deleteUser(userId string) error {
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return err
 	}
@@ -412,8 +609,8 @@ func deleteUser(userId string) error {
 }
 
 func ResetForTest() {
-	ResetQuerierForTest()
-	superTokensInstance = nil
+	setDefaultInstance(nil)
+	postInitCallbacks = []func() error{}
 }
 
 func IsRunningInTestMode() bool {
@@ -0,0 +1,91 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultTenantId is the tenant that requests belong to if no tenant could be resolved.
+const DefaultTenantId = "public"
+
+// minCDIVersionForTenants is the first version of the core driver interface in which the
+// core reads the tenant from the path of a request.
+const minCDIVersionForTenants = "3.0"
+
+// TenantIdResolver returns the tenant that a request is for. It can return a copy of
+// req that is handled instead of req, for example one without the tenant ID in its path.
+// If newReq is nil, req is used.
+type TenantIdResolver func(req *http.Request) (tenantId string, newReq *http.Request, err error)
+
+type tenantIdContextKey struct{}
+
+// WithTenantId returns a copy of ctx for which calls to the core are made for the given tenant.
+// Pass it to the WithContext functions of the recipes via MakeDefaultUserContextFromContext.
+func WithTenantId(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantIdContextKey{}, tenantId)
+}
+
+// GetTenantIdFromContext returns the tenant that was set via WithTenantId, or
+// DefaultTenantId if there is none.
+func GetTenantIdFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if tenantId, ok := ctx.Value(tenantIdContextKey{}).(string); ok && tenantId != "" {
+			return tenantId
+		}
+	}
+	return DefaultTenantId
+}
+
+// SetTenantIdResolver is called by the multitenancy recipe from its init function. The
+// middleware uses the resolver to find the tenant of every request that it handles. If
+// the resolver returns an error, SuperTokens APIs respond with a 400, while other requests
+// are passed on for the default tenant.
+func (s *Instance) SetTenantIdResolver(resolver TenantIdResolver) {
+	s.tenantIdResolver = resolver
+}
+
+func (s *Instance) resolveTenantId(r *http.Request) (*http.Request, error) {
+	if s.tenantIdResolver == nil {
+		return r, nil
+	}
+	tenantId, newReq, err := s.tenantIdResolver(r)
+	if err != nil {
+		return r, err
+	}
+	if newReq != nil {
+		r = newReq
+	}
+	s.logger.Debug("middleware: Resolved tenant", "tenantId", tenantId)
+	return r.WithContext(WithTenantId(r.Context(), tenantId)), nil
+}
+
+// getPathForTenant prefixes recipe paths with the tenant of ctx, which is how
+// the core knows which tenant a request is for. Older cores can only serve the
+// default tenant, so an error is returned for other tenants if the core does not
+// support tenants.
+func (q *Querier) getPathForTenant(ctx context.Context, path NormalisedURLPath) (NormalisedURLPath, error) {
+	tenantId := GetTenantIdFromContext(ctx)
+	if tenantId == DefaultTenantId || !path.IsARecipePath() {
+		return path, nil
+	}
+	err := q.RequireCoreAPIVersionWithContext(ctx, minCDIVersionForTenants, "tenant "+tenantId)
+	if err != nil {
+		return path, err
+	}
+	return NormalisedURLPath{value: "/" + tenantId + path.value}, nil
+}
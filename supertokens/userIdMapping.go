@@ -22,7 +22,15 @@ type CreateUserIdMappingResult struct {
 }
 
 func CreateUserIdMapping(supertokensUserId string, externalUserId string, externalUserIdInfo *string, force *bool) (CreateUserIdMappingResult, error) {
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return CreateUserIdMappingResult{}, err
+	}
+	return instance.CreateUserIdMapping(supertokensUserId, externalUserId, externalUserIdInfo, force)
+}
+
+func (s *Instance) CreateUserIdMapping(supertokensUserId string, externalUserId string, externalUserIdInfo *string, force *bool) (CreateUserIdMappingResult, error) {
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return CreateUserIdMappingResult{}, err
 	}
@@ -79,8 +87,15 @@ type GetUserIdMappingResult struct {
 }
 
 func GetUserIdMapping(userId string, userIdType *UserIdType) (GetUserIdMappingResult, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return GetUserIdMappingResult{}, err
+	}
+	return instance.GetUserIdMapping(userId, userIdType)
+}
 
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+func (s *Instance) GetUserIdMapping(userId string, userIdType *UserIdType) (GetUserIdMappingResult, error) {
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return GetUserIdMappingResult{}, err
 	}
@@ -132,7 +147,15 @@ type DeleteUserIdMappingResult struct {
 }
 
 func DeleteUserIdMapping(userId string, userIdType *UserIdType, force *bool) (DeleteUserIdMappingResult, error) {
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return DeleteUserIdMappingResult{}, err
+	}
+	return instance.DeleteUserIdMapping(userId, userIdType, force)
+}
+
+func (s *Instance) DeleteUserIdMapping(userId string, userIdType *UserIdType, force *bool) (DeleteUserIdMappingResult, error) {
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return DeleteUserIdMappingResult{}, err
 	}
@@ -170,7 +193,15 @@ type UpdateOrDeleteUserIdMappingInfoResult struct {
 }
 
 func UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, externalUserIdInfo *string) (UpdateOrDeleteUserIdMappingInfoResult, error) {
-	querier, err := GetNewQuerierInstanceOrThrowError("")
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return UpdateOrDeleteUserIdMappingInfoResult{}, err
+	}
+	return instance.UpdateOrDeleteUserIdMappingInfo(userId, userIdType, externalUserIdInfo)
+}
+
+func (s *Instance) UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, externalUserIdInfo *string) (UpdateOrDeleteUserIdMappingInfoResult, error) {
+	querier, err := s.GetQuerier("")
 	if err != nil {
 		return UpdateOrDeleteUserIdMappingInfoResult{}, err
 	}
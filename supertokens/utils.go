@@ -17,6 +17,7 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -269,3 +270,66 @@ func MakeDefaultUserContextFromAPI(r *http.Request) UserContext {
 		},
 	}
 }
+
+func MakeDefaultUserContextFromContext(ctx context.Context) UserContext {
+	return &map[string]interface{}{
+		"_default": map[string]interface{}{
+			"context": ctx,
+		},
+	}
+}
+
+// GetRequestFromUserContext returns the request that is present in the user context,
+// or nil if the user context was not created from an API call.
+func GetRequestFromUserContext(userContext UserContext) *http.Request {
+	if userContext == nil {
+		return nil
+	}
+	defaultContext, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	req, _ := defaultContext["request"].(*http.Request)
+	return req
+}
+
+// DetachUserContext returns a copy of the user context that can be used after the request it was
+// created for has finished, for example to send an email in the background. The copy does not
+// contain the request and its context, but keeps the instance that handled the request.
+func DetachUserContext(userContext UserContext) UserContext {
+	result := map[string]interface{}{}
+	if userContext == nil {
+		return &result
+	}
+	for k, v := range *userContext {
+		if k != "_default" {
+			result[k] = v
+		}
+	}
+	if instance := GetInstanceFromContext(GetContextFromUserContext(userContext)); instance != nil {
+		result["_default"] = map[string]interface{}{
+			"context": context.WithValue(context.Background(), instanceContextKey{}, instance),
+		}
+	}
+	return &result
+}
+
+// GetContextFromUserContext returns the context that calls to the core should be bound to.
+// An explicitly set context takes precedence over the context of the request that
+// is present in the user context. If neither exists, context.Background() is returned.
+func GetContextFromUserContext(userContext UserContext) context.Context {
+	if userContext == nil {
+		return context.Background()
+	}
+	defaultContext, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		return context.Background()
+	}
+	if ctx, ok := defaultContext["context"].(context.Context); ok && ctx != nil {
+		return ctx
+	}
+	if req, ok := defaultContext["request"].(*http.Request); ok && req != nil {
+		return req.Context()
+	}
+	return context.Background()
+}
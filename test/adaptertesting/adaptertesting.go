@@ -0,0 +1,94 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package adaptertesting contains the setup that the tests of the framework adapters in
+// supertokens/adapters share.
+package adaptertesting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+// Setup starts a core, initialises SuperTokens with the session recipe, and stops the core again
+// when the test has finished.
+func Setup(t *testing.T) {
+	unittesting.KillAllST()
+	supertokens.ResetForTest()
+	unittesting.SetUpST()
+	unittesting.StartUpST("localhost", "8080")
+	t.Cleanup(func() {
+		unittesting.KillAllST()
+		supertokens.ResetForTest()
+		unittesting.CleanST()
+	})
+
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+// CreateSession creates a session for the user "userId" and sends its tokens in the response.
+// It can be used as the handler of a route in the adapter under test.
+func CreateSession(w http.ResponseWriter, r *http.Request) error {
+	_, err := session.CreateNewSessionWithContext(w, "userId", map[string]interface{}{}, map[string]interface{}{}, supertokens.MakeDefaultUserContextFromAPI(r))
+	return err
+}
+
+// CreateAccessToken creates a session for the user "userId" outside of a request and returns
+// its access token.
+func CreateAccessToken(t *testing.T) string {
+	sessionContainer, err := session.CreateNewSessionWithContext(httptest.NewRecorder(), "userId", map[string]interface{}{}, map[string]interface{}{}, &map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return sessionContainer.GetAccessToken()
+}
+
+// NewRequest returns a request that asks for tokens in headers, and that sends accessToken as
+// its bearer token if it is not empty.
+func NewRequest(method string, path string, accessToken string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("st-auth-mode", "header")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return req
+}
+
+// Serve sends a request created with NewRequest to handler.
+func Serve(handler http.Handler, method string, path string, accessToken string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, NewRequest(method, path, accessToken))
+	return res
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+// linkedUsers returns the recipe users that are linked to the primary user,
+// oldest first. The primary user itself may have been deleted by an unlink.
+func (c *Core) linkedUsers(primaryUserId string) []*user {
+	result := []*user{}
+	for _, u := range c.sortedUsers(true) {
+		if u.primaryUserId == primaryUserId {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// findLinkedUser finds a primary user by its id, or the recipe user with the id.
+func (c *Core) findLinkedUser(userId string) *user {
+	userId = c.resolveUserId(userId)
+	if u, ok := c.users[userId]; ok {
+		return u
+	}
+	if linked := c.linkedUsers(userId); len(linked) > 0 {
+		return linked[0]
+	}
+	return nil
+}
+
+func (c *Core) isLoginMethodVerified(u *user) bool {
+	if u.email == nil {
+		// passwordless users that log in with their phone number
+		return u.phoneNumber != nil
+	}
+	return c.verifiedEmails[verifiedEmailKey(c.getExternalUserId(u.id), *u.email)]
+}
+
+func (c *Core) loginMethodToJSON(u *user) map[string]interface{} {
+	result := c.userToJSON(u)
+	delete(result, "id")
+	result["recipeId"] = u.recipeId
+	result["recipeUserId"] = c.getExternalUserId(u.id)
+	result["verified"] = c.isLoginMethodVerified(u)
+	return result
+}
+
+func (c *Core) linkedUserToJSON(u *user) map[string]interface{} {
+	primaryUserId := u.id
+	users := []*user{u}
+	if u.primaryUserId != "" {
+		primaryUserId = u.primaryUserId
+		users = c.linkedUsers(primaryUserId)
+	}
+
+	emails := []string{}
+	phoneNumbers := []string{}
+	thirdParty := []map[string]interface{}{}
+	loginMethods := []map[string]interface{}{}
+	for _, linked := range users {
+		if linked.email != nil && !contains(emails, *linked.email) {
+			emails = append(emails, *linked.email)
+		}
+		if linked.phoneNumber != nil && !contains(phoneNumbers, *linked.phoneNumber) {
+			phoneNumbers = append(phoneNumbers, *linked.phoneNumber)
+		}
+		if linked.recipeId == thirdPartyRecipeId {
+			thirdParty = append(thirdParty, map[string]interface{}{
+				"id":     linked.thirdPartyId,
+				"userId": linked.thirdPartyUserId,
+			})
+		}
+		loginMethods = append(loginMethods, c.loginMethodToJSON(linked))
+	}
+	return map[string]interface{}{
+		"id":            c.getExternalUserId(primaryUserId),
+		"isPrimaryUser": u.primaryUserId != "",
+		"timeJoined":    users[0].timeJoined,
+		"emails":        emails,
+		"phoneNumbers":  phoneNumbers,
+		"thirdParty":    thirdParty,
+		"loginMethods":  loginMethods,
+	}
+}
+
+func sharesAccountInfo(u *user, other *user) bool {
+	if u.email != nil && equalsPointer(other.email, *u.email) {
+		return true
+	}
+	if u.phoneNumber != nil && equalsPointer(other.phoneNumber, *u.phoneNumber) {
+		return true
+	}
+	return u.recipeId == thirdPartyRecipeId && other.recipeId == thirdPartyRecipeId &&
+		u.thirdPartyId == other.thirdPartyId && u.thirdPartyUserId == other.thirdPartyUserId
+}
+
+// findConflictingPrimaryUserId returns the id of a primary user other than
+// exceptPrimaryUserId that already has a login method with the account info of u.
+func (c *Core) findConflictingPrimaryUserId(u *user, exceptPrimaryUserId string) string {
+	conflicting := c.findUser("", func(other *user) bool {
+		return other.id != u.id && other.tenantId == u.tenantId && other.primaryUserId != "" &&
+			other.primaryUserId != exceptPrimaryUserId && sharesAccountInfo(u, other)
+	})
+	if conflicting == nil {
+		return ""
+	}
+	return conflicting.primaryUserId
+}
+
+func (c *Core) getLinkedUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findLinkedUser(userId)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	return okResponse(map[string]interface{}{
+		"user": c.linkedUserToJSON(u),
+	}), nil
+}
+
+func (c *Core) listUsersByAccountInfo(req coreRequest) (map[string]interface{}, error) {
+	email := normaliseEmail(req.query.Get("email"))
+	phoneNumber := req.query.Get("phoneNumber")
+	thirdPartyId := req.query.Get("thirdPartyId")
+	thirdPartyUserId := req.query.Get("thirdPartyUserId")
+	if email == "" && phoneNumber == "" && thirdPartyId == "" {
+		return nil, badInputError{msg: "Please provide one of email, phoneNumber or thirdPartyId"}
+	}
+
+	users := []map[string]interface{}{}
+	seenPrimaryUserIds := map[string]bool{}
+	for _, u := range c.sortedUsers(true) {
+		if u.tenantId != req.tenantId {
+			continue
+		}
+		matches := (email != "" && equalsPointer(u.email, email)) ||
+			(phoneNumber != "" && equalsPointer(u.phoneNumber, phoneNumber)) ||
+			(thirdPartyId != "" && u.thirdPartyId == thirdPartyId && u.thirdPartyUserId == thirdPartyUserId)
+		if !matches {
+			continue
+		}
+		if u.primaryUserId != "" {
+			if seenPrimaryUserIds[u.primaryUserId] {
+				continue
+			}
+			seenPrimaryUserIds[u.primaryUserId] = true
+		}
+		users = append(users, c.linkedUserToJSON(u))
+	}
+	return okResponse(map[string]interface{}{
+		"users": users,
+	}), nil
+}
+
+func (c *Core) createPrimaryUser(req coreRequest) (map[string]interface{}, error) {
+	recipeUserId, err := req.requireString("recipeUserId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findUserById("", recipeUserId)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	if u.primaryUserId != "" {
+		if u.primaryUserId != u.id {
+			response := statusResponse("RECIPE_USER_ID_ALREADY_LINKED_WITH_PRIMARY_USER_ID_ERROR")
+			response["primaryUserId"] = c.getExternalUserId(u.primaryUserId)
+			return response, nil
+		}
+		return okResponse(map[string]interface{}{
+			"user":                   c.linkedUserToJSON(u),
+			"wasAlreadyAPrimaryUser": true,
+		}), nil
+	}
+	if conflicting := c.findConflictingPrimaryUserId(u, ""); conflicting != "" {
+		response := statusResponse("ACCOUNT_INFO_ALREADY_ASSOCIATED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR")
+		response["primaryUserId"] = c.getExternalUserId(conflicting)
+		return response, nil
+	}
+	u.primaryUserId = u.id
+	return okResponse(map[string]interface{}{
+		"user":                   c.linkedUserToJSON(u),
+		"wasAlreadyAPrimaryUser": false,
+	}), nil
+}
+
+func (c *Core) linkAccounts(req coreRequest) (map[string]interface{}, error) {
+	recipeUserId, err := req.requireString("recipeUserId")
+	if err != nil {
+		return nil, err
+	}
+	primaryUserId, err := req.requireString("primaryUserId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findUserById("", recipeUserId)
+	primaryUser := c.findLinkedUser(primaryUserId)
+	if u == nil || primaryUser == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	if primaryUser.primaryUserId == "" {
+		return statusResponse("INPUT_USER_IS_NOT_A_PRIMARY_USER"), nil
+	}
+	if u.primaryUserId == primaryUser.primaryUserId {
+		return okResponse(map[string]interface{}{
+			"user":                  c.linkedUserToJSON(u),
+			"accountsAlreadyLinked": true,
+		}), nil
+	}
+	if u.primaryUserId != "" {
+		response := statusResponse("RECIPE_USER_ID_ALREADY_LINKED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR")
+		response["primaryUserId"] = c.getExternalUserId(u.primaryUserId)
+		return response, nil
+	}
+	if conflicting := c.findConflictingPrimaryUserId(u, primaryUser.primaryUserId); conflicting != "" {
+		response := statusResponse("ACCOUNT_INFO_ALREADY_ASSOCIATED_WITH_ANOTHER_PRIMARY_USER_ID_ERROR")
+		response["primaryUserId"] = c.getExternalUserId(conflicting)
+		return response, nil
+	}
+	u.primaryUserId = primaryUser.primaryUserId
+	return okResponse(map[string]interface{}{
+		"user":                  c.linkedUserToJSON(u),
+		"accountsAlreadyLinked": false,
+	}), nil
+}
+
+func (c *Core) unlinkAccount(req coreRequest) (map[string]interface{}, error) {
+	recipeUserId, err := req.requireString("recipeUserId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findUserById("", recipeUserId)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	if u.primaryUserId == "" {
+		return okResponse(map[string]interface{}{
+			"wasRecipeUserDeleted": false,
+			"wasLinked":            false,
+		}), nil
+	}
+	if u.primaryUserId == u.id {
+		if len(c.linkedUsers(u.id)) > 1 {
+			// the id of the recipe user lives on as the primary user id of the
+			// other login methods, so the recipe user itself is removed.
+			delete(c.users, u.id)
+			return okResponse(map[string]interface{}{
+				"wasRecipeUserDeleted": true,
+				"wasLinked":            true,
+			}), nil
+		}
+		u.primaryUserId = ""
+		return okResponse(map[string]interface{}{
+			"wasRecipeUserDeleted": false,
+			"wasLinked":            false,
+		}), nil
+	}
+	u.primaryUserId = ""
+	return okResponse(map[string]interface{}{
+		"wasRecipeUserDeleted": false,
+		"wasLinked":            true,
+	}), nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+const passwordResetTokenLifetime = uint64(60 * 60 * 1000)
+
+type passwordResetToken struct {
+	userId string
+	expiry uint64
+}
+
+func (c *Core) findEmailPasswordUserByEmail(tenantId string, email string) *user {
+	return c.findUser(emailPasswordRecipeId, func(u *user) bool {
+		return u.tenantId == tenantId && equalsPointer(u.email, email)
+	})
+}
+
+func (c *Core) signUp(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.requireString("email")
+	if err != nil {
+		return nil, err
+	}
+	email = normaliseEmail(email)
+	password, err := req.requireString("password")
+	if err != nil {
+		return nil, err
+	}
+	if c.findEmailPasswordUserByEmail(req.tenantId, email) != nil {
+		return statusResponse("EMAIL_ALREADY_EXISTS_ERROR"), nil
+	}
+	u := c.addUser(&user{
+		recipeId:     emailPasswordRecipeId,
+		tenantId:     req.tenantId,
+		email:        &email,
+		passwordHash: hashString(password),
+	})
+	return okResponse(map[string]interface{}{
+		"user": c.userToJSON(u),
+	}), nil
+}
+
+func (c *Core) signIn(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.requireString("email")
+	if err != nil {
+		return nil, err
+	}
+	email = normaliseEmail(email)
+	password, err := req.requireString("password")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findEmailPasswordUserByEmail(req.tenantId, email)
+	if u == nil || u.passwordHash != hashString(password) {
+		return statusResponse("WRONG_CREDENTIALS_ERROR"), nil
+	}
+	return okResponse(map[string]interface{}{
+		"user": c.userToJSON(u),
+	}), nil
+}
+
+func (c *Core) createPasswordResetToken(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findUserById(emailPasswordRecipeId, userId)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	token := newRandomToken()
+	c.passwordResetTokens[token] = &passwordResetToken{
+		userId: u.id,
+		expiry: getCurrTimeInMS() + passwordResetTokenLifetime,
+	}
+	return okResponse(map[string]interface{}{
+		"token": token,
+	}), nil
+}
+
+func (c *Core) resetPassword(req coreRequest) (map[string]interface{}, error) {
+	token, err := req.requireString("token")
+	if err != nil {
+		return nil, err
+	}
+	newPassword, err := req.requireString("newPassword")
+	if err != nil {
+		return nil, err
+	}
+	u := c.consumePasswordResetTokenOfUser(token)
+	if u == nil {
+		return statusResponse("RESET_PASSWORD_INVALID_TOKEN_ERROR"), nil
+	}
+	u.passwordHash = hashString(newPassword)
+	return okResponse(map[string]interface{}{
+		"userId": c.getExternalUserId(u.id),
+	}), nil
+}
+
+func (c *Core) consumePasswordResetToken(req coreRequest) (map[string]interface{}, error) {
+	token, err := req.requireString("token")
+	if err != nil {
+		return nil, err
+	}
+	u := c.consumePasswordResetTokenOfUser(token)
+	if u == nil {
+		return statusResponse("RESET_PASSWORD_INVALID_TOKEN_ERROR"), nil
+	}
+	return okResponse(map[string]interface{}{
+		"userId": c.getExternalUserId(u.id),
+		"email":  u.email,
+	}), nil
+}
+
+// consumePasswordResetTokenOfUser removes all password reset tokens of the token's user, and
+// returns the user if the token is valid.
+func (c *Core) consumePasswordResetTokenOfUser(token string) *user {
+	tokenInfo, ok := c.passwordResetTokens[token]
+	if !ok || tokenInfo.expiry < getCurrTimeInMS() {
+		return nil
+	}
+	for t, info := range c.passwordResetTokens {
+		if info.userId == tokenInfo.userId {
+			delete(c.passwordResetTokens, t)
+		}
+	}
+	return c.users[tokenInfo.userId]
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+const emailVerificationTokenLifetime = uint64(24 * 60 * 60 * 1000)
+
+type emailVerificationToken struct {
+	userId string
+	email  string
+	expiry uint64
+}
+
+// verifiedEmailKey returns the key used for verifiedEmails. User ids can not
+// contain new lines, so the user id can be recovered by splitting on "\n".
+func verifiedEmailKey(userId string, email string) string {
+	return userId + "\n" + email
+}
+
+func (c *Core) createEmailVerificationToken(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	email, err := req.requireString("email")
+	if err != nil {
+		return nil, err
+	}
+	if c.verifiedEmails[verifiedEmailKey(userId, email)] {
+		return statusResponse("EMAIL_ALREADY_VERIFIED_ERROR"), nil
+	}
+	token := newRandomToken()
+	c.emailVerificationTokens[token] = &emailVerificationToken{
+		userId: userId,
+		email:  email,
+		expiry: getCurrTimeInMS() + emailVerificationTokenLifetime,
+	}
+	return okResponse(map[string]interface{}{
+		"token": token,
+	}), nil
+}
+
+func (c *Core) verifyEmail(req coreRequest) (map[string]interface{}, error) {
+	token, err := req.requireString("token")
+	if err != nil {
+		return nil, err
+	}
+	tokenInfo, ok := c.emailVerificationTokens[token]
+	if !ok || tokenInfo.expiry < getCurrTimeInMS() {
+		return statusResponse("EMAIL_VERIFICATION_INVALID_TOKEN_ERROR"), nil
+	}
+	c.removeEmailVerificationTokens(tokenInfo.userId, tokenInfo.email)
+	c.verifiedEmails[verifiedEmailKey(tokenInfo.userId, tokenInfo.email)] = true
+	return okResponse(map[string]interface{}{
+		"userId": tokenInfo.userId,
+		"email":  tokenInfo.email,
+	}), nil
+}
+
+func (c *Core) isEmailVerified(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	email, err := req.requireQuery("email")
+	if err != nil {
+		return nil, err
+	}
+	return okResponse(map[string]interface{}{
+		"isVerified": c.verifiedEmails[verifiedEmailKey(userId, email)],
+	}), nil
+}
+
+func (c *Core) removeEmailVerificationTokens(userId string, email string) {
+	for token, info := range c.emailVerificationTokens {
+		if info.userId == userId && info.email == email {
+			delete(c.emailVerificationTokens, token)
+		}
+	}
+}
+
+func (c *Core) revokeEmailVerificationTokens(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	email, err := req.requireString("email")
+	if err != nil {
+		return nil, err
+	}
+	c.removeEmailVerificationTokens(userId, email)
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) unverifyEmail(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	email, err := req.requireString("email")
+	if err != nil {
+		return nil, err
+	}
+	delete(c.verifiedEmails, verifiedEmailKey(userId, email))
+	return statusResponse("OK"), nil
+}
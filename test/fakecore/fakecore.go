@@ -0,0 +1,369 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package fakecore is an in-memory stand-in for the SuperTokens core. It
+// implements the CDI endpoints used by this SDK so that applications and the
+// SDK's own tests can run without a locally installed core.
+//
+//	server := fakecore.NewServer(fakecore.Config{})
+//	defer server.Close()
+//
+//	supertokens.Init(supertokens.TypeInput{
+//		Supertokens: &supertokens.ConnectionInfo{
+//			ConnectionURI: server.URL,
+//		},
+//		...
+//	})
+//
+// All state is kept in memory and is lost when the server is closed.
+package fakecore
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Config mirrors the subset of the core's config.yaml that the fake core
+// understands. Zero values are replaced by the defaults of the real core.
+type Config struct {
+	// AccessTokenValidity is the lifetime of access tokens in seconds. Defaults to 3600.
+	AccessTokenValidity uint64
+	// RefreshTokenValidity is the lifetime of refresh tokens in minutes. Defaults to 144000.
+	RefreshTokenValidity uint64
+	// AccessTokenBlacklisting makes every session verification go through the core.
+	AccessTokenBlacklisting bool
+	// PasswordlessCodeLifetime is the lifetime of passwordless codes in milliseconds. Defaults to 900000.
+	PasswordlessCodeLifetime uint64
+	// PasswordlessMaxCodeInputAttempts defaults to 5.
+	PasswordlessMaxCodeInputAttempts int
+	// APIKeys, if set, are required in the api-key header of every request.
+	APIKeys []string
+	// CDIVersions are the versions returned by /apiversion. Defaults to all versions this SDK
+	// supports, and the newer versions whose endpoints the fake core serves (3.0 for tenants and
+	// 4.0 for account linking).
+	CDIVersions []string
+}
+
+var defaultCDIVersions = []string{"2.8", "2.9", "2.10", "2.11", "2.12", "2.13", "2.14", "2.15", "2.21", "3.0", "4.0"}
+
+func normaliseConfig(config Config) Config {
+	if config.AccessTokenValidity == 0 {
+		config.AccessTokenValidity = 3600
+	}
+	if config.RefreshTokenValidity == 0 {
+		config.RefreshTokenValidity = 144000
+	}
+	if config.PasswordlessCodeLifetime == 0 {
+		config.PasswordlessCodeLifetime = 900000
+	}
+	if config.PasswordlessMaxCodeInputAttempts == 0 {
+		config.PasswordlessMaxCodeInputAttempts = 5
+	}
+	if len(config.CDIVersions) == 0 {
+		config.CDIVersions = defaultCDIVersions
+	}
+	return config
+}
+
+// Core is an http.Handler that serves the CDI endpoints from memory.
+type Core struct {
+	mutex  sync.Mutex
+	config Config
+	routes map[string]handlerFunc
+
+	signingKey          *rsa.PrivateKey
+	publicKey           string
+	signingKeyCreatedAt uint64
+
+	sessions      map[string]*sessionInfo
+	refreshTokens map[string]*refreshTokenInfo
+
+	users                   map[string]*user
+	passwordResetTokens     map[string]*passwordResetToken
+	emailVerificationTokens map[string]*emailVerificationToken
+	verifiedEmails          map[string]bool
+	passwordlessDevices     map[string]*passwordlessDevice
+	roles                   map[string]map[string]bool
+	userRoles               map[string]map[string]bool
+	userMetadata            map[string]map[string]interface{}
+	userIdMappings          []*userIdMapping
+	totpDevices             map[string][]*totpDevice
+}
+
+// New creates a fake core with an empty database and a freshly generated signing key.
+func New(config Config) *Core {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	core := &Core{
+		config:                  normaliseConfig(config),
+		signingKey:              signingKey,
+		publicKey:               base64.StdEncoding.EncodeToString(publicKeyBytes),
+		signingKeyCreatedAt:     getCurrTimeInMS(),
+		sessions:                map[string]*sessionInfo{},
+		refreshTokens:           map[string]*refreshTokenInfo{},
+		users:                   map[string]*user{},
+		passwordResetTokens:     map[string]*passwordResetToken{},
+		emailVerificationTokens: map[string]*emailVerificationToken{},
+		verifiedEmails:          map[string]bool{},
+		passwordlessDevices:     map[string]*passwordlessDevice{},
+		roles:                   map[string]map[string]bool{},
+		userRoles:               map[string]map[string]bool{},
+		userMetadata:            map[string]map[string]interface{}{},
+		totpDevices:             map[string][]*totpDevice{},
+	}
+	core.routes = core.makeRoutes()
+	return core
+}
+
+// Server is a running fake core. URL is the value to use as ConnectionURI.
+type Server struct {
+	*httptest.Server
+	Core *Core
+}
+
+// NewServer starts a fake core on a random local port.
+func NewServer(config Config) *Server {
+	core := New(config)
+	return &Server{
+		Server: httptest.NewServer(core),
+		Core:   core,
+	}
+}
+
+// NewServerOnAddress starts a fake core listening on the given address, for
+// example "localhost:8080".
+func NewServerOnAddress(address string, config Config) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	core := New(config)
+	server := httptest.NewUnstartedServer(core)
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	return &Server{
+		Server: server,
+		Core:   core,
+	}, nil
+}
+
+type coreRequest struct {
+	rid      string
+	tenantId string
+	query    url.Values
+	body     map[string]interface{}
+}
+
+const defaultTenantId = "public"
+
+// splitTenantFromPath removes the tenant ID from paths like /<tenantId>/recipe/signin and
+// /<tenantId>/users/by-accountinfo. Paths without a tenant belong to the default tenant.
+func splitTenantFromPath(path string) (string, string) {
+	if strings.HasPrefix(path, "/recipe/") || path == "/recipe" || path == "/users/by-accountinfo" {
+		return defaultTenantId, path
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) == 2 && (parts[1] == "recipe" || strings.HasPrefix(parts[1], "recipe/") || parts[1] == "users/by-accountinfo") {
+		return parts[0], "/" + parts[1]
+	}
+	return defaultTenantId, path
+}
+
+type handlerFunc func(req coreRequest) (map[string]interface{}, error)
+
+// badInputError makes the core reply with a 400, like the real core does for invalid input.
+type badInputError struct {
+	msg string
+}
+
+func (err badInputError) Error() string {
+	return err.msg
+}
+
+func (c *Core) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/hello" || r.URL.Path == "/" {
+		w.Write([]byte("Hello\n"))
+		return
+	}
+
+	c.mutex.Lock()
+	apiKeys := c.config.APIKeys
+	c.mutex.Unlock()
+	if len(apiKeys) > 0 && !contains(apiKeys, r.Header.Get("api-key")) {
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+
+	tenantId, path := splitTenantFromPath(r.URL.Path)
+	handler, ok := c.routes[r.Method+" "+path]
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	req := coreRequest{
+		rid:      r.Header.Get("rid"),
+		tenantId: tenantId,
+		query:    r.URL.Query(),
+		body:     map[string]interface{}{},
+	}
+	if r.Body != nil {
+		bodyBytes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(bodyBytes) > 0 && string(bodyBytes) != "null" {
+			if err := json.Unmarshal(bodyBytes, &req.body); err != nil {
+				http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	c.mutex.Lock()
+	response, err := handler(req)
+	c.mutex.Unlock()
+
+	if err != nil {
+		if errors.As(err, &badInputError{}) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetConfigValue changes a config value of a running fake core. See Config.Set.
+func (c *Core) SetConfigValue(key string, value string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.config.Set(key, value)
+}
+
+func (c *Core) makeRoutes() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"GET /apiversion": c.apiVersion,
+		"GET /telemetry":  c.telemetry,
+
+		"POST /recipe/handshake":          c.handshake,
+		"POST /recipe/session":            c.createSession,
+		"GET /recipe/session":             c.getSessionInformation,
+		"POST /recipe/session/verify":     c.verifySession,
+		"POST /recipe/session/refresh":    c.refreshSession,
+		"POST /recipe/session/remove":     c.removeSessions,
+		"GET /recipe/session/user":        c.getSessionHandlesForUser,
+		"PUT /recipe/session/data":        c.updateSessionData,
+		"PUT /recipe/jwt/data":            c.updateAccessTokenPayload,
+		"POST /recipe/session/regenerate": c.regenerateAccessToken,
+
+		"POST /recipe/jwt":     c.createJWT,
+		"GET /recipe/jwt/jwks": c.getJWKS,
+
+		"POST /recipe/signup":                            c.signUp,
+		"POST /recipe/signin":                            c.signIn,
+		"GET /recipe/user":                               c.getUser,
+		"PUT /recipe/user":                               c.updateUser,
+		"POST /recipe/user/password/reset/token":         c.createPasswordResetToken,
+		"POST /recipe/user/password/reset":               c.resetPassword,
+		"POST /recipe/user/password/reset/token/consume": c.consumePasswordResetToken,
+
+		"POST /recipe/user/email/verify/token":        c.createEmailVerificationToken,
+		"POST /recipe/user/email/verify":              c.verifyEmail,
+		"GET /recipe/user/email/verify":               c.isEmailVerified,
+		"POST /recipe/user/email/verify/token/remove": c.revokeEmailVerificationTokens,
+		"POST /recipe/user/email/verify/remove":       c.unverifyEmail,
+
+		"POST /recipe/signinup":      c.thirdPartySignInUp,
+		"GET /recipe/users/by-email": c.getThirdPartyUsersByEmail,
+
+		"POST /recipe/signinup/code":         c.createCode,
+		"POST /recipe/signinup/code/consume": c.consumeCode,
+		"GET /recipe/signinup/codes":         c.listCodes,
+		"POST /recipe/signinup/codes/remove": c.revokeAllCodes,
+		"POST /recipe/signinup/code/remove":  c.revokeCode,
+
+		"PUT /recipe/role":                     c.createNewRoleOrAddPermissions,
+		"GET /recipe/role/permissions":         c.getPermissionsForRole,
+		"POST /recipe/role/permissions/remove": c.removePermissionsFromRole,
+		"GET /recipe/permission/roles":         c.getRolesThatHavePermission,
+		"POST /recipe/role/remove":             c.deleteRole,
+		"GET /recipe/roles":                    c.getAllRoles,
+		"PUT /recipe/user/role":                c.addRoleToUser,
+		"POST /recipe/user/role/remove":        c.removeUserRole,
+		"GET /recipe/user/roles":               c.getRolesForUser,
+		"GET /recipe/role/users":               c.getUsersThatHaveRole,
+
+		"GET /recipe/user/metadata":         c.getUserMetadata,
+		"PUT /recipe/user/metadata":         c.updateUserMetadata,
+		"POST /recipe/user/metadata/remove": c.clearUserMetadata,
+
+		"POST /recipe/userid/map":                  c.createUserIdMapping,
+		"GET /recipe/userid/map":                   c.getUserIdMapping,
+		"POST /recipe/userid/map/remove":           c.deleteUserIdMapping,
+		"PUT /recipe/userid/external-user-id-info": c.updateExternalUserIdInfo,
+
+		"GET /user/id":                             c.getLinkedUser,
+		"GET /users/by-accountinfo":                c.listUsersByAccountInfo,
+		"POST /recipe/accountlinking/user/primary": c.createPrimaryUser,
+		"POST /recipe/accountlinking/user/link":    c.linkAccounts,
+		"POST /recipe/accountlinking/user/unlink":  c.unlinkAccount,
+
+		"POST /recipe/totp/device":        c.createTotpDevice,
+		"GET /recipe/totp/device/list":    c.listTotpDevices,
+		"POST /recipe/totp/device/remove": c.removeTotpDevice,
+		"POST /recipe/totp/device/verify": c.verifyTotpDevice,
+		"POST /recipe/totp/verify":        c.verifyTotp,
+
+		"GET /users":        c.getUsers,
+		"GET /users/count":  c.getUserCount,
+		"POST /user/remove": c.deleteUser,
+	}
+}
+
+func (c *Core) apiVersion(req coreRequest) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"versions": c.config.CDIVersions,
+	}, nil
+}
+
+func (c *Core) telemetry(req coreRequest) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"exists": false,
+	}, nil
+}
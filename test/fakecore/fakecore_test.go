@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func callCore(t *testing.T, server *Server, method string, path string, body map[string]interface{}) (int, map[string]interface{}) {
+	bodyBytes, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewBuffer(bodyBytes))
+	assert.NoError(t, err)
+	req.Header.Set("rid", "session")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	result := map[string]interface{}{}
+	if res.StatusCode == http.StatusOK {
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	}
+	return res.StatusCode, result
+}
+
+func TestRefreshTokenRotationAndTheftDetection(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+
+	_, created := callCore(t, server, http.MethodPost, "/recipe/session", map[string]interface{}{
+		"userId":             "user1",
+		"userDataInJWT":      map[string]interface{}{},
+		"userDataInDatabase": map[string]interface{}{},
+		"enableAntiCsrf":     false,
+	})
+	assert.Equal(t, "OK", created["status"])
+	firstRefreshToken := created["refreshToken"].(map[string]interface{})["token"].(string)
+
+	_, refreshed := callCore(t, server, http.MethodPost, "/recipe/session/refresh", map[string]interface{}{
+		"refreshToken":   firstRefreshToken,
+		"enableAntiCsrf": false,
+	})
+	assert.Equal(t, "OK", refreshed["status"])
+	secondRefreshToken := refreshed["refreshToken"].(map[string]interface{})["token"].(string)
+
+	// using the new access token makes the new refresh token the current one
+	_, verified := callCore(t, server, http.MethodPost, "/recipe/session/verify", map[string]interface{}{
+		"accessToken":     refreshed["accessToken"].(map[string]interface{})["token"],
+		"doAntiCsrfCheck": false,
+		"enableAntiCsrf":  false,
+	})
+	assert.Equal(t, "OK", verified["status"])
+	assert.NotNil(t, verified["accessToken"])
+
+	_, theft := callCore(t, server, http.MethodPost, "/recipe/session/refresh", map[string]interface{}{
+		"refreshToken":   firstRefreshToken,
+		"enableAntiCsrf": false,
+	})
+	assert.Equal(t, "TOKEN_THEFT_DETECTED", theft["status"])
+	assert.Equal(t, "user1", theft["session"].(map[string]interface{})["userId"])
+
+	_, refreshedAgain := callCore(t, server, http.MethodPost, "/recipe/session/refresh", map[string]interface{}{
+		"refreshToken":   secondRefreshToken,
+		"enableAntiCsrf": false,
+	})
+	assert.Equal(t, "OK", refreshedAgain["status"])
+}
+
+func TestVerifyWithBlacklistingChecksSessionExists(t *testing.T) {
+	server := NewServer(Config{AccessTokenBlacklisting: true})
+	defer server.Close()
+
+	_, created := callCore(t, server, http.MethodPost, "/recipe/session", map[string]interface{}{
+		"userId":         "user1",
+		"enableAntiCsrf": false,
+	})
+	accessToken := created["accessToken"].(map[string]interface{})["token"]
+	handle := created["session"].(map[string]interface{})["handle"]
+
+	_, verified := callCore(t, server, http.MethodPost, "/recipe/session/verify", map[string]interface{}{
+		"accessToken": accessToken,
+	})
+	assert.Equal(t, "OK", verified["status"])
+
+	_, removed := callCore(t, server, http.MethodPost, "/recipe/session/remove", map[string]interface{}{
+		"sessionHandles": []interface{}{handle},
+	})
+	assert.Equal(t, []interface{}{handle}, removed["sessionHandlesRevoked"])
+
+	_, verified = callCore(t, server, http.MethodPost, "/recipe/session/verify", map[string]interface{}{
+		"accessToken": accessToken,
+	})
+	assert.Equal(t, "UNAUTHORISED", verified["status"])
+}
+
+func TestAPIKeyIsRequiredWhenConfigured(t *testing.T) {
+	config := Config{}
+	assert.NoError(t, config.Set("api_keys", "key1,key2"))
+	server := NewServer(config)
+	defer server.Close()
+
+	statusCode, _ := callCore(t, server, http.MethodGet, "/apiversion", nil)
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/apiversion", nil)
+	assert.NoError(t, err)
+	req.Header.Set("api-key", "key2")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
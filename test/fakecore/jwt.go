@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+const jwtKeyID = "s-fake-core"
+
+func (c *Core) createJWT(req coreRequest) (map[string]interface{}, error) {
+	algorithm, err := req.requireString("algorithm")
+	if err != nil {
+		return nil, err
+	}
+	if algorithm != "RS256" {
+		return statusResponse("UNSUPPORTED_ALGORITHM_ERROR"), nil
+	}
+	jwksDomain, err := req.requireString("jwksDomain")
+	if err != nil {
+		return nil, err
+	}
+	validity, _ := req.body["validity"].(float64)
+	if validity <= 0 {
+		return nil, badInputError{msg: "validity must be greater than or equal to 0"}
+	}
+
+	now := getCurrTimeInMS() / 1000
+	payload := copyMap(req.getMap("payload"))
+	payload["iss"] = jwksDomain
+	payload["iat"] = now
+	payload["exp"] = now + uint64(validity)
+
+	headerBytes, err := json.Marshal(map[string]interface{}{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": jwtKeyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(payloadBytes)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(nil, c.signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return okResponse(map[string]interface{}{
+		"jwt": signingInput + "." + base64.RawURLEncoding.EncodeToString(signature),
+	}), nil
+}
+
+func (c *Core) getJWKS(req coreRequest) (map[string]interface{}, error) {
+	publicKey := c.signingKey.PublicKey
+	return okResponse(map[string]interface{}{
+		"keys": []map[string]interface{}{
+			{
+				"kty": "RSA",
+				"kid": jwtKeyID,
+				"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+				"alg": "RS256",
+				"use": "sig",
+			},
+		},
+	}), nil
+}
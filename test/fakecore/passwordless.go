@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+type passwordlessDevice struct {
+	deviceId                    string
+	preAuthSessionId            string
+	email                       *string
+	phoneNumber                 *string
+	failedCodeInputAttemptCount int
+	codes                       []*passwordlessCode
+}
+
+type passwordlessCode struct {
+	codeId        string
+	userInputCode string
+	linkCode      string
+	timeCreated   uint64
+	codeLifetime  uint64
+}
+
+func (code *passwordlessCode) isExpired() bool {
+	return code.timeCreated+code.codeLifetime < getCurrTimeInMS()
+}
+
+func (c *Core) findPasswordlessDevice(condition func(device *passwordlessDevice) bool) []*passwordlessDevice {
+	result := []*passwordlessDevice{}
+	for _, device := range c.passwordlessDevices {
+		if condition(device) {
+			result = append(result, device)
+		}
+	}
+	return result
+}
+
+func (c *Core) addPasswordlessCode(device *passwordlessDevice, userInputCode string) map[string]interface{} {
+	if userInputCode == "" {
+		userInputCode = newNumericCode(6)
+	}
+	code := &passwordlessCode{
+		codeId:        newUUID(),
+		userInputCode: userInputCode,
+		linkCode:      newRandomToken(),
+		timeCreated:   getCurrTimeInMS(),
+		codeLifetime:  c.config.PasswordlessCodeLifetime,
+	}
+	device.codes = append(device.codes, code)
+	return okResponse(map[string]interface{}{
+		"preAuthSessionId": device.preAuthSessionId,
+		"codeId":           code.codeId,
+		"deviceId":         device.deviceId,
+		"userInputCode":    code.userInputCode,
+		"linkCode":         code.linkCode,
+		"codeLifetime":     code.codeLifetime,
+		"timeCreated":      code.timeCreated,
+	})
+}
+
+func (c *Core) createCode(req coreRequest) (map[string]interface{}, error) {
+	userInputCode, _ := req.getString("userInputCode")
+
+	if deviceId, ok := req.getString("deviceId"); ok {
+		devices := c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return device.deviceId == deviceId
+		})
+		if len(devices) == 0 {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+		for _, code := range devices[0].codes {
+			if userInputCode != "" && code.userInputCode == userInputCode {
+				return statusResponse("USER_INPUT_CODE_ALREADY_USED_ERROR"), nil
+			}
+		}
+		return c.addPasswordlessCode(devices[0], userInputCode), nil
+	}
+
+	device := &passwordlessDevice{
+		deviceId:         newRandomToken(),
+		preAuthSessionId: newRandomToken(),
+	}
+	if email, ok := req.getString("email"); ok {
+		email = normaliseEmail(email)
+		device.email = &email
+	} else if phoneNumber, ok := req.getString("phoneNumber"); ok {
+		device.phoneNumber = &phoneNumber
+	} else {
+		return nil, badInputError{msg: "Please provide exactly one of email, phoneNumber or deviceId"}
+	}
+	c.passwordlessDevices[device.preAuthSessionId] = device
+	return c.addPasswordlessCode(device, userInputCode), nil
+}
+
+func (c *Core) consumeCode(req coreRequest) (map[string]interface{}, error) {
+	preAuthSessionId, err := req.requireString("preAuthSessionId")
+	if err != nil {
+		return nil, err
+	}
+	var device *passwordlessDevice
+	var consumedCode *passwordlessCode
+	if linkCode, ok := req.getString("linkCode"); ok {
+		device, ok = c.passwordlessDevices[preAuthSessionId]
+		if !ok {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+		for _, code := range device.codes {
+			if code.linkCode == linkCode {
+				consumedCode = code
+			}
+		}
+		if consumedCode == nil || consumedCode.isExpired() {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+	} else {
+		deviceId, err := req.requireString("deviceId")
+		if err != nil {
+			return nil, err
+		}
+		userInputCode, err := req.requireString("userInputCode")
+		if err != nil {
+			return nil, err
+		}
+		devices := c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return device.deviceId == deviceId
+		})
+		if len(devices) == 0 {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+		device = devices[0]
+		if device.preAuthSessionId != preAuthSessionId {
+			return nil, badInputError{msg: "preAuthSessionId and deviceId doesn't match"}
+		}
+		for _, code := range device.codes {
+			if code.userInputCode == userInputCode {
+				consumedCode = code
+			}
+		}
+		if consumedCode == nil || consumedCode.isExpired() {
+			device.failedCodeInputAttemptCount++
+			if device.failedCodeInputAttemptCount >= c.config.PasswordlessMaxCodeInputAttempts {
+				delete(c.passwordlessDevices, device.preAuthSessionId)
+				return statusResponse("RESTART_FLOW_ERROR"), nil
+			}
+			status := "INCORRECT_USER_INPUT_CODE_ERROR"
+			if consumedCode != nil {
+				status = "EXPIRED_USER_INPUT_CODE_ERROR"
+			}
+			return map[string]interface{}{
+				"status":                      status,
+				"failedCodeInputAttemptCount": device.failedCodeInputAttemptCount,
+				"maximumCodeInputAttempts":    c.config.PasswordlessMaxCodeInputAttempts,
+			}, nil
+		}
+	}
+
+	c.removePasswordlessDevices(device.email, device.phoneNumber)
+
+	createdNewUser := false
+	u := c.findUser(passwordlessRecipeId, func(u *user) bool {
+		if u.tenantId != req.tenantId {
+			return false
+		}
+		if device.email != nil {
+			return equalsPointer(u.email, *device.email)
+		}
+		return equalsPointer(u.phoneNumber, *device.phoneNumber)
+	})
+	if u == nil {
+		createdNewUser = true
+		u = c.addUser(&user{
+			recipeId:    passwordlessRecipeId,
+			tenantId:    req.tenantId,
+			email:       device.email,
+			phoneNumber: device.phoneNumber,
+		})
+	}
+
+	return okResponse(map[string]interface{}{
+		"createdNewUser": createdNewUser,
+		"user":           c.userToJSON(u),
+	}), nil
+}
+
+func (c *Core) removePasswordlessDevices(email *string, phoneNumber *string) {
+	for preAuthSessionId, device := range c.passwordlessDevices {
+		if (email != nil && equalsPointer(device.email, *email)) ||
+			(phoneNumber != nil && equalsPointer(device.phoneNumber, *phoneNumber)) {
+			delete(c.passwordlessDevices, preAuthSessionId)
+		}
+	}
+}
+
+func (c *Core) listCodes(req coreRequest) (map[string]interface{}, error) {
+	var devices []*passwordlessDevice
+	if deviceId := req.query.Get("deviceId"); deviceId != "" {
+		devices = c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return device.deviceId == deviceId
+		})
+	} else if email := normaliseEmail(req.query.Get("email")); email != "" {
+		devices = c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return equalsPointer(device.email, email)
+		})
+	} else if phoneNumber := req.query.Get("phoneNumber"); phoneNumber != "" {
+		devices = c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return equalsPointer(device.phoneNumber, phoneNumber)
+		})
+	} else if preAuthSessionId := req.query.Get("preAuthSessionId"); preAuthSessionId != "" {
+		devices = c.findPasswordlessDevice(func(device *passwordlessDevice) bool {
+			return device.preAuthSessionId == preAuthSessionId
+		})
+	} else {
+		return nil, badInputError{msg: "Please provide exactly one of deviceId, email, phoneNumber or preAuthSessionId"}
+	}
+
+	result := []map[string]interface{}{}
+	for _, device := range devices {
+		codes := []map[string]interface{}{}
+		for _, code := range device.codes {
+			codes = append(codes, map[string]interface{}{
+				"codeId":       code.codeId,
+				"timeCreated":  code.timeCreated,
+				"codeLifetime": code.codeLifetime,
+			})
+		}
+		deviceJSON := map[string]interface{}{
+			"preAuthSessionId":            device.preAuthSessionId,
+			"failedCodeInputAttemptCount": device.failedCodeInputAttemptCount,
+			"codes":                       codes,
+		}
+		if device.email != nil {
+			deviceJSON["email"] = *device.email
+		}
+		if device.phoneNumber != nil {
+			deviceJSON["phoneNumber"] = *device.phoneNumber
+		}
+		result = append(result, deviceJSON)
+	}
+	return okResponse(map[string]interface{}{
+		"devices": result,
+	}), nil
+}
+
+func (c *Core) revokeAllCodes(req coreRequest) (map[string]interface{}, error) {
+	var email, phoneNumber *string
+	if v, ok := req.getString("email"); ok {
+		v = normaliseEmail(v)
+		email = &v
+	} else if v, ok := req.getString("phoneNumber"); ok {
+		phoneNumber = &v
+	} else {
+		return nil, badInputError{msg: "Please provide exactly one of email or phoneNumber"}
+	}
+	c.removePasswordlessDevices(email, phoneNumber)
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) revokeCode(req coreRequest) (map[string]interface{}, error) {
+	codeId, err := req.requireString("codeId")
+	if err != nil {
+		return nil, err
+	}
+	for preAuthSessionId, device := range c.passwordlessDevices {
+		codes := []*passwordlessCode{}
+		for _, code := range device.codes {
+			if code.codeId != codeId {
+				codes = append(codes, code)
+			}
+		}
+		device.codes = codes
+		if len(codes) == 0 {
+			delete(c.passwordlessDevices, preAuthSessionId)
+		}
+	}
+	return statusResponse("OK"), nil
+}
@@ -0,0 +1,500 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+/*
+	{
+		"alg":     "RS256",
+		"typ":     "JWT",
+		"version": "2",
+	}
+*/
+const accessTokenHeader = "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCIsInZlcnNpb24iOiIyIn0="
+
+const signingKeyValidity = uint64(7 * 24 * 60 * 60 * 1000)
+
+type sessionInfo struct {
+	handle             string
+	userId             string
+	userDataInJWT      map[string]interface{}
+	userDataInDatabase map[string]interface{}
+	refreshTokenHash1  string
+	timeCreated        uint64
+	expiry             uint64
+}
+
+type refreshTokenInfo struct {
+	sessionHandle           string
+	parentRefreshTokenHash1 *string
+	antiCsrfToken           *string
+}
+
+func (c *Core) signingKeyFields(response map[string]interface{}) map[string]interface{} {
+	expiryTime := getCurrTimeInMS() + signingKeyValidity
+	response["jwtSigningPublicKey"] = c.publicKey
+	response["jwtSigningPublicKeyExpiryTime"] = expiryTime
+	response["jwtSigningPublicKeyList"] = []map[string]interface{}{
+		{
+			"publicKey":  c.publicKey,
+			"expiryTime": expiryTime,
+			"createdAt":  c.signingKeyCreatedAt,
+		},
+	}
+	return response
+}
+
+func (c *Core) handshake(req coreRequest) (map[string]interface{}, error) {
+	return c.signingKeyFields(okResponse(map[string]interface{}{
+		"accessTokenBlacklistingEnabled": c.config.AccessTokenBlacklisting,
+		"accessTokenValidity":            c.config.AccessTokenValidity * 1000,
+		"refreshTokenValidity":           c.config.RefreshTokenValidity * 60 * 1000,
+	})), nil
+}
+
+func (c *Core) signAccessToken(payload map[string]interface{}) (string, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload := base64.StdEncoding.EncodeToString(payloadBytes)
+	digest := sha256.Sum256([]byte(accessTokenHeader + "." + encodedPayload))
+	signature, err := rsa.SignPKCS1v15(nil, c.signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return accessTokenHeader + "." + encodedPayload + "." + base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func (c *Core) verifyAccessToken(token string) (map[string]interface{}, error) {
+	splitted := strings.Split(token, ".")
+	if len(splitted) != 3 || splitted[0] != accessTokenHeader {
+		return nil, errors.New("Invalid JWT")
+	}
+	digest := sha256.Sum256([]byte(splitted[0] + "." + splitted[1]))
+	signature, err := base64.StdEncoding.DecodeString(splitted[2])
+	if err != nil {
+		return nil, err
+	}
+	if err := rsa.VerifyPKCS1v15(&c.signingKey.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, err
+	}
+	payloadBytes, err := base64.StdEncoding.DecodeString(splitted[1])
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (c *Core) createAccessToken(session *sessionInfo, refreshTokenHash1 string, parentRefreshTokenHash1 *string, antiCsrfToken *string, expiryTime uint64) (map[string]interface{}, error) {
+	timeCreated := getCurrTimeInMS()
+	if expiryTime == 0 {
+		expiryTime = timeCreated + c.config.AccessTokenValidity*1000
+	}
+	token, err := c.signAccessToken(map[string]interface{}{
+		"sessionHandle":           session.handle,
+		"userId":                  session.userId,
+		"refreshTokenHash1":       refreshTokenHash1,
+		"parentRefreshTokenHash1": parentRefreshTokenHash1,
+		"userData":                session.userDataInJWT,
+		"antiCsrfToken":           antiCsrfToken,
+		"expiryTime":              expiryTime,
+		"timeCreated":             timeCreated,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"token":       token,
+		"expiry":      expiryTime,
+		"createdTime": timeCreated,
+	}, nil
+}
+
+// createTokens issues a new refresh token, id refresh token and access token
+// for the session, in the shape returned by session creation and refresh.
+func (c *Core) createTokens(session *sessionInfo, parentRefreshTokenHash1 *string, enableAntiCsrf bool) (map[string]interface{}, error) {
+	now := getCurrTimeInMS()
+	session.expiry = now + c.config.RefreshTokenValidity*60*1000
+
+	var antiCsrfToken *string
+	if enableAntiCsrf {
+		token := newUUID()
+		antiCsrfToken = &token
+	}
+
+	refreshToken := newRandomToken()
+	c.refreshTokens[refreshToken] = &refreshTokenInfo{
+		sessionHandle:           session.handle,
+		parentRefreshTokenHash1: parentRefreshTokenHash1,
+		antiCsrfToken:           antiCsrfToken,
+	}
+	refreshTokenHash1 := hashString(refreshToken)
+	if parentRefreshTokenHash1 == nil {
+		session.refreshTokenHash1 = refreshTokenHash1
+	}
+
+	accessToken, err := c.createAccessToken(session, refreshTokenHash1, parentRefreshTokenHash1, antiCsrfToken, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response := okResponse(map[string]interface{}{
+		"session": map[string]interface{}{
+			"handle":        session.handle,
+			"userId":        session.userId,
+			"userDataInJWT": session.userDataInJWT,
+		},
+		"accessToken": accessToken,
+		"refreshToken": map[string]interface{}{
+			"token":       refreshToken,
+			"expiry":      session.expiry,
+			"createdTime": now,
+		},
+		"idRefreshToken": map[string]interface{}{
+			"token":       newUUID(),
+			"expiry":      session.expiry,
+			"createdTime": now,
+		},
+	})
+	if antiCsrfToken != nil {
+		response["antiCsrfToken"] = *antiCsrfToken
+	}
+	return response, nil
+}
+
+func (c *Core) getActiveSession(sessionHandle string) *sessionInfo {
+	session, ok := c.sessions[sessionHandle]
+	if !ok {
+		return nil
+	}
+	if session.expiry < getCurrTimeInMS() {
+		c.removeSession(sessionHandle)
+		return nil
+	}
+	return session
+}
+
+func (c *Core) removeSession(sessionHandle string) {
+	delete(c.sessions, sessionHandle)
+	for token, info := range c.refreshTokens {
+		if info.sessionHandle == sessionHandle {
+			delete(c.refreshTokens, token)
+		}
+	}
+}
+
+func (c *Core) createSession(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	enableAntiCsrf, _ := req.body["enableAntiCsrf"].(bool)
+	session := &sessionInfo{
+		handle:             newUUID(),
+		userId:             userId,
+		userDataInJWT:      req.getMap("userDataInJWT"),
+		userDataInDatabase: req.getMap("userDataInDatabase"),
+		timeCreated:        getCurrTimeInMS(),
+	}
+	c.sessions[session.handle] = session
+	response, err := c.createTokens(session, nil, enableAntiCsrf)
+	if err != nil {
+		return nil, err
+	}
+	return c.signingKeyFields(response), nil
+}
+
+func (c *Core) verifySession(req coreRequest) (map[string]interface{}, error) {
+	accessToken, err := req.requireString("accessToken")
+	if err != nil {
+		return nil, err
+	}
+	doAntiCsrfCheck, _ := req.body["doAntiCsrfCheck"].(bool)
+	enableAntiCsrf, _ := req.body["enableAntiCsrf"].(bool)
+
+	tryRefreshToken := func(msg string) (map[string]interface{}, error) {
+		return c.signingKeyFields(map[string]interface{}{
+			"status":  "TRY_REFRESH_TOKEN",
+			"message": msg,
+		}), nil
+	}
+	unauthorised := map[string]interface{}{
+		"status":  "UNAUTHORISED",
+		"message": "Either the session has ended or has been blacklisted",
+	}
+
+	payload, err := c.verifyAccessToken(accessToken)
+	if err != nil {
+		return tryRefreshToken(err.Error())
+	}
+	if expiryTime, _ := payload["expiryTime"].(float64); uint64(expiryTime) < getCurrTimeInMS() {
+		return tryRefreshToken("Access token expired")
+	}
+	if enableAntiCsrf && doAntiCsrfCheck {
+		expectedAntiCsrfToken, _ := payload["antiCsrfToken"].(string)
+		antiCsrfToken, _ := req.getString("antiCsrfToken")
+		if expectedAntiCsrfToken == "" || antiCsrfToken != expectedAntiCsrfToken {
+			return tryRefreshToken("Anti-CSRF token missing or does not match")
+		}
+	}
+
+	sessionHandle, _ := payload["sessionHandle"].(string)
+	refreshTokenHash1, _ := payload["refreshTokenHash1"].(string)
+	parentRefreshTokenHash1, _ := payload["parentRefreshTokenHash1"].(string)
+
+	response := okResponse(map[string]interface{}{
+		"session": map[string]interface{}{
+			"handle":        sessionHandle,
+			"userId":        payload["userId"],
+			"userDataInJWT": payload["userData"],
+		},
+	})
+
+	if parentRefreshTokenHash1 == "" && !c.config.AccessTokenBlacklisting {
+		return c.signingKeyFields(response), nil
+	}
+
+	session := c.getActiveSession(sessionHandle)
+	if session == nil {
+		return unauthorised, nil
+	}
+
+	if parentRefreshTokenHash1 != "" {
+		// the client has received the result of a refresh, so the new refresh
+		// token becomes the current one for this session.
+		if session.refreshTokenHash1 != parentRefreshTokenHash1 && session.refreshTokenHash1 != refreshTokenHash1 {
+			return tryRefreshToken("Using access token whose refresh token has been used")
+		}
+		session.refreshTokenHash1 = refreshTokenHash1
+
+		var antiCsrfToken *string
+		if v, ok := payload["antiCsrfToken"].(string); ok {
+			antiCsrfToken = &v
+		}
+		newAccessToken, err := c.createAccessToken(session, refreshTokenHash1, nil, antiCsrfToken, 0)
+		if err != nil {
+			return nil, err
+		}
+		response["accessToken"] = newAccessToken
+		response["session"] = map[string]interface{}{
+			"handle":        session.handle,
+			"userId":        session.userId,
+			"userDataInJWT": session.userDataInJWT,
+		}
+	}
+
+	return c.signingKeyFields(response), nil
+}
+
+func (c *Core) refreshSession(req coreRequest) (map[string]interface{}, error) {
+	refreshToken, err := req.requireString("refreshToken")
+	if err != nil {
+		return nil, err
+	}
+	enableAntiCsrf, _ := req.body["enableAntiCsrf"].(bool)
+
+	unauthorised := func(msg string) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"status":  "UNAUTHORISED",
+			"message": msg,
+		}, nil
+	}
+
+	tokenInfo, ok := c.refreshTokens[refreshToken]
+	if !ok {
+		return unauthorised("Refresh token not found. Maybe the session has been revoked or has expired?")
+	}
+	if enableAntiCsrf && tokenInfo.antiCsrfToken != nil {
+		antiCsrfToken, _ := req.getString("antiCsrfToken")
+		if antiCsrfToken != *tokenInfo.antiCsrfToken {
+			return unauthorised("Anti-CSRF token missing or does not match")
+		}
+	}
+	session := c.getActiveSession(tokenInfo.sessionHandle)
+	if session == nil {
+		return unauthorised("Session does not exist or has expired")
+	}
+
+	refreshTokenHash1 := hashString(refreshToken)
+	if session.refreshTokenHash1 != refreshTokenHash1 {
+		if tokenInfo.parentRefreshTokenHash1 == nil || *tokenInfo.parentRefreshTokenHash1 != session.refreshTokenHash1 {
+			return map[string]interface{}{
+				"status": "TOKEN_THEFT_DETECTED",
+				"session": map[string]interface{}{
+					"handle": session.handle,
+					"userId": session.userId,
+				},
+			}, nil
+		}
+		// the child of the current refresh token is being used, so the
+		// previous refresh token can not be used anymore.
+		session.refreshTokenHash1 = refreshTokenHash1
+	}
+
+	return c.createTokens(session, &refreshTokenHash1, enableAntiCsrf)
+}
+
+func (c *Core) getSessionInformation(req coreRequest) (map[string]interface{}, error) {
+	sessionHandle, err := req.requireQuery("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	session := c.getActiveSession(sessionHandle)
+	if session == nil {
+		return map[string]interface{}{
+			"status":  "UNAUTHORISED",
+			"message": "Session does not exist.",
+		}, nil
+	}
+	return okResponse(map[string]interface{}{
+		"sessionHandle":      session.handle,
+		"userId":             session.userId,
+		"userDataInDatabase": session.userDataInDatabase,
+		"userDataInJWT":      session.userDataInJWT,
+		"expiry":             session.expiry,
+		"timeCreated":        session.timeCreated,
+	}), nil
+}
+
+func (c *Core) getSessionHandlesForUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	sessionHandles := []string{}
+	for handle, session := range c.sessions {
+		if session.userId == userId && c.getActiveSession(handle) != nil {
+			sessionHandles = append(sessionHandles, handle)
+		}
+	}
+	return okResponse(map[string]interface{}{
+		"sessionHandles": sessionHandles,
+	}), nil
+}
+
+func (c *Core) removeSessions(req coreRequest) (map[string]interface{}, error) {
+	sessionHandlesRevoked := []string{}
+	if userId, ok := req.getString("userId"); ok {
+		for handle, session := range c.sessions {
+			if session.userId == userId {
+				c.removeSession(handle)
+				sessionHandlesRevoked = append(sessionHandlesRevoked, handle)
+			}
+		}
+	} else {
+		for _, handle := range req.getStringArray("sessionHandles") {
+			if _, ok := c.sessions[handle]; ok {
+				c.removeSession(handle)
+				sessionHandlesRevoked = append(sessionHandlesRevoked, handle)
+			}
+		}
+	}
+	return okResponse(map[string]interface{}{
+		"sessionHandlesRevoked": sessionHandlesRevoked,
+	}), nil
+}
+
+func (c *Core) updateSessionData(req coreRequest) (map[string]interface{}, error) {
+	sessionHandle, err := req.requireString("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	session := c.getActiveSession(sessionHandle)
+	if session == nil {
+		return map[string]interface{}{
+			"status":  "UNAUTHORISED",
+			"message": "Session does not exist.",
+		}, nil
+	}
+	session.userDataInDatabase = req.getMap("userDataInDatabase")
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) updateAccessTokenPayload(req coreRequest) (map[string]interface{}, error) {
+	sessionHandle, err := req.requireString("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	session := c.getActiveSession(sessionHandle)
+	if session == nil {
+		return map[string]interface{}{
+			"status":  "UNAUTHORISED",
+			"message": "Session does not exist.",
+		}, nil
+	}
+	session.userDataInJWT = req.getMap("userDataInJWT")
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) regenerateAccessToken(req coreRequest) (map[string]interface{}, error) {
+	accessToken, err := req.requireString("accessToken")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := c.verifyAccessToken(accessToken)
+	if err != nil {
+		return nil, badInputError{msg: err.Error()}
+	}
+	sessionHandle, _ := payload["sessionHandle"].(string)
+	session := c.getActiveSession(sessionHandle)
+	if session == nil {
+		return map[string]interface{}{
+			"status":  "UNAUTHORISED",
+			"message": "Session does not exist.",
+		}, nil
+	}
+	if _, ok := req.body["userDataInJWT"].(map[string]interface{}); ok {
+		session.userDataInJWT = req.getMap("userDataInJWT")
+	}
+
+	refreshTokenHash1, _ := payload["refreshTokenHash1"].(string)
+	var parentRefreshTokenHash1 *string
+	if v, ok := payload["parentRefreshTokenHash1"].(string); ok {
+		parentRefreshTokenHash1 = &v
+	}
+	var antiCsrfToken *string
+	if v, ok := payload["antiCsrfToken"].(string); ok {
+		antiCsrfToken = &v
+	}
+	expiryTime, _ := payload["expiryTime"].(float64)
+	newAccessToken, err := c.createAccessToken(session, refreshTokenHash1, parentRefreshTokenHash1, antiCsrfToken, uint64(expiryTime))
+	if err != nil {
+		return nil, err
+	}
+	return okResponse(map[string]interface{}{
+		"session": map[string]interface{}{
+			"handle":        session.handle,
+			"userId":        session.userId,
+			"userDataInJWT": session.userDataInJWT,
+		},
+		"accessToken": newAccessToken,
+	}), nil
+}
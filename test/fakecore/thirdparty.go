@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+func (c *Core) thirdPartySignInUp(req coreRequest) (map[string]interface{}, error) {
+	thirdPartyId, err := req.requireString("thirdPartyId")
+	if err != nil {
+		return nil, err
+	}
+	thirdPartyUserId, err := req.requireString("thirdPartyUserId")
+	if err != nil {
+		return nil, err
+	}
+	email, ok := req.getMap("email")["id"].(string)
+	if !ok {
+		return nil, badInputError{msg: "Field name 'email' is invalid in JSON input"}
+	}
+	email = normaliseEmail(email)
+
+	createdNewUser := false
+	u := c.findUser(thirdPartyRecipeId, func(u *user) bool {
+		return u.tenantId == req.tenantId && u.thirdPartyId == thirdPartyId && u.thirdPartyUserId == thirdPartyUserId
+	})
+	if u == nil {
+		createdNewUser = true
+		u = c.addUser(&user{
+			recipeId:         thirdPartyRecipeId,
+			tenantId:         req.tenantId,
+			thirdPartyId:     thirdPartyId,
+			thirdPartyUserId: thirdPartyUserId,
+		})
+	}
+	u.email = &email
+
+	return okResponse(map[string]interface{}{
+		"createdNewUser": createdNewUser,
+		"user":           c.userToJSON(u),
+	}), nil
+}
+
+func (c *Core) getThirdPartyUsersByEmail(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.requireQuery("email")
+	if err != nil {
+		return nil, err
+	}
+	email = normaliseEmail(email)
+	users := []map[string]interface{}{}
+	for _, u := range c.sortedUsers(true) {
+		if u.recipeId == thirdPartyRecipeId && equalsPointer(u.email, email) {
+			users = append(users, c.userToJSON(u))
+		}
+	}
+	return okResponse(map[string]interface{}{
+		"users": users,
+	}), nil
+}
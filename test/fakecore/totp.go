@@ -0,0 +1,216 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	defaultTotpPeriod       = 30
+	defaultTotpSkew         = 1
+	totpSecretLengthInBytes = 20
+	totpCodeModulo          = 1000000
+	totpCodeFormat          = "%06d"
+)
+
+type totpDevice struct {
+	name     string
+	secret   string
+	period   int64
+	skew     int64
+	verified bool
+	// lastUsedCounter is the time step of the last code that was accepted, so
+	// that a code can not be used twice.
+	lastUsedCounter int64
+}
+
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTotp(secret string, counter int64) (string, error) {
+	key, err := totpSecretEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf(totpCodeFormat, value%totpCodeModulo), nil
+}
+
+// checkTotp returns true if the code is valid for the device within its skew window
+// and was not used before.
+func checkTotp(device *totpDevice, code string) bool {
+	currentCounter := int64(getCurrTimeInMS()/1000) / device.period
+	for counter := currentCounter - device.skew; counter <= currentCounter+device.skew; counter++ {
+		if counter <= device.lastUsedCounter {
+			continue
+		}
+		expected, err := generateTotp(device.secret, counter)
+		if err == nil && hmac.Equal([]byte(expected), []byte(code)) {
+			device.lastUsedCounter = counter
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Core) findTotpDevice(userId string, deviceName string) *totpDevice {
+	for _, device := range c.totpDevices[userId] {
+		if device.name == deviceName {
+			return device
+		}
+	}
+	return nil
+}
+
+func (c *Core) createTotpDevice(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	deviceName, err := req.requireString("deviceName")
+	if err != nil {
+		return nil, err
+	}
+	period := req.getInt("period", defaultTotpPeriod)
+	skew := req.getInt("skew", defaultTotpSkew)
+	if period < 1 || skew < 0 {
+		return nil, badInputError{msg: "period must be positive and skew must not be negative"}
+	}
+	if c.findTotpDevice(userId, deviceName) != nil {
+		return statusResponse("DEVICE_ALREADY_EXISTS_ERROR"), nil
+	}
+	device := &totpDevice{
+		name:            deviceName,
+		secret:          totpSecretEncoding.EncodeToString(randomBytes(totpSecretLengthInBytes)),
+		period:          period,
+		skew:            skew,
+		lastUsedCounter: -1,
+	}
+	c.totpDevices[userId] = append(c.totpDevices[userId], device)
+	return okResponse(map[string]interface{}{
+		"secret": device.secret,
+	}), nil
+}
+
+func (c *Core) listTotpDevices(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	devices := []map[string]interface{}{}
+	for _, device := range c.totpDevices[userId] {
+		devices = append(devices, map[string]interface{}{
+			"name":     device.name,
+			"period":   device.period,
+			"skew":     device.skew,
+			"verified": device.verified,
+		})
+	}
+	return okResponse(map[string]interface{}{
+		"devices": devices,
+	}), nil
+}
+
+func (c *Core) removeTotpDevice(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	deviceName, err := req.requireString("deviceName")
+	if err != nil {
+		return nil, err
+	}
+	didDeviceExist := false
+	devices := []*totpDevice{}
+	for _, device := range c.totpDevices[userId] {
+		if device.name == deviceName {
+			didDeviceExist = true
+		} else {
+			devices = append(devices, device)
+		}
+	}
+	c.totpDevices[userId] = devices
+	return okResponse(map[string]interface{}{
+		"didDeviceExist": didDeviceExist,
+	}), nil
+}
+
+func (c *Core) verifyTotpDevice(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	deviceName, err := req.requireString("deviceName")
+	if err != nil {
+		return nil, err
+	}
+	totp, err := req.requireString("totp")
+	if err != nil {
+		return nil, err
+	}
+	device := c.findTotpDevice(userId, deviceName)
+	if device == nil {
+		return statusResponse("UNKNOWN_DEVICE_ERROR"), nil
+	}
+	if device.verified {
+		return okResponse(map[string]interface{}{
+			"wasAlreadyVerified": true,
+		}), nil
+	}
+	if !checkTotp(device, totp) {
+		return statusResponse("INVALID_TOTP_ERROR"), nil
+	}
+	device.verified = true
+	return okResponse(map[string]interface{}{
+		"wasAlreadyVerified": false,
+	}), nil
+}
+
+func (c *Core) verifyTotp(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	totp, err := req.requireString("totp")
+	if err != nil {
+		return nil, err
+	}
+	hasVerifiedDevice := false
+	for _, device := range c.totpDevices[userId] {
+		if !device.verified {
+			continue
+		}
+		hasVerifiedDevice = true
+		if checkTotp(device, totp) {
+			return statusResponse("OK"), nil
+		}
+	}
+	if !hasVerifiedDevice {
+		return statusResponse("TOTP_NOT_ENABLED_ERROR"), nil
+	}
+	return statusResponse("INVALID_TOTP_ERROR"), nil
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+type userIdMapping struct {
+	superTokensUserId  string
+	externalUserId     string
+	externalUserIdInfo *string
+}
+
+func (c *Core) findUserIdMapping(userId string, userIdType string) *userIdMapping {
+	if userIdType != "EXTERNAL" {
+		for _, mapping := range c.userIdMappings {
+			if mapping.superTokensUserId == userId {
+				return mapping
+			}
+		}
+	}
+	if userIdType != "SUPERTOKENS" {
+		for _, mapping := range c.userIdMappings {
+			if mapping.externalUserId == userId {
+				return mapping
+			}
+		}
+	}
+	return nil
+}
+
+// resolveUserId converts an external user id to the SuperTokens user id. Other
+// ids are returned as they are.
+func (c *Core) resolveUserId(userId string) string {
+	if _, ok := c.users[userId]; ok {
+		return userId
+	}
+	mapping := c.findUserIdMapping(userId, "EXTERNAL")
+	if mapping == nil {
+		return userId
+	}
+	return mapping.superTokensUserId
+}
+
+// getExternalUserId returns the external user id of a SuperTokens user if it
+// has one, and the SuperTokens user id otherwise.
+func (c *Core) getExternalUserId(superTokensUserId string) string {
+	mapping := c.findUserIdMapping(superTokensUserId, "SUPERTOKENS")
+	if mapping == nil {
+		return superTokensUserId
+	}
+	return mapping.externalUserId
+}
+
+func (c *Core) removeUserIdMappings(superTokensUserId string) {
+	mappings := []*userIdMapping{}
+	for _, mapping := range c.userIdMappings {
+		if mapping.superTokensUserId != superTokensUserId {
+			mappings = append(mappings, mapping)
+		}
+	}
+	c.userIdMappings = mappings
+}
+
+// checkUserIdNotInUse returns an error if other recipes store data for the user
+// id. Creating or deleting a mapping for such an id requires force.
+func (c *Core) checkUserIdNotInUse(userId string) error {
+	if _, ok := c.userMetadata[userId]; ok {
+		return badInputError{msg: "UserId is already in use in UserMetadata recipe"}
+	}
+	if len(c.userRoles[userId]) > 0 {
+		return badInputError{msg: "UserId is already in use in UserRoles recipe"}
+	}
+	return nil
+}
+
+func (c *Core) createUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	superTokensUserId, err := req.requireString("superTokensUserId")
+	if err != nil {
+		return nil, err
+	}
+	externalUserId, err := req.requireString("externalUserId")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.users[superTokensUserId]; !ok {
+		return statusResponse("UNKNOWN_SUPERTOKENS_USER_ID_ERROR"), nil
+	}
+
+	if force, _ := req.body["force"].(bool); !force {
+		if err := c.checkUserIdNotInUse(superTokensUserId); err != nil {
+			return nil, err
+		}
+	}
+
+	doesSuperTokensUserIdExist := c.findUserIdMapping(superTokensUserId, "SUPERTOKENS") != nil
+	doesExternalUserIdExist := c.findUserIdMapping(externalUserId, "EXTERNAL") != nil
+	if doesSuperTokensUserIdExist || doesExternalUserIdExist {
+		return map[string]interface{}{
+			"status":                     "USER_ID_MAPPING_ALREADY_EXISTS_ERROR",
+			"doesSuperTokensUserIdExist": doesSuperTokensUserIdExist,
+			"doesExternalUserIdExist":    doesExternalUserIdExist,
+		}, nil
+	}
+
+	mapping := &userIdMapping{
+		superTokensUserId: superTokensUserId,
+		externalUserId:    externalUserId,
+	}
+	if externalUserIdInfo, ok := req.getString("externalUserIdInfo"); ok {
+		mapping.externalUserIdInfo = &externalUserIdInfo
+	}
+	c.userIdMappings = append(c.userIdMappings, mapping)
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) getUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	mapping := c.findUserIdMapping(userId, req.query.Get("userIdType"))
+	if mapping == nil {
+		return statusResponse("UNKNOWN_MAPPING_ERROR"), nil
+	}
+	response := okResponse(map[string]interface{}{
+		"superTokensUserId": mapping.superTokensUserId,
+		"externalUserId":    mapping.externalUserId,
+	})
+	if mapping.externalUserIdInfo != nil {
+		response["externalUserIdInfo"] = *mapping.externalUserIdInfo
+	}
+	return response, nil
+}
+
+func (c *Core) deleteUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	userIdType, _ := req.getString("userIdType")
+	mapping := c.findUserIdMapping(userId, userIdType)
+	if mapping != nil {
+		if force, _ := req.body["force"].(bool); !force {
+			if err := c.checkUserIdNotInUse(mapping.externalUserId); err != nil {
+				return nil, err
+			}
+		}
+		c.removeUserIdMappings(mapping.superTokensUserId)
+	}
+	return okResponse(map[string]interface{}{
+		"didMappingExist": mapping != nil,
+	}), nil
+}
+
+func (c *Core) updateExternalUserIdInfo(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	userIdType, _ := req.getString("userIdType")
+	mapping := c.findUserIdMapping(userId, userIdType)
+	if mapping == nil {
+		return statusResponse("UNKNOWN_MAPPING_ERROR"), nil
+	}
+	if externalUserIdInfo, ok := req.getString("externalUserIdInfo"); ok {
+		mapping.externalUserIdInfo = &externalUserIdInfo
+	} else {
+		mapping.externalUserIdInfo = nil
+	}
+	return statusResponse("OK"), nil
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+func (c *Core) getUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := c.userMetadata[userId]
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	return okResponse(map[string]interface{}{
+		"metadata": metadata,
+	}), nil
+}
+
+// updateUserMetadata does a shallow merge of metadataUpdate into the stored
+// metadata. Top level keys set to null are removed.
+func (c *Core) updateUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	metadataUpdate, ok := req.body["metadataUpdate"].(map[string]interface{})
+	if !ok {
+		return nil, badInputError{msg: "Field name 'metadataUpdate' is invalid in JSON input"}
+	}
+	metadata := copyMap(c.userMetadata[userId])
+	for k, v := range metadataUpdate {
+		if v == nil {
+			delete(metadata, k)
+		} else {
+			metadata[k] = v
+		}
+	}
+	c.userMetadata[userId] = metadata
+	return okResponse(map[string]interface{}{
+		"metadata": metadata,
+	}), nil
+}
+
+func (c *Core) clearUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	delete(c.userMetadata, userId)
+	return statusResponse("OK"), nil
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import "sort"
+
+func setToSortedArray(set map[string]bool) []string {
+	result := []string{}
+	for k := range set {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (c *Core) createNewRoleOrAddPermissions(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.requireString("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, createdNewRole := c.roles[role]
+	createdNewRole = !createdNewRole
+	if createdNewRole {
+		permissions = map[string]bool{}
+		c.roles[role] = permissions
+	}
+	for _, permission := range req.getStringArray("permissions") {
+		permissions[permission] = true
+	}
+	return okResponse(map[string]interface{}{
+		"createdNewRole": createdNewRole,
+	}), nil
+}
+
+func (c *Core) getPermissionsForRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.requireQuery("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, ok := c.roles[role]
+	if !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	return okResponse(map[string]interface{}{
+		"permissions": setToSortedArray(permissions),
+	}), nil
+}
+
+func (c *Core) removePermissionsFromRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.requireString("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, ok := c.roles[role]
+	if !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	if _, ok := req.body["permissions"]; ok {
+		for _, permission := range req.getStringArray("permissions") {
+			delete(permissions, permission)
+		}
+	} else {
+		c.roles[role] = map[string]bool{}
+	}
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) getRolesThatHavePermission(req coreRequest) (map[string]interface{}, error) {
+	permission, err := req.requireQuery("permission")
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]bool{}
+	for role, permissions := range c.roles {
+		if permissions[permission] {
+			roles[role] = true
+		}
+	}
+	return okResponse(map[string]interface{}{
+		"roles": setToSortedArray(roles),
+	}), nil
+}
+
+func (c *Core) deleteRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.requireString("role")
+	if err != nil {
+		return nil, err
+	}
+	_, didRoleExist := c.roles[role]
+	delete(c.roles, role)
+	for _, roles := range c.userRoles {
+		delete(roles, role)
+	}
+	return okResponse(map[string]interface{}{
+		"didRoleExist": didRoleExist,
+	}), nil
+}
+
+func (c *Core) getAllRoles(req coreRequest) (map[string]interface{}, error) {
+	roles := map[string]bool{}
+	for role := range c.roles {
+		roles[role] = true
+	}
+	return okResponse(map[string]interface{}{
+		"roles": setToSortedArray(roles),
+	}), nil
+}
+
+func (c *Core) addRoleToUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	role, err := req.requireString("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	roles, ok := c.userRoles[userId]
+	if !ok {
+		roles = map[string]bool{}
+		c.userRoles[userId] = roles
+	}
+	didUserAlreadyHaveRole := roles[role]
+	roles[role] = true
+	return okResponse(map[string]interface{}{
+		"didUserAlreadyHaveRole": didUserAlreadyHaveRole,
+	}), nil
+}
+
+func (c *Core) removeUserRole(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	role, err := req.requireString("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	didUserHaveRole := c.userRoles[userId][role]
+	delete(c.userRoles[userId], role)
+	return okResponse(map[string]interface{}{
+		"didUserHaveRole": didUserHaveRole,
+	}), nil
+}
+
+func (c *Core) getRolesForUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireQuery("userId")
+	if err != nil {
+		return nil, err
+	}
+	return okResponse(map[string]interface{}{
+		"roles": setToSortedArray(c.userRoles[userId]),
+	}), nil
+}
+
+func (c *Core) getUsersThatHaveRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.requireQuery("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	users := map[string]bool{}
+	for userId, roles := range c.userRoles {
+		if roles[role] {
+			users[userId] = true
+		}
+	}
+	return okResponse(map[string]interface{}{
+		"users": setToSortedArray(users),
+	}), nil
+}
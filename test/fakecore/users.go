@@ -0,0 +1,308 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	emailPasswordRecipeId = "emailpassword"
+	thirdPartyRecipeId    = "thirdparty"
+	passwordlessRecipeId  = "passwordless"
+)
+
+type user struct {
+	id               string
+	recipeId         string
+	tenantId         string
+	timeJoined       uint64
+	sequence         int
+	email            *string
+	phoneNumber      *string
+	passwordHash     string
+	thirdPartyId     string
+	thirdPartyUserId string
+	// primaryUserId is the id of the primary user this user is linked to, or
+	// empty if it is not linked. Primary users are linked to themselves.
+	primaryUserId string
+}
+
+func (c *Core) addUser(u *user) *user {
+	u.id = newUUID()
+	u.timeJoined = getCurrTimeInMS()
+	u.sequence = len(c.users)
+	for _, existing := range c.users {
+		if existing.sequence >= u.sequence {
+			u.sequence = existing.sequence + 1
+		}
+	}
+	c.users[u.id] = u
+	return u
+}
+
+func (c *Core) userToJSON(u *user) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":         c.getExternalUserId(u.id),
+		"timeJoined": u.timeJoined,
+	}
+	if u.email != nil {
+		result["email"] = *u.email
+	}
+	if u.phoneNumber != nil {
+		result["phoneNumber"] = *u.phoneNumber
+	}
+	if u.recipeId == thirdPartyRecipeId {
+		result["thirdParty"] = map[string]interface{}{
+			"id":     u.thirdPartyId,
+			"userId": u.thirdPartyUserId,
+		}
+	}
+	return result
+}
+
+// findUser returns the first user of the recipe (or of any recipe if recipeId
+// is empty) that matches the condition.
+func (c *Core) findUser(recipeId string, condition func(u *user) bool) *user {
+	for _, u := range c.sortedUsers(true) {
+		if (recipeId == "" || u.recipeId == recipeId) && condition(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (c *Core) findUserById(recipeId string, userId string) *user {
+	u, ok := c.users[c.resolveUserId(userId)]
+	if !ok || (recipeId != "" && u.recipeId != recipeId) {
+		return nil
+	}
+	return u
+}
+
+func (c *Core) sortedUsers(ascending bool) []*user {
+	result := []*user{}
+	for _, u := range c.users {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if ascending {
+			return result[i].sequence < result[j].sequence
+		}
+		return result[i].sequence > result[j].sequence
+	})
+	return result
+}
+
+func equalsPointer(value *string, other string) bool {
+	return value != nil && *value == other
+}
+
+func (c *Core) getUser(req coreRequest) (map[string]interface{}, error) {
+	var u *user
+	notFoundStatus := "UNKNOWN_USER_ID_ERROR"
+	if userId := req.query.Get("userId"); userId != "" {
+		u = c.findUserById(req.rid, userId)
+	} else if email := normaliseEmail(req.query.Get("email")); email != "" {
+		notFoundStatus = "UNKNOWN_EMAIL_ERROR"
+		u = c.findUser(req.rid, func(u *user) bool {
+			return u.recipeId != thirdPartyRecipeId && equalsPointer(u.email, email)
+		})
+	} else if phoneNumber := req.query.Get("phoneNumber"); phoneNumber != "" {
+		notFoundStatus = "UNKNOWN_PHONE_NUMBER_ERROR"
+		u = c.findUser(req.rid, func(u *user) bool {
+			return equalsPointer(u.phoneNumber, phoneNumber)
+		})
+	} else if thirdPartyId := req.query.Get("thirdPartyId"); thirdPartyId != "" {
+		notFoundStatus = "UNKNOWN_THIRD_PARTY_USER_ERROR"
+		thirdPartyUserId := req.query.Get("thirdPartyUserId")
+		u = c.findUser(thirdPartyRecipeId, func(u *user) bool {
+			return u.thirdPartyId == thirdPartyId && u.thirdPartyUserId == thirdPartyUserId
+		})
+	} else {
+		return nil, badInputError{msg: "Please provide one of userId, email, phoneNumber or thirdPartyId"}
+	}
+	if u == nil {
+		return statusResponse(notFoundStatus), nil
+	}
+	return okResponse(map[string]interface{}{
+		"user": c.userToJSON(u),
+	}), nil
+}
+
+func (c *Core) updateUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := c.findUserById(req.rid, userId)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+
+	emailValue, hasEmail := req.body["email"]
+	phoneNumberValue, hasPhoneNumber := req.body["phoneNumber"]
+	if u.recipeId == passwordlessRecipeId {
+		clearsEmail := (hasEmail && emailValue == nil) || (!hasEmail && u.email == nil)
+		clearsPhoneNumber := (hasPhoneNumber && phoneNumberValue == nil) || (!hasPhoneNumber && u.phoneNumber == nil)
+		if clearsEmail && clearsPhoneNumber {
+			return nil, badInputError{msg: "You cannot clear both email and phone number of a user"}
+		}
+	}
+
+	if email, ok := emailValue.(string); ok {
+		email = normaliseEmail(email)
+		existing := c.findUser(u.recipeId, func(other *user) bool {
+			return other.id != u.id && equalsPointer(other.email, email)
+		})
+		if existing != nil {
+			return statusResponse("EMAIL_ALREADY_EXISTS_ERROR"), nil
+		}
+	}
+	if phoneNumber, ok := phoneNumberValue.(string); ok && u.recipeId == passwordlessRecipeId {
+		existing := c.findUser(u.recipeId, func(other *user) bool {
+			return other.id != u.id && equalsPointer(other.phoneNumber, phoneNumber)
+		})
+		if existing != nil {
+			return statusResponse("PHONE_NUMBER_ALREADY_EXISTS_ERROR"), nil
+		}
+	}
+
+	if email, ok := emailValue.(string); ok {
+		email = normaliseEmail(email)
+		u.email = &email
+	} else if hasEmail && u.recipeId == passwordlessRecipeId {
+		u.email = nil
+	}
+	if hasPhoneNumber && u.recipeId == passwordlessRecipeId {
+		if phoneNumber, ok := phoneNumberValue.(string); ok {
+			u.phoneNumber = &phoneNumber
+		} else {
+			u.phoneNumber = nil
+		}
+	}
+	if password, ok := req.getString("password"); ok && u.recipeId == emailPasswordRecipeId {
+		u.passwordHash = hashString(password)
+	}
+	return statusResponse("OK"), nil
+}
+
+func (c *Core) getUsers(req coreRequest) (map[string]interface{}, error) {
+	ascending := req.query.Get("timeJoinedOrder") != "DESC"
+
+	limit := 100
+	if v := req.query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			return nil, badInputError{msg: "limit must a positive integer with min value 1"}
+		}
+		if parsed > 1000 {
+			return nil, badInputError{msg: "max limit allowed is 1000"}
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if v := req.query.Get("paginationToken"); v != "" {
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil {
+			return nil, badInputError{msg: "invalid pagination token"}
+		}
+	}
+
+	users := c.filterUsersByRecipeIds(c.sortedUsers(ascending), req.query.Get("includeRecipeIds"))
+
+	result := []map[string]interface{}{}
+	for i := offset; i < len(users) && i < offset+limit; i++ {
+		result = append(result, map[string]interface{}{
+			"recipeId": users[i].recipeId,
+			"user":     c.userToJSON(users[i]),
+		})
+	}
+	response := okResponse(map[string]interface{}{
+		"users": result,
+	})
+	if offset+limit < len(users) {
+		response["nextPaginationToken"] = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + limit)))
+	}
+	return response, nil
+}
+
+func (c *Core) filterUsersByRecipeIds(users []*user, includeRecipeIds string) []*user {
+	if includeRecipeIds == "" {
+		return users
+	}
+	recipeIds := strings.Split(includeRecipeIds, ",")
+	result := []*user{}
+	for _, u := range users {
+		if contains(recipeIds, u.recipeId) {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+func (c *Core) getUserCount(req coreRequest) (map[string]interface{}, error) {
+	users := c.filterUsersByRecipeIds(c.sortedUsers(true), req.query.Get("includeRecipeIds"))
+	return okResponse(map[string]interface{}{
+		"count": len(users),
+	}), nil
+}
+
+func (c *Core) deleteUser(req coreRequest) (map[string]interface{}, error) {
+	userId, err := req.requireString("userId")
+	if err != nil {
+		return nil, err
+	}
+	superTokensUserId := c.resolveUserId(userId)
+	userIds := []string{superTokensUserId, c.getExternalUserId(superTokensUserId)}
+
+	delete(c.users, superTokensUserId)
+	for handle, session := range c.sessions {
+		if contains(userIds, session.userId) {
+			c.removeSession(handle)
+		}
+	}
+	for _, id := range userIds {
+		delete(c.userMetadata, id)
+		delete(c.userRoles, id)
+		delete(c.totpDevices, id)
+	}
+	for key := range c.verifiedEmails {
+		if contains(userIds, strings.SplitN(key, "\n", 2)[0]) {
+			delete(c.verifiedEmails, key)
+		}
+	}
+	for token, info := range c.emailVerificationTokens {
+		if contains(userIds, info.userId) {
+			delete(c.emailVerificationTokens, token)
+		}
+	}
+	for token, info := range c.passwordResetTokens {
+		if info.userId == superTokensUserId {
+			delete(c.passwordResetTokens, token)
+		}
+	}
+	c.removeUserIdMappings(superTokensUserId)
+	return statusResponse("OK"), nil
+}
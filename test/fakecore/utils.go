@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func getCurrTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / 1000000)
+}
+
+func contains(arr []string, item string) bool {
+	for _, v := range arr {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+func randomBytes(size int) []byte {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func newUUID() string {
+	b := randomBytes(16)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func newRandomToken() string {
+	return base64.RawURLEncoding.EncodeToString(randomBytes(32))
+}
+
+func newNumericCode(length int) string {
+	code := ""
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			panic(err)
+		}
+		code += n.String()
+	}
+	return code
+}
+
+// normaliseEmail matches the normalisation done by the core before emails are stored or compared.
+func normaliseEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func hashString(value string) string {
+	h := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(h[:])
+}
+
+// Set changes a config value using the key names of the core's config.yaml,
+// for example "access_token_validity".
+func (config *Config) Set(key string, value string) error {
+	value = strings.Trim(strings.TrimSpace(value), "\"")
+	switch key {
+	case "access_token_validity":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.AccessTokenValidity = v
+	case "refresh_token_validity":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.RefreshTokenValidity = v
+	case "access_token_blacklisting":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		config.AccessTokenBlacklisting = v
+	case "passwordless_code_lifetime":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.PasswordlessCodeLifetime = v
+	case "passwordless_max_code_input_attempts":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		config.PasswordlessMaxCodeInputAttempts = v
+	case "api_keys":
+		config.APIKeys = strings.Split(value, ",")
+	default:
+		return fmt.Errorf("config key %s is not supported by the fake core", key)
+	}
+	return nil
+}
+
+func (req coreRequest) getString(key string) (string, bool) {
+	v, ok := req.body[key].(string)
+	return v, ok
+}
+
+// getInt returns the number in the body, or defaultValue if there is none.
+func (req coreRequest) getInt(key string, defaultValue int64) int64 {
+	if value, ok := req.body[key].(float64); ok {
+		return int64(value)
+	}
+	return defaultValue
+}
+
+func (req coreRequest) requireString(key string) (string, error) {
+	v, ok := req.getString(key)
+	if !ok {
+		return "", badInputError{msg: "Field name '" + key + "' is invalid in JSON input"}
+	}
+	return v, nil
+}
+
+func (req coreRequest) getStringArray(key string) []string {
+	result := []string{}
+	values, _ := req.body[key].([]interface{})
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func (req coreRequest) getMap(key string) map[string]interface{} {
+	v, ok := req.body[key].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return v
+}
+
+func (req coreRequest) requireQuery(key string) (string, error) {
+	v := req.query.Get(key)
+	if v == "" {
+		return "", badInputError{msg: "Field name '" + key + "' is missing in GET request"}
+	}
+	return v, nil
+}
+
+func okResponse(fields map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		"status": "OK",
+	}
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
+
+func statusResponse(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"status": status,
+	}
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
 	"gopkg.in/h2non/gock.v1"
 )
 
@@ -56,11 +57,38 @@ func getListOfPids() []string {
 	return result
 }
 
+// If the SUPERTOKENS_FAKE_CORE env var is set to anything other than "false",
+// the functions below start an in-memory fake core (see test/fakecore) instead
+// of the one installed in INSTALL_DIR. CI sets it in the "Run tests against the
+// fake core" workflow; the "Run tests" workflow uses the real core.
+var (
+	fakeCoreConfig  = fakecore.Config{}
+	fakeCoreServers = map[string]*fakecore.Server{}
+)
+
+func isUsingFakeCore() bool {
+	value, exists := os.LookupEnv("SUPERTOKENS_FAKE_CORE")
+	return exists && value != "false"
+}
+
 func SetUpST() {
+	if isUsingFakeCore() {
+		fakeCoreConfig = fakecore.Config{}
+		return
+	}
 	shellout(true, "cp", "temp/config.yaml", "./config.yaml")
 }
 
 func StartUpST(host string, port string) string {
+	if isUsingFakeCore() {
+		server, err := fakecore.NewServerOnAddress(host+":"+port, fakeCoreConfig)
+		if err != nil {
+			panic(err)
+		}
+		pid := "fake-" + host + ":" + port
+		fakeCoreServers[pid] = server
+		return pid
+	}
 	pidsBefore := getListOfPids()
 	command := fmt.Sprintf(`java -Djava.security.egd=file:/dev/urandom -classpath "./core/*:./plugin-interface/*" io.supertokens.Main ./ DEV host=%s port=%s test_mode`, host, port)
 	startTime := getCurrTimeInMS()
@@ -147,6 +175,10 @@ func itemExists(arr []string, item string) bool {
 }
 
 func CleanST() {
+	if isUsingFakeCore() {
+		fakeCoreConfig = fakecore.Config{}
+		return
+	}
 	shellout(true, "rm", "config.yaml")
 	shellout(true, "rm", "-rf", ".webserver-temp-*")
 	shellout(true, "rm", "-rf", ".started")
@@ -176,6 +208,10 @@ func MaxVersion(version1 string, version2 string) string {
 }
 
 func KillAllST() {
+	for pid, server := range fakeCoreServers {
+		server.Close()
+		delete(fakeCoreServers, pid)
+	}
 	pids := getListOfPids()
 	for i := 0; i < len(pids); i++ {
 		stopST(pids[i])
@@ -374,6 +410,12 @@ func getInstallationDir() string {
 }
 
 func SetKeyValueInConfig(key string, value string) {
+	if isUsingFakeCore() {
+		if err := fakeCoreConfig.Set(key, value); err != nil {
+			panic(err)
+		}
+		return
+	}
 	installationPath := getInstallationDir()
 	pathToConfigYamlFile := installationPath + "/config.yaml"
 	f, err := os.OpenFile(pathToConfigYamlFile, os.O_APPEND|os.O_WRONLY, 0600)